@@ -2,6 +2,10 @@
 package sqlite3
 
 // Code generated by xo. DO NOT EDIT.
+// Template: go
+// Version: 0.0.0-dev
+// Source: sqlite3:a_bit_of_everything.db
+// Schema: sha256:e9014833be39ad68
 
 import (
 	"database/sql"
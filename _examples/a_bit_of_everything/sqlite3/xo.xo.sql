@@ -1,4 +1,8 @@
 -- Generated by xo for the a_bit_of_everything.db schema.
+-- Template: createdb
+-- Version: 0.0.0-dev
+-- Source: sqlite3:a_bit_of_everything.db
+-- Schema: sha256:e9014833be39ad68
 
 -- table a_bit_of_everything
 CREATE TABLE a_bit_of_everything (
@@ -2,6 +2,10 @@
 package sqlite3
 
 // Code generated by xo. DO NOT EDIT.
+// Template: go
+// Version: 0.0.0-dev
+// Source: sqlite3:booktest.db
+// Schema: sha256:0c1a4081ee638587
 
 import (
 	"context"
@@ -1,6 +1,10 @@
 package sqlite3
 
 // Code generated by xo. DO NOT EDIT.
+// Template: go
+// Version: 0.0.0-dev
+// Source: sqlite3:booktest.db
+// Schema: sha256:cf411ed1c97ef732
 
 import (
 	"context"
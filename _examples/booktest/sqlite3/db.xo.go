@@ -1,6 +1,10 @@
 package sqlite3
 
 // Code generated by xo. DO NOT EDIT.
+// Template: go
+// Version: 0.0.0-dev
+// Source: sqlite3:booktest.db
+// Schema: sha256:0c1a4081ee638587
 
 import (
 	"context"
@@ -95,6 +99,8 @@ const (
 	ErrDoesNotExist Error = "does not exist"
 	// ErrMarkedForDeletion is the marked for deletion error.
 	ErrMarkedForDeletion Error = "marked for deletion"
+	// ErrVersionConflict is the version conflict error.
+	ErrVersionConflict Error = "version conflict"
 )
 
 // ErrInsertFailed is the insert failed error.
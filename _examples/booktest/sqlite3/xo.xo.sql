@@ -1,4 +1,8 @@
 -- Generated by xo for the booktest.db schema.
+-- Template: createdb
+-- Version: 0.0.0-dev
+-- Source: sqlite3:booktest.db
+-- Schema: sha256:0c1a4081ee638587
 
 -- table authors
 CREATE TABLE authors (
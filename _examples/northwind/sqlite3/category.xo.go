@@ -2,6 +2,10 @@
 package sqlite3
 
 // Code generated by xo. DO NOT EDIT.
+// Template: go
+// Version: 0.0.0-dev
+// Source: sqlite3:northwind.db
+// Schema: sha256:62e5e896d2d0713e
 
 import (
 	"context"
@@ -1,4 +1,8 @@
 -- Generated by xo for the northwind.db schema.
+-- Template: createdb
+-- Version: 0.0.0-dev
+-- Source: sqlite3:northwind.db
+-- Schema: sha256:62e5e896d2d0713e
 
 -- table categories
 CREATE TABLE categories (
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Setenv("XO_TEST_DB", "/tmp/db.sqlite3")
+	tests := []struct {
+		name   string
+		config string
+		exp    []string
+		err    bool
+	}{
+		{
+			name:   "schema",
+			config: "targets:\n  - dsn: sq:db.sqlite3\n    out: models\n",
+			exp:    []string{"schema --out=models sq:db.sqlite3"},
+		},
+		{
+			name:   "flags",
+			config: "targets:\n  - name: models\n    dsn: sq:$XO_TEST_DB\n    out: models\n    flags:\n      go-context: both\n      exclude: [a, b.c]\n      single: models.go\n      go-int32: int\n",
+			exp:    []string{"schema --out=models --exclude=a --exclude=b.c --go-context=both --go-int32=int --single=models.go sq:/tmp/db.sqlite3"},
+		},
+		{
+			name:   "multiple",
+			config: "targets:\n  - dsn: pg://\n    out: models\n    src: tpl\n  - dsn: pg://\n    out: docs\n    template: dot\n    flags:\n      dot-direction: false\n  - name: q\n    mode: query\n    dsn: pg://\n    out: models\n    flags:\n      query-file: q.sql\n",
+			exp: []string{
+				"schema --src tpl --out=models pg://",
+				"schema --template dot --out=docs --dot-direction=false pg://",
+				"query --out=models --query-file=q.sql pg://",
+			},
+		},
+		{
+			name:   "go and python",
+			config: "targets:\n  - name: go\n    dsn: pg://\n    out: models\n  - name: python\n    dsn: pg://\n    template: python\n    out: python/models\n    flags:\n      python-paginate: true\n      python-esc: [table, column]\n",
+			exp: []string{
+				"schema --out=models pg://",
+				"schema --template python --out=python/models --python-esc=table --python-esc=column --python-paginate=true pg://",
+			},
+		},
+		{
+			name:   "no targets",
+			config: "targets: []\n",
+			err:    true,
+		},
+		{
+			name:   "no out",
+			config: "targets:\n  - dsn: pg://\n",
+			err:    true,
+		},
+		{
+			name:   "no dsn",
+			config: "targets:\n  - out: models\n",
+			err:    true,
+		},
+		{
+			name:   "invalid mode",
+			config: "targets:\n  - dsn: pg://\n    out: models\n    mode: dump\n",
+			err:    true,
+		},
+		{
+			name:   "no query",
+			config: "targets:\n  - dsn: pg://\n    out: models\n    mode: query\n",
+			err:    true,
+		},
+		{
+			name:   "duplicate name",
+			config: "targets:\n  - dsn: pg://\n    out: models\n  - dsn: pg://\n    out: models\n",
+			err:    true,
+		},
+		{
+			name:   "out flag",
+			config: "targets:\n  - dsn: pg://\n    out: models\n    flags:\n      out: other\n",
+			err:    true,
+		},
+		{
+			name:   "unknown field",
+			config: "targets:\n  - dsn: pg://\n    output: models\n",
+			err:    true,
+		},
+	}
+	for i, test := range tests {
+		name := filepath.Join(t.TempDir(), ConfigFile)
+		if err := os.WriteFile(name, []byte(test.config), 0o644); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		c, err := LoadConfig(name)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%s) expected error, got nil", i, test.name)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		if len(c.Targets) != len(test.exp) {
+			t.Fatalf("test %d (%s) expected %d targets, got: %d", i, test.name, len(test.exp), len(c.Targets))
+		}
+		for j, target := range c.Targets {
+			if s := strings.Join(target.Args(), " "); s != test.exp[j] {
+				t.Errorf("test %d (%s) expected %q, got: %q", i, test.name, test.exp[j], s)
+			}
+		}
+	}
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/kenshaw/snaker"
 	"github.com/xo/xo/loader"
 	xo "github.com/xo/xo/types"
 )
@@ -16,10 +17,21 @@ import (
 // LoadQuery loads a query.
 func LoadQuery(ctx context.Context, set *xo.Set, args *Args) error {
 	driver, _, _ := xo.DriverDbSchema(ctx)
+	// rewrite named params
+	sqlstr := args.QueryParams.Query
+	if args.QueryParams.Named {
+		types, err := parseParamTypes(args.QueryParams.ParamTypes)
+		if err != nil {
+			return err
+		}
+		if sqlstr, err = ParseNamedParams(sqlstr, args.QueryParams.Delimiter, types); err != nil {
+			return err
+		}
+	}
 	// introspect query if not exec mode
 	query, inspect, comments, fields, err := ParseQuery(
 		ctx,
-		args.QueryParams.Query,
+		sqlstr,
 		args.QueryParams.Delimiter,
 		args.QueryParams.Interpolate,
 		args.QueryParams.Trim,
@@ -29,7 +41,20 @@ func LoadQuery(ctx context.Context, set *xo.Set, args *Args) error {
 		return err
 	}
 	var typeFields []xo.Field
-	if !args.QueryParams.Exec {
+	var resultSets [][]xo.Field
+	switch {
+	case args.QueryParams.Multi:
+		// build result set types
+		resultSets, err = LoadQueryResultSets(
+			ctx,
+			inspect,
+			args.QueryParams.Fields,
+			args.QueryParams.AllowNulls,
+		)
+		if err != nil {
+			return err
+		}
+	case !args.QueryParams.Exec:
 		// build query type
 		typeFields, err = LoadQueryFields(
 			ctx,
@@ -42,6 +67,10 @@ func LoadQuery(ctx context.Context, set *xo.Set, args *Args) error {
 			return err
 		}
 	}
+	// override nullability
+	if err := overrideNullable(append([][]xo.Field{typeFields}, resultSets...), args.QueryParams.Nullable, args.QueryParams.NotNull); err != nil {
+		return err
+	}
 	set.Queries = append(set.Queries, xo.Query{
 		Driver:       driver,
 		Name:         args.QueryParams.Func,
@@ -54,6 +83,7 @@ func LoadQuery(ctx context.Context, set *xo.Set, args *Args) error {
 		TypeComment:  args.QueryParams.TypeComment,
 		Fields:       typeFields,
 		ManualFields: args.QueryParams.Fields != "",
+		ResultSets:   resultSets,
 		Params:       fields,
 		Query:        query,
 		Comments:     comments,
@@ -180,6 +210,10 @@ func ParseQueryFields(query, delim string, interpolate, paramInterpolate bool, n
 		} else {
 			n := i
 			if prevIndex != -1 {
+				// placeholders without an index (ie, ?) cannot be reused
+				if paramInterpolate && nth(0) == nth(1) {
+					return "", nil, fmt.Errorf("query parameter %q cannot be used more than once with the driver's placeholders", name)
+				}
 				n = prevIndex
 			} else {
 				i++
@@ -196,6 +230,174 @@ func ParseQueryFields(query, delim string, interpolate, paramInterpolate bool, n
 	return sqlstr + query[last:], fields, nil
 }
 
+// ParseNamedParams takes a SQL query and looks for named params in the form of
+// ":<name>" or "@<name>", replacing them with "<delim><name> <type><delim>",
+// for use with ParseQueryFields. Named params in string literals, quoted
+// identifiers, and comments are ignored, as are casts (::) and variables
+// (@@).
+//
+// Param names are converted to Go identifiers (ie, user_id becomes userID),
+// and param types are looked up by name in types, defaulting to interface{}.
+func ParseNamedParams(query, delim string, types map[string]string) (string, error) {
+	isIdent := func(c byte, first bool) bool {
+		return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || !first && '0' <= c && c <= '9'
+	}
+	var sb strings.Builder
+	used := make(map[string]bool)
+	for i := 0; i < len(query); i++ {
+		// skip string literals, quoted identifiers, and comments
+		j, err := skipLiteral(query, i)
+		switch {
+		case err != nil:
+			return "", err
+		case j != i:
+			sb.WriteString(query[i:j])
+			i = j - 1
+			continue
+		}
+		c := query[i]
+		switch {
+		case (c == ':' || c == '@') && i+1 < len(query) && isIdent(query[i+1], true) &&
+			(i == 0 || query[i-1] != c && !isIdent(query[i-1], false)):
+			// named param
+			j := i + 1
+			for j < len(query) && isIdent(query[j], false) {
+				j++
+			}
+			name := query[i+1 : j]
+			typ, ok := types[name]
+			if !ok {
+				typ = "interface{}"
+			}
+			used[name] = true
+			sb.WriteString(delim + snaker.ForceLowerCamelIdentifier(name) + " " + typ + delim)
+			i = j - 1
+			continue
+		}
+		sb.WriteByte(c)
+	}
+	for name := range types {
+		if !used[name] {
+			return "", fmt.Errorf("param type defined for unknown named param %q", name)
+		}
+	}
+	return sb.String(), nil
+}
+
+// skipLiteral returns the end position of the string literal, quoted
+// identifier, or comment starting at position i in query, or i when there is
+// none.
+func skipLiteral(query string, i int) (int, error) {
+	switch c := query[i]; {
+	case c == '\'' || c == '"' || c == '`':
+		j := strings.IndexByte(query[i+1:], c)
+		if j == -1 {
+			return 0, fmt.Errorf("unterminated %c at position %d", c, i)
+		}
+		return i + j + 2, nil
+	case c == '-' && strings.HasPrefix(query[i:], "--"):
+		j := strings.IndexByte(query[i:], '\n')
+		if j == -1 {
+			return len(query), nil
+		}
+		return i + j, nil
+	case c == '/' && strings.HasPrefix(query[i:], "/*"):
+		j := strings.Index(query[i+2:], "*/")
+		if j == -1 {
+			return 0, fmt.Errorf("unterminated comment at position %d", i)
+		}
+		return i + j + 4, nil
+	}
+	return i, nil
+}
+
+// SplitStatements splits a SQL query into its statements, separated by
+// semicolons. Semicolons in string literals, quoted identifiers, and comments
+// are ignored. Empty statements are discarded.
+func SplitStatements(query string) ([]string, error) {
+	var stmts []string
+	add := func(stmt string) {
+		if stmt = strings.TrimSpace(stmt); stmt != "" {
+			stmts = append(stmts, stmt)
+		}
+	}
+	last := 0
+	for i := 0; i < len(query); i++ {
+		j, err := skipLiteral(query, i)
+		switch {
+		case err != nil:
+			return nil, err
+		case j != i:
+			i = j - 1
+		case query[i] == ';':
+			add(query[last:i])
+			last = i + 1
+		}
+	}
+	add(query[last:])
+	return stmts, nil
+}
+
+// returnsRows determines if the SQL statement returns rows, based on its
+// first keyword (after any leading comments).
+func returnsRows(stmt string) bool {
+	for stmt = strings.TrimSpace(stmt); strings.HasPrefix(stmt, "--") || strings.HasPrefix(stmt, "/*"); {
+		j, err := skipLiteral(stmt, 0)
+		if err != nil {
+			return false
+		}
+		stmt = strings.TrimSpace(stmt[j:])
+	}
+	if i := strings.IndexFunc(stmt, func(r rune) bool {
+		return !('a' <= r && r <= 'z' || 'A' <= r && r <= 'Z')
+	}); i != -1 {
+		stmt = stmt[:i]
+	}
+	switch strings.ToUpper(stmt) {
+	case "SELECT", "WITH", "VALUES":
+		return true
+	}
+	return false
+}
+
+// parseParamTypes parses named param types in the form of <name>=<type>.
+func parseParamTypes(v []string) (map[string]string, error) {
+	types := make(map[string]string, len(v))
+	for _, s := range v {
+		name, typ, ok := strings.Cut(s, "=")
+		if name, typ = strings.TrimSpace(name), strings.TrimSpace(typ); !ok || name == "" || typ == "" {
+			return nil, fmt.Errorf("invalid --param-type %q: must be <name>=<type>", s)
+		}
+		types[name] = typ
+	}
+	return types, nil
+}
+
+// overrideNullable overrides the nullability of the named fields in each
+// field set.
+func overrideNullable(sets [][]xo.Field, nullable, notNull []string) error {
+	for _, v := range []struct {
+		names    []string
+		nullable bool
+	}{
+		{nullable, true},
+		{notNull, false},
+	} {
+		for _, name := range v.names {
+			var found bool
+			for _, fields := range sets {
+				if i := index(fields, name); i != -1 {
+					fields[i].Type.Nullable, found = v.nullable, true
+				}
+			}
+			if !found {
+				return fmt.Errorf("unknown result field %q", name)
+			}
+		}
+	}
+	return nil
+}
+
 // LoadQueryFields loads the query type fields.
 func LoadQueryFields(ctx context.Context, query []string, fields string, allowNulls, flat bool) ([]xo.Field, error) {
 	// introspect or use defined user fields
@@ -209,6 +411,42 @@ func LoadQueryFields(ctx context.Context, query []string, fields string, allowNu
 	return f(ctx, query, allowNulls, flat)
 }
 
+// LoadQueryResultSets loads the fields for each result set of a query
+// returning multiple result sets. When fields is not empty, each result set's
+// fields are separated by a semicolon (ie, "a int, b string; c int").
+// Otherwise, each statement of the query that returns rows is introspected.
+func LoadQueryResultSets(ctx context.Context, query []string, fields string, allowNulls bool) ([][]xo.Field, error) {
+	var sets [][]xo.Field
+	if fields != "" {
+		for _, s := range strings.Split(fields, ";") {
+			f, err := SplitFields(s)
+			if err != nil {
+				return nil, err
+			}
+			sets = append(sets, f)
+		}
+		return sets, nil
+	}
+	stmts, err := SplitStatements(strings.Join(query, "\n"))
+	if err != nil {
+		return nil, err
+	}
+	for _, stmt := range stmts {
+		if !returnsRows(stmt) {
+			continue
+		}
+		f, err := Introspect(ctx, strings.Split(stmt, "\n"), allowNulls, false)
+		if err != nil {
+			return nil, err
+		}
+		sets = append(sets, f)
+	}
+	if len(sets) == 0 {
+		return nil, errors.New("query has no statements returning rows (use --fields to specify the fields for each result set)")
+	}
+	return sets, nil
+}
+
 // Introspect creates a view of a query, introspecting the query's columns and
 // returning as fields.
 //
@@ -246,6 +484,17 @@ func Introspect(ctx context.Context, query []string, allowNulls, flat bool) ([]x
 	if err != nil {
 		return nil, err
 	}
+	// infer not null columns, when the database does not report them
+	notNull := make(map[string]bool)
+	if allowNulls {
+		names, err := loader.ViewNotNull(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range names {
+			notNull[name] = true
+		}
+	}
 	// truncate view
 	if _, err := loader.ViewTruncate(ctx, id); err != nil {
 		return nil, err
@@ -263,7 +512,7 @@ func Introspect(ctx context.Context, query []string, allowNulls, flat bool) ([]x
 			return nil, err
 		}
 		if allowNulls {
-			d.Nullable = !col.NotNull
+			d.Nullable = !col.NotNull && !notNull[col.ColumnName]
 		}
 		fields = append(fields, xo.Field{
 			Name: col.ColumnName,
@@ -0,0 +1,110 @@
+package cmd
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestParseNamedParams(t *testing.T) {
+	tests := []struct {
+		query string
+		types map[string]string
+		exp   string
+		err   bool
+	}{
+		{"SELECT * FROM users", nil, "SELECT * FROM users", false},
+		{"SELECT * FROM users WHERE id = :id", map[string]string{"id": "int"}, "SELECT * FROM users WHERE id = %%id int%%", false},
+		{"SELECT * FROM users WHERE user_id = @user_id", nil, "SELECT * FROM users WHERE user_id = %%userID interface{}%%", false},
+		{"SELECT :a, :a", map[string]string{"a": "string"}, "SELECT %%a string%%, %%a string%%", false},
+		{"SELECT id::text, @@ROWCOUNT FROM t WHERE x=:x", nil, "SELECT id::text, @@ROWCOUNT FROM t WHERE x=%%x interface{}%%", false},
+		{"SELECT ':a', \"@b\", `:c` FROM t -- :d\nWHERE e = :e /* :f */", nil, "SELECT ':a', \"@b\", `:c` FROM t -- :d\nWHERE e = %%e interface{}%% /* :f */", false},
+		{"SELECT 'it''s', :a", nil, "SELECT 'it''s', %%a interface{}%%", false},
+		{"SELECT a:b, :1, t.x:=1", nil, "SELECT a:b, :1, t.x:=1", false},
+		{"SELECT 'a", nil, "", true},
+		{"SELECT /* a", nil, "", true},
+		{"SELECT :a", map[string]string{"b": "int"}, "", true},
+	}
+	for i, test := range tests {
+		s, err := ParseNamedParams(test.query, "%%", test.types)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%q) expected error, got nil", i, test.query)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%q) expected no error, got: %v", i, test.query, err)
+		case s != test.exp:
+			t.Errorf("test %d (%q) expected %q, got: %q", i, test.query, test.exp, s)
+		}
+	}
+}
+
+func TestParseQueryFields(t *testing.T) {
+	tests := []struct {
+		query string
+		mask  string
+		exp   string
+		n     int
+		err   bool
+	}{
+		{"SELECT %%a int%%, %%b int%%", "$%d", "SELECT $1, $2", 2, false},
+		{"SELECT %%a int%%, %%a int%%", "$%d", "SELECT $1, $1", 1, false},
+		{"SELECT %%a int%%, %%b int%%", "?", "SELECT ?, ?", 2, false},
+		{"SELECT %%a int%%, %%a int%%", "?", "", 0, true},
+	}
+	for i, test := range tests {
+		mask := test.mask
+		nth := func(n int) string {
+			if mask == "?" {
+				return mask
+			}
+			return fmt.Sprintf(mask, n+1)
+		}
+		s, fields, err := ParseQueryFields(test.query, "%%", false, true, nth)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%q) expected error, got nil", i, test.query)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%q) expected no error, got: %v", i, test.query, err)
+		case s != test.exp:
+			t.Errorf("test %d (%q) expected %q, got: %q", i, test.query, test.exp, s)
+		case len(fields) != test.n:
+			t.Errorf("test %d (%q) expected %d fields, got: %d", i, test.query, test.n, len(fields))
+		}
+	}
+}
+
+func TestSplitStatements(t *testing.T) {
+	tests := []struct {
+		query string
+		exp   []string
+		rows  []bool
+		err   bool
+	}{
+		{"SELECT 1", []string{"SELECT 1"}, []bool{true}, false},
+		{"SELECT 1; SELECT 2;\n", []string{"SELECT 1", "SELECT 2"}, []bool{true, true}, false},
+		{"SET NOCOUNT ON;\nSELECT ';' FROM t; -- a;b\nwith x AS (SELECT 1) SELECT * FROM x", []string{"SET NOCOUNT ON", "SELECT ';' FROM t", "-- a;b\nwith x AS (SELECT 1) SELECT * FROM x"}, []bool{false, true, true}, false},
+		{"/* ; */ UPDATE t SET a = 1;; EXEC p", []string{"/* ; */ UPDATE t SET a = 1", "EXEC p"}, []bool{false, false}, false},
+		{"SELECT 'a; SELECT 2", nil, nil, true},
+	}
+	for i, test := range tests {
+		stmts, err := SplitStatements(test.query)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%q) expected error, got nil", i, test.query)
+			continue
+		case !test.err && err != nil:
+			t.Errorf("test %d (%q) expected no error, got: %v", i, test.query, err)
+			continue
+		case len(stmts) != len(test.exp):
+			t.Errorf("test %d (%q) expected %d statements, got: %d", i, test.query, len(test.exp), len(stmts))
+			continue
+		}
+		for j, stmt := range stmts {
+			if stmt != test.exp[j] {
+				t.Errorf("test %d (%q) expected statement %d to be %q, got: %q", i, test.query, j, test.exp[j], stmt)
+			}
+			if rows := returnsRows(stmt); rows != test.rows[j] {
+				t.Errorf("test %d (%q) expected statement %d returns rows to be %t, got: %t", i, test.query, j, test.rows[j], rows)
+			}
+		}
+	}
+}
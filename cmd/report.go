@@ -0,0 +1,373 @@
+package cmd
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"sort"
+
+	"github.com/xo/xo/templates"
+	xo "github.com/xo/xo/types"
+)
+
+// Report is a machine-readable report of the files generated by a run, and
+// the tables, queries, and types they were generated from, written with
+// --report=json.
+type Report struct {
+	// Template is the name of the template.
+	Template string `json:"template"`
+	// Mode is the command mode (schema or query).
+	Mode string `json:"mode"`
+	// Out is the out path.
+	Out string `json:"out"`
+	// Files are the generated files, and the files removed by --prune.
+	Files []ReportFile `json:"files"`
+	// Tables are the tables, views, and materialized views generated.
+	Tables []ReportTable `json:"tables,omitempty"`
+	// Queries are the queries generated.
+	Queries []ReportQuery `json:"queries,omitempty"`
+	// Types are the database types mapped by the template, and the types
+	// they were mapped to.
+	Types []ReportType `json:"types,omitempty"`
+	// Skipped are the constructs skipped when loading or generating.
+	Skipped []ReportSkipped `json:"skipped,omitempty"`
+}
+
+// ReportFile is a generated file.
+type ReportFile struct {
+	// Name is the file's path relative to the out path.
+	Name string `json:"name"`
+	// Op is the change made to the file (write, change, remove, or
+	// unchanged).
+	Op string `json:"op"`
+	// Hash is the SHA-256 hash of the file's content. Empty for removed
+	// files.
+	Hash string `json:"hash,omitempty"`
+	// Artifacts are the partial templates generated to the file.
+	Artifacts []string `json:"artifacts,omitempty"`
+}
+
+// ReportTable is a generated table.
+type ReportTable struct {
+	// Schema is the schema of the table.
+	Schema string `json:"schema,omitempty"`
+	// Name is the name of the table.
+	Name string `json:"name"`
+	// Type is the type of the table (table, view, or matview).
+	Type string `json:"type"`
+	// Columns are the columns of the table.
+	Columns []ReportColumn `json:"columns,omitempty"`
+	// Files are the files generated for the table.
+	Files []string `json:"files,omitempty"`
+}
+
+// ReportColumn is a generated column.
+type ReportColumn struct {
+	// Name is the name of the column.
+	Name string `json:"name"`
+	// Type is the database type of the column.
+	Type string `json:"type"`
+	// Nullable is whether the column is nullable.
+	Nullable bool `json:"nullable,omitempty"`
+	// Mapped is the type the template mapped the column to, when known.
+	Mapped string `json:"mapped,omitempty"`
+}
+
+// ReportQuery is a generated query.
+type ReportQuery struct {
+	// Name is the func name of the query.
+	Name string `json:"name"`
+	// Type is the result type name of the query.
+	Type string `json:"type,omitempty"`
+	// Files are the files generated for the query.
+	Files []string `json:"files,omitempty"`
+}
+
+// ReportType is a database type mapped by the template.
+type ReportType struct {
+	// Type is the database type.
+	Type string `json:"type"`
+	// Mapped is the type the database type was mapped to.
+	Mapped string `json:"mapped"`
+	// Columns is the number of columns mapped.
+	Columns int `json:"columns"`
+}
+
+// ReportSkipped is a construct skipped when loading or generating.
+type ReportSkipped struct {
+	// Kind is the kind of construct (ie, table, column, or foreign key).
+	Kind string `json:"kind"`
+	// Name is the name of the construct.
+	Name string `json:"name"`
+	// Reason is the reason the construct was skipped.
+	Reason string `json:"reason"`
+	// File is the file the construct was skipped in, for constructs not
+	// supported by the template.
+	File string `json:"file,omitempty"`
+}
+
+// reportKey is the context key for the constructs skipped when loading,
+// collected when --report is used.
+type reportKey struct{}
+
+// reportSkips are the constructs skipped when loading.
+type reportSkips struct {
+	skipped []ReportSkipped
+}
+
+// addSkipped adds a construct skipped when loading to the report. The name
+// is qualified with the schema in the context.
+func addSkipped(ctx context.Context, kind, name, reason string) {
+	r, ok := ctx.Value(reportKey{}).(*reportSkips)
+	if !ok {
+		return
+	}
+	if _, _, schema := xo.DriverDbSchema(ctx); schema != "" {
+		name = schema + "." + name
+	}
+	skipped := ReportSkipped{Kind: kind, Name: name, Reason: reason}
+	for _, s := range r.skipped {
+		if s == skipped {
+			return
+		}
+	}
+	r.skipped = append(r.skipped, skipped)
+}
+
+// writeReport writes the report of the generated files, and the changes made
+// to the out path, to w.
+func writeReport(ctx context.Context, w io.Writer, mode string, ts *templates.Set, set *xo.Set, args *Args, changes []templates.Change) error {
+	ops := make(map[string]templates.Op)
+	for _, change := range changes {
+		ops[change.File] = change.Op
+	}
+	var skipped []ReportSkipped
+	if r, ok := ctx.Value(reportKey{}).(*reportSkips); ok {
+		skipped = r.skipped
+	}
+	r := newReport(mode, set, ts.Files(), ops, skipped)
+	r.Template, r.Out = args.TemplateParams.Type.AsString(), args.OutParams.Out
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// withoutRemoved returns the changes, without the files that would be removed.
+func withoutRemoved(changes []templates.Change) []templates.Change {
+	var v []templates.Change
+	for _, change := range changes {
+		if change.Op != templates.OpRemove {
+			v = append(v, change)
+		}
+	}
+	return v
+}
+
+// unsupportedRE matches the markers of unsupported constructs in generated
+// files (ie, '[[ UNSUPPORTED TYPE 1: int ]]').
+var unsupportedRE = regexp.MustCompile(`\[\[ (UNSUPPORTED[^\]]*?) \]\]`)
+
+// newReport creates a report for the set and the generated files, using the
+// changes made to each file (files not in ops are unchanged).
+//
+// Files are associated with the tables in the template data emitted to them.
+// Template data is either the set, a schema, or a struct having SQLName and
+// Schema fields (or a Table field with them), and a Fields slice of structs
+// having SQLName and Type fields, from which the mapped types are read.
+func newReport(mode string, set *xo.Set, files map[string]*templates.EmittedTemplate, ops map[string]templates.Op, skipped []ReportSkipped) *Report {
+	r := &Report{
+		Mode:    mode,
+		Files:   []ReportFile{},
+		Skipped: append([]ReportSkipped(nil), skipped...),
+	}
+	// files
+	names := make([]string, 0, len(files))
+	for name := range files {
+		names = append(names, name)
+	}
+	for name, op := range ops {
+		if _, ok := files[name]; !ok && op == templates.OpRemove {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	tableFiles, mapped := make(map[string][]string), make(map[string]map[string]string)
+	queryFiles := make(map[string][]string)
+	for _, name := range names {
+		file := filepath.ToSlash(name)
+		f := ReportFile{Name: file, Op: "unchanged"}
+		if op, ok := ops[name]; ok {
+			f.Op = string(op)
+		}
+		emitted, ok := files[name]
+		if !ok {
+			r.Files = append(r.Files, f)
+			continue
+		}
+		buf := emitted.Buf.Bytes()
+		sum := sha256.Sum256(buf)
+		f.Hash = hex.EncodeToString(sum[:])
+		seen, tables, queries := make(map[string]bool), make(map[string]bool), make(map[string]bool)
+		for _, tpl := range emitted.Template {
+			if tpl.Partial != "" && !seen[tpl.Partial] {
+				f.Artifacts, seen[tpl.Partial] = append(f.Artifacts, tpl.Partial), true
+			}
+			for key, types := range dataTables(tpl.Data) {
+				if !tables[key] {
+					tableFiles[key], tables[key] = append(tableFiles[key], file), true
+				}
+				if mapped[key] == nil {
+					mapped[key] = make(map[string]string)
+				}
+				for column, typ := range types {
+					mapped[key][column] = typ
+				}
+			}
+			if mode == "query" {
+				for _, q := range set.Queries {
+					if (tpl.SortName == q.Name || q.Type != "" && tpl.SortType == q.Type) && !queries[q.Name] {
+						queryFiles[q.Name], queries[q.Name] = append(queryFiles[q.Name], file), true
+					}
+				}
+			}
+		}
+		for _, m := range unsupportedRE.FindAllSubmatch(buf, -1) {
+			r.Skipped = append(r.Skipped, ReportSkipped{
+				Kind:   "template",
+				Name:   string(m[1]),
+				Reason: "not supported by the template",
+				File:   file,
+			})
+		}
+		r.Files = append(r.Files, f)
+	}
+	// tables
+	counts := make(map[ReportType]int)
+	for _, schema := range set.Schemas {
+		for _, tables := range [][]xo.Table{schema.Tables, schema.Views, schema.MatViews} {
+			for _, table := range tables {
+				key := schema.Name + "." + table.Name
+				t := ReportTable{
+					Schema: schema.Name,
+					Name:   table.Name,
+					Type:   table.Type,
+					Files:  tableFiles[key],
+				}
+				for _, f := range table.Columns {
+					c := ReportColumn{
+						Name:     f.Name,
+						Type:     dbType(f.Type),
+						Nullable: f.Type.Nullable,
+						Mapped:   mapped[key][f.Name],
+					}
+					if c.Mapped != "" {
+						counts[ReportType{Type: c.Type, Mapped: c.Mapped}]++
+					}
+					t.Columns = append(t.Columns, c)
+				}
+				r.Tables = append(r.Tables, t)
+			}
+		}
+	}
+	// queries
+	for _, q := range set.Queries {
+		r.Queries = append(r.Queries, ReportQuery{
+			Name:  q.Name,
+			Type:  q.Type,
+			Files: queryFiles[q.Name],
+		})
+	}
+	// types
+	for typ, n := range counts {
+		typ.Columns = n
+		r.Types = append(r.Types, typ)
+	}
+	sort.Slice(r.Types, func(i, j int) bool {
+		if r.Types[i].Type != r.Types[j].Type {
+			return r.Types[i].Type < r.Types[j].Type
+		}
+		return r.Types[i].Mapped < r.Types[j].Mapped
+	})
+	return r
+}
+
+// dataTables returns the tables (as <schema>.<table>) in the template data,
+// and the types the table columns were mapped to, when known.
+func dataTables(data interface{}) map[string]map[string]string {
+	m := make(map[string]map[string]string)
+	add := func(schema xo.Schema) {
+		for _, tables := range [][]xo.Table{schema.Tables, schema.Views, schema.MatViews} {
+			for _, table := range tables {
+				m[schema.Name+"."+table.Name] = nil
+			}
+		}
+	}
+	switch v := data.(type) {
+	case *xo.Set:
+		for _, schema := range v.Schemas {
+			add(schema)
+		}
+		return m
+	case xo.Schema:
+		add(v)
+		return m
+	case *xo.Schema:
+		add(*v)
+		return m
+	}
+	v := reflect.Indirect(reflect.ValueOf(data))
+	if v.Kind() != reflect.Struct {
+		return m
+	}
+	if t := v.FieldByName("Table"); t.IsValid() && t.Kind() == reflect.Struct {
+		v = t
+	}
+	name, schema := stringField(v, "SQLName"), stringField(v, "Schema")
+	if name == "" || schema == "" {
+		return m
+	}
+	types := make(map[string]string)
+	if fields := v.FieldByName("Fields"); fields.IsValid() && fields.Kind() == reflect.Slice {
+		for i := 0; i < fields.Len(); i++ {
+			f := reflect.Indirect(fields.Index(i))
+			if f.Kind() != reflect.Struct {
+				continue
+			}
+			if column, typ := stringField(f, "SQLName"), stringField(f, "Type"); column != "" && typ != "" {
+				types[column] = typ
+			}
+		}
+	}
+	m[schema+"."+name] = types
+	return m
+}
+
+// stringField returns the value of the string field of the struct, or an
+// empty string when the struct does not have the field.
+func stringField(v reflect.Value, name string) string {
+	if f := v.FieldByName(name); f.IsValid() && f.Kind() == reflect.String {
+		return f.String()
+	}
+	return ""
+}
+
+// dbType returns the database type as a string (ie, numeric(10,2)[]).
+func dbType(d xo.Type) string {
+	typ := d.Type
+	switch {
+	case d.Prec != 0 && d.Scale != 0:
+		typ += fmt.Sprintf("(%d,%d)", d.Prec, d.Scale)
+	case d.Prec != 0:
+		typ += fmt.Sprintf("(%d)", d.Prec)
+	}
+	if d.IsArray {
+		typ += "[]"
+	}
+	return typ
+}
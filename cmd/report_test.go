@@ -0,0 +1,190 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/xo/xo/templates"
+	xo "github.com/xo/xo/types"
+)
+
+func TestNewReport(t *testing.T) {
+	type field struct {
+		SQLName string
+		Type    string
+	}
+	type table struct {
+		SQLName string
+		Schema  string
+		Fields  []field
+	}
+	type index struct {
+		Func  string
+		Table table
+	}
+	set := &xo.Set{
+		Schemas: []xo.Schema{{
+			Name: "public",
+			Tables: []xo.Table{{
+				Type: "table",
+				Name: "authors",
+				Columns: []xo.Field{
+					{Name: "author_id", Type: xo.Type{Type: "integer"}},
+					{Name: "name", Type: xo.Type{Type: "varchar", Prec: 255, Nullable: true}},
+				},
+			}},
+			Views: []xo.Table{{
+				Type: "view",
+				Name: "names",
+				Columns: []xo.Field{
+					{Name: "name", Type: xo.Type{Type: "varchar", Prec: 255, Nullable: true}},
+				},
+			}},
+		}},
+	}
+	author := table{"authors", "public", []field{{"author_id", "int"}, {"name", "sql.NullString"}}}
+	emitted := func(buf string, tpls ...xo.Template) *templates.EmittedTemplate {
+		e := &templates.EmittedTemplate{Template: tpls}
+		e.Buf.WriteString(buf)
+		return e
+	}
+	files := map[string]*templates.EmittedTemplate{
+		"author.xo.go": emitted("author",
+			xo.Template{Partial: "typedef", Data: author},
+			xo.Template{Partial: "index", Data: index{"AuthorByAuthorID", author}},
+		),
+		"name.xo.go": emitted("[[ UNSUPPORTED TYPE 1: int ]]",
+			xo.Template{Partial: "typedef", Data: &table{"names", "public", nil}},
+		),
+		"xo.xo.json": emitted("{}",
+			xo.Template{Partial: "json", Data: set},
+		),
+	}
+	ops := map[string]templates.Op{
+		"author.xo.go": templates.OpWrite,
+		"name.xo.go":   templates.OpChange,
+		"stale.xo.go":  templates.OpRemove,
+	}
+	skipped := []ReportSkipped{{Kind: "column", Name: "public.authors.secret", Reason: "excluded"}}
+	r := newReport("schema", set, files, ops, skipped)
+	var names, opNames, artifacts []string
+	for _, f := range r.Files {
+		names, opNames = append(names, f.Name), append(opNames, f.Op)
+		if f.Name == "author.xo.go" {
+			artifacts = f.Artifacts
+		}
+	}
+	tests := []struct {
+		name string
+		v    interface{}
+		exp  interface{}
+	}{
+		{"files", names, []string{"author.xo.go", "name.xo.go", "stale.xo.go", "xo.xo.json"}},
+		{"ops", opNames, []string{"write", "change", "remove", "unchanged"}},
+		{"artifacts", artifacts, []string{"typedef", "index"}},
+		{"table files", r.Tables[0].Files, []string{"author.xo.go", "xo.xo.json"}},
+		{"view files", r.Tables[1].Files, []string{"name.xo.go", "xo.xo.json"}},
+		{"columns", r.Tables[0].Columns, []ReportColumn{
+			{Name: "author_id", Type: "integer", Mapped: "int"},
+			{Name: "name", Type: "varchar(255)", Nullable: true, Mapped: "sql.NullString"},
+		}},
+		{"types", r.Types, []ReportType{
+			{Type: "integer", Mapped: "int", Columns: 1},
+			{Type: "varchar(255)", Mapped: "sql.NullString", Columns: 1},
+		}},
+		{"skipped", r.Skipped, []ReportSkipped{
+			{Kind: "column", Name: "public.authors.secret", Reason: "excluded"},
+			{Kind: "template", Name: "UNSUPPORTED TYPE 1: int", Reason: "not supported by the template", File: "name.xo.go"},
+		}},
+	}
+	for i, test := range tests {
+		if !reflect.DeepEqual(test.v, test.exp) {
+			t.Errorf("test %d (%s) expected %v, got: %v", i, test.name, test.exp, test.v)
+		}
+	}
+	if r.Files[2].Hash != "" {
+		t.Errorf("expected no hash for removed file, got: %q", r.Files[2].Hash)
+	}
+}
+
+// TestDataTablesGo checks that the tables and mapped types are read from the
+// data of the Go template's emitted templates.
+func TestDataTablesGo(t *testing.T) {
+	ctx := context.Background()
+	ts, err := NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	set := &xo.Set{
+		Schemas: []xo.Schema{{
+			Driver: "postgres",
+			Name:   "public",
+			Tables: []xo.Table{{
+				Type: "table",
+				Name: "authors",
+				Columns: []xo.Field{
+					{Name: "author_id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true},
+					{Name: "name", Type: xo.Type{Type: "varchar", Prec: 255, Nullable: true}},
+				},
+				PrimaryKeys: []xo.Field{
+					{Name: "author_id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true},
+				},
+				Indexes: []xo.Index{{
+					Name:      "authors_pkey",
+					Func:      "AuthorByAuthorID",
+					Fields:    []xo.Field{{Name: "author_id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}},
+					IsUnique:  true,
+					IsPrimary: true,
+				}},
+			}},
+		}},
+	}
+	args := NewArgs(ts.Target(), ts.Targets()...)
+	c, err := SchemaCommand(ctx, ts, args)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c.RunE = func(*cobra.Command, []string) error {
+		ts.Use(args.TemplateParams.Type.AsString())
+		ctx := BuildContext(ctx, args)
+		ctx = context.WithValue(ctx, xo.DriverKey, "postgres")
+		ctx = context.WithValue(ctx, xo.SchemaKey, "public")
+		return Generate(ctx, "schema", ts, set, args)
+	}
+	// the package name is the out directory's name
+	out := filepath.Join(t.TempDir(), "models")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	c.SetArgs([]string{"postgres://", "--out=" + out})
+	c.SetOut(new(bytes.Buffer))
+	c.SilenceErrors, c.SilenceUsage = true, true
+	if err := c.ExecuteContext(ctx); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	emitted := ts.Files()["author.xo.go"]
+	if emitted == nil {
+		t.Fatalf("expected author.xo.go to be emitted")
+	}
+	author := map[string]map[string]string{
+		"public.authors": {"author_id": "int", "name": "NullVarchar"},
+	}
+	exp := map[string]map[string]map[string]string{
+		"header":  {},
+		"typedef": author,
+		"index":   author,
+	}
+	if len(emitted.Template) != len(exp) {
+		t.Fatalf("expected %d templates, got: %d", len(exp), len(emitted.Template))
+	}
+	for i, tpl := range emitted.Template {
+		if m := dataTables(tpl.Data); !reflect.DeepEqual(m, exp[tpl.Partial]) {
+			t.Errorf("test %d (%s) expected %v, got: %v", i, tpl.Partial, exp[tpl.Partial], m)
+		}
+	}
+}
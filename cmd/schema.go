@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"regexp"
@@ -9,32 +10,276 @@ import (
 	"strconv"
 	"strings"
 
+	"github.com/gobwas/glob"
 	"github.com/kenshaw/inflector"
 	"github.com/xo/xo/loader"
 	"github.com/xo/xo/models"
 	xo "github.com/xo/xo/types"
 )
 
-// LoadSchema loads a schema from a database.
+// LoadSchema loads the schemas from a database.
+//
+// The primary schema (the schema in the context) is loaded first, followed by
+// any additional schemas specified by the user. Foreign keys are loaded after
+// all schemas, so that references across schemas can be resolved.
+//
+// When generating for tenants, the primary schema is the canonical tenant
+// schema, and the additional schemas are checked to have the same structure
+// instead of being loaded.
 func LoadSchema(ctx context.Context, set *xo.Set, args *Args) error {
+	_, _, primary := xo.DriverDbSchema(ctx)
+	names, seen := []string{primary}, map[string]bool{primary: true}
+	var tenants []string
+	for _, name := range args.LoaderParams.Schemas {
+		switch {
+		case seen[name]:
+		case args.SchemaParams.Tenant != "":
+			tenants, seen[name] = append(tenants, name), true
+		default:
+			names, seen[name] = append(names, name), true
+		}
+	}
+	// load schemas
+	start := len(set.Schemas)
+	for _, name := range names {
+		schema, err := LoadSchemaTables(context.WithValue(ctx, xo.SchemaKey, name), args)
+		if err != nil {
+			return err
+		}
+		set.Schemas = append(set.Schemas, schema)
+	}
+	if err := checkTableArgs(ctx, args, set.Schemas[start:]); err != nil {
+		return err
+	}
+	// load foreign keys
+	schemas := set.Schemas[start:]
+	for i := range schemas {
+		if err := loadForeignKeys(ctx, args, schemas, &schemas[i]); err != nil {
+			return err
+		}
+	}
+	// check tenants
+	if args.SchemaParams.Tenant != "" {
+		if err := checkTenants(ctx, args, schemas, tenants); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadForeignKeys loads the foreign keys for the tables and views of the
+// schema, resolving references against the schemas.
+func loadForeignKeys(ctx context.Context, args *Args, schemas []xo.Schema, schema *xo.Schema) error {
+	ctx = context.WithValue(ctx, xo.SchemaKey, schema.Name)
+	for _, tables := range [][]xo.Table{schema.Tables, schema.Views} {
+		for i, table := range tables {
+			var err error
+			if tables[i].ForeignKeys, err = LoadTableForeignKeys(ctx, args, schemas, table); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkTableArgs checks that the tables named by --json-type and --sensitive
+// were loaded in one of the schemas, or were excluded.
+func checkTableArgs(ctx context.Context, args *Args, schemas []xo.Schema) error {
+	tables := make(map[string]bool)
+	for _, schema := range schemas {
+		for _, t := range schema.Tables {
+			tables[t.Name] = true
+		}
+		for _, t := range schema.Views {
+			tables[t.Name] = true
+		}
+		for _, t := range schema.MatViews {
+			tables[t.Name] = true
+		}
+	}
+	for _, s := range args.SchemaParams.JSONTypes {
+		name, _, _, err := parseJSONType(s)
+		if err != nil {
+			return err
+		}
+		if !tables[name] && validType(ctx, args, false, name) {
+			return fmt.Errorf("unknown table %q in --json-type", name)
+		}
+	}
+	for _, s := range args.SchemaParams.Sensitive {
+		name, _, err := parseSensitive(s)
+		if err != nil {
+			return err
+		}
+		if !tables[name] && validType(ctx, args, false, name) {
+			return fmt.Errorf("unknown table %q in --sensitive", name)
+		}
+	}
+	return nil
+}
+
+// checkTenants checks that the tenant schemas have the same structure as the
+// canonical tenant schema (the first of the schemas), adding them to the
+// schema's tenants.
+func checkTenants(ctx context.Context, args *Args, schemas []xo.Schema, tenants []string) error {
+	schema := &schemas[0]
+	if schema.Driver == "sqlite3" {
+		return errors.New("--tenant is not supported with sqlite3")
+	}
+	schema.Tenants = append([]string{schema.Name}, tenants...)
+	for _, name := range tenants {
+		tenant, err := LoadSchemaTables(context.WithValue(ctx, xo.SchemaKey, name), args)
+		if err != nil {
+			return err
+		}
+		// resolve the tenant's foreign keys against the tenant in place of
+		// the canonical tenant schema
+		if err := loadForeignKeys(ctx, args, append([]xo.Schema{tenant}, schemas[1:]...), &tenant); err != nil {
+			return err
+		}
+		if err := tenantDiff(*schema, tenant); err != nil {
+			return fmt.Errorf("tenant schema %q does not match %q: %w", name, schema.Name, err)
+		}
+	}
+	return nil
+}
+
+// tenantDiff returns an error describing the first difference between the
+// structure of the canonical tenant schema and the tenant schema.
+func tenantDiff(schema, tenant xo.Schema) error {
+	a, b := tenantStructure(schema), tenantStructure(tenant)
+	var keys []string
+	for k := range a {
+		keys = append(keys, k)
+	}
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		x, xok := a[k]
+		y, yok := b[k]
+		switch {
+		case !yok:
+			return fmt.Errorf("missing %s", k)
+		case !xok:
+			return fmt.Errorf("unexpected %s", k)
+		case x != y:
+			return fmt.Errorf("%s differs: %s != %s", k, y, x)
+		}
+	}
+	return nil
+}
+
+// tenantStructure returns the structure of the enums, composites, ranges,
+// procs, tables, views, and materialized views of the schema, and the indexes
+// and foreign keys of the tables, keyed by kind and name (ie, 'table books').
+func tenantStructure(schema xo.Schema) map[string]string {
+	fields := func(fields []xo.Field) string {
+		var s []string
+		for _, f := range fields {
+			typ := dbType(f.Type)
+			if f.Type.Nullable {
+				typ += " null"
+			}
+			if f.IsPrimary {
+				typ += " primary key"
+			}
+			s = append(s, f.Name+" "+typ)
+		}
+		return "(" + strings.Join(s, ", ") + ")"
+	}
+	names := func(fields []xo.Field) string {
+		var s []string
+		for _, f := range fields {
+			s = append(s, f.Name)
+		}
+		return "(" + strings.Join(s, ", ") + ")"
+	}
+	m := make(map[string]string)
+	for _, e := range schema.Enums {
+		m["enum "+e.Name] = fields(e.Values)
+	}
+	for _, c := range schema.Composites {
+		m["composite "+c.Name] = fields(c.Fields)
+	}
+	for _, r := range schema.Ranges {
+		m["range "+r.Name] = dbType(r.SubType)
+	}
+	for _, p := range schema.Procs {
+		// procs may be overloaded
+		m[p.Type+" "+p.Name+fields(p.Params)] = fields(p.Returns)
+	}
+	for _, kind := range []struct {
+		name   string
+		tables []xo.Table
+	}{
+		{"table", schema.Tables},
+		{"view", schema.Views},
+		{"materialized view", schema.MatViews},
+	} {
+		for _, t := range kind.tables {
+			m[kind.name+" "+t.Name] = fields(t.Columns)
+			for _, i := range t.Indexes {
+				s := names(i.Fields)
+				if len(i.Expressions) != 0 {
+					s += " (" + strings.Join(i.Expressions, ", ") + ")"
+				}
+				switch {
+				case i.IsPrimary:
+					s += " primary key"
+				case i.IsUnique:
+					s += " unique"
+				}
+				if i.Predicate != "" {
+					s += " where " + i.Predicate
+				}
+				m["index "+t.Name+"."+i.Name] = s
+			}
+			for _, fk := range t.ForeignKeys {
+				ref := fk.RefTable
+				if fk.RefSchema != "" {
+					ref = fk.RefSchema + "." + ref
+				}
+				m["foreign key "+t.Name+"."+fk.Name] = names(fk.Fields) + " references " + ref + names(fk.RefFields)
+			}
+		}
+	}
+	return m
+}
+
+// LoadSchemaTables loads the enums, procs, tables, views, and materialized
+// views for the schema in the context, without foreign keys.
+func LoadSchemaTables(ctx context.Context, args *Args) (xo.Schema, error) {
 	driver, _, schemaName := xo.DriverDbSchema(ctx)
 	schema := xo.Schema{
 		Driver: driver,
 		Name:   schemaName,
 	}
 	var err error
-	// load enums, procs, tables, views
+	// load enums, composites, ranges, procs, tables, views, materialized views
 	if schema.Enums, err = LoadEnums(ctx, args); err != nil {
-		return err
+		return xo.Schema{}, err
+	}
+	if schema.Composites, err = LoadComposites(ctx, args); err != nil {
+		return xo.Schema{}, err
+	}
+	if schema.Ranges, err = LoadRanges(ctx, args); err != nil {
+		return xo.Schema{}, err
 	}
 	if schema.Procs, err = LoadProcs(ctx, args); err != nil {
-		return err
+		return xo.Schema{}, err
 	}
 	if schema.Tables, err = LoadTables(ctx, args, "table"); err != nil {
-		return err
+		return xo.Schema{}, err
 	}
 	if schema.Views, err = LoadTables(ctx, args, "view"); err != nil {
-		return err
+		return xo.Schema{}, err
+	}
+	if schema.MatViews, err = LoadTables(ctx, args, "matview"); err != nil {
+		return xo.Schema{}, err
 	}
 	// fix enums for mysql
 	if driver == "mysql" {
@@ -46,9 +291,7 @@ func LoadSchema(ctx context.Context, set *xo.Set, args *Args) error {
 			}
 		}
 	}
-	// emit
-	set.Schemas = append(set.Schemas, schema)
-	return nil
+	return schema, nil
 }
 
 // LoadEnums loads enums.
@@ -61,10 +304,23 @@ func LoadEnums(ctx context.Context, args *Args) ([]xo.Enum, error) {
 	sort.Slice(enumNames, func(i, j int) bool {
 		return enumNames[i].EnumName < enumNames[j].EnumName
 	})
+	// alias overrides
+	overrides := make(map[string]map[string]string)
+	for _, s := range args.SchemaParams.EnumAliases {
+		enum, label, alias, err := parseEnumAlias(s)
+		if err != nil {
+			return nil, err
+		}
+		if overrides[enum] == nil {
+			overrides[enum] = make(map[string]string)
+		}
+		overrides[enum][label] = alias
+	}
 	// process enums
 	var enums []xo.Enum
 	for _, enum := range enumNames {
-		if !validType(args, false, enum.EnumName) {
+		if !validType(ctx, args, false, enum.EnumName) {
+			addSkipped(ctx, "enum", enum.EnumName, "excluded")
 			continue
 		}
 		e := &xo.Enum{
@@ -73,11 +329,27 @@ func LoadEnums(ctx context.Context, args *Args) ([]xo.Enum, error) {
 		if err := LoadEnumValues(ctx, args, e); err != nil {
 			return nil, err
 		}
+		for label := range overrides[e.Name] {
+			if !hasEnumValue(*e, label) {
+				return nil, fmt.Errorf("unknown value %q for enum %q in --enum-alias", label, e.Name)
+			}
+		}
+		xo.SetEnumAliases(e, overrides[e.Name])
 		enums = append(enums, *e)
 	}
 	return enums, nil
 }
 
+// hasEnumValue determines if the enum has a value with the label.
+func hasEnumValue(e xo.Enum, label string) bool {
+	for _, v := range e.Values {
+		if v.Name == label {
+			return true
+		}
+	}
+	return false
+}
+
 // LoadEnumValues loads enum values.
 func LoadEnumValues(ctx context.Context, args *Args, enum *xo.Enum) error {
 	// load enum values
@@ -95,6 +367,68 @@ func LoadEnumValues(ctx context.Context, args *Args, enum *xo.Enum) error {
 	return nil
 }
 
+// LoadComposites loads composite types.
+func LoadComposites(ctx context.Context, args *Args) ([]xo.Composite, error) {
+	driver, _, _ := xo.DriverDbSchema(ctx)
+	// load composite type attributes, ordered by type
+	attrs, err := loader.Composites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// process attributes
+	var composites []xo.Composite
+	for _, attr := range attrs {
+		if !validType(ctx, args, false, attr.TypeName) {
+			addSkipped(ctx, "composite", attr.TypeName, "excluded")
+			continue
+		}
+		d, err := xo.ParseType(attr.DataType, driver)
+		if err != nil {
+			return nil, err
+		}
+		// attributes of composite types are always nullable
+		d.Nullable = true
+		if n := len(composites); n == 0 || composites[n-1].Name != attr.TypeName {
+			composites = append(composites, xo.Composite{
+				Name: attr.TypeName,
+			})
+		}
+		c := &composites[len(composites)-1]
+		c.Fields = append(c.Fields, xo.Field{
+			Name: attr.AttrName,
+			Type: d,
+		})
+	}
+	return composites, nil
+}
+
+// LoadRanges loads range types.
+func LoadRanges(ctx context.Context, args *Args) ([]xo.Range, error) {
+	driver, _, _ := xo.DriverDbSchema(ctx)
+	// load ranges
+	rangeTypes, err := loader.Ranges(ctx)
+	if err != nil {
+		return nil, err
+	}
+	// process ranges
+	var ranges []xo.Range
+	for _, r := range rangeTypes {
+		if !validType(ctx, args, false, r.RangeName) {
+			addSkipped(ctx, "range", r.RangeName, "excluded")
+			continue
+		}
+		d, err := xo.ParseType(r.SubType, driver)
+		if err != nil {
+			return nil, err
+		}
+		ranges = append(ranges, xo.Range{
+			Name:    r.RangeName,
+			SubType: d,
+		})
+	}
+	return ranges, nil
+}
+
 // LoadProcs loads stored procedures definitions.
 func LoadProcs(ctx context.Context, args *Args) ([]xo.Proc, error) {
 	driver, _, _ := xo.DriverDbSchema(ctx)
@@ -106,7 +440,8 @@ func LoadProcs(ctx context.Context, args *Args) ([]xo.Proc, error) {
 	// process procs
 	procMap := make(map[string]xo.Proc)
 	for _, proc := range procs {
-		if !validType(args, false, proc.ProcName) {
+		if !validType(ctx, args, false, proc.ProcName) {
+			addSkipped(ctx, "proc", proc.ProcName, "excluded")
 			continue
 		}
 		// parse return type into template
@@ -195,15 +530,26 @@ func LoadTables(ctx context.Context, args *Args, typ string) ([]xo.Table, error)
 	// create types
 	var m []xo.Table
 	for _, table := range tables {
-		if !validType(args, false, table.TableName) {
+		if !validType(ctx, args, false, table.TableName) {
+			addSkipped(ctx, typ, table.TableName, "excluded")
+			continue
+		}
+		// skip partitions, as they have the same columns as the partitioned
+		// table
+		if table.PartitionOf != "" && !args.SchemaParams.IncludePartitions {
+			addSkipped(ctx, typ, table.TableName, fmt.Sprintf("partition of %q", table.PartitionOf))
 			continue
 		}
 		// create table
 		t := &xo.Table{
-			Type:       typ,
-			Name:       table.TableName,
-			Manual:     true,
-			Definition: strings.TrimSpace(table.ViewDef),
+			Type:         typ,
+			Name:         table.TableName,
+			Manual:       true,
+			Definition:   strings.TrimSpace(table.ViewDef),
+			PartitionOf:  table.PartitionOf,
+			RowSecurity:  table.RowSecurity,
+			Strict:       table.Strict,
+			WithoutRowid: table.WithoutRowid,
 		}
 		// process columns
 		if err := LoadColumns(ctx, args, t); err != nil {
@@ -213,13 +559,15 @@ func LoadTables(ctx context.Context, args *Args, typ string) ([]xo.Table, error)
 		if err := LoadTableIndexes(ctx, args, t); err != nil {
 			return nil, err
 		}
-		m = append(m, *t)
-	}
-	// load foreign keys
-	for i, table := range m {
-		if m[i].ForeignKeys, err = LoadTableForeignKeys(ctx, args, m, table); err != nil {
+		// load triggers
+		if err := LoadTableTriggers(ctx, args, t); err != nil {
 			return nil, err
 		}
+		// load row level security policies
+		if err := LoadTablePolicies(ctx, args, t); err != nil {
+			return nil, err
+		}
+		m = append(m, *t)
 	}
 	return m, nil
 }
@@ -232,19 +580,74 @@ func LoadColumns(ctx context.Context, args *Args, table *xo.Table) error {
 	if err != nil {
 		return err
 	}
-	sqMap := make(map[string]bool)
+	sqMap := make(map[string]*models.Sequence)
 	for _, s := range sequences {
 		table.Manual = false
-		sqMap[s.ColumnName] = true
+		sqMap[s.ColumnName] = s
 	}
 	// load columns
 	columns, err := loader.TableColumns(ctx, table.Name)
 	if err != nil {
 		return err
 	}
+	// load column comments
+	comments, err := loader.ColumnComments(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	commentMap := make(map[string]string)
+	for _, c := range comments {
+		commentMap[c.ColumnName] = c.Comment
+	}
+	// json type overrides
+	jsonTypes := make(map[string]string)
+	for _, s := range args.SchemaParams.JSONTypes {
+		tableName, column, typ, err := parseJSONType(s)
+		if err != nil {
+			return err
+		}
+		if tableName == table.Name {
+			jsonTypes[column] = typ
+		}
+	}
+	for column := range jsonTypes {
+		if !hasColumn(columns, column) {
+			return fmt.Errorf("unknown column %q for table %q in --json-type", column, table.Name)
+		}
+	}
+	// sqlite3 rowid alias, which is the sole primary key declared as INTEGER
+	var rowidAlias string
+	if driver == "sqlite3" && !table.WithoutRowid {
+		var pks []*models.Column
+		for _, c := range columns {
+			if c.IsPrimaryKey {
+				pks = append(pks, c)
+			}
+		}
+		if len(pks) == 1 && strings.EqualFold(pks[0].DataType, "integer") {
+			rowidAlias = pks[0].ColumnName
+		}
+	}
+	// sensitive columns
+	sensitive := make(map[string]bool)
+	for _, s := range args.SchemaParams.Sensitive {
+		tableName, column, err := parseSensitive(s)
+		if err != nil {
+			return err
+		}
+		if tableName == table.Name {
+			sensitive[column] = true
+		}
+	}
+	for column := range sensitive {
+		if !hasColumn(columns, column) {
+			return fmt.Errorf("unknown column %q for table %q in --sensitive", column, table.Name)
+		}
+	}
 	// process columns
 	for _, c := range columns {
-		if !validType(args, true, table.Name, c.ColumnName) {
+		if !validType(ctx, args, true, table.Name, c.ColumnName) {
+			addSkipped(ctx, "column", table.Name+"."+c.ColumnName, "excluded")
 			continue
 		}
 		// set col info
@@ -253,16 +656,32 @@ func LoadColumns(ctx context.Context, args *Args, table *xo.Table) error {
 			return err
 		}
 		d.Nullable = !c.NotNull
+		// sqlite3 permits NULL primary keys, except for the rowid alias and
+		// in WITHOUT ROWID tables
+		if driver == "sqlite3" && c.IsPrimaryKey && (table.WithoutRowid || c.ColumnName == rowidAlias) {
+			d.Nullable = false
+		}
+		seq := sqMap[c.ColumnName]
 		defaultValue := c.DefaultValue.String
-		if defaultValue == "NULL" || sqMap[c.ColumnName] {
+		if defaultValue == "NULL" || seq != nil {
 			defaultValue = ""
 		}
 		col := xo.Field{
-			Name:       c.ColumnName,
-			Type:       d,
-			Default:    defaultValue,
-			IsPrimary:  c.IsPrimaryKey,
-			IsSequence: sqMap[c.ColumnName],
+			Name:        c.ColumnName,
+			Type:        d,
+			Default:     defaultValue,
+			IsPrimary:   c.IsPrimaryKey,
+			IsGenerated: c.IsGenerated,
+			Comment:     commentMap[c.ColumnName],
+		}
+		if seq != nil {
+			col.IsSequence, col.SequenceName, col.IsIdentity, col.IsIdentityAlways = true, seq.SequenceName, seq.IsIdentity, seq.IsIdentityAlways
+		}
+		if col.JSONType, err = jsonType(table.Name, col, jsonTypes[c.ColumnName]); err != nil {
+			return err
+		}
+		if _, ok := xo.CommentTag(col.Comment, "sensitive"); ok || sensitive[c.ColumnName] {
+			col.Sensitive = true
 		}
 		table.Columns = append(table.Columns, col)
 		if col.IsPrimary {
@@ -272,6 +691,92 @@ func LoadColumns(ctx context.Context, args *Args, table *xo.Table) error {
 	return nil
 }
 
+// hasColumn determines if the columns have a column with the name.
+func hasColumn(columns []*models.Column, name string) bool {
+	for _, c := range columns {
+		if c.ColumnName == name {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the concrete type declared for a json column, either by
+// the override or by a '@json(<type>)' tag in the column's comment.
+func jsonType(table string, col xo.Field, override string) (string, error) {
+	typ := override
+	if typ == "" {
+		var ok bool
+		if typ, ok = xo.CommentTag(col.Comment, "json"); !ok {
+			return "", nil
+		}
+		if !jsonTypeRE.MatchString(typ) {
+			return "", fmt.Errorf("invalid @json type %q in comment for column %q of table %q", typ, col.Name, table)
+		}
+	}
+	if (col.Type.Type != "json" && col.Type.Type != "jsonb") || col.Type.IsArray {
+		return "", fmt.Errorf("json type %q declared for column %q of table %q, which is not a json column", typ, col.Name, table)
+	}
+	return typ, nil
+}
+
+// LoadTableTriggers loads trigger definitions per table.
+func LoadTableTriggers(ctx context.Context, args *Args, table *xo.Table) error {
+	triggers, err := loader.TableTriggers(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	for _, trigger := range triggers {
+		table.Triggers = append(table.Triggers, xo.Trigger{
+			Name:       trigger.TriggerName,
+			Timing:     trigger.Timing,
+			Event:      trigger.Event,
+			Function:   trigger.FunctionName,
+			Definition: strings.TrimSpace(trigger.Definition),
+		})
+	}
+	return nil
+}
+
+// LoadTablePolicies loads row level security policy definitions per table.
+func LoadTablePolicies(ctx context.Context, args *Args, table *xo.Table) error {
+	policies, err := loader.TablePolicies(ctx, table.Name)
+	if err != nil {
+		return err
+	}
+	for _, policy := range policies {
+		var roles []string
+		if policy.Roles != "" {
+			roles = strings.Split(policy.Roles, ",")
+		}
+		table.Policies = append(table.Policies, xo.Policy{
+			Name:        policy.PolicyName,
+			Command:     policy.Command,
+			Roles:       roles,
+			Restrictive: !policy.Permissive,
+			Using:       policy.UsingExpr,
+			WithCheck:   policy.WithCheck,
+			Settings:    policySettings(policy.UsingExpr, policy.WithCheck),
+		})
+	}
+	return nil
+}
+
+// policySettings returns the settings read with current_setting in the
+// expressions of a row level security policy.
+func policySettings(exprs ...string) []string {
+	var settings []string
+	seen := make(map[string]bool)
+	for _, expr := range exprs {
+		for _, m := range currentSettingRE.FindAllStringSubmatch(expr, -1) {
+			if name := strings.ToLower(m[1]); !seen[name] {
+				settings, seen[name] = append(settings, name), true
+			}
+		}
+	}
+	return settings
+}
+
 // LoadTableIndexes loads index definitions per table.
 func LoadTableIndexes(ctx context.Context, args *Args, table *xo.Table) error {
 	// load indexes
@@ -292,13 +797,14 @@ func LoadTableIndexes(ctx context.Context, args *Args, table *xo.Table) error {
 			Name:      index.IndexName,
 			IsPrimary: index.IsPrimary,
 			IsUnique:  index.IsUnique,
+			Predicate: index.IndexPredicate,
 		}
 		// load index columns
 		if err := LoadIndexColumns(ctx, args, table, index); err != nil {
 			return err
 		}
 		// load index func name
-		index.Func = indexFuncName(*index, table.Name, args.SchemaParams.UseIndexNames)
+		index.Func = indexFuncName(xo.Naming(ctx), *index, table.Name, args.SchemaParams.UseIndexNames)
 		table.Indexes = append(table.Indexes, *index)
 	}
 	pkeys := table.PrimaryKeys
@@ -319,11 +825,14 @@ func LoadTableIndexes(ctx context.Context, args *Args, table *xo.Table) error {
 			IsUnique:  true,
 			IsPrimary: true,
 		}
-		index.Func = indexFuncName(index, table.Name, args.SchemaParams.UseIndexNames)
+		index.Func = indexFuncName(xo.Naming(ctx), index, table.Name, args.SchemaParams.UseIndexNames)
 		table.Indexes = append(table.Indexes, index)
 	} else if driver == "oracle" && len(table.PrimaryKeys) != 0 {
 	loop:
 		for i, index := range table.Indexes {
+			if len(index.Fields) == 0 || len(index.Expressions) != 0 {
+				continue
+			}
 			for _, field := range index.Fields {
 				if !field.IsPrimary {
 					continue loop
@@ -344,7 +853,15 @@ func LoadIndexColumns(ctx context.Context, args *Args, table *xo.Table, index *x
 		return err
 	}
 	// process index columns
+	var keys []string
+	var hasExpr bool
 	for _, col := range cols {
+		// expression key part
+		if col.Expression != "" {
+			keys, hasExpr = append(keys, col.Expression), true
+			continue
+		}
+		keys = append(keys, col.ColumnName)
 		var field *xo.Field
 		// find field
 		for _, f := range table.Columns {
@@ -359,22 +876,68 @@ func LoadIndexColumns(ctx context.Context, args *Args, table *xo.Table, index *x
 		}
 		index.Fields = append(index.Fields, *field)
 	}
+	// retain the full, ordered key list when there are expressions
+	if hasExpr {
+		index.Expressions = keys
+	}
 	return nil
 }
 
 // LoadTableForeignKeys loads foreign key definitions per table.
-func LoadTableForeignKeys(ctx context.Context, args *Args, tables []xo.Table, table xo.Table) ([]xo.ForeignKey, error) {
+//
+// Referenced tables are resolved against the loaded schemas, allowing foreign
+// keys to refer to tables in other schemas.
+func LoadTableForeignKeys(ctx context.Context, args *Args, schemas []xo.Schema, table xo.Table) ([]xo.ForeignKey, error) {
+	_, _, schemaName := xo.DriverDbSchema(ctx)
 	// load foreign keys
 	foreignKeys, err := loader.TableForeignKeys(ctx, table.Name)
 	if err != nil {
 		return nil, err
 	}
-	fkMap := make(map[string]xo.ForeignKey)
+	fkMap, skip := make(map[string]xo.ForeignKey), make(map[string]bool)
 	// loop over foreign keys for table
 	for _, fkey := range foreignKeys {
+		// ForeignKeyName should only be empty on SQLite. When this happens, we
+		// resort to using the keyid (which is unique to each foreign key, even
+		// if it references multiple columns) as the map for the foreign key
+		key := fkey.ForeignKeyName
+		if fkey.ForeignKeyName == "" {
+			key = strconv.Itoa(fkey.KeyID)
+		}
+		if skip[key] {
+			continue
+		}
+		// determine referenced schema
+		refSchema := fkey.RefSchemaName
+		if refSchema == "" {
+			refSchema = schemaName
+		}
+		refCtx := context.WithValue(ctx, xo.SchemaKey, refSchema)
 		// if the referenced table is excluded, we don't want to omit it
-		if !validType(args, false, fkey.RefTableName) {
+		if !validType(refCtx, args, false, fkey.RefTableName) {
 			fmt.Fprintf(os.Stderr, "WARNING: skipping table %q foreign key %q (%q previously excluded)\n", table.Name, fkey.ForeignKeyName, fkey.RefTableName)
+			addSkipped(ctx, "foreign key", table.Name+"."+key, fmt.Sprintf("%q previously excluded", fkey.RefTableName))
+			skip[key] = true
+			continue
+		}
+		// skip the whole foreign key when any of its columns are excluded
+		if !validType(ctx, args, true, table.Name, fkey.ColumnName) || !validType(refCtx, args, true, fkey.RefTableName, fkey.RefColumnName) {
+			fmt.Fprintf(os.Stderr, "WARNING: skipping table %q foreign key %q (column previously excluded)\n", table.Name, fkey.ForeignKeyName)
+			addSkipped(ctx, "foreign key", table.Name+"."+key, "column previously excluded")
+			delete(fkMap, key)
+			skip[key] = true
+			continue
+		}
+		var tables []xo.Table
+		for _, s := range schemas {
+			if s.Name == refSchema {
+				tables = append(s.Tables, s.Views...)
+				break
+			}
+		}
+		if tables == nil {
+			fmt.Fprintf(os.Stderr, "WARNING: skipping table %q foreign key %q (schema %q not loaded)\n", table.Name, fkey.ForeignKeyName, refSchema)
+			addSkipped(ctx, "foreign key", table.Name+"."+key, fmt.Sprintf("schema %q not loaded", refSchema))
 			continue
 		}
 		// check foreign key
@@ -382,24 +945,29 @@ func LoadTableForeignKeys(ctx context.Context, args *Args, tables []xo.Table, ta
 		if err := checkFk(tables, table, fkey, &field, &refTable, &refField); err != nil {
 			return nil, err
 		}
-		// ForeignKeyName should only be empty on SQLite. When this happens, we
-		// resort to using the keyid (which is unique to each foreign key, even
-		// if it references multiple columns) as the map for the foreign key
-		key := fkey.ForeignKeyName
-		if fkey.ForeignKeyName == "" {
-			key = strconv.Itoa(fkey.KeyID)
+		// only record the referenced schema when it differs
+		if refSchema == schemaName {
+			refSchema = ""
 		}
 		f := fkMap[key]
 		fkMap[key] = xo.ForeignKey{
 			Name:      fkey.ForeignKeyName,
 			Fields:    append(f.Fields, field),
+			RefSchema: refSchema,
 			RefTable:  refTable.Name,
 			RefFields: append(f.RefFields, refField),
 		}
 	}
-	// convert from map to slice
+	// convert from map to slice, in key order, as generated names (ie, for
+	// sqlite3) may not be unique
+	keys := make([]string, 0, len(fkMap))
+	for key := range fkMap {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
 	var fkeys []xo.ForeignKey
-	for _, fkey := range fkMap {
+	for _, key := range keys {
+		fkey := fkMap[key]
 		// manual foreign key name generation if name not found
 		if fkey.Name == "" {
 			var names []string
@@ -409,16 +977,16 @@ func LoadTableForeignKeys(ctx context.Context, args *Args, tables []xo.Table, ta
 			fkey.Name = table.Name + "_" + strings.Join(names, "_") + "_fkey"
 		}
 		// determine foreign key func name
-		fkey.Func = resolveFkName(fkey, table, args.SchemaParams.FkMode.AsString())
+		fkey.Func = resolveFkName(xo.Naming(ctx), fkey, table, args.SchemaParams.FkMode.AsString())
 		// foreign key called func name
-		fkey.RefFunc = indexFuncName(xo.Index{
+		fkey.RefFunc = indexFuncName(xo.Naming(ctx), xo.Index{
 			IsUnique: true,
 			Fields:   fkey.RefFields,
 		}, fkey.RefTable, false)
 		fkeys = append(fkeys, fkey)
 	}
 	// sort fkeys
-	sort.Slice(fkeys, func(i, j int) bool {
+	sort.SliceStable(fkeys, func(i, j int) bool {
 		return fkeys[i].Name < fkeys[j].Name
 	})
 	return fkeys, nil
@@ -426,26 +994,36 @@ func LoadTableForeignKeys(ctx context.Context, args *Args, tables []xo.Table, ta
 
 // validType returns whether the type name given is valid, given the --include
 // and --exclude options provided by the user.
-func validType(args *Args, skipIncludes bool, names ...string) bool {
+//
+// Patterns are matched against both the name (ie, <type> or <type>.<field>)
+// and the name qualified with the schema (ie, <schema>.<type>).
+func validType(ctx context.Context, args *Args, skipIncludes bool, names ...string) bool {
 	include, exclude := args.SchemaParams.Include.AsGlob(), args.SchemaParams.Exclude.AsGlob()
 	if len(include) == 0 && len(exclude) == 0 {
 		return true
 	}
-	target := strings.Join(names, ".")
-	for _, pattern := range exclude {
-		if pattern.Match(target) {
-			return false
+	_, _, schema := xo.DriverDbSchema(ctx)
+	targets := []string{strings.Join(names, ".")}
+	if schema != "" {
+		targets = append(targets, schema+"."+targets[0])
+	}
+	match := func(patterns []glob.Glob) bool {
+		for _, pattern := range patterns {
+			for _, target := range targets {
+				if pattern.Match(target) {
+					return true
+				}
+			}
 		}
+		return false
 	}
-	if len(include) == 0 || skipIncludes {
+	switch {
+	case match(exclude):
+		return false
+	case len(include) == 0 || skipIncludes:
 		return true
 	}
-	for _, pattern := range include {
-		if pattern.Match(target) {
-			return true
-		}
-	}
-	return false
+	return match(include)
 }
 
 // checkFk checks that the foreign key has a matching field, ref table, and ref
@@ -486,8 +1064,8 @@ func checkFk(tables []xo.Table, table xo.Table, fkey *models.ForeignKey, field *
 
 // resolveFkName returns the foreign key name for the passed foreign key.
 // The function converts all names to snake_case.
-func resolveFkName(fkey xo.ForeignKey, table xo.Table, mode string) string {
-	tableName := singularize(fkey.RefTable)
+func resolveFkName(naming *xo.Namer, fkey xo.ForeignKey, table xo.Table, mode string) string {
+	tableName := naming.Type(fkey.RefTable)
 	switch mode {
 	case "parent":
 		// parent causes a foreign key field to be named in the form of
@@ -504,7 +1082,7 @@ func resolveFkName(fkey xo.ForeignKey, table xo.Table, mode string) string {
 		// foreign key func will be book.AuthorByAuthorIDAuthorName
 		var names []string
 		for _, f := range fkey.Fields {
-			names = append(names, f.Name)
+			names = append(names, naming.Column(table.Name, f.Name))
 		}
 		return tableName + "_by_" + strings.Join(names, "_")
 	case "key":
@@ -523,30 +1101,31 @@ func resolveFkName(fkey xo.ForeignKey, table xo.Table, mode string) string {
 		//
 		// inspect all foreign keys and use field if conflict found
 		for _, v := range table.ForeignKeys {
-			if fkey.Name != v.Name && fkey.RefTable == v.RefTable {
-				return resolveFkName(fkey, table, "field")
+			if fkey.Name != v.Name && fkey.RefSchema == v.RefSchema && fkey.RefTable == v.RefTable {
+				return resolveFkName(naming, fkey, table, "field")
 			}
 		}
 		// no conflict, so use parent mode
-		return resolveFkName(fkey, table, "parent")
+		return resolveFkName(naming, fkey, table, "parent")
 	}
 	panic(fmt.Sprintf("invalid mode %q", mode))
 }
 
 // indexFuncName creates the func name for an index and its supplied fields.
-func indexFuncName(index xo.Index, tableName string, useIndexNames bool) string {
+func indexFuncName(naming *xo.Namer, index xo.Index, tableName string, useIndexNames bool) string {
 	// func name
+	typeName, chop := naming.Name(tableName), tableName
 	if index.IsUnique {
-		tableName = inflector.Singularize(tableName)
+		typeName, chop = naming.Type(tableName), inflector.Singularize(tableName)
 	}
-	name := indexName(index.Name, tableName)
+	name := indexName(index.Name, chop)
 	if useIndexNames && name != "" {
-		return tableName + "_by_" + name
+		return typeName + "_by_" + name
 	}
-	names := []string{tableName, "by"}
+	names := []string{typeName, "by"}
 	// add param names
 	for _, field := range index.Fields {
-		names = append(names, field.Name)
+		names = append(names, naming.Column(tableName, field.Name))
 	}
 	return strings.Join(names, "_")
 }
@@ -572,10 +1151,11 @@ func indexName(name, tableName string) string {
 // indexSuffixRE is the regexp of index name suffixes that will be chopped off.
 var indexSuffixRE = regexp.MustCompile(`(?i)_(ix|idx|index|pkey|ukey|key)$`)
 
-// singuralize will singularize a identifier, returning in CamelCase.
-func singularize(s string) string {
-	if i := strings.LastIndex(s, "_"); i != -1 {
-		return s[:i+1] + inflector.Singularize(s[i+1:])
-	}
-	return inflector.Singularize(s)
-}
+// identRE matches identifiers.
+var identRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// currentSettingRE matches the setting name read by current_setting.
+var currentSettingRE = regexp.MustCompile(`(?i)\bcurrent_setting\(\s*'([^']+)'`)
+
+// jsonTypeRE matches a (qualified) type name.
+var jsonTypeRE = regexp.MustCompile(`^(?:[A-Za-z_][A-Za-z0-9_]*\.)?[A-Za-z_][A-Za-z0-9_]*$`)
@@ -0,0 +1,276 @@
+package cmd
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestValidType(t *testing.T) {
+	tests := []struct {
+		name    string
+		include []string
+		exclude []string
+		skip    bool
+		names   []string
+		exp     bool
+	}{
+		{
+			name:  "no patterns",
+			names: []string{"authors"},
+			exp:   true,
+		},
+		{
+			name:    "exclude glob",
+			exclude: []string{"django_*"},
+			names:   []string{"django_session"},
+			exp:     false,
+		},
+		{
+			name:    "exclude schema qualified glob",
+			exclude: []string{"public.schema_migrations"},
+			names:   []string{"schema_migrations"},
+			exp:     false,
+		},
+		{
+			name:    "exclude other schema",
+			exclude: []string{"other.schema_migrations"},
+			names:   []string{"schema_migrations"},
+			exp:     true,
+		},
+		{
+			name:    "exclude column regexp",
+			exclude: []string{`/users\.(password|token)_.*/`},
+			names:   []string{"users", "password_hash"},
+			exp:     false,
+		},
+		{
+			name:    "regexp matches whole name",
+			exclude: []string{"/user/"},
+			names:   []string{"users"},
+			exp:     true,
+		},
+		{
+			name:    "include regexp",
+			include: []string{"/(authors|books)/"},
+			names:   []string{"books"},
+			exp:     true,
+		},
+		{
+			name:    "include miss",
+			include: []string{"/(authors|books)/"},
+			names:   []string{"tags"},
+			exp:     false,
+		},
+		{
+			name:    "include skipped for columns",
+			include: []string{"authors"},
+			skip:    true,
+			names:   []string{"authors", "name"},
+			exp:     true,
+		},
+		{
+			name:    "exclude takes precedence",
+			include: []string{"*"},
+			exclude: []string{"public.*"},
+			names:   []string{"authors"},
+			exp:     false,
+		},
+	}
+	ctx := context.WithValue(context.Background(), xo.SchemaKey, "public")
+	for i, test := range tests {
+		args := NewArgs("go")
+		for _, s := range test.include {
+			if err := args.SchemaParams.Include.Set(s); err != nil {
+				t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+			}
+		}
+		for _, s := range test.exclude {
+			if err := args.SchemaParams.Exclude.Set(s); err != nil {
+				t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+			}
+		}
+		if b := validType(ctx, args, test.skip, test.names...); b != test.exp {
+			t.Errorf("test %d (%s) expected validType(%q) = %t, got: %t", i, test.name, strings.Join(test.names, "."), test.exp, b)
+		}
+	}
+}
+
+func TestJSONType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		array    bool
+		comment  string
+		override string
+		exp      string
+		err      bool
+	}{
+		{"jsonb", false, "", "", "", false},
+		{"jsonb", false, "settings", "", "", false},
+		{"jsonb", false, "user settings @json(Settings)", "", "Settings", false},
+		{"json", false, "@json( types.Settings )", "", "types.Settings", false},
+		{"jsonb", false, "@json(Settings)", "Prefs", "Prefs", false},
+		{"jsonb", false, "", "Prefs", "Prefs", false},
+		{"jsonb", false, "mail@json(Settings)", "", "", false},
+		{"jsonb", false, "@json(map[string]any)", "", "", true},
+		{"text", false, "@json(Settings)", "", "", true},
+		{"text", false, "", "Prefs", "", true},
+		{"jsonb", true, "@json(Settings)", "", "", true},
+	}
+	for i, test := range tests {
+		col := xo.Field{Name: "settings", Type: xo.Type{Type: test.typ, IsArray: test.array}, Comment: test.comment}
+		typ, err := jsonType("users", col, test.override)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%s) expected error, got nil", i, test.comment)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.comment, err)
+		case typ != test.exp:
+			t.Errorf("test %d (%s) expected %q, got: %q", i, test.comment, test.exp, typ)
+		}
+	}
+	// invalid overrides
+	for i, s := range []string{"users=Settings", "users.settings=", ".settings=Settings", "users.=Settings", "users.settings=[]Settings"} {
+		if _, _, _, err := parseJSONType(s); err == nil {
+			t.Errorf("test %d (%q) expected error, got nil", i, s)
+		}
+	}
+}
+
+func TestCheckTableArgs(t *testing.T) {
+	schemas := []xo.Schema{
+		{Name: "public", Tables: []xo.Table{{Name: "users"}}, Views: []xo.Table{{Name: "user_settings"}}},
+		{Name: "audit", MatViews: []xo.Table{{Name: "logins"}}},
+	}
+	tests := []struct {
+		jsonTypes []string
+		sensitive []string
+		exclude   string
+		err       bool
+	}{
+		{nil, nil, "", false},
+		{[]string{"users.settings=Settings"}, nil, "", false},
+		{[]string{"user_settings.settings=Settings", "logins.device=Device"}, nil, "", false},
+		{[]string{"accounts.settings=Settings"}, nil, "", true},
+		{[]string{"accounts.settings=Settings"}, nil, "accounts", false},
+		{nil, []string{"users.password", "logins.ip"}, "", false},
+		{nil, []string{"accounts.password"}, "", true},
+		{nil, []string{"accounts.password"}, "accounts", false},
+	}
+	for i, test := range tests {
+		args := NewArgs("")
+		args.SchemaParams.JSONTypes, args.SchemaParams.Sensitive = test.jsonTypes, test.sensitive
+		if test.exclude != "" {
+			if err := args.SchemaParams.Exclude.Set(test.exclude); err != nil {
+				t.Fatalf("test %d expected no error, got: %v", i, err)
+			}
+		}
+		err := checkTableArgs(context.Background(), args, schemas)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%v %v) expected error, got nil", i, test.jsonTypes, test.sensitive)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%v %v) expected no error, got: %v", i, test.jsonTypes, test.sensitive, err)
+		}
+	}
+}
+
+func TestParseSensitive(t *testing.T) {
+	tests := []struct {
+		s      string
+		table  string
+		column string
+		err    bool
+	}{
+		{"users.password", "users", "password", false},
+		{"users.api.key", "users", "api.key", false},
+		{"users", "", "", true},
+		{".password", "", "", true},
+		{"users.", "", "", true},
+	}
+	for i, test := range tests {
+		table, column, err := parseSensitive(test.s)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%s) expected error, got nil", i, test.s)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.s, err)
+		case table != test.table || column != test.column:
+			t.Errorf("test %d (%s) expected %q %q, got: %q %q", i, test.s, test.table, test.column, table, column)
+		}
+	}
+}
+
+func TestPolicySettings(t *testing.T) {
+	tests := []struct {
+		exprs []string
+		exp   []string
+	}{
+		{[]string{"(owner = CURRENT_USER)"}, nil},
+		{[]string{"(tenant_id = (current_setting('app.current_tenant'::text))::integer)", ""}, []string{"app.current_tenant"}},
+		{[]string{"(tenant_id = current_setting( 'App.Tenant', true)::int)", "(tenant_id = current_setting('app.tenant')::int AND current_setting('app.role') = 'admin')"}, []string{"app.tenant", "app.role"}},
+	}
+	for i, test := range tests {
+		if settings := policySettings(test.exprs...); !reflect.DeepEqual(settings, test.exp) {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, settings)
+		}
+	}
+}
+
+func TestTenantDiff(t *testing.T) {
+	books := func(name string, columns ...xo.Field) xo.Schema {
+		return xo.Schema{
+			Name: name,
+			Tables: []xo.Table{{
+				Name:    "books",
+				Columns: columns,
+			}},
+		}
+	}
+	id := xo.Field{Name: "book_id", Type: xo.Type{Type: "integer"}, IsPrimary: true}
+	title := xo.Field{Name: "title", Type: xo.Type{Type: "text"}}
+	title2 := xo.Field{Name: "title", Type: xo.Type{Type: "varchar", Prec: 255, Nullable: true}}
+	// full returns the books schema with a composite, range, index, and
+	// foreign key, modified by f
+	full := func(name string, f func(*xo.Schema)) xo.Schema {
+		s := books(name, id, title)
+		s.Composites = []xo.Composite{{Name: "address", Fields: []xo.Field{{Name: "street", Type: xo.Type{Type: "text"}}}}}
+		s.Ranges = []xo.Range{{Name: "price_range", SubType: xo.Type{Type: "numeric"}}}
+		s.Tables[0].Indexes = []xo.Index{{Name: "books_title_idx", Fields: []xo.Field{title}, IsUnique: true}}
+		s.Tables[0].ForeignKeys = []xo.ForeignKey{{Name: "books_book_id_fkey", Fields: []xo.Field{id}, RefTable: "authors", RefFields: []xo.Field{{Name: "author_id"}}}}
+		f(&s)
+		return s
+	}
+	tests := []struct {
+		schema xo.Schema
+		tenant xo.Schema
+		exp    string
+	}{
+		{books("tenant_001", id, title), books("tenant_002", id, title), ""},
+		{books("tenant_001", id, title), books("tenant_002", id), "table books differs: (book_id integer primary key) != (book_id integer primary key, title text)"},
+		{books("tenant_001", id, title), books("tenant_002", id, title2), "table books differs: (book_id integer primary key, title varchar(255) null) != (book_id integer primary key, title text)"},
+		{books("tenant_001", id, title), xo.Schema{Name: "tenant_002"}, "missing table books"},
+		{books("tenant_001", id, title), xo.Schema{Name: "tenant_002", Views: []xo.Table{{Name: "books"}}}, "missing table books"},
+		{books("tenant_001", id, title), xo.Schema{Name: "tenant_002", Enums: []xo.Enum{{Name: "book_type"}}, Tables: []xo.Table{{Name: "books", Columns: []xo.Field{id, title}}}}, "unexpected enum book_type"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(*xo.Schema) {}), ""},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Composites = nil }), "missing composite address"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Ranges[0].SubType = xo.Type{Type: "integer"} }), "range price_range differs: integer != numeric"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Tables[0].Indexes = nil }), "missing index books.books_title_idx"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Tables[0].Indexes[0].IsUnique = false }), "index books.books_title_idx differs: (title) != (title) unique"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Tables[0].Indexes[0].Predicate = "(title IS NOT NULL)" }), "index books.books_title_idx differs: (title) unique where (title IS NOT NULL) != (title) unique"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Tables[0].ForeignKeys = nil }), "missing foreign key books.books_book_id_fkey"},
+		{full("tenant_001", func(*xo.Schema) {}), full("tenant_002", func(s *xo.Schema) { s.Tables[0].ForeignKeys[0].RefTable = "people" }), "foreign key books.books_book_id_fkey differs: (book_id) references people(author_id) != (book_id) references authors(author_id)"},
+	}
+	for i, test := range tests {
+		var s string
+		if err := tenantDiff(test.schema, test.tenant); err != nil {
+			s = err.Error()
+		}
+		if s != test.exp {
+			t.Errorf("test %d expected %q, got: %q", i, test.exp, s)
+		}
+	}
+}
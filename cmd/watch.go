@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/xo/xo/templates"
+	xo "github.com/xo/xo/types"
+)
+
+// Watch generates files, and then polls the database schema (and query file,
+// in query mode) for changes, regenerating files when there are changes.
+// Watches until interrupted.
+func Watch(ctx context.Context, mode string, ts *templates.Set, args *Args) error {
+	ctx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	var last []byte
+	var lastErr string
+	for {
+		set, buf, err := watchLoad(ctx, mode, ts, args)
+		switch {
+		case ctx.Err() != nil:
+			return nil
+		case err != nil:
+			// only display the error when it changes, and regenerate once
+			// it is fixed
+			if s := err.Error(); s != lastErr {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+				lastErr = s
+			}
+			last = nil
+		case !bytes.Equal(buf, last):
+			last, lastErr = buf, ""
+			ts.Reset()
+			if err := Generate(ctx, mode, ts, set, args); err != nil {
+				fmt.Fprintf(os.Stderr, "error: %v\n", err)
+			} else {
+				fmt.Fprintf(os.Stderr, "%s: generated %s\n", time.Now().Format("15:04:05"), args.OutParams.Out)
+			}
+		}
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(args.OutParams.WatchInterval):
+		}
+	}
+}
+
+// watchLoad loads the query file and database, returning the set and its
+// encoded form, used to check for changes.
+func watchLoad(ctx context.Context, mode string, ts *templates.Set, args *Args) (*xo.Set, []byte, error) {
+	if mode == "query" && args.QueryParams.File != "" {
+		if err := readQueryFile(args); err != nil {
+			return nil, nil, err
+		}
+	}
+	set, err := Load(ctx, mode, args)
+	if err != nil {
+		return nil, nil, err
+	}
+	buf, err := json.Marshal(set)
+	if err != nil {
+		return nil, nil, err
+	}
+	return set, buf, nil
+}
+
+// readQueryFile reads the query from the query file.
+func readQueryFile(args *Args) error {
+	buf, err := os.ReadFile(args.QueryParams.File)
+	if err != nil {
+		return err
+	}
+	args.QueryParams.Query = string(bytes.TrimRight(buf, "\r\n"))
+	return nil
+}
@@ -43,6 +43,29 @@ WHERE n.nspname LIKE 'pg_temp%'
   AND c.relname = %%id string%%
 ENDSQL
 
+# postgres view rule query
+COMMENT='{{ . }} retrieves the query tree for a view created for introspection.'
+$XOBIN query $PGDB -M -B -l -F PostgresViewRule --func-comment "$COMMENT" --single=models.xo.go -a -o $DEST $@ << ENDSQL
+SELECT
+  r.ev_action::text AS query_tree
+FROM pg_rewrite r
+  JOIN pg_class c ON c.oid = r.ev_class
+  JOIN pg_namespace n ON n.oid = c.relnamespace
+WHERE n.nspname = %%schema string%%
+  AND c.relname = %%id string%%
+  AND r.rulename = '_RETURN'
+ENDSQL
+
+# postgres column not null query
+COMMENT='{{ . }} determines if a table column is not null.'
+$XOBIN query $PGDB -M -B -l -F PostgresColumnNotNull --func-comment "$COMMENT" --single=models.xo.go -a -o $DEST $@ << ENDSQL
+SELECT
+  a.attnotnull::boolean AS not_null
+FROM pg_attribute a
+WHERE a.attrelid = %%tableOid int64%%
+  AND a.attnum = %%attnum int%%
+ENDSQL
+
 # postgres view drop query
 COMMENT='{{ . }} drops a view created for introspection.'
 $XOBIN query $PGDB -M -B -X -F PostgresViewDrop --func-comment "$COMMENT" --single=models.xo.go -I -a -o $DEST $@ << ENDSQL
@@ -79,6 +102,39 @@ FROM pg_type t
   LEFT JOIN pg_enum e ON t.oid = e.enumtypid
 WHERE n.nspname = %%schema string%%
   AND t.typname = %%enum string%%
+ORDER BY e.enumsortorder
+ENDSQL
+
+# postgres composite type attribute list query
+COMMENT='{{ . }} is a composite type attribute.'
+$XOBIN query $PGDB -M -B -2 -T CompositeAttribute -F PostgresCompositeAttributes --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
+SELECT
+  t.typname::varchar AS type_name,
+  a.attname::varchar AS attr_name,
+  format_type(a.atttypid, a.atttypmod)::varchar AS data_type
+FROM pg_type t
+  JOIN ONLY pg_namespace n ON n.oid = t.typnamespace
+  JOIN ONLY pg_class c ON c.oid = t.typrelid
+  JOIN pg_attribute a ON a.attrelid = c.oid
+WHERE t.typtype = 'c'
+  AND c.relkind = 'c'
+  AND a.attnum > 0
+  AND a.attisdropped = false
+  AND n.nspname = %%schema string%%
+ORDER BY t.typname, a.attnum
+ENDSQL
+
+# postgres range type list query
+COMMENT='{{ . }} is a range type.'
+$XOBIN query $PGDB -M -B -2 -T Range -F PostgresRanges --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
+SELECT
+  t.typname::varchar AS range_name,
+  format_type(r.rngsubtype, NULL)::varchar AS sub_type
+FROM pg_range r
+  JOIN ONLY pg_type t ON t.oid = r.rngtypid
+  JOIN ONLY pg_namespace n ON n.oid = t.typnamespace
+WHERE n.nspname = %%schema string%%
+ORDER BY t.typname
 ENDSQL
 
 # postgres proc list query
@@ -152,22 +208,35 @@ $XOBIN query $PGDB -M -B -2 -T Table -F PostgresTables --type-comment "$COMMENT"
 SELECT
   (CASE c.relkind
     WHEN 'r' THEN 'table'
+    WHEN 'p' THEN 'table'
     WHEN 'v' THEN 'view'
+    WHEN 'm' THEN 'matview'
   END)::varchar AS type,
   c.relname::varchar AS table_name,
   false::boolean AS manual_pk,
   CASE c.relkind
     WHEN 'r' THEN ''
+    WHEN 'p' THEN ''
     WHEN 'v' THEN v.definition
-  END AS view_def
+    WHEN 'm' THEN mv.definition
+  END AS view_def,
+  COALESCE(pc.relname, '')::varchar AS partition_of,
+  c.relrowsecurity::boolean AS row_security
 FROM pg_class c
   JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
   LEFT JOIN pg_views v ON n.nspname = v.schemaname
     AND v.viewname = c.relname
+  LEFT JOIN pg_matviews mv ON n.nspname = mv.schemaname
+    AND mv.matviewname = c.relname
+  LEFT JOIN pg_inherits i ON i.inhrelid = c.oid
+    AND c.relispartition
+  LEFT JOIN pg_class pc ON pc.oid = i.inhparent
 WHERE n.nspname = %%schema string%%
   AND (CASE c.relkind
     WHEN 'r' THEN 'table'
+    WHEN 'p' THEN 'table'
     WHEN 'v' THEN 'view'
+    WHEN 'm' THEN 'matview'
   END) = LOWER(%%typ string%%)
 ENDSQL
 
@@ -181,7 +250,8 @@ SELECT
   format_type(a.atttypid, a.atttypmod)::varchar AS data_type,
   a.attnotnull::boolean AS not_null,
   COALESCE(pg_get_expr(ad.adbin, ad.adrelid), '')::varchar AS default_value,
-  COALESCE(ct.contype = 'p', false)::boolean AS is_primary_key
+  COALESCE(ct.contype = 'p', false)::boolean AS is_primary_key,
+  (a.attgenerated <> '')::boolean AS is_generated
 FROM pg_attribute a
   JOIN ONLY pg_class c ON c.oid = a.attrelid
   JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
@@ -201,7 +271,10 @@ ENDSQL
 COMMENT='{{ . }} is a sequence.'
 $XOBIN query $PGDB -M -B -2 -T Sequence -F PostgresTableSequences --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
 SELECT
-  a.attname::varchar as column_name
+  a.attname::varchar as column_name,
+  s.relname::varchar AS sequence_name,
+  (a.attidentity <> '')::boolean AS is_identity,
+  (a.attidentity = 'a')::boolean AS is_identity_always
 FROM pg_class s
   JOIN pg_depend d ON d.objid = s.oid
   JOIN pg_class t ON d.objid = s.oid AND d.refobjid = t.oid
@@ -212,6 +285,67 @@ WHERE s.relkind = 'S'
   AND t.relname = %%table string%%
 ENDSQL
 
+# postgres column comment list query
+COMMENT='{{ . }} is a column comment.'
+$XOBIN query $PGDB -M -B -2 -T ColumnComment -F PostgresColumnComments --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
+SELECT
+  a.attname::varchar AS column_name,
+  col_description(c.oid, a.attnum)::varchar AS comment
+FROM pg_attribute a
+  JOIN ONLY pg_class c ON c.oid = a.attrelid
+  JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+WHERE a.attisdropped = false
+  AND a.attnum > 0
+  AND col_description(c.oid, a.attnum) IS NOT NULL
+  AND n.nspname = %%schema string%%
+  AND c.relname = %%table string%%
+ORDER BY a.attnum
+ENDSQL
+
+# postgres table trigger list query
+COMMENT='{{ . }} is a trigger.'
+$XOBIN query $PGDB -M -B -2 -T Trigger -F PostgresTableTriggers --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
+SELECT
+  t.tgname::varchar AS trigger_name,
+  (CASE
+    WHEN t.tgtype & 2 <> 0 THEN 'BEFORE'
+    WHEN t.tgtype & 64 <> 0 THEN 'INSTEAD OF'
+    ELSE 'AFTER'
+  END)::varchar AS timing,
+  concat_ws(' OR ',
+    CASE WHEN t.tgtype & 4 <> 0 THEN 'INSERT' END,
+    CASE WHEN t.tgtype & 16 <> 0 THEN 'UPDATE' END,
+    CASE WHEN t.tgtype & 8 <> 0 THEN 'DELETE' END,
+    CASE WHEN t.tgtype & 32 <> 0 THEN 'TRUNCATE' END
+  )::varchar AS event,
+  p.proname::varchar AS function_name,
+  pg_get_triggerdef(t.oid, true)::varchar AS definition
+FROM pg_trigger t
+  JOIN ONLY pg_class c ON c.oid = t.tgrelid
+  JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
+  JOIN pg_proc p ON p.oid = t.tgfoid
+WHERE NOT t.tgisinternal
+  AND n.nspname = %%schema string%%
+  AND c.relname = %%table string%%
+ORDER BY t.tgname
+ENDSQL
+
+# postgres table row level security policy list query
+COMMENT='{{ . }} is a row level security policy.'
+$XOBIN query $PGDB -M -B -2 -T Policy -F PostgresTablePolicies --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
+SELECT
+  policyname::varchar AS policy_name,
+  (permissive = 'PERMISSIVE')::boolean AS permissive,
+  array_to_string(roles, ',')::varchar AS roles,
+  cmd::varchar AS command,
+  COALESCE(qual, '')::varchar AS using_expr,
+  COALESCE(with_check, '')::varchar AS with_check
+FROM pg_policies
+WHERE schemaname = %%schema string%%
+  AND tablename = %%table string%%
+ORDER BY policyname
+ENDSQL
+
 # postgres table foreign key list query
 COMMENT='{{ . }} is a foreign key.'
 $XOBIN query $PGDB -M -B -2 -T ForeignKey -F PostgresTableForeignKeys --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
@@ -220,7 +354,8 @@ SELECT
   kcu.column_name::varchar AS column_name,
   ccu.table_name::varchar AS ref_table_name,
   ccu.column_name::varchar AS ref_column_name,
-  0::integer AS key_id
+  0::integer AS key_id,
+  ccu.table_schema::varchar AS ref_schema_name
 FROM information_schema.table_constraints tc
   JOIN information_schema.key_column_usage AS kcu ON tc.constraint_name = kcu.constraint_name
     AND tc.table_schema = kcu.table_schema
@@ -230,12 +365,14 @@ FROM information_schema.table_constraints tc
         PARTITION BY
           table_schema,
           table_name,
+          constraint_schema,
           constraint_name
         ORDER BY row_num
       ) AS ordinal_position,
       table_schema,
       table_name,
       column_name,
+      constraint_schema,
       constraint_name
     FROM (
       SELECT
@@ -243,11 +380,12 @@ FROM information_schema.table_constraints tc
         table_schema,
         table_name,
         column_name,
+        constraint_schema,
         constraint_name
       FROM information_schema.constraint_column_usage
     ) t
   ) AS ccu ON ccu.constraint_name = tc.constraint_name
-    AND ccu.table_schema = tc.table_schema
+    AND ccu.constraint_schema = tc.constraint_schema
     AND ccu.ordinal_position = kcu.ordinal_position
 WHERE tc.constraint_type = 'FOREIGN KEY'
   AND tc.table_schema = %%schema string%%
@@ -260,13 +398,13 @@ $XOBIN query $PGDB -M -B -2 -T Index -F PostgresTableIndexes --type-comment "$CO
 SELECT
   DISTINCT ic.relname::varchar AS index_name,
   i.indisunique::boolean AS is_unique,
-  i.indisprimary::boolean AS is_primary
+  i.indisprimary::boolean AS is_primary,
+  COALESCE(pg_get_expr(i.indpred, i.indrelid, true), '')::varchar AS index_predicate
 FROM pg_index i
   JOIN ONLY pg_class c ON c.oid = i.indrelid
   JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
   JOIN ONLY pg_class ic ON ic.oid = i.indexrelid
-WHERE i.indkey <> '0'
-  AND n.nspname = %%schema string%%
+WHERE n.nspname = %%schema string%%
   AND c.relname = %%table string%%
 ENDSQL
 
@@ -274,19 +412,21 @@ ENDSQL
 COMMENT='{{ . }} is a index column.'
 $XOBIN query $PGDB -M -B -2 -T IndexColumn -F PostgresIndexColumns --type-comment "$COMMENT" -o $DEST $@ << ENDSQL
 SELECT
-  (row_number() over())::integer AS seq_no,
-  a.attnum::integer AS cid,
-  a.attname::varchar AS column_name
+  k.n::integer AS seq_no,
+  i.indkey[k.n - 1]::integer AS cid,
+  COALESCE(a.attname, '')::varchar AS column_name,
+  (CASE WHEN i.indkey[k.n - 1] = 0 THEN pg_get_indexdef(i.indexrelid, k.n, true) ELSE '' END)::varchar AS expression
 FROM pg_index i
   JOIN ONLY pg_class c ON c.oid = i.indrelid
   JOIN ONLY pg_namespace n ON n.oid = c.relnamespace
   JOIN ONLY pg_class ic ON ic.oid = i.indexrelid
-  LEFT JOIN pg_attribute a ON i.indrelid = a.attrelid
-    AND a.attnum = ANY(i.indkey)
+  CROSS JOIN LATERAL generate_series(1, i.indnatts) k(n)
+  LEFT JOIN pg_attribute a ON a.attrelid = i.indrelid
+    AND a.attnum = i.indkey[k.n - 1]
     AND a.attisdropped = false
-WHERE i.indkey <> '0'
-  AND n.nspname = %%schema string%%
+WHERE n.nspname = %%schema string%%
   AND ic.relname = %%index string%%
+ORDER BY k.n
 ENDSQL
 
 # postgres index column order query
@@ -403,7 +543,8 @@ SELECT
   IF(data_type = 'enum', column_name, column_type) AS data_type,
   IF(is_nullable = 'YES', false, true) AS not_null,
   column_default AS default_value,
-  IF(column_key = 'PRI', true, false) AS is_primary_key
+  IF(column_key = 'PRI', true, false) AS is_primary_key,
+  IF(extra IN ('VIRTUAL GENERATED', 'STORED GENERATED', 'PERSISTENT GENERATED'), true, false) AS is_generated
 FROM information_schema.columns
 WHERE table_schema = %%schema string%%
   AND table_name = %%table string%%
@@ -413,24 +554,53 @@ ENDSQL
 # mysql sequence list query
 $XOBIN query $MYDB -M -B -2 -T Sequence -F MysqlTableSequences -a -o $DEST $@ << ENDSQL
 SELECT
-  column_name
+  column_name,
+  true AS is_identity
 FROM information_schema.columns c
 WHERE c.extra = 'auto_increment'
   AND c.table_schema = %%schema string%%
   AND c.table_name = %%table string%%
 ENDSQL
 
+# mysql column comment list query
+$XOBIN query $MYDB -M -B -2 -T ColumnComment -F MysqlColumnComments -a -o $DEST $@ << ENDSQL
+SELECT
+  column_name,
+  column_comment AS comment
+FROM information_schema.columns
+WHERE column_comment <> ''
+  AND table_schema = %%schema string%%
+  AND table_name = %%table string%%
+ORDER BY ordinal_position
+ENDSQL
+
+# mysql table trigger list query
+$XOBIN query $MYDB -M -B -2 -T Trigger -F MysqlTableTriggers -a -o $DEST $@ << ENDSQL
+SELECT
+  trigger_name,
+  action_timing AS timing,
+  event_manipulation AS event,
+  '' AS function_name,
+  CONCAT('CREATE TRIGGER ', trigger_name, ' ', action_timing, ' ', event_manipulation, ' ON ', event_object_table, ' FOR EACH ', action_orientation, ' ', action_statement) AS definition
+FROM information_schema.triggers
+WHERE event_object_schema = %%schema string%%
+  AND event_object_table = %%table string%%
+ORDER BY trigger_name
+ENDSQL
+
 # mysql table foreign key list query
 $XOBIN query $MYDB -M -B -2 -T ForeignKey -F MysqlTableForeignKeys -a -o $DEST $@ << ENDSQL
 SELECT
   constraint_name AS foreign_key_name,
   column_name AS column_name,
   referenced_table_name AS ref_table_name,
-  referenced_column_name AS ref_column_name
+  referenced_column_name AS ref_column_name,
+  referenced_table_schema AS ref_schema_name
 FROM information_schema.key_column_usage
 WHERE referenced_table_name IS NOT NULL
   AND table_schema = %%schema string%%
   AND table_name = %%table string%%
+ORDER BY constraint_name, ordinal_position
 ENDSQL
 
 # mysql table index list query
@@ -482,15 +652,19 @@ ENDSQL
 $XOBIN query $SQDB -M -B -2 -T Table -F Sqlite3Tables -I -a -o $DEST $@ << ENDSQL
 /* %%schema string,interpolate%% */
 SELECT
-  type,
-  tbl_name AS table_name,
-  CASE LOWER(type)
+  m.type,
+  m.tbl_name AS table_name,
+  CASE LOWER(m.type)
     WHEN 'table' THEN ''
-    WHEN 'view' THEN sql
-  END AS view_def
-FROM sqlite_master
-WHERE tbl_name NOT LIKE 'sqlite_%'
-  AND LOWER(type) = LOWER(%%typ string%%)
+    WHEN 'view' THEN m.sql
+  END AS view_def,
+  COALESCE(p.strict, 0) <> 0 AS strict,
+  COALESCE(p.wr, 0) <> 0 AS without_rowid
+FROM sqlite_master m
+  LEFT JOIN pragma_table_list p ON p.schema = 'main'
+    AND p.name = m.tbl_name
+WHERE m.tbl_name NOT LIKE 'sqlite_%'
+  AND LOWER(m.type) = LOWER(%%typ string%%)
 ENDSQL
 
 # sqlite3 table column list query
@@ -502,8 +676,10 @@ SELECT
   type AS data_type,
   "notnull" AS not_null,
   dflt_value AS default_value,
-  CAST(pk <> 0 AS boolean) AS is_primary_key
-FROM pragma_table_info(%%table string%%)
+  CAST(pk <> 0 AS boolean) AS is_primary_key,
+  CAST(hidden IN (2, 3) AS boolean) AS is_generated
+FROM pragma_table_xinfo(%%table string%%)
+WHERE hidden <> 1
 ENDSQL
 
 # sqlite3 sequence list query
@@ -535,11 +711,29 @@ WITH RECURSIVE
     FROM c
     WHERE col LIKE '%autoincrement%'
   )
-SELECT col AS column_name
+SELECT
+  col AS column_name,
+  1 AS is_identity
 FROM d
 WHERE name = %%table string%%
 ENDSQL
 
+# sqlite3 table trigger list query (main schema only, temp triggers are in
+# sqlite_temp_master)
+$XOBIN query $SQDB -M -B -2 -T Trigger -F Sqlite3TableTriggers -I -a -o $DEST $@ << ENDSQL
+/* %%schema string,interpolate%% */
+SELECT
+  name AS trigger_name,
+  '' AS timing,
+  '' AS event,
+  '' AS function_name,
+  sql AS definition
+FROM sqlite_master
+WHERE type = 'trigger'
+  AND tbl_name = %%table string%%
+ORDER BY name
+ENDSQL
+
 # sqlite3 table foreign key list query
 $XOBIN query $SQDB -M -B -2 -T ForeignKey -F Sqlite3TableForeignKeys -I -a -o $DEST $@ << ENDSQL
 /* %%schema string,interpolate%% */
@@ -555,10 +749,13 @@ ENDSQL
 $XOBIN query $SQDB -M -B -2 -T Index -F Sqlite3TableIndexes -I -a -o $DEST $@ << ENDSQL
 /* %%schema string,interpolate%% */
 SELECT
-  name AS index_name,
-  "unique" AS is_unique,
-  CAST(origin = 'pk' AS boolean) AS is_primary
-FROM pragma_index_list(%%table string%%)
+  il.name AS index_name,
+  il."unique" AS is_unique,
+  CAST(il.origin = 'pk' AS boolean) AS is_primary,
+  CASE WHEN il.partial THEN TRIM(SUBSTR(m.sql, INSTR(UPPER(m.sql), ' WHERE ') + 7)) ELSE '' END AS index_predicate
+FROM pragma_index_list(%%table string%%) il
+  LEFT JOIN sqlite_master m ON m.type = 'index'
+    AND m.name = il.name
 ENDSQL
 
 # sqlite3 index column list query
@@ -567,10 +764,21 @@ $XOBIN query $SQDB -M -B -2 -T IndexColumn -F Sqlite3IndexColumns -I -a -o $DEST
 SELECT
   seqno AS seq_no,
   cid,
-  name AS column_name
+  COALESCE(name, '') AS column_name
 FROM pragma_index_info(%%index string%%)
 ENDSQL
 
+# sqlite3 index definition query
+COMMENT='{{ . }} retrieves the create statement for an index.'
+$XOBIN query $SQDB -M -B -l -F Sqlite3IndexSQL --func-comment "$COMMENT" --single=models.xo.go -I -a -o $DEST $@ << ENDSQL
+/* %%schema string,interpolate%% */
+SELECT
+  COALESCE(sql, '') AS index_sql
+FROM sqlite_master
+WHERE type = 'index'
+  AND name = %%index string%%
+ENDSQL
+
 # sqlserver view create query
 COMMENT='{{ . }} creates a view for introspection.'
 $XOBIN query $MSDB -M -B -X -F SqlserverViewCreate --func-comment "$COMMENT" --single=models.xo.go -I -a -o $DEST $@ << ENDSQL
@@ -670,7 +878,8 @@ SELECT
         AND z.colid = c.colid
     WHERE i.id = o.id
       AND i.name = k.name
-  ), 0) > 0, 1, 0) AS is_primary_key
+  ), 0) > 0, 1, 0) AS is_primary_key,
+  IIF(c.iscomputed = 1, 1, 0) AS is_generated
 FROM syscolumns c
   JOIN sysobjects o ON o.id = c.id
   LEFT JOIN sysobjects k ON k.xtype = 'PK'
@@ -685,7 +894,8 @@ ENDSQL
 # sqlserver sequence list query
 $XOBIN query $MSDB -M -B -2 -T Sequence -F SqlserverTableSequences -a -o $DEST $@ << ENDSQL
 SELECT
-  COL_NAME(o.object_id, c.column_id) AS column_name
+  COL_NAME(o.object_id, c.column_id) AS column_name,
+  CAST(1 AS BIT) AS is_identity
 FROM sys.objects o
   INNER JOIN sys.columns c ON o.object_id = c.object_id
 WHERE c.is_identity = 1
@@ -700,7 +910,8 @@ SELECT
   fk.name AS foreign_key_name,
   col.name AS column_name,
   pk_tab.name AS ref_table_name,
-  pk_col.name AS ref_column_name
+  pk_col.name AS ref_column_name,
+  schema_name(pk_tab.schema_id) AS ref_schema_name
 FROM sys.tables tab
   INNER JOIN sys.columns col ON col.object_id = tab.object_id
   LEFT OUTER JOIN sys.foreign_key_columns fk_cols ON fk_cols.parent_object_id = tab.object_id
@@ -712,6 +923,7 @@ FROM sys.tables tab
 WHERE schema_name(tab.schema_id) = %%schema string%%
   AND tab.name = %%table string%%
   AND fk.object_id IS NOT NULL
+ORDER BY fk.name, fk_cols.constraint_column_id
 ENDSQL
 
 # sqlserver table index list query
@@ -719,7 +931,8 @@ $XOBIN query $MSDB -M -B -2 -T Index -F SqlserverTableIndexes -a -o $DEST $@ <<
 SELECT
   i.name AS index_name,
   i.is_primary_key AS is_primary,
-  i.is_unique
+  i.is_unique,
+  COALESCE(i.filter_definition, '') AS index_predicate
 FROM sys.indexes i
   INNER JOIN sysobjects o ON i.object_id = o.id
 WHERE i.name IS NOT NULL
@@ -863,8 +1076,9 @@ SELECT
     WHEN 'RAW' THEN 'RAW(' || c.data_length || ')'
     ELSE c.data_type END) AS data_type,
   CASE WHEN c.nullable = 'N' THEN '1' ELSE '0' END AS not_null,
-  CASE WHEN p.column_id IS NOT NULL THEN '1' ELSE '0' END as is_primary_key
-FROM all_tab_columns c
+  CASE WHEN p.column_id IS NOT NULL THEN '1' ELSE '0' END as is_primary_key,
+  CASE WHEN c.virtual_column = 'YES' THEN '1' ELSE '0' END AS is_generated
+FROM all_tab_cols c
   LEFT JOIN (
     SELECT distinct c.column_id FROM all_tab_columns c
     JOIN all_cons_columns l ON l.owner = c.owner
@@ -878,13 +1092,15 @@ FROM all_tab_columns c
   ) p on p.column_id = c.column_id
 WHERE c.owner = UPPER(%%schema string%%)
   AND c.table_name = UPPER(%%table string%%)
+  AND c.hidden_column = 'NO'
 ORDER BY c.column_id
 ENDSQL
 
 # oracle sequence list query
 $XOBIN query $ORDB -M -B -2 -T Sequence -F OracleTableSequences -a -o $DEST $@ << ENDSQL
 SELECT
-  LOWER(c.column_name) AS column_name
+  LOWER(c.column_name) AS column_name,
+  1 AS is_identity
 FROM all_tab_columns c
 WHERE c.identity_column='YES'
   AND c.owner = UPPER(%%schema string%%)
@@ -908,6 +1124,7 @@ FROM user_cons_columns a
 WHERE c.constraint_type = 'R'
   AND a.owner = UPPER(%%schema string%%)
   AND a.table_name = UPPER(%%table string%%)
+ORDER BY a.constraint_name, a.position
 ENDSQL
 
 # oracle table index list query
@@ -10,6 +10,8 @@ import (
 func init() {
 	Symbols["github.com/xo/xo/loader/loader"] = map[string]reflect.Value{
 		// function, constant and variable definitions
+		"ColumnComments":       reflect.ValueOf(loader.ColumnComments),
+		"Composites":           reflect.ValueOf(loader.Composites),
 		"EnumValues":           reflect.ValueOf(loader.EnumValues),
 		"Enums":                reflect.ValueOf(loader.Enums),
 		"Flags":                reflect.ValueOf(loader.Flags),
@@ -19,26 +21,34 @@ func init() {
 		"NthParam":             reflect.ValueOf(loader.NthParam),
 		"OracleGoType":         reflect.ValueOf(loader.OracleGoType),
 		"PQPostgresGoType":     reflect.ValueOf(loader.PQPostgresGoType),
+		"PgxPostgresGoType":    reflect.ValueOf(loader.PgxPostgresGoType),
 		"PostgresFlags":        reflect.ValueOf(loader.PostgresFlags),
 		"PostgresGoType":       reflect.ValueOf(loader.PostgresGoType),
 		"PostgresIndexColumns": reflect.ValueOf(loader.PostgresIndexColumns),
 		"PostgresTableColumns": reflect.ValueOf(loader.PostgresTableColumns),
+		"PostgresViewNotNull":  reflect.ValueOf(loader.PostgresViewNotNull),
 		"PostgresViewStrip":    reflect.ValueOf(loader.PostgresViewStrip),
 		"ProcParams":           reflect.ValueOf(loader.ProcParams),
 		"Procs":                reflect.ValueOf(loader.Procs),
+		"Ranges":               reflect.ValueOf(loader.Ranges),
 		"Register":             reflect.ValueOf(loader.Register),
 		"Schema":               reflect.ValueOf(loader.Schema),
 		"Sqlite3GoType":        reflect.ValueOf(loader.Sqlite3GoType),
+		"Sqlite3IndexColumns":  reflect.ValueOf(loader.Sqlite3IndexColumns),
+		"Sqlite3TableTriggers": reflect.ValueOf(loader.Sqlite3TableTriggers),
 		"SqlserverGoType":      reflect.ValueOf(loader.SqlserverGoType),
 		"SqlserverViewStrip":   reflect.ValueOf(loader.SqlserverViewStrip),
 		"StdlibPostgresGoType": reflect.ValueOf(loader.StdlibPostgresGoType),
 		"TableColumns":         reflect.ValueOf(loader.TableColumns),
 		"TableForeignKeys":     reflect.ValueOf(loader.TableForeignKeys),
 		"TableIndexes":         reflect.ValueOf(loader.TableIndexes),
+		"TablePolicies":        reflect.ValueOf(loader.TablePolicies),
 		"TableSequences":       reflect.ValueOf(loader.TableSequences),
+		"TableTriggers":        reflect.ValueOf(loader.TableTriggers),
 		"Tables":               reflect.ValueOf(loader.Tables),
 		"ViewCreate":           reflect.ValueOf(loader.ViewCreate),
 		"ViewDrop":             reflect.ValueOf(loader.ViewDrop),
+		"ViewNotNull":          reflect.ValueOf(loader.ViewNotNull),
 		"ViewSchema":           reflect.ValueOf(loader.ViewSchema),
 		"ViewStrip":            reflect.ValueOf(loader.ViewStrip),
 		"ViewTruncate":         reflect.ValueOf(loader.ViewTruncate),
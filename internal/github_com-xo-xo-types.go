@@ -10,19 +10,40 @@ import (
 func init() {
 	Symbols["github.com/xo/xo/types/types"] = map[string]reflect.Value{
 		// function, constant and variable definitions
+		"AddInitialisms": reflect.ValueOf(types.AddInitialisms),
 		"ArrayModeKey":   reflect.ValueOf(types.ArrayModeKey),
+		"CommentTag":     reflect.ValueOf(types.CommentTag),
 		"DbKey":          reflect.ValueOf(types.DbKey),
 		"DriverDbSchema": reflect.ValueOf(types.DriverDbSchema),
 		"DriverKey":      reflect.ValueOf(types.DriverKey),
+		"EnumAlias":      reflect.ValueOf(types.EnumAlias),
+		"Factories":      reflect.ValueOf(types.Factories),
+		"FactoriesKey":   reflect.ValueOf(types.FactoriesKey),
+		"HashSet":        reflect.ValueOf(types.HashSet),
+		"Jobs":           reflect.ValueOf(types.Jobs),
+		"JobsKey":        reflect.ValueOf(types.JobsKey),
+		"Naming":         reflect.ValueOf(types.Naming),
+		"NamingKey":      reflect.ValueOf(types.NamingKey),
+		"NewNamer":       reflect.ValueOf(types.NewNamer),
 		"NewValue":       reflect.ValueOf(types.NewValue),
 		"Out":            reflect.ValueOf(types.Out),
 		"OutKey":         reflect.ValueOf(types.OutKey),
 		"ParseType":      reflect.ValueOf(types.ParseType),
+		"ProvenanceKey":  reflect.ValueOf(types.ProvenanceKey),
+		"ProvenanceOf":   reflect.ValueOf(types.ProvenanceOf),
 		"SchemaKey":      reflect.ValueOf(types.SchemaKey),
+		"SetEnumAliases": reflect.ValueOf(types.SetEnumAliases),
 		"Single":         reflect.ValueOf(types.Single),
 		"SingleKey":      reflect.ValueOf(types.SingleKey),
+		"SourceKey":      reflect.ValueOf(types.SourceKey),
+		"Tenant":         reflect.ValueOf(types.Tenant),
+		"TenantKey":      reflect.ValueOf(types.TenantKey),
+		"Tests":          reflect.ValueOf(types.Tests),
+		"TestsKey":       reflect.ValueOf(types.TestsKey),
+		"VersionKey":     reflect.ValueOf(types.VersionKey),
 
 		// type definitions
+		"Composite":    reflect.ValueOf((*types.Composite)(nil)),
 		"ContextKey":   reflect.ValueOf((*types.ContextKey)(nil)),
 		"Enum":         reflect.ValueOf((*types.Enum)(nil)),
 		"Field":        reflect.ValueOf((*types.Field)(nil)),
@@ -30,13 +51,18 @@ func init() {
 		"FlagSet":      reflect.ValueOf((*types.FlagSet)(nil)),
 		"ForeignKey":   reflect.ValueOf((*types.ForeignKey)(nil)),
 		"Index":        reflect.ValueOf((*types.Index)(nil)),
+		"Namer":        reflect.ValueOf((*types.Namer)(nil)),
+		"Policy":       reflect.ValueOf((*types.Policy)(nil)),
 		"Proc":         reflect.ValueOf((*types.Proc)(nil)),
+		"Provenance":   reflect.ValueOf((*types.Provenance)(nil)),
 		"Query":        reflect.ValueOf((*types.Query)(nil)),
+		"Range":        reflect.ValueOf((*types.Range)(nil)),
 		"Schema":       reflect.ValueOf((*types.Schema)(nil)),
 		"Set":          reflect.ValueOf((*types.Set)(nil)),
 		"Table":        reflect.ValueOf((*types.Table)(nil)),
 		"Template":     reflect.ValueOf((*types.Template)(nil)),
 		"TemplateType": reflect.ValueOf((*types.TemplateType)(nil)),
+		"Trigger":      reflect.ValueOf((*types.Trigger)(nil)),
 		"Type":         reflect.ValueOf((*types.Type)(nil)),
 		"Value":        reflect.ValueOf((*types.Value)(nil)),
 	}
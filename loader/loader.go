@@ -58,16 +58,22 @@ type Loader struct {
 	Schema           func(context.Context, models.DB) (string, error)
 	Enums            func(context.Context, models.DB, string) ([]*models.Enum, error)
 	EnumValues       func(context.Context, models.DB, string, string) ([]*models.EnumValue, error)
+	Composites       func(context.Context, models.DB, string) ([]*models.CompositeAttribute, error)
+	Ranges           func(context.Context, models.DB, string) ([]*models.Range, error)
 	Procs            func(context.Context, models.DB, string) ([]*models.Proc, error)
 	ProcParams       func(context.Context, models.DB, string, string) ([]*models.ProcParam, error)
 	Tables           func(context.Context, models.DB, string, string) ([]*models.Table, error)
 	TableColumns     func(context.Context, models.DB, string, string) ([]*models.Column, error)
 	TableSequences   func(context.Context, models.DB, string, string) ([]*models.Sequence, error)
+	TableTriggers    func(context.Context, models.DB, string, string) ([]*models.Trigger, error)
+	TablePolicies    func(context.Context, models.DB, string, string) ([]*models.Policy, error)
+	ColumnComments   func(context.Context, models.DB, string, string) ([]*models.ColumnComment, error)
 	TableForeignKeys func(context.Context, models.DB, string, string) ([]*models.ForeignKey, error)
 	TableIndexes     func(context.Context, models.DB, string, string) ([]*models.Index, error)
 	IndexColumns     func(context.Context, models.DB, string, string, string) ([]*models.IndexColumn, error)
 	ViewCreate       func(context.Context, models.DB, string, string, []string) (sql.Result, error)
 	ViewSchema       func(context.Context, models.DB, string) (string, error)
+	ViewNotNull      func(context.Context, models.DB, string, string) ([]string, error)
 	ViewTruncate     func(context.Context, models.DB, string, string) (sql.Result, error)
 	ViewDrop         func(context.Context, models.DB, string, string) (sql.Result, error)
 	ViewStrip        func([]string, []string) ([]string, []string, []string, error)
@@ -137,6 +143,31 @@ func EnumValues(ctx context.Context, enum string) ([]*models.EnumValue, error) {
 	return l.EnumValues(ctx, db, schema, enum)
 }
 
+// Composites returns the attributes of the database composite types, ordered
+// by type.
+func Composites(ctx context.Context) ([]*models.CompositeAttribute, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.Composites != nil {
+		return l.Composites(ctx, db, schema)
+	}
+	return nil, nil
+}
+
+// Ranges returns the database range types.
+func Ranges(ctx context.Context) ([]*models.Range, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.Ranges != nil {
+		return l.Ranges(ctx, db, schema)
+	}
+	return nil, nil
+}
+
 // Procs returns the database procs.
 func Procs(ctx context.Context) ([]*models.Proc, error) {
 	db, l, schema, err := get(ctx)
@@ -188,6 +219,45 @@ func TableSequences(ctx context.Context, table string) ([]*models.Sequence, erro
 	return l.TableSequences(ctx, db, schema, table)
 }
 
+// TableTriggers returns the database table triggers, for databases that
+// support introspecting triggers.
+func TableTriggers(ctx context.Context, table string) ([]*models.Trigger, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.TableTriggers != nil {
+		return l.TableTriggers(ctx, db, schema, table)
+	}
+	return nil, nil
+}
+
+// TablePolicies returns the database table row level security policies, for
+// databases that support row level security.
+func TablePolicies(ctx context.Context, table string) ([]*models.Policy, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.TablePolicies != nil {
+		return l.TablePolicies(ctx, db, schema, table)
+	}
+	return nil, nil
+}
+
+// ColumnComments returns the database table column comments, for databases
+// that support comments on columns.
+func ColumnComments(ctx context.Context, table string) ([]*models.ColumnComment, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.ColumnComments != nil {
+		return l.ColumnComments(ctx, db, schema, table)
+	}
+	return nil, nil
+}
+
 // TableForeignKeys returns the database table foreign keys.
 func TableForeignKeys(ctx context.Context, table string) ([]*models.ForeignKey, error) {
 	db, l, schema, err := get(ctx)
@@ -236,6 +306,20 @@ func ViewSchema(ctx context.Context, id string) (string, error) {
 	return "", nil
 }
 
+// ViewNotNull returns the names of the introspection view's columns that are
+// known to never be NULL, for databases that do not report the nullability of
+// view columns.
+func ViewNotNull(ctx context.Context, id string) ([]string, error) {
+	db, l, schema, err := get(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if l.ViewNotNull != nil {
+		return l.ViewNotNull(ctx, db, schema, id)
+	}
+	return nil, nil
+}
+
 // ViewTruncate truncates the introspection view.
 func ViewTruncate(ctx context.Context, id string) (sql.Result, error) {
 	db, l, schema, err := get(ctx)
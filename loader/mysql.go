@@ -20,6 +20,8 @@ func init() {
 		Tables:           models.MysqlTables,
 		TableColumns:     models.MysqlTableColumns,
 		TableSequences:   models.MysqlTableSequences,
+		TableTriggers:    models.MysqlTableTriggers,
+		ColumnComments:   models.MysqlColumnComments,
 		TableForeignKeys: models.MysqlTableForeignKeys,
 		TableIndexes:     models.MysqlTableIndexes,
 		IndexColumns:     models.MysqlIndexColumns,
@@ -100,6 +102,8 @@ func MysqlGoType(d xo.Type, schema, itype, utype string) (string, string, error)
 		goType, zero = "[]byte", "nil"
 	case "json":
 		goType, zero = "json.RawMessage", "nil"
+	case "geometry", "point", "linestring", "polygon", "multipoint", "multilinestring", "multipolygon", "geometrycollection", "geomcollection":
+		goType, zero = "Geometry", "Geometry{}"
 	case "timestamp", "datetime", "date":
 		goType, zero = "time.Time", "time.Time{}"
 		if d.Nullable {
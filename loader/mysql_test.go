@@ -147,6 +147,19 @@ func TestMysqlGoType(t *testing.T) {
 			zero:   "0",
 			prec:   5,
 		},
+		{
+			name:     "nullable point parses into Geometry",
+			typ:      "point",
+			nullable: true,
+			goType:   "Geometry",
+			zero:     "Geometry{}",
+		},
+		{
+			name:   "multipolygon parses into Geometry",
+			typ:    "multipolygon",
+			goType: "Geometry",
+			zero:   "Geometry{}",
+		},
 	}
 	for i, test := range tests {
 		d, err := xo.ParseType(test.typ, "mysql")
@@ -37,17 +37,26 @@ func OracleGoType(d xo.Type, schema, itype, utype string) (string, string, error
 			goType, zero = "sql.NullString", "sql.NullString{}"
 		}
 	case "number":
+		// a negative scale rounds to the left of the decimal point, adding
+		// to the integer digits
+		digits := d.Prec
+		if d.Scale < 0 {
+			digits -= d.Scale
+		}
 		switch {
-		case d.Prec == 0 && d.Scale == 0 && !d.Nullable:
-			goType, zero = "int", "0"
-		case d.Scale != 0 && !d.Nullable:
-			goType, zero = "float64", "0.0"
-		case d.Scale != 0 && d.Nullable:
-			goType, zero = "sql.NullFloat64", "sql.NullFloat64{}"
-		case !d.Nullable:
-			goType, zero = "int64", "0"
-		default:
+		case (d.Scale > 0 || digits >= 19) && !d.Nullable:
+			// decimals, and integers that overflow int64, are kept exact
+			// as their decimal string
+			goType, zero = "string", `""`
+		case d.Scale > 0 || digits >= 19:
+			goType, zero = "sql.NullString", "sql.NullString{}"
+		case d.Nullable:
 			goType, zero = "sql.NullInt64", "sql.NullInt64{}"
+		case d.Prec == 0, digits > 1 && digits <= 9:
+			// unconstrained, or fits in 32 bits
+			goType, zero = itype, "0"
+		default:
+			goType, zero = "int64", "0"
 		}
 	case "float":
 		goType, zero = "float64", "0.0"
@@ -66,9 +75,9 @@ func OracleGoType(d xo.Type, schema, itype, utype string) (string, string, error
 	}
 	// handle bools
 	switch {
-	case goType == "int64" && d.Prec == 1 && !d.Nullable:
+	case goType == "int64" && d.Prec == 1 && d.Scale == 0 && !d.Nullable:
 		goType, zero = "bool", "false"
-	case goType == "sql.NullInt64" && d.Prec == 1 && d.Nullable:
+	case goType == "sql.NullInt64" && d.Prec == 1 && d.Scale == 0 && d.Nullable:
 		goType, zero = "sql.NullBool", "sql.NullBool{}"
 	}
 	return goType, zero, nil
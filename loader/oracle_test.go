@@ -0,0 +1,51 @@
+package loader
+
+import (
+	"testing"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestOracleGoType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		nullable bool
+		goType   string
+		zero     string
+	}{
+		{"number(0,0)", false, "int", "0"},
+		{"number(0,0)", true, "sql.NullInt64", "sql.NullInt64{}"},
+		{"number(1,0)", false, "bool", "false"},
+		{"number(1,0)", true, "sql.NullBool", "sql.NullBool{}"},
+		{"number(9,0)", false, "int", "0"},
+		{"number(10,0)", false, "int64", "0"},
+		{"number(18,0)", false, "int64", "0"},
+		{"number(18,0)", true, "sql.NullInt64", "sql.NullInt64{}"},
+		{"number(19)", false, "string", `""`},
+		{"number(19)", true, "sql.NullString", "sql.NullString{}"},
+		{"number(38)", false, "string", `""`},
+		{"number(38,0)", false, "string", `""`},
+		{"number(38,0)", true, "sql.NullString", "sql.NullString{}"},
+		{"number(7,-3)", false, "int64", "0"},
+		{"number(17,-2)", false, "string", `""`},
+		{"number(1,-2)", true, "sql.NullInt64", "sql.NullInt64{}"},
+		{"number(10,2)", false, "string", `""`},
+		{"number(10,2)", true, "sql.NullString", "sql.NullString{}"},
+		{"float", false, "float64", "0.0"},
+		{"varchar2(255)", true, "sql.NullString", "sql.NullString{}"},
+	}
+	for i, test := range tests {
+		d, err := xo.ParseType(test.typ, "oracle")
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.typ, err)
+		}
+		d.Nullable = test.nullable
+		goType, zero, err := OracleGoType(d, "xo", "int", "uint")
+		if err != nil {
+			t.Fatalf("test %d (%s) OracleGoType(%#v) expected no error, got: %v", i, test.typ, d, err)
+		}
+		if goType != test.goType || zero != test.zero {
+			t.Errorf("test %d (%s) (nullable: %t) expected %q %q, got: %q %q", i, test.typ, test.nullable, test.goType, test.zero, goType, zero)
+		}
+	}
+}
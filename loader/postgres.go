@@ -2,7 +2,6 @@ package loader
 
 import (
 	"context"
-	"fmt"
 	"regexp"
 	"strconv"
 	"strings"
@@ -18,16 +17,22 @@ func init() {
 		Schema:           models.PostgresSchema,
 		Enums:            models.PostgresEnums,
 		EnumValues:       models.PostgresEnumValues,
+		Composites:       models.PostgresCompositeAttributes,
+		Ranges:           models.PostgresRanges,
 		Procs:            models.PostgresProcs,
 		ProcParams:       models.PostgresProcParams,
 		Tables:           models.PostgresTables,
 		TableColumns:     PostgresTableColumns,
 		TableSequences:   models.PostgresTableSequences,
+		TableTriggers:    models.PostgresTableTriggers,
+		TablePolicies:    models.PostgresTablePolicies,
+		ColumnComments:   models.PostgresColumnComments,
 		TableForeignKeys: models.PostgresTableForeignKeys,
 		TableIndexes:     models.PostgresTableIndexes,
 		IndexColumns:     PostgresIndexColumns,
 		ViewCreate:       models.PostgresViewCreate,
 		ViewSchema:       models.PostgresViewSchema,
+		ViewNotNull:      PostgresViewNotNull,
 		ViewDrop:         models.PostgresViewDrop,
 		ViewStrip:        PostgresViewStrip,
 	})
@@ -81,6 +86,92 @@ func PQPostgresGoType(d xo.Type, schema, itype, _ string) (string, string, error
 	return goType, zero, nil
 }
 
+// PgxPostgresGoType parses a type into a Go type based on the database type
+// definition, for use with `github.com/jackc/pgx/v5`.
+//
+// Nullable, numeric, uuid, interval and time types are returned as their
+// equivalent in `github.com/jackc/pgx/v5/pgtype`, and array types as
+// standard go arrays ([]<type>).
+func PgxPostgresGoType(d xo.Type, schema, itype, _ string) (string, string, error) {
+	// SETOF -> []T
+	if strings.HasPrefix(d.Type, "SETOF ") {
+		d.Type = d.Type[len("SETOF "):]
+		goType, _, err := PgxPostgresGoType(d, schema, itype, "")
+		if err != nil {
+			return "", "", err
+		}
+		return "[]" + goType, "nil", nil
+	}
+	// arrays of types with a pgx codec are scanned natively
+	if d.IsArray {
+		elem := d
+		elem.IsArray, elem.Nullable = false, false
+		goType, _, err := PgxPostgresGoType(elem, schema, itype, "")
+		if err != nil {
+			return "", "", err
+		}
+		if !pgxArrElems[goType] {
+			return "[]byte", "nil", nil
+		}
+		return "[]" + goType, "nil", nil
+	}
+	switch d.Type {
+	case "numeric":
+		return "pgtype.Numeric", "pgtype.Numeric{}", nil
+	case "uuid":
+		return "pgtype.UUID", "pgtype.UUID{}", nil
+	case "interval":
+		return "pgtype.Interval", "pgtype.Interval{}", nil
+	case "time without time zone":
+		return "pgtype.Time", "pgtype.Time{}", nil
+	case "time with time zone":
+		// pgx has no codec for timetz, so it is scanned as text
+		if d.Nullable {
+			return "pgtype.Text", "pgtype.Text{}", nil
+		}
+		return "string", `""`, nil
+	case "hstore":
+		return "pgtype.Hstore", "nil", nil
+	case "int4range", "int8range", "numrange", "tsrange", "tstzrange", "daterange":
+		goType := "pgtype.Range[" + pgxRangeElems[d.Type] + "]"
+		return goType, goType + "{}", nil
+	}
+	goType, zero, err := PostgresGoType(d, schema, itype)
+	if err != nil {
+		return "", "", err
+	}
+	switch goType {
+	case "sql.NullBool":
+		goType = "pgtype.Bool"
+	case "sql.NullString":
+		goType = "pgtype.Text"
+	case "sql.NullInt64":
+		goType = "pgtype.Int8"
+		switch d.Type {
+		case "smallint":
+			goType = "pgtype.Int2"
+		case "integer":
+			goType = "pgtype.Int4"
+		}
+	case "sql.NullFloat64":
+		goType = "pgtype.Float8"
+		if d.Type == "real" {
+			goType = "pgtype.Float4"
+		}
+	case "sql.NullTime":
+		goType = "pgtype.Timestamptz"
+		switch d.Type {
+		case "date":
+			goType = "pgtype.Date"
+		case "timestamp without time zone":
+			goType = "pgtype.Timestamp"
+		}
+	default:
+		return goType, zero, nil
+	}
+	return goType, goType + "{}", nil
+}
+
 // PostgresGoType parse a type into a Go type based on the database type
 // definition.
 func PostgresGoType(d xo.Type, schema, itype string) (string, string, error) {
@@ -163,6 +254,16 @@ func PostgresGoType(d xo.Type, schema, itype string) (string, string, error) {
 		goType, zero = "[]byte", "nil"
 	case "hstore":
 		goType, zero = "hstore.Hstore", "nil"
+	case "geometry", "geography":
+		goType, zero = "Geometry", "Geometry{}"
+	case "int4range":
+		goType, zero = "Range["+itype+"]", "Range["+itype+"]{}"
+	case "int8range":
+		goType, zero = "Range[int64]", "Range[int64]{}"
+	case "numrange":
+		goType, zero = "Range[float64]", "Range[float64]{}"
+	case "tsrange", "tstzrange", "daterange":
+		goType, zero = "Range[time.Time]", "Range[time.Time]{}"
 	case "uuid":
 		goType, zero = "uuid.UUID", "uuid.UUID{}"
 		if typNullable {
@@ -181,46 +282,146 @@ func PostgresTableColumns(ctx context.Context, db models.DB, schema string, tabl
 
 // PostgresIndexColumns returns the column list for an index.
 //
-// FIXME: rewrite this using SQL exclusively using OVER
+// Expression key parts (ie, lower(email)) are returned with an empty column
+// name and the expression definition.
 func PostgresIndexColumns(ctx context.Context, db models.DB, schema string, table string, index string) ([]*models.IndexColumn, error) {
-	// load columns
-	cols, err := models.PostgresIndexColumns(ctx, db, schema, index)
-	if err != nil {
-		return nil, err
-	}
-	// load col order
-	colOrd, err := models.PostgresGetColOrder(ctx, db, schema, index)
+	return models.PostgresIndexColumns(ctx, db, schema, index)
+}
+
+// PostgresViewNotNull returns the columns of an introspection view that are
+// known to be not null, by tracing the view's columns back to their origin
+// table columns.
+//
+// Postgres does not track the nullability of view columns, so the view's
+// query tree is inspected instead. When the query has outer joins or grouping
+// sets, no columns are returned, as the origin table column's nullability does
+// not apply.
+func PostgresViewNotNull(ctx context.Context, db models.DB, schema, id string) ([]string, error) {
+	tree, err := models.PostgresViewRule(ctx, db, schema, id)
 	if err != nil {
 		return nil, err
 	}
-	// build schema name used in errors
-	s := schema
-	if s != "" {
-		s += "."
+	origins, ok := pgTargetOrigins(tree)
+	if !ok {
+		return nil, nil
 	}
-	// put cols in order using colOrder
-	var ret []*models.IndexColumn
-	for _, v := range strings.Split(colOrd.Ord, " ") {
-		cid, err := strconv.Atoi(v)
+	var names []string
+	for _, o := range origins {
+		if o.Table == 0 {
+			continue
+		}
+		notNull, err := models.PostgresColumnNotNull(ctx, db, o.Table, o.Column)
 		if err != nil {
-			return nil, fmt.Errorf("could not convert %s%s index %s column %s to int", s, table, index, v)
+			return nil, err
+		}
+		if notNull {
+			names = append(names, o.Name)
 		}
-		// find column
-		found := false
-		var c *models.IndexColumn
-		for _, ic := range cols {
-			if cid == ic.Cid {
-				found, c = true, ic
-				break
+	}
+	return names, nil
+}
+
+// pgTargetOrigin is the origin table column of a query's target list entry.
+type pgTargetOrigin struct {
+	Name   string
+	Table  int64
+	Column int
+}
+
+// pgTargetOrigins parses the origin table columns of the target list of the
+// top-level query in a postgres query tree (ie, pg_rewrite.ev_action).
+// Entries that are not a direct reference to a table column have a zero
+// table.
+//
+// Returns false when the query tree has outer joins or grouping sets.
+func pgTargetOrigins(tree string) ([]pgTargetOrigin, bool) {
+	toks := pgNodeTokens(tree)
+	var origins []pgTargetOrigin
+	var found bool
+	for i, tok := range toks {
+		var next string
+		if i+1 < len(toks) {
+			next = toks[i+1].s
+		}
+		switch {
+		case tok.s == ":jointype" && next != "0",
+			tok.s == ":groupingSets" && next == "(":
+			return nil, false
+		case tok.s == ":targetList" && tok.depth == 2 && !found:
+			// the top-level query's fields are at depth 2, and its target
+			// entries' fields are at depth 4
+			found = true
+			var o pgTargetOrigin
+			var junk bool
+			for j := i + 2; j < len(toks) && toks[j].depth > 2; j++ {
+				t, v := toks[j], ""
+				if j+1 < len(toks) {
+					v = toks[j+1].s
+				}
+				switch {
+				case t.depth == 3 && t.s == "{":
+					o, junk = pgTargetOrigin{}, false
+				case t.depth == 3 && t.s == "}" && !junk:
+					origins = append(origins, o)
+				case t.depth != 4:
+				case t.s == ":resname":
+					o.Name = v
+				case t.s == ":resorigtbl":
+					o.Table, _ = strconv.ParseInt(v, 10, 64)
+				case t.s == ":resorigcol":
+					o.Column, _ = strconv.Atoi(v)
+				case t.s == ":resjunk":
+					junk = v == "true"
+				}
 			}
 		}
-		// sanity check
-		if !found {
-			return nil, fmt.Errorf("could not find %s%s index %s column id %d", s, table, index, cid)
+	}
+	return origins, true
+}
+
+// pgNodeToken is a token of a postgres node tree.
+type pgNodeToken struct {
+	s     string
+	depth int
+}
+
+// pgNodeTokens splits a postgres node tree into tokens, with the nesting
+// depth of each token. Opening and closing parens and braces are returned as
+// tokens with the depth of their enclosing node.
+func pgNodeTokens(tree string) []pgNodeToken {
+	var toks []pgNodeToken
+	var sb strings.Builder
+	depth := 0
+	flush := func() {
+		if sb.Len() != 0 {
+			toks = append(toks, pgNodeToken{sb.String(), depth})
+			sb.Reset()
+		}
+	}
+	for i := 0; i < len(tree); i++ {
+		switch c := tree[i]; c {
+		case '\\':
+			// escaped char
+			if i+1 < len(tree) {
+				i++
+				sb.WriteByte(tree[i])
+			}
+		case '(', '{':
+			flush()
+			toks = append(toks, pgNodeToken{string(c), depth})
+			depth++
+		case ')', '}':
+			flush()
+			depth--
+			toks = append(toks, pgNodeToken{string(c), depth})
+		case ' ', '\t', '\n':
+			flush()
+		default:
+			sb.WriteByte(c)
 		}
-		ret = append(ret, c)
 	}
-	return ret, nil
+	flush()
+	return toks
 }
 
 // PostgresViewStrip strips '::type AS name' in queries.
@@ -250,6 +451,34 @@ var pgStdArrMapping = map[string]string{
 	// default: "[]byte"
 }
 
+// pgxRangeElems are the pgx types of the bounds of the built-in range types.
+var pgxRangeElems = map[string]string{
+	"int4range": "pgtype.Int4",
+	"int8range": "pgtype.Int8",
+	"numrange":  "pgtype.Numeric",
+	"tsrange":   "pgtype.Timestamp",
+	"tstzrange": "pgtype.Timestamptz",
+	"daterange": "pgtype.Date",
+}
+
+// pgxArrElems are the Go types scanned natively as array elements by pgx.
+var pgxArrElems = map[string]bool{
+	"bool":            true,
+	"[]byte":          true,
+	"float64":         true,
+	"float32":         true,
+	"int":             true,
+	"int16":           true,
+	"int32":           true,
+	"int64":           true,
+	"string":          true,
+	"time.Time":       true,
+	"pgtype.Numeric":  true,
+	"pgtype.UUID":     true,
+	"pgtype.Interval": true,
+	"pgtype.Time":     true,
+}
+
 var pqArrMapping = map[string]string{
 	"bool":    "pq.BoolArray",
 	"[]byte":  "pq.ByteArray",
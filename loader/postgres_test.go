@@ -0,0 +1,147 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestPgTargetOrigins(t *testing.T) {
+	const inner = `({QUERY :commandType 1 :querySource 0 :canSetTag true :utilityStmt <> :resultRelation 0 :hasAggs false ` +
+		`:cteList <> :rtable ({RANGETBLENTRY :alias {ALIAS :aliasname a :colnames <>} :eref {ALIAS :aliasname a :colnames ("author_id" "name")} ` +
+		`:rtekind 0 :relid 16385 :relkind r :rellockmode 1 :tablesample <> :lateral false :inh true :inFromCl true}) ` +
+		`:jointree {FROMEXPR :fromlist ({RANGETBLREF :rtindex 1}) :quals <>} ` +
+		`:targetList (` +
+		`{TARGETENTRY :expr {VAR :varno 1 :varattno 1 :vartype 23 :vartypmod -1 :varcollid 0 :varlevelsup 0 :location 7} :resno 1 :resname author_id :ressortgroupref 0 :resorigtbl 16385 :resorigcol 1 :resjunk false} ` +
+		`{TARGETENTRY :expr {FUNCEXPR :funcid 871 :funcresulttype 25 :args ({VAR :varno 1 :varattno 2 :location 24}) :location 18} :resno 2 :resname upper :ressortgroupref 0 :resorigtbl 0 :resorigcol 0 :resjunk false} ` +
+		`{TARGETENTRY :expr {VAR :varno 1 :varattno 2 :location 40} :resno 3 :resname my\ name :ressortgroupref 0 :resorigtbl 16385 :resorigcol 2 :resjunk false} ` +
+		`{TARGETENTRY :expr {VAR :varno 1 :varattno 2 :location 52} :resno 4 :resname <> :ressortgroupref 1 :resorigtbl 16385 :resorigcol 2 :resjunk true}` +
+		`) :override 0 :onConflict <> :returningList <> :groupClause <> :groupDistinct false :groupingSets <> :havingQual <> :windowClause <>})`
+	tests := []struct {
+		name string
+		tree string
+		exp  []pgTargetOrigin
+		ok   bool
+	}{
+		{
+			name: "columns",
+			tree: inner,
+			exp: []pgTargetOrigin{
+				{"author_id", 16385, 1},
+				{"upper", 0, 0},
+				{"my name", 16385, 2},
+			},
+			ok: true,
+		},
+		{
+			name: "outer join",
+			tree: `({QUERY :commandType 1 :rtable ({RANGETBLENTRY :rtekind 2 :jointype 1}) :jointree {FROMEXPR :fromlist ({JOINEXPR :jointype 1 :isNatural false}) :quals <>} ` +
+				`:targetList ({TARGETENTRY :expr {VAR :varno 1} :resno 1 :resname a :resorigtbl 16385 :resorigcol 1 :resjunk false}) :groupingSets <>})`,
+			ok: false,
+		},
+		{
+			name: "inner join",
+			tree: `({QUERY :commandType 1 :jointree {FROMEXPR :fromlist ({JOINEXPR :jointype 0 :isNatural false}) :quals <>} ` +
+				`:targetList ({TARGETENTRY :expr {VAR :varno 1} :resno 1 :resname a :resorigtbl 16385 :resorigcol 1 :resjunk false}) :groupingSets <>})`,
+			exp: []pgTargetOrigin{{"a", 16385, 1}},
+			ok:  true,
+		},
+		{
+			name: "grouping sets",
+			tree: `({QUERY :commandType 1 :targetList ({TARGETENTRY :expr {VAR :varno 1} :resno 1 :resname a :resorigtbl 16385 :resorigcol 1 :resjunk false}) ` +
+				`:groupingSets ({GROUPINGSET :kind 3 :content <> :location 40})})`,
+			ok: false,
+		},
+		{
+			name: "subquery",
+			tree: `({QUERY :commandType 1 :rtable ({RANGETBLENTRY :rtekind 1 :subquery {QUERY :commandType 1 :targetList ({TARGETENTRY :expr {VAR :varno 1} :resno 1 :resname b :resorigtbl 16390 :resorigcol 3 :resjunk false}) :groupingSets <>}}) ` +
+				`:targetList ({TARGETENTRY :expr {VAR :varno 1} :resno 1 :resname c :resorigtbl 16390 :resorigcol 3 :resjunk false}) :groupingSets <>})`,
+			exp: []pgTargetOrigin{{"c", 16390, 3}},
+			ok:  true,
+		},
+	}
+	for i, test := range tests {
+		origins, ok := pgTargetOrigins(test.tree)
+		switch {
+		case ok != test.ok:
+			t.Errorf("test %d (%s) expected ok = %t, got: %t", i, test.name, test.ok, ok)
+		case !reflect.DeepEqual(origins, test.exp):
+			t.Errorf("test %d (%s) expected origins = %v, got: %v", i, test.name, test.exp, origins)
+		}
+	}
+}
+
+func TestPgxPostgresGoType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		nullable bool
+		array    bool
+		exp      string
+	}{
+		{"integer", false, false, "int"},
+		{"integer", true, false, "pgtype.Int4"},
+		{"smallint", true, false, "pgtype.Int2"},
+		{"bigint", true, false, "pgtype.Int8"},
+		{"real", true, false, "pgtype.Float4"},
+		{"double precision", true, false, "pgtype.Float8"},
+		{"text", true, false, "pgtype.Text"},
+		{"boolean", true, false, "pgtype.Bool"},
+		{"date", true, false, "pgtype.Date"},
+		{"timestamp with time zone", false, false, "time.Time"},
+		{"timestamp with time zone", true, false, "pgtype.Timestamptz"},
+		{"timestamp without time zone", true, false, "pgtype.Timestamp"},
+		{"time without time zone", false, false, "pgtype.Time"},
+		{"numeric", false, false, "pgtype.Numeric"},
+		{"uuid", true, false, "pgtype.UUID"},
+		{"jsonb", true, false, "[]byte"},
+		{"text", true, true, "[]string"},
+		{"integer", false, true, "[]int"},
+		{"uuid", false, true, "[]pgtype.UUID"},
+		{"public.book_type", false, false, "BookType"},
+		{"public.book_type", false, true, "[]byte"},
+		{"SETOF numeric", false, false, "[]pgtype.Numeric"},
+		{"geometry", true, false, "Geometry"},
+		{"geography", false, false, "Geometry"},
+		{"int4range", false, false, "pgtype.Range[pgtype.Int4]"},
+		{"tstzrange", true, false, "pgtype.Range[pgtype.Timestamptz]"},
+		{"public.float_range", true, false, "NullFloatRange"},
+		{"public.address", false, false, "Address"},
+	}
+	for i, test := range tests {
+		d := xo.Type{Type: test.typ, Nullable: test.nullable, IsArray: test.array}
+		typ, _, err := PgxPostgresGoType(d, "public", "int", "uint")
+		switch {
+		case err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.typ, err)
+		case typ != test.exp:
+			t.Errorf("test %d (%s) expected %q, got: %q", i, test.typ, test.exp, typ)
+		}
+	}
+}
+
+func TestPostgresGoTypeRange(t *testing.T) {
+	tests := []struct {
+		typ      string
+		nullable bool
+		exp      string
+	}{
+		{"int4range", false, "Range[int]"},
+		{"int8range", true, "Range[int64]"},
+		{"numrange", false, "Range[float64]"},
+		{"daterange", false, "Range[time.Time]"},
+		{"tstzrange", true, "Range[time.Time]"},
+		{"public.float_range", false, "FloatRange"},
+		{"public.float_range", true, "NullFloatRange"},
+	}
+	for i, test := range tests {
+		d := xo.Type{Type: test.typ, Nullable: test.nullable}
+		typ, _, err := PostgresGoType(d, "public", "int")
+		switch {
+		case err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.typ, err)
+		case typ != test.exp:
+			t.Errorf("test %d (%s) expected %q, got: %q", i, test.typ, test.exp, typ)
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package loader
 
 import (
+	"context"
+	"regexp"
+	"strings"
+
 	"github.com/xo/xo/models"
 	xo "github.com/xo/xo/types"
 )
@@ -14,7 +18,8 @@ func init() {
 		TableSequences:   models.Sqlite3TableSequences,
 		TableForeignKeys: models.Sqlite3TableForeignKeys,
 		TableIndexes:     models.Sqlite3TableIndexes,
-		IndexColumns:     models.Sqlite3IndexColumns,
+		IndexColumns:     Sqlite3IndexColumns,
+		TableTriggers:    Sqlite3TableTriggers,
 		ViewCreate:       models.Sqlite3ViewCreate,
 		ViewDrop:         models.Sqlite3ViewDrop,
 	})
@@ -53,14 +58,38 @@ func Sqlite3GoType(d xo.Type, schema, itype, utype string) (string, string, erro
 			goType, zero = "*Time", "nil"
 		}
 	default:
-		// case "varchar", "character", "varying character", "nchar", "native character", "nvarchar", "text", "clob", "time":
-		goType, zero = "string", `""`
-		if d.Nullable {
-			goType, zero = "sql.NullString", "sql.NullString{}"
+		// use the declared type's affinity
+		switch sqlite3Affinity(d.Type) {
+		case "INTEGER":
+			goType, zero = itype, "0"
+			if strings.Contains(d.Type, "big") || d.Type == "int8" {
+				goType = "int64"
+			}
+			if d.Nullable {
+				goType, zero = "sql.NullInt64", "sql.NullInt64{}"
+			}
+		case "REAL":
+			goType, zero = "float64", "0.0"
+			if d.Nullable {
+				goType, zero = "sql.NullFloat64", "sql.NullFloat64{}"
+			}
+		case "BLOB":
+			if d.Type != "" {
+				goType, zero = "[]byte", "nil"
+				break
+			}
+			// columns without a declared type can hold any value
+			fallthrough
+		default:
+			// case "varchar", "character", "varying character", "nchar", "native character", "nvarchar", "text", "clob", "time":
+			goType, zero = "string", `""`
+			if d.Nullable {
+				goType, zero = "sql.NullString", "sql.NullString{}"
+			}
 		}
 	}
 	// if unsigned ...
-	if intRE.MatchString(goType) && d.Unsigned {
+	if intRE.MatchString(goType) && (d.Unsigned || strings.HasPrefix(d.Type, "unsigned ")) {
 		if goType == itype {
 			goType, zero = utype, "0"
 		} else {
@@ -69,3 +98,133 @@ func Sqlite3GoType(d xo.Type, schema, itype, utype string) (string, string, erro
 	}
 	return goType, zero, nil
 }
+
+// sqlite3Affinity returns the type affinity ('INTEGER', 'TEXT', 'BLOB',
+// 'REAL' or 'NUMERIC') of a declared column type, using the rules sqlite3
+// applies to columns of tables that are not STRICT.
+//
+// See: https://www.sqlite.org/datatype3.html#determination_of_column_affinity
+func sqlite3Affinity(typ string) string {
+	typ = strings.ToLower(typ)
+	switch {
+	case strings.Contains(typ, "int"):
+		return "INTEGER"
+	case strings.Contains(typ, "char"), strings.Contains(typ, "clob"), strings.Contains(typ, "text"):
+		return "TEXT"
+	case strings.Contains(typ, "blob"), typ == "":
+		return "BLOB"
+	case strings.Contains(typ, "real"), strings.Contains(typ, "floa"), strings.Contains(typ, "doub"):
+		return "REAL"
+	}
+	return "NUMERIC"
+}
+
+// Sqlite3IndexColumns returns the column list for an index.
+//
+// As sqlite3 does not report the definition of expression key parts (ie,
+// lower(email)), the expressions are parsed from the index's create
+// statement.
+func Sqlite3IndexColumns(ctx context.Context, db models.DB, schema string, table string, index string) ([]*models.IndexColumn, error) {
+	cols, err := models.Sqlite3IndexColumns(ctx, db, schema, table, index)
+	if err != nil {
+		return nil, err
+	}
+	var keys []string
+	for _, col := range cols {
+		// cid -2 denotes an expression
+		if col.Cid != -2 {
+			continue
+		}
+		if keys == nil {
+			sqlstr, err := models.Sqlite3IndexSQL(ctx, db, schema, index)
+			if err != nil {
+				return nil, err
+			}
+			keys = sqlite3IndexKeys(sqlstr)
+		}
+		if col.SeqNo < len(keys) {
+			col.Expression = keys[col.SeqNo]
+		}
+	}
+	return cols, nil
+}
+
+// sqlite3IndexKeys splits the key parts out of a sqlite3 create index
+// statement, stripping any sort order.
+func sqlite3IndexKeys(sqlstr string) []string {
+	keys := []string{}
+	start, depth := -1, 0
+	var quote rune
+	for i, c := range sqlstr {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+			continue
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '[':
+			quote = ']'
+		case c == '(':
+			if depth++; depth == 1 {
+				start = i + 1
+			}
+		case c == ',' && depth == 1:
+			keys, start = append(keys, sqlite3TrimKey(sqlstr[start:i])), i+1
+		case c == ')':
+			if depth--; depth == 0 {
+				return append(keys, sqlite3TrimKey(sqlstr[start:i]))
+			}
+		}
+	}
+	return keys
+}
+
+// sqlite3TrimKey trims whitespace and any sort order from a key part.
+func sqlite3TrimKey(key string) string {
+	key = strings.TrimSpace(key)
+	for _, suffix := range []string{" ASC", " DESC"} {
+		if n := len(key) - len(suffix); n > 0 && strings.EqualFold(key[n:], suffix) {
+			return strings.TrimSpace(key[:n])
+		}
+	}
+	return key
+}
+
+// Sqlite3TableTriggers returns the triggers for a table.
+//
+// As sqlite3 only stores the trigger's create statement, the timing and event
+// are parsed from the statement.
+//
+// Only triggers in the main schema (sqlite_master) are returned. Triggers in
+// attached databases, and TEMP triggers (stored in sqlite_temp_master), are
+// not loaded, even when they are on the table.
+func Sqlite3TableTriggers(ctx context.Context, db models.DB, schema string, table string) ([]*models.Trigger, error) {
+	triggers, err := models.Sqlite3TableTriggers(ctx, db, schema, table)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range triggers {
+		t.Timing, t.Event = sqlite3TriggerEvent(t.Definition)
+	}
+	return triggers, nil
+}
+
+// sqlite3TriggerRE matches the timing and event of a sqlite3 create trigger
+// statement.
+var sqlite3TriggerRE = regexp.MustCompile(`(?is)^\s*CREATE\s+(?:TEMP(?:ORARY)?\s+)?TRIGGER\s+(?:IF\s+NOT\s+EXISTS\s+)?(?:"[^"]*"|\[[^\]]*\]|` + "`[^`]*`" + `|\S+)\s+(?:(BEFORE|AFTER|INSTEAD\s+OF)\s+)?(INSERT|DELETE|UPDATE)\b`)
+
+// sqlite3TriggerEvent returns the timing and event of a sqlite3 create
+// trigger statement. Triggers without a timing fire before the event.
+func sqlite3TriggerEvent(sqlstr string) (string, string) {
+	m := sqlite3TriggerRE.FindStringSubmatch(sqlstr)
+	if m == nil {
+		return "", ""
+	}
+	timing := "BEFORE"
+	if m[1] != "" {
+		timing = strings.Join(strings.Fields(strings.ToUpper(m[1])), " ")
+	}
+	return timing, strings.ToUpper(m[2])
+}
@@ -0,0 +1,95 @@
+package loader
+
+import (
+	"reflect"
+	"testing"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestSqlite3IndexKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		sqlstr string
+		exp    []string
+	}{
+		{
+			name:   "columns",
+			sqlstr: "CREATE INDEX a_idx ON a (b, c)",
+			exp:    []string{"b", "c"},
+		},
+		{
+			name:   "expressions with sort order",
+			sqlstr: "CREATE INDEX a_idx ON a (lower(b), substr(c, 1, 2) DESC, d asc)",
+			exp:    []string{"lower(b)", "substr(c, 1, 2)", "d"},
+		},
+		{
+			name:   "quoted names and partial",
+			sqlstr: `CREATE UNIQUE INDEX "a (idx)" ON "a" ("b,c", [d)]) WHERE (e IS NULL)`,
+			exp:    []string{`"b,c"`, "[d)]"},
+		},
+	}
+	for i, test := range tests {
+		if keys := sqlite3IndexKeys(test.sqlstr); !reflect.DeepEqual(keys, test.exp) {
+			t.Errorf("test %d (%s) expected keys = %q, got: %q", i, test.name, test.exp, keys)
+		}
+	}
+}
+
+func TestSqlite3TriggerEvent(t *testing.T) {
+	tests := []struct {
+		sqlstr string
+		timing string
+		event  string
+	}{
+		{"CREATE TRIGGER a_ins AFTER INSERT ON a BEGIN SELECT 1; END", "AFTER", "INSERT"},
+		{"create trigger if not exists a_upd before update of b on a begin select 1; end", "BEFORE", "UPDATE"},
+		{"CREATE TEMP TRIGGER \"a del\" INSTEAD\n  OF DELETE ON v BEGIN SELECT 1; END", "INSTEAD OF", "DELETE"},
+		{"CREATE TRIGGER [a_ins] INSERT ON a BEGIN SELECT 1; END", "BEFORE", "INSERT"},
+		{"CREATE TABLE a (b integer)", "", ""},
+	}
+	for i, test := range tests {
+		timing, event := sqlite3TriggerEvent(test.sqlstr)
+		if timing != test.timing || event != test.event {
+			t.Errorf("test %d (%q) expected %q %q, got: %q %q", i, test.sqlstr, test.timing, test.event, timing, event)
+		}
+	}
+}
+
+func TestSqlite3GoType(t *testing.T) {
+	tests := []struct {
+		typ      string
+		nullable bool
+		goType   string
+		zero     string
+	}{
+		{"BOOLEAN", false, "bool", "false"},
+		{"DATETIME", true, "*Time", "nil"},
+		{"INTEGER", false, "int", "0"},
+		{"INT8", false, "int64", "0"},
+		{"UNSIGNED BIG INT", false, "uint64", "0"},
+		{"MEDIUMINT UNSIGNED", true, "sql.NullInt64", "sql.NullInt64{}"},
+		{"FLOATING POINT", false, "int", "0"},
+		{"DOUBLE PRECISION", false, "float64", "0.0"},
+		{"NVARCHAR(20)", true, "sql.NullString", "sql.NullString{}"},
+		{"CLOB", false, "string", `""`},
+		{"LONGBLOB", true, "[]byte", "nil"},
+		{"ANY", true, "sql.NullString", "sql.NullString{}"},
+		{"", false, "string", `""`},
+		{"MONEY", false, "string", `""`},
+	}
+	for i, test := range tests {
+		d, err := xo.ParseType(test.typ, "sqlite3")
+		if err != nil {
+			t.Fatalf("test %d %q expected no error, got: %v", i, test.typ, err)
+		}
+		d.Nullable = test.nullable
+		goType, zero, err := Sqlite3GoType(d, "main", "int", "uint")
+		if err != nil {
+			t.Fatalf("test %d %q Sqlite3GoType(%#v) expected no error, got: %v", i, test.typ, d, err)
+		}
+		if goType != test.goType || zero != test.zero {
+			t.Errorf("test %d %q (nullable: %t) expected %q %q, got: %q %q", i, test.typ, test.nullable, test.goType, test.zero, goType, zero)
+		}
+	}
+}
@@ -15,6 +15,7 @@ type Column struct {
 	NotNull      bool           `json:"not_null"`       // not_null
 	DefaultValue sql.NullString `json:"default_value"`  // default_value
 	IsPrimaryKey bool           `json:"is_primary_key"` // is_primary_key
+	IsGenerated  bool           `json:"is_generated"`   // is_generated
 }
 
 // PostgresTableColumns runs a custom query, returning results as Column.
@@ -26,7 +27,8 @@ func PostgresTableColumns(ctx context.Context, db DB, schema, table string, sys
 		`format_type(a.atttypid, a.atttypmod), ` + // ::varchar AS data_type
 		`a.attnotnull, ` + // ::boolean AS not_null
 		`COALESCE(pg_get_expr(ad.adbin, ad.adrelid), ''), ` + // ::varchar AS default_value
-		`COALESCE(ct.contype = 'p', false) ` + // ::boolean AS is_primary_key
+		`COALESCE(ct.contype = 'p', false), ` + // ::boolean AS is_primary_key
+		`a.attgenerated <> '' ` + // ::boolean AS is_generated
 		`FROM pg_attribute a ` +
 		`JOIN ONLY pg_class c ON c.oid = a.attrelid ` +
 		`JOIN ONLY pg_namespace n ON n.oid = c.relnamespace ` +
@@ -52,7 +54,7 @@ func PostgresTableColumns(ctx context.Context, db DB, schema, table string, sys
 	for rows.Next() {
 		var c Column
 		// scan
-		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey); err != nil {
+		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey, &c.IsGenerated); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -72,7 +74,8 @@ func MysqlTableColumns(ctx context.Context, db DB, schema, table string) ([]*Col
 		`IF(data_type = 'enum', column_name, column_type) AS data_type, ` +
 		`IF(is_nullable = 'YES', false, true) AS not_null, ` +
 		`column_default AS default_value, ` +
-		`IF(column_key = 'PRI', true, false) AS is_primary_key ` +
+		`IF(column_key = 'PRI', true, false) AS is_primary_key, ` +
+		`IF(extra IN ('VIRTUAL GENERATED', 'STORED GENERATED', 'PERSISTENT GENERATED'), true, false) AS is_generated ` +
 		`FROM information_schema.columns ` +
 		`WHERE table_schema = ? ` +
 		`AND table_name = ? ` +
@@ -89,7 +92,7 @@ func MysqlTableColumns(ctx context.Context, db DB, schema, table string) ([]*Col
 	for rows.Next() {
 		var c Column
 		// scan
-		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey); err != nil {
+		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey, &c.IsGenerated); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -110,8 +113,10 @@ func Sqlite3TableColumns(ctx context.Context, db DB, schema, table string) ([]*C
 		`type AS data_type, ` +
 		`"notnull" AS not_null, ` +
 		`dflt_value AS default_value, ` +
-		`CAST(pk <> 0 AS boolean) AS is_primary_key ` +
-		`FROM pragma_table_info($1)`
+		`CAST(pk <> 0 AS boolean) AS is_primary_key, ` +
+		`CAST(hidden IN (2, 3) AS boolean) AS is_generated ` +
+		`FROM pragma_table_xinfo($1) ` +
+		`WHERE hidden <> 1`
 	// run
 	logf(sqlstr, table)
 	rows, err := db.QueryContext(ctx, sqlstr, table)
@@ -124,7 +129,7 @@ func Sqlite3TableColumns(ctx context.Context, db DB, schema, table string) ([]*C
 	for rows.Next() {
 		var c Column
 		// scan
-		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey); err != nil {
+		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey, &c.IsGenerated); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -152,7 +157,8 @@ func SqlserverTableColumns(ctx context.Context, db DB, schema, table string) ([]
 		`AND z.colid = c.colid ` +
 		`WHERE i.id = o.id ` +
 		`AND i.name = k.name ` +
-		`), 0) > 0, 1, 0) AS is_primary_key ` +
+		`), 0) > 0, 1, 0) AS is_primary_key, ` +
+		`IIF(c.iscomputed = 1, 1, 0) AS is_generated ` +
 		`FROM syscolumns c ` +
 		`JOIN sysobjects o ON o.id = c.id ` +
 		`LEFT JOIN sysobjects k ON k.xtype = 'PK' ` +
@@ -174,7 +180,7 @@ func SqlserverTableColumns(ctx context.Context, db DB, schema, table string) ([]
 	for rows.Next() {
 		var c Column
 		// scan
-		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey); err != nil {
+		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.DefaultValue, &c.IsPrimaryKey, &c.IsGenerated); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
@@ -200,8 +206,9 @@ func OracleTableColumns(ctx context.Context, db DB, schema, table string) ([]*Co
 		`WHEN 'RAW' THEN 'RAW(' || c.data_length || ')' ` +
 		`ELSE c.data_type END) AS data_type, ` +
 		`CASE WHEN c.nullable = 'N' THEN '1' ELSE '0' END AS not_null, ` +
-		`CASE WHEN p.column_id IS NOT NULL THEN '1' ELSE '0' END as is_primary_key ` +
-		`FROM all_tab_columns c ` +
+		`CASE WHEN p.column_id IS NOT NULL THEN '1' ELSE '0' END as is_primary_key, ` +
+		`CASE WHEN c.virtual_column = 'YES' THEN '1' ELSE '0' END AS is_generated ` +
+		`FROM all_tab_cols c ` +
 		`LEFT JOIN ( ` +
 		`SELECT distinct c.column_id FROM all_tab_columns c ` +
 		`JOIN all_cons_columns l ON l.owner = c.owner ` +
@@ -215,6 +222,7 @@ func OracleTableColumns(ctx context.Context, db DB, schema, table string) ([]*Co
 		`) p on p.column_id = c.column_id ` +
 		`WHERE c.owner = UPPER(:1) ` +
 		`AND c.table_name = UPPER(:2) ` +
+		`AND c.hidden_column = 'NO' ` +
 		`ORDER BY c.column_id`
 	// run
 	logf(sqlstr, schema, table)
@@ -228,7 +236,7 @@ func OracleTableColumns(ctx context.Context, db DB, schema, table string) ([]*Co
 	for rows.Next() {
 		var c Column
 		// scan
-		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.IsPrimaryKey); err != nil {
+		if err := rows.Scan(&c.FieldOrdinal, &c.ColumnName, &c.DataType, &c.NotNull, &c.IsPrimaryKey, &c.IsGenerated); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &c)
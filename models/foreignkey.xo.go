@@ -13,6 +13,7 @@ type ForeignKey struct {
 	RefTableName   string `json:"ref_table_name"`   // ref_table_name
 	RefColumnName  string `json:"ref_column_name"`  // ref_column_name
 	KeyID          int    `json:"key_id"`           // key_id
+	RefSchemaName  string `json:"ref_schema_name"`  // ref_schema_name
 }
 
 // PostgresTableForeignKeys runs a custom query, returning results as ForeignKey.
@@ -23,7 +24,8 @@ func PostgresTableForeignKeys(ctx context.Context, db DB, schema, table string)
 		`kcu.column_name, ` + // ::varchar AS column_name
 		`ccu.table_name, ` + // ::varchar AS ref_table_name
 		`ccu.column_name, ` + // ::varchar AS ref_column_name
-		`0 ` + // ::integer AS key_id
+		`0, ` + // ::integer AS key_id
+		`ccu.table_schema ` + // ::varchar AS ref_schema_name
 		`FROM information_schema.table_constraints tc ` +
 		`JOIN information_schema.key_column_usage AS kcu ON tc.constraint_name = kcu.constraint_name ` +
 		`AND tc.table_schema = kcu.table_schema ` +
@@ -33,12 +35,14 @@ func PostgresTableForeignKeys(ctx context.Context, db DB, schema, table string)
 		`PARTITION BY ` +
 		`table_schema, ` +
 		`table_name, ` +
+		`constraint_schema, ` +
 		`constraint_name ` +
 		`ORDER BY row_num ` +
 		`) AS ordinal_position, ` +
 		`table_schema, ` +
 		`table_name, ` +
 		`column_name, ` +
+		`constraint_schema, ` +
 		`constraint_name ` +
 		`FROM ( ` +
 		`SELECT ` +
@@ -46,11 +50,12 @@ func PostgresTableForeignKeys(ctx context.Context, db DB, schema, table string)
 		`table_schema, ` +
 		`table_name, ` +
 		`column_name, ` +
+		`constraint_schema, ` +
 		`constraint_name ` +
 		`FROM information_schema.constraint_column_usage ` +
 		`) t ` +
 		`) AS ccu ON ccu.constraint_name = tc.constraint_name ` +
-		`AND ccu.table_schema = tc.table_schema ` +
+		`AND ccu.constraint_schema = tc.constraint_schema ` +
 		`AND ccu.ordinal_position = kcu.ordinal_position ` +
 		`WHERE tc.constraint_type = 'FOREIGN KEY' ` +
 		`AND tc.table_schema = $1 ` +
@@ -67,7 +72,7 @@ func PostgresTableForeignKeys(ctx context.Context, db DB, schema, table string)
 	for rows.Next() {
 		var fk ForeignKey
 		// scan
-		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName, &fk.KeyID); err != nil {
+		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName, &fk.KeyID, &fk.RefSchemaName); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &fk)
@@ -85,11 +90,13 @@ func MysqlTableForeignKeys(ctx context.Context, db DB, schema, table string) ([]
 		`constraint_name AS foreign_key_name, ` +
 		`column_name AS column_name, ` +
 		`referenced_table_name AS ref_table_name, ` +
-		`referenced_column_name AS ref_column_name ` +
+		`referenced_column_name AS ref_column_name, ` +
+		`referenced_table_schema AS ref_schema_name ` +
 		`FROM information_schema.key_column_usage ` +
 		`WHERE referenced_table_name IS NOT NULL ` +
 		`AND table_schema = ? ` +
-		`AND table_name = ?`
+		`AND table_name = ? ` +
+		`ORDER BY constraint_name, ordinal_position`
 	// run
 	logf(sqlstr, schema, table)
 	rows, err := db.QueryContext(ctx, sqlstr, schema, table)
@@ -102,7 +109,7 @@ func MysqlTableForeignKeys(ctx context.Context, db DB, schema, table string) ([]
 	for rows.Next() {
 		var fk ForeignKey
 		// scan
-		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName); err != nil {
+		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName, &fk.RefSchemaName); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &fk)
@@ -153,7 +160,8 @@ func SqlserverTableForeignKeys(ctx context.Context, db DB, schema, table string)
 		`fk.name AS foreign_key_name, ` +
 		`col.name AS column_name, ` +
 		`pk_tab.name AS ref_table_name, ` +
-		`pk_col.name AS ref_column_name ` +
+		`pk_col.name AS ref_column_name, ` +
+		`schema_name(pk_tab.schema_id) AS ref_schema_name ` +
 		`FROM sys.tables tab ` +
 		`INNER JOIN sys.columns col ON col.object_id = tab.object_id ` +
 		`LEFT OUTER JOIN sys.foreign_key_columns fk_cols ON fk_cols.parent_object_id = tab.object_id ` +
@@ -164,7 +172,8 @@ func SqlserverTableForeignKeys(ctx context.Context, db DB, schema, table string)
 		`AND pk_col.object_id = fk_cols.referenced_object_id ` +
 		`WHERE schema_name(tab.schema_id) = @p1 ` +
 		`AND tab.name = @p2 ` +
-		`AND fk.object_id IS NOT NULL`
+		`AND fk.object_id IS NOT NULL ` +
+		`ORDER BY fk.name, fk_cols.constraint_column_id`
 	// run
 	logf(sqlstr, schema, table)
 	rows, err := db.QueryContext(ctx, sqlstr, schema, table)
@@ -177,7 +186,7 @@ func SqlserverTableForeignKeys(ctx context.Context, db DB, schema, table string)
 	for rows.Next() {
 		var fk ForeignKey
 		// scan
-		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName); err != nil {
+		if err := rows.Scan(&fk.ForeignKeyName, &fk.ColumnName, &fk.RefTableName, &fk.RefColumnName, &fk.RefSchemaName); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &fk)
@@ -205,7 +214,8 @@ func OracleTableForeignKeys(ctx context.Context, db DB, schema, table string) ([
 		`AND C_PK.CONSTRAINT_NAME = b.constraint_name AND b.POSITION = a.POSITION ` +
 		`WHERE c.constraint_type = 'R' ` +
 		`AND a.owner = UPPER(:1) ` +
-		`AND a.table_name = UPPER(:2)`
+		`AND a.table_name = UPPER(:2) ` +
+		`ORDER BY a.constraint_name, a.position`
 	// run
 	logf(sqlstr, schema, table)
 	rows, err := db.QueryContext(ctx, sqlstr, schema, table)
@@ -8,9 +8,10 @@ import (
 
 // Index is a index.
 type Index struct {
-	IndexName string `json:"index_name"` // index_name
-	IsUnique  bool   `json:"is_unique"`  // is_unique
-	IsPrimary bool   `json:"is_primary"` // is_primary
+	IndexName      string `json:"index_name"`      // index_name
+	IsUnique       bool   `json:"is_unique"`       // is_unique
+	IsPrimary      bool   `json:"is_primary"`      // is_primary
+	IndexPredicate string `json:"index_predicate"` // index_predicate
 }
 
 // PostgresTableIndexes runs a custom query, returning results as Index.
@@ -19,13 +20,13 @@ func PostgresTableIndexes(ctx context.Context, db DB, schema, table string) ([]*
 	const sqlstr = `SELECT ` +
 		`DISTINCT ic.relname, ` + // ::varchar AS index_name
 		`i.indisunique, ` + // ::boolean AS is_unique
-		`i.indisprimary ` + // ::boolean AS is_primary
+		`i.indisprimary, ` + // ::boolean AS is_primary
+		`COALESCE(pg_get_expr(i.indpred, i.indrelid, true), '') ` + // ::varchar AS index_predicate
 		`FROM pg_index i ` +
 		`JOIN ONLY pg_class c ON c.oid = i.indrelid ` +
 		`JOIN ONLY pg_namespace n ON n.oid = c.relnamespace ` +
 		`JOIN ONLY pg_class ic ON ic.oid = i.indexrelid ` +
-		`WHERE i.indkey <> '0' ` +
-		`AND n.nspname = $1 ` +
+		`WHERE n.nspname = $1 ` +
 		`AND c.relname = $2`
 	// run
 	logf(sqlstr, schema, table)
@@ -39,7 +40,7 @@ func PostgresTableIndexes(ctx context.Context, db DB, schema, table string) ([]*
 	for rows.Next() {
 		var i Index
 		// scan
-		if err := rows.Scan(&i.IndexName, &i.IsUnique, &i.IsPrimary); err != nil {
+		if err := rows.Scan(&i.IndexName, &i.IsUnique, &i.IsPrimary, &i.IndexPredicate); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &i)
@@ -88,10 +89,13 @@ func Sqlite3TableIndexes(ctx context.Context, db DB, schema, table string) ([]*I
 	// query
 	sqlstr := `/* ` + schema + ` */ ` +
 		`SELECT ` +
-		`name AS index_name, ` +
-		`"unique" AS is_unique, ` +
-		`CAST(origin = 'pk' AS boolean) AS is_primary ` +
-		`FROM pragma_index_list($1)`
+		`il.name AS index_name, ` +
+		`il."unique" AS is_unique, ` +
+		`CAST(il.origin = 'pk' AS boolean) AS is_primary, ` +
+		`CASE WHEN il.partial THEN TRIM(SUBSTR(m.sql, INSTR(UPPER(m.sql), ' WHERE ') + 7)) ELSE '' END AS index_predicate ` +
+		`FROM pragma_index_list($1) il ` +
+		`LEFT JOIN sqlite_master m ON m.type = 'index' ` +
+		`AND m.name = il.name`
 	// run
 	logf(sqlstr, table)
 	rows, err := db.QueryContext(ctx, sqlstr, table)
@@ -104,7 +108,7 @@ func Sqlite3TableIndexes(ctx context.Context, db DB, schema, table string) ([]*I
 	for rows.Next() {
 		var i Index
 		// scan
-		if err := rows.Scan(&i.IndexName, &i.IsUnique, &i.IsPrimary); err != nil {
+		if err := rows.Scan(&i.IndexName, &i.IsUnique, &i.IsPrimary, &i.IndexPredicate); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &i)
@@ -121,7 +125,8 @@ func SqlserverTableIndexes(ctx context.Context, db DB, schema, table string) ([]
 	const sqlstr = `SELECT ` +
 		`i.name AS index_name, ` +
 		`i.is_primary_key AS is_primary, ` +
-		`i.is_unique ` +
+		`i.is_unique, ` +
+		`COALESCE(i.filter_definition, '') AS index_predicate ` +
 		`FROM sys.indexes i ` +
 		`INNER JOIN sysobjects o ON i.object_id = o.id ` +
 		`WHERE i.name IS NOT NULL ` +
@@ -140,7 +145,7 @@ func SqlserverTableIndexes(ctx context.Context, db DB, schema, table string) ([]
 	for rows.Next() {
 		var i Index
 		// scan
-		if err := rows.Scan(&i.IndexName, &i.IsPrimary, &i.IsUnique); err != nil {
+		if err := rows.Scan(&i.IndexName, &i.IsPrimary, &i.IsUnique, &i.IndexPredicate); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &i)
@@ -11,25 +11,28 @@ type IndexColumn struct {
 	SeqNo      int    `json:"seq_no"`      // seq_no
 	Cid        int    `json:"cid"`         // cid
 	ColumnName string `json:"column_name"` // column_name
+	Expression string `json:"expression"`  // expression
 }
 
 // PostgresIndexColumns runs a custom query, returning results as IndexColumn.
 func PostgresIndexColumns(ctx context.Context, db DB, schema, index string) ([]*IndexColumn, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`(row_number() over()), ` + // ::integer AS seq_no
-		`a.attnum, ` + // ::integer AS cid
-		`a.attname ` + // ::varchar AS column_name
+		`k.n, ` + // ::integer AS seq_no
+		`i.indkey[k.n - 1], ` + // ::integer AS cid
+		`COALESCE(a.attname, ''), ` + // ::varchar AS column_name
+		`(CASE WHEN i.indkey[k.n - 1] = 0 THEN pg_get_indexdef(i.indexrelid, k.n, true) ELSE '' END) ` + // ::varchar AS expression
 		`FROM pg_index i ` +
 		`JOIN ONLY pg_class c ON c.oid = i.indrelid ` +
 		`JOIN ONLY pg_namespace n ON n.oid = c.relnamespace ` +
 		`JOIN ONLY pg_class ic ON ic.oid = i.indexrelid ` +
-		`LEFT JOIN pg_attribute a ON i.indrelid = a.attrelid ` +
-		`AND a.attnum = ANY(i.indkey) ` +
+		`CROSS JOIN LATERAL generate_series(1, i.indnatts) k(n) ` +
+		`LEFT JOIN pg_attribute a ON a.attrelid = i.indrelid ` +
+		`AND a.attnum = i.indkey[k.n - 1] ` +
 		`AND a.attisdropped = false ` +
-		`WHERE i.indkey <> '0' ` +
-		`AND n.nspname = $1 ` +
-		`AND ic.relname = $2`
+		`WHERE n.nspname = $1 ` +
+		`AND ic.relname = $2 ` +
+		`ORDER BY k.n`
 	// run
 	logf(sqlstr, schema, index)
 	rows, err := db.QueryContext(ctx, sqlstr, schema, index)
@@ -42,7 +45,7 @@ func PostgresIndexColumns(ctx context.Context, db DB, schema, index string) ([]*
 	for rows.Next() {
 		var ic IndexColumn
 		// scan
-		if err := rows.Scan(&ic.SeqNo, &ic.Cid, &ic.ColumnName); err != nil {
+		if err := rows.Scan(&ic.SeqNo, &ic.Cid, &ic.ColumnName, &ic.Expression); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &ic)
@@ -94,7 +97,7 @@ func Sqlite3IndexColumns(ctx context.Context, db DB, schema, table, index string
 		`SELECT ` +
 		`seqno AS seq_no, ` +
 		`cid, ` +
-		`name AS column_name ` +
+		`COALESCE(name, '') AS column_name ` +
 		`FROM pragma_index_info($1)`
 	// run
 	logf(sqlstr, index)
@@ -100,6 +100,8 @@ const (
 	ErrDoesNotExist Error = "does not exist"
 	// ErrMarkedForDeletion is the marked for deletion error.
 	ErrMarkedForDeletion Error = "marked for deletion"
+	// ErrVersionConflict is the version conflict error.
+	ErrVersionConflict Error = "version conflict"
 )
 
 // ErrInsertFailed is the insert failed error.
@@ -231,6 +233,43 @@ func PostgresViewSchema(ctx context.Context, db DB, id string) (string, error) {
 	return schemaName, nil
 }
 
+// PostgresViewRule retrieves the query tree for a view created for introspection.
+func PostgresViewRule(ctx context.Context, db DB, schema, id string) (string, error) {
+	// query
+	const sqlstr = `SELECT ` +
+		`r.ev_action ` + // ::text AS query_tree
+		`FROM pg_rewrite r ` +
+		`JOIN pg_class c ON c.oid = r.ev_class ` +
+		`JOIN pg_namespace n ON n.oid = c.relnamespace ` +
+		`WHERE n.nspname = $1 ` +
+		`AND c.relname = $2 ` +
+		`AND r.rulename = '_RETURN'`
+	// run
+	logf(sqlstr, schema, id)
+	var queryTree string
+	if err := db.QueryRowContext(ctx, sqlstr, schema, id).Scan(&queryTree); err != nil {
+		return "", logerror(err)
+	}
+	return queryTree, nil
+}
+
+// PostgresColumnNotNull determines if a table column is not null.
+func PostgresColumnNotNull(ctx context.Context, db DB, tableOid int64, attnum int) (bool, error) {
+	// query
+	const sqlstr = `SELECT ` +
+		`a.attnotnull ` + // ::boolean AS not_null
+		`FROM pg_attribute a ` +
+		`WHERE a.attrelid = $1 ` +
+		`AND a.attnum = $2`
+	// run
+	logf(sqlstr, tableOid, attnum)
+	var notNull bool
+	if err := db.QueryRowContext(ctx, sqlstr, tableOid, attnum).Scan(&notNull); err != nil {
+		return false, logerror(err)
+	}
+	return notNull, nil
+}
+
 // PostgresViewDrop drops a view created for introspection.
 func PostgresViewDrop(ctx context.Context, db DB, schema, id string) (sql.Result, error) {
 	// query
@@ -324,6 +363,24 @@ func Sqlite3Schema(ctx context.Context, db DB) (string, error) {
 	return schemaName, nil
 }
 
+// Sqlite3IndexSQL retrieves the create statement for an index.
+func Sqlite3IndexSQL(ctx context.Context, db DB, schema, index string) (string, error) {
+	// query
+	sqlstr := `/* ` + schema + ` */ ` +
+		`SELECT ` +
+		`COALESCE(sql, '') AS index_sql ` +
+		`FROM sqlite_master ` +
+		`WHERE type = 'index' ` +
+		`AND name = $1`
+	// run
+	logf(sqlstr, index)
+	var indexSQL string
+	if err := db.QueryRowContext(ctx, sqlstr, index).Scan(&indexSQL); err != nil {
+		return "", logerror(err)
+	}
+	return indexSQL, nil
+}
+
 // SqlserverViewCreate creates a view for introspection.
 func SqlserverViewCreate(ctx context.Context, db DB, schema, id string, query []string) (sql.Result, error) {
 	// query
@@ -8,14 +8,20 @@ import (
 
 // Sequence is a sequence.
 type Sequence struct {
-	ColumnName string `json:"column_name"` // column_name
+	ColumnName       string `json:"column_name"`        // column_name
+	SequenceName     string `json:"sequence_name"`      // sequence_name
+	IsIdentity       bool   `json:"is_identity"`        // is_identity
+	IsIdentityAlways bool   `json:"is_identity_always"` // is_identity_always
 }
 
 // PostgresTableSequences runs a custom query, returning results as Sequence.
 func PostgresTableSequences(ctx context.Context, db DB, schema, table string) ([]*Sequence, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`a.attname ` + // ::varchar as column_name
+		`a.attname, ` + // ::varchar as column_name
+		`s.relname, ` + // ::varchar AS sequence_name
+		`(a.attidentity <> ''), ` + // ::boolean AS is_identity
+		`(a.attidentity = 'a') ` + // ::boolean AS is_identity_always
 		`FROM pg_class s ` +
 		`JOIN pg_depend d ON d.objid = s.oid ` +
 		`JOIN pg_class t ON d.objid = s.oid AND d.refobjid = t.oid ` +
@@ -36,7 +42,7 @@ func PostgresTableSequences(ctx context.Context, db DB, schema, table string) ([
 	for rows.Next() {
 		var s Sequence
 		// scan
-		if err := rows.Scan(&s.ColumnName); err != nil {
+		if err := rows.Scan(&s.ColumnName, &s.SequenceName, &s.IsIdentity, &s.IsIdentityAlways); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &s)
@@ -51,7 +57,8 @@ func PostgresTableSequences(ctx context.Context, db DB, schema, table string) ([
 func MysqlTableSequences(ctx context.Context, db DB, schema, table string) ([]*Sequence, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`column_name ` +
+		`column_name, ` +
+		`true AS is_identity ` +
 		`FROM information_schema.columns c ` +
 		`WHERE c.extra = 'auto_increment' ` +
 		`AND c.table_schema = ? ` +
@@ -68,7 +75,7 @@ func MysqlTableSequences(ctx context.Context, db DB, schema, table string) ([]*S
 	for rows.Next() {
 		var s Sequence
 		// scan
-		if err := rows.Scan(&s.ColumnName); err != nil {
+		if err := rows.Scan(&s.ColumnName, &s.IsIdentity); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &s)
@@ -109,7 +116,9 @@ func Sqlite3TableSequences(ctx context.Context, db DB, schema, table string) ([]
 		`FROM c ` +
 		`WHERE col LIKE '%autoincrement%' ` +
 		`) ` +
-		`SELECT col AS column_name ` +
+		`SELECT ` +
+		`col AS column_name, ` +
+		`1 AS is_identity ` +
 		`FROM d ` +
 		`WHERE name = $1`
 	// run
@@ -124,7 +133,7 @@ func Sqlite3TableSequences(ctx context.Context, db DB, schema, table string) ([]
 	for rows.Next() {
 		var s Sequence
 		// scan
-		if err := rows.Scan(&s.ColumnName); err != nil {
+		if err := rows.Scan(&s.ColumnName, &s.IsIdentity); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &s)
@@ -139,7 +148,8 @@ func Sqlite3TableSequences(ctx context.Context, db DB, schema, table string) ([]
 func SqlserverTableSequences(ctx context.Context, db DB, schema, table string) ([]*Sequence, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`COL_NAME(o.object_id, c.column_id) AS column_name ` +
+		`COL_NAME(o.object_id, c.column_id) AS column_name, ` +
+		`CAST(1 AS BIT) AS is_identity ` +
 		`FROM sys.objects o ` +
 		`INNER JOIN sys.columns c ON o.object_id = c.object_id ` +
 		`WHERE c.is_identity = 1 ` +
@@ -158,7 +168,7 @@ func SqlserverTableSequences(ctx context.Context, db DB, schema, table string) (
 	for rows.Next() {
 		var s Sequence
 		// scan
-		if err := rows.Scan(&s.ColumnName); err != nil {
+		if err := rows.Scan(&s.ColumnName, &s.IsIdentity); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &s)
@@ -173,7 +183,8 @@ func SqlserverTableSequences(ctx context.Context, db DB, schema, table string) (
 func OracleTableSequences(ctx context.Context, db DB, schema, table string) ([]*Sequence, error) {
 	// query
 	const sqlstr = `SELECT ` +
-		`LOWER(c.column_name) AS column_name ` +
+		`LOWER(c.column_name) AS column_name, ` +
+		`1 AS is_identity ` +
 		`FROM all_tab_columns c ` +
 		`WHERE c.identity_column='YES' ` +
 		`AND c.owner = UPPER(:1) ` +
@@ -190,7 +201,7 @@ func OracleTableSequences(ctx context.Context, db DB, schema, table string) ([]*
 	for rows.Next() {
 		var s Sequence
 		// scan
-		if err := rows.Scan(&s.ColumnName); err != nil {
+		if err := rows.Scan(&s.ColumnName, &s.IsIdentity); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &s)
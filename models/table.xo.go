@@ -8,10 +8,14 @@ import (
 
 // Table is a table.
 type Table struct {
-	Type      string `json:"type"`       // type
-	TableName string `json:"table_name"` // table_name
-	ManualPk  bool   `json:"manual_pk"`  // manual_pk
-	ViewDef   string `json:"view_def"`   // view_def
+	Type         string `json:"type"`          // type
+	TableName    string `json:"table_name"`    // table_name
+	ManualPk     bool   `json:"manual_pk"`     // manual_pk
+	ViewDef      string `json:"view_def"`      // view_def
+	PartitionOf  string `json:"partition_of"`  // partition_of
+	RowSecurity  bool   `json:"row_security"`  // row_security
+	Strict       bool   `json:"strict"`        // strict
+	WithoutRowid bool   `json:"without_rowid"` // without_rowid
 }
 
 // PostgresTables runs a custom query, returning results as Table.
@@ -20,22 +24,35 @@ func PostgresTables(ctx context.Context, db DB, schema, typ string) ([]*Table, e
 	const sqlstr = `SELECT ` +
 		`(CASE c.relkind ` +
 		`WHEN 'r' THEN 'table' ` +
+		`WHEN 'p' THEN 'table' ` +
 		`WHEN 'v' THEN 'view' ` +
+		`WHEN 'm' THEN 'matview' ` +
 		`END), ` + // ::varchar AS type
 		`c.relname, ` + // ::varchar AS table_name
 		`false, ` + // ::boolean AS manual_pk
 		`CASE c.relkind ` +
 		`WHEN 'r' THEN '' ` +
+		`WHEN 'p' THEN '' ` +
 		`WHEN 'v' THEN v.definition ` +
-		`END AS view_def ` +
+		`WHEN 'm' THEN mv.definition ` +
+		`END AS view_def, ` +
+		`COALESCE(pc.relname, ''), ` + // ::varchar AS partition_of
+		`c.relrowsecurity ` + // ::boolean AS row_security
 		`FROM pg_class c ` +
 		`JOIN ONLY pg_namespace n ON n.oid = c.relnamespace ` +
 		`LEFT JOIN pg_views v ON n.nspname = v.schemaname ` +
 		`AND v.viewname = c.relname ` +
+		`LEFT JOIN pg_matviews mv ON n.nspname = mv.schemaname ` +
+		`AND mv.matviewname = c.relname ` +
+		`LEFT JOIN pg_inherits i ON i.inhrelid = c.oid ` +
+		`AND c.relispartition ` +
+		`LEFT JOIN pg_class pc ON pc.oid = i.inhparent ` +
 		`WHERE n.nspname = $1 ` +
 		`AND (CASE c.relkind ` +
 		`WHEN 'r' THEN 'table' ` +
+		`WHEN 'p' THEN 'table' ` +
 		`WHEN 'v' THEN 'view' ` +
+		`WHEN 'm' THEN 'matview' ` +
 		`END) = LOWER($2)`
 	// run
 	logf(sqlstr, schema, typ)
@@ -49,7 +66,7 @@ func PostgresTables(ctx context.Context, db DB, schema, typ string) ([]*Table, e
 	for rows.Next() {
 		var t Table
 		// scan
-		if err := rows.Scan(&t.Type, &t.TableName, &t.ManualPk, &t.ViewDef); err != nil {
+		if err := rows.Scan(&t.Type, &t.TableName, &t.ManualPk, &t.ViewDef, &t.PartitionOf, &t.RowSecurity); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &t)
@@ -109,15 +126,19 @@ func Sqlite3Tables(ctx context.Context, db DB, schema, typ string) ([]*Table, er
 	// query
 	sqlstr := `/* ` + schema + ` */ ` +
 		`SELECT ` +
-		`type, ` +
-		`tbl_name AS table_name, ` +
-		`CASE LOWER(type) ` +
+		`m.type, ` +
+		`m.tbl_name AS table_name, ` +
+		`CASE LOWER(m.type) ` +
 		`WHEN 'table' THEN '' ` +
-		`WHEN 'view' THEN sql ` +
-		`END AS view_def ` +
-		`FROM sqlite_master ` +
-		`WHERE tbl_name NOT LIKE 'sqlite_%' ` +
-		`AND LOWER(type) = LOWER($1)`
+		`WHEN 'view' THEN m.sql ` +
+		`END AS view_def, ` +
+		`COALESCE(p.strict, 0) <> 0 AS strict, ` +
+		`COALESCE(p.wr, 0) <> 0 AS without_rowid ` +
+		`FROM sqlite_master m ` +
+		`LEFT JOIN pragma_table_list p ON p.schema = 'main' ` +
+		`AND p.name = m.tbl_name ` +
+		`WHERE m.tbl_name NOT LIKE 'sqlite_%' ` +
+		`AND LOWER(m.type) = LOWER($1)`
 	// run
 	logf(sqlstr, typ)
 	rows, err := db.QueryContext(ctx, sqlstr, typ)
@@ -130,7 +151,7 @@ func Sqlite3Tables(ctx context.Context, db DB, schema, typ string) ([]*Table, er
 	for rows.Next() {
 		var t Table
 		// scan
-		if err := rows.Scan(&t.Type, &t.TableName, &t.ViewDef); err != nil {
+		if err := rows.Scan(&t.Type, &t.TableName, &t.ViewDef, &t.Strict, &t.WithoutRowid); err != nil {
 			return nil, logerror(err)
 		}
 		res = append(res, &t)
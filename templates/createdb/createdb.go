@@ -73,18 +73,27 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 				Desc:       "trim leading comment from views and procs",
 				Default:    "true",
 			},
+			{
+				ContextKey: SchemaLayoutKey,
+				Type:       "string",
+				Desc:       "output layout for multiple schemas (flat writes one file, dir writes a file per schema)",
+				Default:    "flat",
+				Enums:      []string{"flat", "dir"},
+			},
 		},
 		Funcs: NewFuncs,
 		Order: func(ctx context.Context, mode string) []string {
 			return []string{"header", "createdb"}
 		},
 		Pre: func(ctx context.Context, mode string, set *xo.Set, out fs.FS, emit func(xo.Template)) error {
-			for _, schema := range set.Schemas {
+			schemas := set.Schemas
+			if SchemaLayout(ctx) != "dir" && len(schemas) > 1 {
+				// All schemas are written to the same file.
+				schemas = schemas[:1]
+			}
+			for _, schema := range schemas {
 				// Add support for append mode.
-				filename := "xo.xo.sql"
-				if single := xo.Single(ctx); single != "" {
-					filename = single
-				}
+				filename := fileName(ctx, schema.Name)
 
 				// Emit "Generated by xo..." heading to the top of the file.
 				if !Append(ctx) {
@@ -93,7 +102,7 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 						Dest:    filename,
 						Data:    schema,
 					})
-					return nil
+					continue
 				}
 
 				f, err := out.Open(filename)
@@ -105,7 +114,7 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 						Dest:    filename,
 						Data:    schema,
 					})
-					return nil
+					continue
 				case err != nil:
 					return err
 				}
@@ -129,11 +138,11 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 				return errors.New("createdb template must be passed at least one schema")
 			}
 			for _, schema := range set.Schemas {
-				schema.Tables = sortTables(schema.Tables)
+				schema.Tables = schema.TablesByDependency()
 
 				emit(xo.Template{
 					Partial:  "createdb",
-					Dest:     "xo.xo.sql",
+					Dest:     fileName(ctx, schema.Name),
 					SortName: schema.Name,
 					Data:     schema,
 				})
@@ -176,8 +185,19 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 	return nil
 }
 
-// cleanRE matches empty lines.
-var cleanRE = regexp.MustCompile(`([\.;])\n{2,}--`)
+// fileName returns the output file name for the schema.
+func fileName(ctx context.Context, schema string) string {
+	switch {
+	case xo.Single(ctx) != "":
+		return xo.Single(ctx)
+	case SchemaLayout(ctx) == "dir":
+		return schema + "/xo.xo.sql"
+	}
+	return "xo.xo.sql"
+}
+
+// cleanRE matches empty lines after a statement or header comment.
+var cleanRE = regexp.MustCompile(`(?m)([\.;]|^--.*)\n{2,}--`)
 
 // cleanEnd trims the end of any spaces, ensuring it ends with exactly one
 // newline.
@@ -185,42 +205,6 @@ func cleanEnd(buf []byte) []byte {
 	return append(bytes.TrimRightFunc(buf, unicode.IsSpace), '\n')
 }
 
-// sortTables sorts tables.
-func sortTables(tables []xo.Table) []xo.Table {
-	m := make(map[string]xo.Table)
-	for _, table := range tables {
-		m[table.Name] = table
-	}
-	seen := make(map[string]bool)
-	var sorted []xo.Table
-	for _, table := range tables {
-		sorted = sortAppendTable(m, seen, sorted, table)
-	}
-	return sorted
-}
-
-// sortAppendTable appends and returns the list of foreign key dependencies for
-// the table if not already in seen.
-func sortAppendTable(m map[string]xo.Table, seen map[string]bool, sorted []xo.Table, table xo.Table) []xo.Table {
-	if seen[table.Name] {
-		return sorted
-	}
-	for _, fk := range table.ForeignKeys {
-		t, ok := m[fk.RefTable]
-		if !ok {
-			panic(fmt.Sprintf(
-				"unexpected missing foreign key ref table %q for table %q",
-				fk.RefTable, table.Name,
-			))
-		}
-		if table.Name != t.Name && !seen[t.Name] {
-			sorted = sortAppendTable(m, seen, sorted, t)
-		}
-	}
-	seen[table.Name] = true
-	return append(sorted, table)
-}
-
 // Funcs is a set of template funcs.
 type Funcs struct {
 	driver      string
@@ -244,15 +228,23 @@ func NewFuncs(ctx context.Context, _ string) (template.FuncMap, error) {
 	}
 	return template.FuncMap{
 		"coldef":          funcs.coldef,
+		"attrdef":         funcs.attrdef,
+		"normalize":       funcs.normalize,
 		"viewdef":         funcs.viewdef,
 		"procdef":         funcs.procdef,
+		"triggerdef":      funcs.triggerdef,
+		"policydef":       funcs.policydef,
 		"driver":          funcs.driverfn,
 		"constraint":      funcs.constraintfn,
 		"esc":             funcs.escType,
+		"refTable":        funcs.refTable,
 		"fields":          funcs.fields,
 		"engine":          funcs.enginefn,
+		"tableopts":       funcs.tableopts,
 		"literal":         funcs.literal,
 		"isEndConstraint": funcs.isEndConstraint,
+		"isIndex":         funcs.isIndex,
+		"indexdef":        funcs.indexdef,
 		"comma":           comma,
 	}, nil
 }
@@ -267,8 +259,12 @@ func (f *Funcs) coldef(table xo.Table, field xo.Field) string {
 	}
 	// column def
 	def := []string{f.escCol(field.Name), typ}
-	// add default value
-	if field.Default != "" && !field.IsSequence {
+	// add default value, which postgres reports as the expression for
+	// generated columns
+	switch {
+	case field.IsGenerated && f.driver == "postgres" && field.Default != "":
+		def = append(def, "GENERATED ALWAYS AS ("+field.Default+") STORED")
+	case field.Default != "" && !field.IsSequence && !field.IsGenerated:
 		def = append(def, "DEFAULT", f.alterDefault(field.Default))
 	}
 	if !field.Type.Nullable && !field.IsSequence {
@@ -281,6 +277,11 @@ func (f *Funcs) coldef(table xo.Table, field xo.Field) string {
 	return strings.Join(def, " ")
 }
 
+// attrdef generates a composite type attribute definition.
+func (f *Funcs) attrdef(field xo.Field) string {
+	return f.escCol(field.Name) + " " + f.normalize(field.Type)
+}
+
 // alterDefault parses and alters default column values based on the driver.
 func (f *Funcs) alterDefault(s string) string {
 	switch f.driver {
@@ -315,6 +316,12 @@ var sqliteDefaultNeedsParenRE = regexp.MustCompile(`^([\('"].*[\)'"]|\d+)$`)
 func (f *Funcs) resolveSequence(typ string, field xo.Field) string {
 	switch f.driver {
 	case "postgres":
+		switch {
+		case field.IsIdentityAlways:
+			return typ + " GENERATED ALWAYS AS IDENTITY"
+		case field.IsIdentity:
+			return typ + " GENERATED BY DEFAULT AS IDENTITY"
+		}
 		switch typ {
 		case "SMALLINT":
 			return "SMALLSERIAL"
@@ -353,8 +360,10 @@ func (f *Funcs) colFKey(table xo.Table, field xo.Field) string {
 // viewdef generates a view definition.
 func (f *Funcs) viewdef(view xo.Table) string {
 	def := view.Definition
-	switch f.driver {
-	case "postgres", "mysql", "oracle":
+	switch {
+	case view.Type == "matview":
+		def = fmt.Sprintf("CREATE MATERIALIZED VIEW %s AS\n%s", f.escType(view.Name), view.Definition)
+	case f.driver == "postgres" || f.driver == "mysql" || f.driver == "oracle":
 		def = fmt.Sprintf("CREATE VIEW %s AS\n%s", f.escType(view.Name), view.Definition)
 	}
 	if f.trimComment {
@@ -375,6 +384,34 @@ func (f *Funcs) procdef(proc xo.Proc) string {
 	return def
 }
 
+// triggerdef generates a trigger definition, escaping semicolons in the
+// trigger's body.
+func (f *Funcs) triggerdef(trigger xo.Trigger) string {
+	def := strings.TrimSuffix(strings.TrimSpace(trigger.Definition), ";")
+	return strings.ReplaceAll(def, ";", "\\;")
+}
+
+// policydef generates a row level security policy definition.
+func (f *Funcs) policydef(table xo.Table, policy xo.Policy) string {
+	def := []string{"CREATE POLICY", f.escType(policy.Name), "ON", f.escType(table.Name)}
+	if policy.Restrictive {
+		def = append(def, "AS RESTRICTIVE")
+	}
+	if policy.Command != "" && policy.Command != "ALL" {
+		def = append(def, "FOR", policy.Command)
+	}
+	if len(policy.Roles) != 0 {
+		def = append(def, "TO", strings.Join(policy.Roles, ", "))
+	}
+	if policy.Using != "" {
+		def = append(def, "USING ("+policy.Using+")")
+	}
+	if policy.WithCheck != "" {
+		def = append(def, "WITH CHECK ("+policy.WithCheck+")")
+	}
+	return strings.Join(def, " ")
+}
+
 // celanProcDef cleans a proc definition.
 func (f *Funcs) cleanProcDef(def string) string {
 	switch f.driver {
@@ -473,6 +510,15 @@ func (f *Funcs) esc(s string, esc bool) string {
 	return start + s + end
 }
 
+// refTable returns the table referenced by the foreign key, qualified with its
+// schema when it is in a different schema.
+func (f *Funcs) refTable(fkey xo.ForeignKey) string {
+	if fkey.RefSchema != "" {
+		return f.escType(fkey.RefSchema) + "." + f.escType(fkey.RefTable)
+	}
+	return f.escType(fkey.RefTable)
+}
+
 // escType
 func (f *Funcs) escType(value string) string {
 	return f.esc(value, f.escTypes)
@@ -491,12 +537,35 @@ func (f *Funcs) enginefn() string {
 	return fmt.Sprintf(" ENGINE=%s", f.engine)
 }
 
+// tableopts returns the table options for the table (sqlite3).
+func (f *Funcs) tableopts(table xo.Table) string {
+	if f.driver != "sqlite3" {
+		return ""
+	}
+	var opts []string
+	if table.WithoutRowid {
+		opts = append(opts, "WITHOUT ROWID")
+	}
+	if table.Strict {
+		opts = append(opts, "STRICT")
+	}
+	if len(opts) == 0 {
+		return ""
+	}
+	return " " + strings.Join(opts, ", ")
+}
+
 // normalize normalizes a datatype.
 func (f *Funcs) normalize(datatype xo.Type) string {
 	typ := f.convert(datatype)
-	if datatype.Scale > 0 && !omitPrecision[f.driver][typ] {
+	switch {
+	case datatype.Geometry != "" && datatype.SRID != 0:
+		typ += fmt.Sprintf("(%s, %d)", datatype.Geometry, datatype.SRID)
+	case datatype.Geometry != "":
+		typ += fmt.Sprintf("(%s)", datatype.Geometry)
+	case datatype.Scale > 0 && !omitPrecision[f.driver][typ]:
 		typ += fmt.Sprintf("(%d, %d)", datatype.Prec, datatype.Scale)
-	} else if datatype.Prec > 0 && !omitPrecision[f.driver][typ] {
+	case datatype.Prec > 0 && !omitPrecision[f.driver][typ]:
 		typ += fmt.Sprintf("(%d)", datatype.Prec)
 	}
 	if datatype.Unsigned {
@@ -533,12 +602,42 @@ func (f *Funcs) literal(literal string) string {
 }
 
 func (f *Funcs) isEndConstraint(idx xo.Index) bool {
+	// partial and expression indexes cannot be declared as constraints
+	if idx.Predicate != "" || len(idx.Expressions) != 0 || len(idx.Fields) == 0 {
+		return false
+	}
 	if f.driver == "sqlite3" && idx.Fields[0].IsSequence {
 		return false
 	}
 	return idx.IsPrimary || idx.IsUnique
 }
 
+// isIndex returns true if the index should be created separately from the
+// table definition.
+func (f *Funcs) isIndex(idx xo.Index) bool {
+	if idx.IsPrimary {
+		return false
+	}
+	return !idx.IsUnique || idx.Predicate != "" || len(idx.Expressions) != 0
+}
+
+// indexdef generates a create index statement.
+func (f *Funcs) indexdef(table xo.Table, idx xo.Index) string {
+	typ := "INDEX"
+	if idx.IsUnique {
+		typ = "UNIQUE INDEX"
+	}
+	keys := f.fields(idx.Fields)
+	if len(idx.Expressions) != 0 {
+		keys = strings.Join(idx.Expressions, ", ")
+	}
+	def := fmt.Sprintf("CREATE %s %s ON %s (%s)", typ, f.escType(idx.Name), f.escType(table.Name), keys)
+	if idx.Predicate != "" {
+		def += " WHERE " + idx.Predicate
+	}
+	return def
+}
+
 var typeAliases = map[string]map[string]string{
 	"postgres": {
 		"character varying":           "varchar",
@@ -588,13 +687,14 @@ func comma(i int, v interface{}) string {
 
 // Context keys.
 var (
-	AppendKey      xo.ContextKey = "append"
-	FmtKey         xo.ContextKey = "fmt"
-	FmtOptsKey     xo.ContextKey = "fmt-opts"
-	ConstraintKey  xo.ContextKey = "constraint"
-	EscKey         xo.ContextKey = "escape"
-	EngineKey      xo.ContextKey = "engine"
-	TrimCommentKey xo.ContextKey = "trim-comment"
+	AppendKey       xo.ContextKey = "append"
+	FmtKey          xo.ContextKey = "fmt"
+	FmtOptsKey      xo.ContextKey = "fmt-opts"
+	ConstraintKey   xo.ContextKey = "constraint"
+	EscKey          xo.ContextKey = "escape"
+	EngineKey       xo.ContextKey = "engine"
+	TrimCommentKey  xo.ContextKey = "trim-comment"
+	SchemaLayoutKey xo.ContextKey = "schema-layout"
 )
 
 // Append returns append from the context.
@@ -639,6 +739,12 @@ func TrimComment(ctx context.Context) bool {
 	return b
 }
 
+// SchemaLayout returns schema-layout from the context.
+func SchemaLayout(ctx context.Context) string {
+	s, _ := ctx.Value(SchemaLayoutKey).(string)
+	return s
+}
+
 // Lang returns the sql-formatter language to use from the context based on the
 // context driver.
 func Lang(ctx context.Context) string {
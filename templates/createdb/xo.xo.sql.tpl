@@ -1,6 +1,8 @@
 {{ define "header" -}}
 {{- $s := .Data -}}
 -- Generated by xo for the {{ .Data.Name }} schema.
+{{ with provenance "-- " }}{{ . }}
+{{ end -}}
 {{ end }}
 
 {{ define "createdb" -}}
@@ -15,6 +17,22 @@ CREATE TYPE {{ esc $e.Name }} AS ENUM (
 );
 {{ end -}}
 {{- end -}}
+{{ if and $s.Composites (driver "postgres") }}
+{{ range $c := $s.Composites }}
+-- composite {{ $c.Name }}
+CREATE TYPE {{ esc $c.Name }} AS (
+{{- range $i, $f := $c.Fields }}
+  {{ attrdef $f }}{{ comma $i $c.Fields }}
+{{- end }}
+);
+{{ end -}}
+{{- end -}}
+{{ if and $s.Ranges (driver "postgres") }}
+{{ range $r := $s.Ranges }}
+-- range {{ $r.Name }}
+CREATE TYPE {{ esc $r.Name }} AS RANGE (SUBTYPE = {{ normalize $r.SubType }});
+{{ end -}}
+{{- end -}}
 {{- if $s.Tables }}
 {{- range $t := $s.Tables }}
 -- table {{ $t.Name }}
@@ -26,13 +44,13 @@ CREATE TABLE {{ esc $t.Name }} (
   {{ constraint $idx.Name -}} {{ if $idx.IsPrimary }}PRIMARY KEY{{ else }}UNIQUE{{ end }} ({{ fields $idx.Fields }})
 {{- end -}}{{- end -}}
 {{- range $fk := $t.ForeignKeys -}}{{- if gt (len $fk.Fields) 1 }},
-  {{ constraint $fk.Name -}} FOREIGN KEY ({{ fields $fk.Fields }}) REFERENCES {{ esc $fk.RefTable }} ({{ fields $fk.RefFields }})
+  {{ constraint $fk.Name -}} FOREIGN KEY ({{ fields $fk.Fields }}) REFERENCES {{ refTable $fk }} ({{ fields $fk.RefFields }})
 {{- end -}}{{- end }}
-){{ engine }};
+){{ engine }}{{ tableopts $t }};
 {{- if $t.Indexes }}
-{{ range $idx := $t.Indexes }}{{ if not (or $idx.IsPrimary $idx.IsUnique) }}
+{{ range $idx := $t.Indexes }}{{ if isIndex $idx }}
 -- index {{ $idx.Name }}
-CREATE INDEX {{ esc $idx.Name }} ON {{ esc $t.Name }} ({{ fields $idx.Fields }});
+{{ indexdef $t $idx }};
 {{ end -}}{{- end -}}{{- end }}
 {{ end -}}
 {{- end -}}
@@ -42,10 +60,43 @@ CREATE INDEX {{ esc $idx.Name }} ON {{ esc $t.Name }} ({{ fields $idx.Fields }})
 {{ viewdef $v }};
 {{ end }}
 {{ end -}}
+{{- if $s.MatViews }}
+{{- range $v := $s.MatViews }}
+-- materialized view {{ $v.Name }}
+{{ viewdef $v }};
+{{- range $idx := $v.Indexes }}
+{{ indexdef $v $idx }};
+{{- end }}
+{{ end }}
+{{ end -}}
 {{- if $s.Procs }}
 {{- range $p := $s.Procs }}
 -- {{ $p.Type }} {{ $p.Name }}
 {{ procdef $p }};
 {{ end -}}
 {{ end -}}
+{{- range $t := $s.Tables }}
+{{- range $tr := $t.Triggers }}
+-- trigger {{ $tr.Name }} on {{ $t.Name }}
+{{ triggerdef $tr }};
+{{ end -}}
+{{- end -}}
+{{- range $v := $s.Views }}
+{{- range $tr := $v.Triggers }}
+-- trigger {{ $tr.Name }} on {{ $v.Name }}
+{{ triggerdef $tr }};
+{{ end -}}
+{{- end -}}
+{{- if driver "postgres" }}
+{{- range $t := $s.Tables }}
+{{- if $t.RowSecurity }}
+-- row level security on {{ $t.Name }}
+ALTER TABLE {{ esc $t.Name }} ENABLE ROW LEVEL SECURITY;
+{{ end -}}
+{{- range $p := $t.Policies }}
+-- policy {{ $p.Name }} on {{ $t.Name }}
+{{ policydef $t $p }};
+{{ end -}}
+{{- end -}}
+{{- end -}}
 {{ end -}}
@@ -0,0 +1,274 @@
+package templates
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Op is a change operation.
+type Op string
+
+// Change operations.
+const (
+	OpWrite  Op = "write"
+	OpChange Op = "change"
+	OpRemove Op = "remove"
+)
+
+// Change is a change that generation would make to a file in the out
+// directory.
+type Change struct {
+	Op   Op
+	File string
+	Old  []byte
+	New  []byte
+}
+
+// Changes compares the generated files with the existing files in the out
+// directory, returning the files that would be written, changed, or removed.
+//
+// Files recorded in the out directory's sum file that are no longer generated
+// are considered stale, and would be removed. When there is no sum file,
+// existing files in the same directories as the generated files, and having
+// the same compound extension (such as .xo.go), are considered stale instead.
+func (ts *Set) Changes(out string) ([]Change, error) {
+	sums, err := ReadSum(out)
+	if err != nil {
+		return nil, err
+	}
+	var files []string
+	for file := range ts.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	var changes []Change
+	dirs, exts := make(map[string]bool), make(map[string]bool)
+	for _, file := range files {
+		buf := ts.files[file].Buf.Bytes()
+		dirs[filepath.Dir(file)] = true
+		if ext := compoundExt(file); ext != "" {
+			exts[ext] = true
+		}
+		switch old, err := os.ReadFile(filepath.Join(out, file)); {
+		case os.IsNotExist(err):
+			changes = append(changes, Change{Op: OpWrite, File: file, New: buf})
+		case err != nil:
+			return nil, err
+		case !bytes.Equal(old, buf):
+			changes = append(changes, Change{Op: OpChange, File: file, Old: old, New: buf})
+		}
+	}
+	// stale files
+	var stale []string
+	for file := range sums {
+		if _, ok := ts.files[file]; !ok {
+			if _, err := os.Stat(filepath.Join(out, file)); err == nil {
+				stale = append(stale, file)
+			}
+		}
+	}
+	if len(sums) == 0 {
+		for dir := range dirs {
+			entries, err := os.ReadDir(filepath.Join(out, dir))
+			switch {
+			case os.IsNotExist(err):
+				continue
+			case err != nil:
+				return nil, err
+			}
+			for _, entry := range entries {
+				file := filepath.Join(dir, entry.Name())
+				if _, ok := ts.files[file]; !ok && entry.Type().IsRegular() && exts[compoundExt(file)] {
+					stale = append(stale, file)
+				}
+			}
+		}
+	}
+	sort.Strings(stale)
+	for _, file := range stale {
+		old, err := os.ReadFile(filepath.Join(out, file))
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, Change{Op: OpRemove, File: file, Old: old})
+	}
+	return changes, nil
+}
+
+// compoundExt returns the compound extension of a file name (ie, .xo.go for
+// author.xo.go), or an empty string when the file does not have a compound
+// extension.
+func compoundExt(file string) string {
+	name := filepath.Base(file)
+	if i := strings.Index(name, "."); i > 0 && strings.Count(name[i:], ".") > 1 {
+		return name[i:]
+	}
+	return ""
+}
+
+// diffContext is the number of lines of context in a unified diff.
+const diffContext = 3
+
+// Diff writes a unified diff of the change to w.
+func (c Change) Diff(w io.Writer) error {
+	oldName, newName := "a/"+filepath.ToSlash(c.File), "b/"+filepath.ToSlash(c.File)
+	switch c.Op {
+	case OpWrite:
+		oldName = "/dev/null"
+	case OpRemove:
+		newName = "/dev/null"
+	}
+	edits := diffLines(splitLines(c.Old), splitLines(c.New))
+	buf := new(bytes.Buffer)
+	fmt.Fprintf(buf, "--- %s\n+++ %s\n", oldName, newName)
+	for i := 0; i < len(edits); {
+		// find next change
+		for i < len(edits) && edits[i].op == ' ' {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+		start := i - diffContext
+		if start < 0 {
+			start = 0
+		}
+		// extend hunk until there is enough unchanged context after the last
+		// change
+		end, n := i, 0
+		for ; end < len(edits) && n <= 2*diffContext; end++ {
+			if edits[end].op == ' ' {
+				n++
+			} else {
+				n = 0
+			}
+		}
+		if n > diffContext {
+			end -= n - diffContext
+		}
+		// count lines before and in the hunk
+		oldStart, newStart := 1, 1
+		for _, e := range edits[:start] {
+			if e.op != '+' {
+				oldStart++
+			}
+			if e.op != '-' {
+				newStart++
+			}
+		}
+		var oldCount, newCount int
+		for _, e := range edits[start:end] {
+			if e.op != '+' {
+				oldCount++
+			}
+			if e.op != '-' {
+				newCount++
+			}
+		}
+		if oldCount == 0 {
+			oldStart--
+		}
+		if newCount == 0 {
+			newStart--
+		}
+		fmt.Fprintf(buf, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		for _, e := range edits[start:end] {
+			buf.WriteByte(e.op)
+			buf.WriteString(e.line)
+			if !strings.HasSuffix(e.line, "\n") {
+				buf.WriteString("\n\\ No newline at end of file\n")
+			}
+		}
+		i = end
+	}
+	_, err := w.Write(buf.Bytes())
+	return err
+}
+
+// edit is a line edit.
+type edit struct {
+	op   byte
+	line string
+}
+
+// maxDiffCells is the maximum size of the table used to find the longest
+// common subsequence of the changed lines.
+const maxDiffCells = 1 << 24
+
+// diffLines returns the edits to change a to b.
+func diffLines(a, b []string) []edit {
+	// trim common prefix and suffix
+	var pre, suf int
+	for pre < len(a) && pre < len(b) && a[pre] == b[pre] {
+		pre++
+	}
+	for suf < len(a)-pre && suf < len(b)-pre && a[len(a)-1-suf] == b[len(b)-1-suf] {
+		suf++
+	}
+	var edits []edit
+	for _, line := range a[:pre] {
+		edits = append(edits, edit{' ', line})
+	}
+	x, y := a[pre:len(a)-suf], b[pre:len(b)-suf]
+	if len(x)*len(y) > maxDiffCells {
+		// too large to compare, replace all lines
+		for _, line := range x {
+			edits = append(edits, edit{'-', line})
+		}
+		for _, line := range y {
+			edits = append(edits, edit{'+', line})
+		}
+	} else {
+		// longest common subsequence of the remaining lines
+		m := len(y) + 1
+		lcs := make([]int, (len(x)+1)*m)
+		for i := len(x) - 1; i >= 0; i-- {
+			for j := len(y) - 1; j >= 0; j-- {
+				switch {
+				case x[i] == y[j]:
+					lcs[i*m+j] = lcs[(i+1)*m+j+1] + 1
+				case lcs[(i+1)*m+j] >= lcs[i*m+j+1]:
+					lcs[i*m+j] = lcs[(i+1)*m+j]
+				default:
+					lcs[i*m+j] = lcs[i*m+j+1]
+				}
+			}
+		}
+		i, j := 0, 0
+		for i < len(x) || j < len(y) {
+			switch {
+			case i < len(x) && j < len(y) && x[i] == y[j]:
+				edits = append(edits, edit{' ', x[i]})
+				i++
+				j++
+			case j == len(y) || (i < len(x) && lcs[(i+1)*m+j] >= lcs[i*m+j+1]):
+				edits = append(edits, edit{'-', x[i]})
+				i++
+			default:
+				edits = append(edits, edit{'+', y[j]})
+				j++
+			}
+		}
+	}
+	for _, line := range a[len(a)-suf:] {
+		edits = append(edits, edit{' ', line})
+	}
+	return edits
+}
+
+// splitLines splits buf into lines, keeping line endings.
+func splitLines(buf []byte) []string {
+	if len(buf) == 0 {
+		return nil
+	}
+	lines := strings.SplitAfter(string(buf), "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
@@ -0,0 +1,62 @@
+package templates
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDiff(t *testing.T) {
+	lines := func(n int) string {
+		var s []string
+		for i := 1; i <= n; i++ {
+			s = append(s, string(rune('a'+i-1)))
+		}
+		return strings.Join(s, "\n") + "\n"
+	}
+	tests := []struct {
+		name string
+		c    Change
+		exp  string
+	}{
+		{
+			name: "write",
+			c:    Change{Op: OpWrite, File: "a.xo.go", New: []byte("a\nb\n")},
+			exp:  "--- /dev/null\n+++ b/a.xo.go\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "remove",
+			c:    Change{Op: OpRemove, File: "a.xo.go", Old: []byte("a\n")},
+			exp:  "--- a/a.xo.go\n+++ /dev/null\n@@ -1,1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "change",
+			c:    Change{Op: OpChange, File: "a.xo.go", Old: []byte(lines(10)), New: []byte(strings.Replace(lines(10), "e\n", "E\n", 1))},
+			exp:  "--- a/a.xo.go\n+++ b/a.xo.go\n@@ -2,7 +2,7 @@\n b\n c\n d\n-e\n+E\n f\n g\n h\n",
+		},
+		{
+			name: "hunks",
+			c:    Change{Op: OpChange, File: "a.xo.go", Old: []byte(lines(20)), New: []byte(strings.Replace(strings.Replace(lines(20), "b\n", "", 1), "s\n", "s\nS\n", 1))},
+			exp:  "--- a/a.xo.go\n+++ b/a.xo.go\n@@ -1,5 +1,4 @@\n a\n-b\n c\n d\n e\n@@ -17,4 +16,5 @@\n q\n r\n s\n+S\n t\n",
+		},
+		{
+			name: "merged hunks",
+			c:    Change{Op: OpChange, File: "a.xo.go", Old: []byte(lines(10)), New: []byte(strings.Replace(strings.Replace(lines(10), "b\n", "B\n", 1), "i\n", "I\n", 1))},
+			exp:  "--- a/a.xo.go\n+++ b/a.xo.go\n@@ -1,10 +1,10 @@\n a\n-b\n+B\n c\n d\n e\n f\n g\n h\n-i\n+I\n j\n",
+		},
+		{
+			name: "no newline",
+			c:    Change{Op: OpChange, File: "a.xo.go", Old: []byte("a\nb"), New: []byte("a\nb\n")},
+			exp:  "--- a/a.xo.go\n+++ b/a.xo.go\n@@ -1,2 +1,2 @@\n a\n-b\n\\ No newline at end of file\n+b\n",
+		},
+	}
+	for i, test := range tests {
+		buf := new(bytes.Buffer)
+		if err := test.c.Diff(buf); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		if s := buf.String(); s != test.exp {
+			t.Errorf("test %d (%s) expected:\n%s\ngot:\n%s", i, test.name, test.exp, s)
+		}
+	}
+}
@@ -104,6 +104,7 @@ func NewFuncs(ctx context.Context, _ string) (template.FuncMap, error) {
 func (f *Funcs) FuncMap() template.FuncMap {
 	return template.FuncMap{
 		"schema":    f.schemafn,
+		"qualify":   f.qualify,
 		"defaults":  f.defaultsfn,
 		"header":    f.header,
 		"row":       f.rowfn,
@@ -132,8 +133,12 @@ func (f *Funcs) rowfn(field xo.Field) string {
 	return fmt.Sprintf(`<td align="left" PORT=%q>%s</td>`, field.Name, buf.String())
 }
 
-func (f *Funcs) edge(table xo.Table, fkey xo.ForeignKey, i int) string {
-	node, toNode := f.schemafn(table.Name), f.schemafn(fkey.RefTable)
+func (f *Funcs) edge(schema string, table xo.Table, fkey xo.ForeignKey, i int) string {
+	refSchema := schema
+	if fkey.RefSchema != "" {
+		refSchema = fkey.RefSchema
+	}
+	node, toNode := f.qualify(schema, table.Name), f.qualify(refSchema, fkey.RefTable)
 	row, toRow := quotes(fkey.Fields[i].Name), quotes(fkey.RefFields[i].Name)
 	var dirFrom, dirTo string
 	if f.direction {
@@ -145,7 +150,12 @@ func (f *Funcs) edge(table xo.Table, fkey xo.ForeignKey, i int) string {
 
 // schemafn takes a series of names and joins them with the schema name.
 func (f *Funcs) schemafn(names ...string) string {
-	s, n := f.schema, strings.Join(names, ".")
+	return f.qualify(f.schema, names...)
+}
+
+// qualify takes a schema and a series of names and joins them.
+func (f *Funcs) qualify(s string, names ...string) string {
+	n := strings.Join(names, ".")
 	switch {
 	case s == "" && n == "":
 		return ""
@@ -1,6 +1,8 @@
 {{ define "dot" -}}
 {{ $s := .Data -}}
 // Generated by xo for the {{ $s.Name }} schema.
+{{ with provenance "// " }}{{ . }}
+{{ end -}}
 digraph {{ $s.Name|normalize }} {
 	{{ if defaults -}}
 	// Defaults
@@ -11,9 +13,9 @@ digraph {{ $s.Name|normalize }} {
 
 	// Nodes (tables)
 	{{- range $s.Tables }}
-	{{ schema .Name }} [ label=<
+	{{ qualify $s.Name .Name }} [ label=<
 		<table border="0" cellborder="1" cellspacing="0" cellpadding="4">
-		<tr>{{ header (schema .Name) }}</tr>
+		<tr>{{ header (qualify $s.Name .Name) }}</tr>
 		{{ range .Columns -}}
 		<tr>{{ row . }}</tr>
 		{{ end -}}
@@ -25,7 +27,7 @@ digraph {{ $s.Name|normalize }} {
 	{{- range $t.ForeignKeys -}}
 	{{- $fkey := . -}}
 	{{- range $i, $field := .Fields }}
-	{{ edge $t $fkey $i }} [
+	{{ edge $s.Name $t $fkey $i }} [
 		headlabel={{ quotes $fkey.Name }}]
 	{{- end }}
 	{{- end -}}
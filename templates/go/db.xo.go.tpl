@@ -60,8 +60,17 @@ func convLogger(logger interface{}) func(string, ...interface{}) {
 }
 
 // DB is the common interface for database operations that can be used with
-// types from schema '{{ schema }}'.
+// types from schema '{{ qualify .Data }}'.
 //
+{{ if pgx -}}
+// This works with pgx.Conn, pgx.Tx and pgxpool.Pool.
+type DB interface {
+	Exec(context.Context, string, ...interface{}) (pgconn.CommandTag, error)
+	Query(context.Context, string, ...interface{}) (pgx.Rows, error)
+	QueryRow(context.Context, string, ...interface{}) pgx.Row
+	SendBatch(context.Context, *pgx.Batch) pgx.BatchResults
+}
+{{- else -}}
 // This works with both database/sql.DB and database/sql.Tx.
 type DB interface {
 {{ if context -}}
@@ -74,7 +83,388 @@ type DB interface {
 	QueryRow(string, ...interface{}) *sql.Row
 {{- end }}
 }
+{{- end }}
+
+{{ if tenant -}}
+// TenantDB is a DB running the generated queries against a tenant schema with
+// the same structure as schema '{{ qualify .Data }}'.
+type TenantDB struct {
+	DB
+	schema string
+}
+
+// Tenant returns a TenantDB running the generated queries on db against the
+// tenant schema. The schema name is used as-is in the queries, and must be a
+// valid identifier.
+func Tenant(db DB, schema string) (*TenantDB, error) {
+	if !tenantRE.MatchString(schema) {
+		return nil, logerror(fmt.Errorf("invalid tenant schema %q", schema))
+	}
+	return &TenantDB{
+		DB:     db,
+		schema: schema,
+	}, nil
+}
+
+// tenantRE matches valid tenant schema names.
+var tenantRE = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_$]*$`)
+
+// Schema returns the tenant schema.
+func (t *TenantDB) Schema() string {
+	return t.schema
+}
+
+// query returns the query using the tenant schema in place of the '{tenant}'
+// placeholder of the generated queries.
+func (t *TenantDB) query(sqlstr string) string {
+	return strings.ReplaceAll(sqlstr, "{tenant}", t.schema)
+}
+{{ if pgx }}
+// Exec satisfies the DB interface.
+func (t *TenantDB) Exec(ctx context.Context, sqlstr string, args ...interface{}) (pgconn.CommandTag, error) {
+	return t.DB.Exec(ctx, t.query(sqlstr), args...)
+}
+
+// Query satisfies the DB interface.
+func (t *TenantDB) Query(ctx context.Context, sqlstr string, args ...interface{}) (pgx.Rows, error) {
+	return t.DB.Query(ctx, t.query(sqlstr), args...)
+}
+
+// QueryRow satisfies the DB interface.
+func (t *TenantDB) QueryRow(ctx context.Context, sqlstr string, args ...interface{}) pgx.Row {
+	return t.DB.QueryRow(ctx, t.query(sqlstr), args...)
+}
+
+// SendBatch satisfies the DB interface. The queries are sent as a copy of the
+// batch, leaving the batch unchanged.
+func (t *TenantDB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	batch := new(pgx.Batch)
+	for _, q := range b.QueuedQueries {
+		query := *q
+		query.SQL = t.query(q.SQL)
+		batch.QueuedQueries = append(batch.QueuedQueries, &query)
+	}
+	return t.DB.SendBatch(ctx, batch)
+}
+{{ else }}
+{{- if context }}
+// ExecContext satisfies the DB interface.
+func (t *TenantDB) ExecContext(ctx context.Context, sqlstr string, args ...interface{}) (sql.Result, error) {
+	return t.DB.ExecContext(ctx, t.query(sqlstr), args...)
+}
+
+// QueryContext satisfies the DB interface.
+func (t *TenantDB) QueryContext(ctx context.Context, sqlstr string, args ...interface{}) (*sql.Rows, error) {
+	return t.DB.QueryContext(ctx, t.query(sqlstr), args...)
+}
+
+// QueryRowContext satisfies the DB interface.
+func (t *TenantDB) QueryRowContext(ctx context.Context, sqlstr string, args ...interface{}) *sql.Row {
+	return t.DB.QueryRowContext(ctx, t.query(sqlstr), args...)
+}
+{{ end -}}
+{{ if or context_both context_disable }}
+// Exec satisfies the DB interface.
+func (t *TenantDB) Exec(sqlstr string, args ...interface{}) (sql.Result, error) {
+	return t.DB.Exec(t.query(sqlstr), args...)
+}
+
+// Query satisfies the DB interface.
+func (t *TenantDB) Query(sqlstr string, args ...interface{}) (*sql.Rows, error) {
+	return t.DB.Query(t.query(sqlstr), args...)
+}
+
+// QueryRow satisfies the DB interface.
+func (t *TenantDB) QueryRow(sqlstr string, args ...interface{}) *sql.Row {
+	return t.DB.QueryRow(t.query(sqlstr), args...)
+}
+{{ end -}}
+{{ end }}
+{{ end -}}
+{{ if hooks -}}
+// QueryInfo is the query run by a generated func, passed to a QueryHook.
+type QueryInfo struct {
+	// Name is the name of the generated func (ie, AuthorByAuthorID or
+	// Author.Insert). Name is empty for queries not run by a generated func.
+	Name string
+	// Table is the name of the queried table, view, or proc, if any.
+	Table string
+	// Op is the operation (ie, select, insert, update, upsert, delete).
+	Op string
+	// SQL is the query.
+	SQL string
+}
+
+// QueryHook is called around the queries run on a HookedDB, for collecting
+// metrics and tracing.
+type QueryHook interface {
+	// Before is called before the query is run, returning the context used
+	// to run the query.
+	Before(context.Context, QueryInfo) context.Context
+	// After is called after the query is run, with the context returned by
+	// Before and the error of the query, if any. Rows returned by the query
+	// may not have been read yet.
+	After(context.Context, QueryInfo, error)
+}
+
+// HookedDB is a DB calling a QueryHook around each query.
+type HookedDB struct {
+	DB
+	hook QueryHook
+}
+
+// Hooked returns a HookedDB calling hook around the queries run on db.
+func Hooked(db DB, hook QueryHook) *HookedDB {
+	return &HookedDB{
+		DB:   db,
+		hook: hook,
+	}
+}
+
+// queryKey is the context key for the QueryInfo of a generated func.
+type queryKey struct{}
+
+// withQuery returns a context with the name, table, and operation of the
+// query run by a generated func.
+func withQuery(ctx context.Context, name, table, op string) context.Context {
+	return context.WithValue(ctx, queryKey{}, QueryInfo{
+		Name:  name,
+		Table: table,
+		Op:    op,
+	})
+}
+
+// before calls the hook before running sqlstr, returning the context used to
+// run the query and the query info.
+func (h *HookedDB) before(ctx context.Context, sqlstr string) (context.Context, QueryInfo) {
+	q, _ := ctx.Value(queryKey{}).(QueryInfo)
+	q.SQL = sqlstr
+	return h.hook.Before(ctx, q), q
+}
+{{ if pgx }}
+// Exec satisfies the DB interface.
+func (h *HookedDB) Exec(ctx context.Context, sqlstr string, args ...interface{}) (pgconn.CommandTag, error) {
+	ctx, q := h.before(ctx, sqlstr)
+	res, err := h.DB.Exec(ctx, sqlstr, args...)
+	h.hook.After(ctx, q, err)
+	return res, err
+}
+
+// Query satisfies the DB interface.
+func (h *HookedDB) Query(ctx context.Context, sqlstr string, args ...interface{}) (pgx.Rows, error) {
+	ctx, q := h.before(ctx, sqlstr)
+	rows, err := h.DB.Query(ctx, sqlstr, args...)
+	h.hook.After(ctx, q, err)
+	return rows, err
+}
+
+// QueryRow satisfies the DB interface. The hook is called after the row is
+// scanned.
+func (h *HookedDB) QueryRow(ctx context.Context, sqlstr string, args ...interface{}) pgx.Row {
+	ctx, q := h.before(ctx, sqlstr)
+	return hookedRow{
+		Row: h.DB.QueryRow(ctx, sqlstr, args...),
+		after: func(err error) {
+			h.hook.After(ctx, q, err)
+		},
+	}
+}
+
+// SendBatch satisfies the DB interface. The hook is called after the batch
+// results are closed.
+func (h *HookedDB) SendBatch(ctx context.Context, b *pgx.Batch) pgx.BatchResults {
+	ctx, q := h.before(ctx, "")
+	return hookedBatchResults{
+		BatchResults: h.DB.SendBatch(ctx, b),
+		after: func(err error) {
+			h.hook.After(ctx, q, err)
+		},
+	}
+}
+
+// hookedRow is a row calling after once scanned.
+type hookedRow struct {
+	pgx.Row
+	after func(error)
+}
+
+// Scan satisfies the pgx.Row interface.
+func (r hookedRow) Scan(v ...interface{}) error {
+	err := r.Row.Scan(v...)
+	r.after(err)
+	return err
+}
+
+// hookedBatchResults are batch results calling after once closed.
+type hookedBatchResults struct {
+	pgx.BatchResults
+	after func(error)
+}
+
+// Close satisfies the pgx.BatchResults interface.
+func (r hookedBatchResults) Close() error {
+	err := r.BatchResults.Close()
+	r.after(err)
+	return err
+}
+{{ else }}
+// ExecContext satisfies the DB interface.
+func (h *HookedDB) ExecContext(ctx context.Context, sqlstr string, args ...interface{}) (sql.Result, error) {
+	ctx, q := h.before(ctx, sqlstr)
+	res, err := h.DB.ExecContext(ctx, sqlstr, args...)
+	h.hook.After(ctx, q, err)
+	return res, err
+}
+
+// QueryContext satisfies the DB interface.
+func (h *HookedDB) QueryContext(ctx context.Context, sqlstr string, args ...interface{}) (*sql.Rows, error) {
+	ctx, q := h.before(ctx, sqlstr)
+	rows, err := h.DB.QueryContext(ctx, sqlstr, args...)
+	h.hook.After(ctx, q, err)
+	return rows, err
+}
+
+// QueryRowContext satisfies the DB interface. As a *sql.Row cannot be
+// wrapped, the hook is called before the row is scanned, with the error of
+// the query, if any. The generated funcs instead call the hook after the row
+// is scanned.
+func (h *HookedDB) QueryRowContext(ctx context.Context, sqlstr string, args ...interface{}) *sql.Row {
+	ctx, q := h.before(ctx, sqlstr)
+	row := h.DB.QueryRowContext(ctx, sqlstr, args...)
+	h.hook.After(ctx, q, row.Err())
+	return row
+}
+
+// rowScanner is the interface for scanning a row.
+type rowScanner interface {
+	Scan(...interface{}) error
+}
+
+// queryRow runs sqlstr on db, calling the hook of a HookedDB after the row is
+// scanned.
+func queryRow(ctx context.Context, db DB, sqlstr string, args ...interface{}) rowScanner {
+	h, ok := db.(*HookedDB)
+	if !ok {
+		return db.QueryRowContext(ctx, sqlstr, args...)
+	}
+	ctx, q := h.before(ctx, sqlstr)
+	return hookedRow{
+		Row: h.DB.QueryRowContext(ctx, sqlstr, args...),
+		after: func(err error) {
+			h.hook.After(ctx, q, err)
+		},
+	}
+}
+
+// hookedRow is a row calling after once scanned.
+type hookedRow struct {
+	*sql.Row
+	after func(error)
+}
+
+// Scan satisfies the rowScanner interface.
+func (r hookedRow) Scan(v ...interface{}) error {
+	err := r.Row.Scan(v...)
+	r.after(err)
+	return err
+}
+{{ end -}}
+{{ if otel }}
+// OtelHook is a QueryHook creating an OpenTelemetry span for each query, with
+// the query's name, table, and operation as attributes.
+type OtelHook struct {
+	tracer trace.Tracer
+}
+
+// NewOtelHook creates a OtelHook creating spans with the tracer.
+func NewOtelHook(tracer trace.Tracer) *OtelHook {
+	return &OtelHook{
+		tracer: tracer,
+	}
+}
+
+// Before satisfies the QueryHook interface.
+func (h *OtelHook) Before(ctx context.Context, q QueryInfo) context.Context {
+	name := q.Name
+	if name == "" {
+		name = "query"
+	}
+	ctx, _ = h.tracer.Start(ctx, name,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("db.system", "{{ if driver "postgres" }}postgresql{{ else if driver "sqlite3" }}sqlite{{ else if driver "sqlserver" }}mssql{{ else if driver "mysql" }}mysql{{ else }}oracle{{ end }}"),
+			attribute.String("db.operation", q.Op),
+			attribute.String("db.sql.table", q.Table),
+			attribute.String("db.statement", q.SQL),
+			attribute.String("xo.query", q.Name),
+		),
+	)
+	return ctx
+}
+
+// After satisfies the QueryHook interface.
+func (h *OtelHook) After(ctx context.Context, q QueryInfo, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+{{ end }}
+{{ end -}}
+{{ if repository -}}
+// Tx is a transaction exposing the funcs for types from schema
+// '{{ qualify .Data }}' as methods, so that multiple operations can be composed in
+// the same transaction.
+type Tx struct {
+{{- if pgx }}
+	pgx.Tx
+{{- else }}
+	*sql.Tx
+{{- end }}
+}
 
+{{ if pgx -}}
+// TxBeginner is the interface for starting a transaction.
+//
+// This works with both pgx.Conn and pgxpool.Pool.
+type TxBeginner interface {
+	BeginTx(context.Context, pgx.TxOptions) (pgx.Tx, error)
+}
+
+// BeginTx starts a transaction on db.
+func BeginTx(ctx context.Context, db TxBeginner, opts pgx.TxOptions) (*Tx, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, logerror(err)
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+{{ else if context -}}
+// BeginTx starts a transaction on db.
+func BeginTx(ctx context.Context, db *sql.DB, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.BeginTx(ctx, opts)
+	if err != nil {
+		return nil, logerror(err)
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+{{ end -}}
+{{ if or context_both context_disable -}}
+// Begin starts a transaction on db.
+func Begin(db *sql.DB) (*Tx, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, logerror(err)
+	}
+	return &Tx{Tx: tx}, nil
+}
+
+{{ end -}}
+{{ end -}}
 // Error is an error.
 type Error string
 
@@ -91,6 +481,8 @@ const (
 	ErrDoesNotExist Error = "does not exist"
 	// ErrMarkedForDeletion is the marked for deletion error.
 	ErrMarkedForDeletion Error = "marked for deletion"
+	// ErrVersionConflict is the version conflict error.
+	ErrVersionConflict Error = "version conflict"
 )
 
 // ErrInsertFailed is the insert failed error.
@@ -138,6 +530,134 @@ func (err *ErrUpsertFailed) Unwrap() error {
 	return err.Err
 }
 
+{{ if bulk -}}
+const (
+	// bulkMaxParams is the maximum number of parameters used by a bulk
+	// statement.
+	bulkMaxParams = {{ if driver "sqlserver" }}2000{{ else if driver "sqlite3" }}32766{{ else }}65535{{ end }}
+	// bulkMaxRows is the maximum number of rows used by a bulk statement.
+	bulkMaxRows = 1000
+)
+
+// bulkSize returns the number of rows with m parameters to use per bulk
+// statement.
+func bulkSize(m int) int {
+	if n := bulkMaxParams / m; n < bulkMaxRows {
+		return n
+	}
+	return bulkMaxRows
+}
+
+// bulkParams returns the placeholders for n rows of m parameters, formatting
+// each row with format and joining the rows with sep.
+func bulkParams(format, sep string, n, m int) string {
+	rows := make([]string, n)
+	for i := range rows {
+		params := make([]interface{}, m)
+		for j := range params {
+			params[j] = {{ bulk_param "i*m+j+1" }}
+		}
+		rows[i] = fmt.Sprintf(format, params...)
+	}
+	return strings.Join(rows, sep)
+}
+
+{{ end -}}
+{{ if factories -}}
+// factorySeq is the sequence used by factory funcs to generate unique fake
+// data.
+var factorySeq int64
+
+// nextFactorySeq returns the next value of the factory sequence.
+func nextFactorySeq() int {
+	return int(atomic.AddInt64(&factorySeq, 1))
+}
+
+// factoryTime returns the fake time for the factory sequence value n.
+func factoryTime(n int) time.Time {
+	return time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC).Add(time.Duration(n) * time.Minute)
+}
+
+{{ end -}}
+{{ if filter -}}
+// filter is a WHERE clause built by filter funcs.
+type filter struct {
+	conds []string
+	args  []interface{}
+}
+
+// add adds a condition comparing col to args with op (ie, "=", "IN", or
+// "IS NULL").
+func (f *filter) add(col, op string, args ...interface{}) {
+	params := make([]string, len(args))
+	for i := range params {
+		params[i] = {{ bulk_param "len(f.args)+i+1" }}
+	}
+	switch {
+	case op == "IN" && len(args) == 0:
+		f.conds = append(f.conds, "1 = 0")
+	case op == "IN":
+		f.conds = append(f.conds, col+" IN ("+strings.Join(params, ", ")+")")
+	case len(args) == 0:
+		f.conds = append(f.conds, col+" "+op)
+	default:
+		f.conds = append(f.conds, col+" "+op+" "+params[0])
+	}
+	f.args = append(f.args, args...)
+}
+
+// clause returns the WHERE clause for the filter's conditions.
+func (f *filter) clause() string {
+	if len(f.conds) == 0 {
+		return ""
+	}
+	return " WHERE " + strings.Join(f.conds, " AND ")
+}
+
+{{ end -}}
+{{ if paginate -}}
+// ErrInvalidCursor is the invalid cursor error.
+type ErrInvalidCursor string
+
+// Error satisfies the error interface.
+func (err ErrInvalidCursor) Error() string {
+	return fmt.Sprintf("invalid cursor (%s)", string(err))
+}
+
+// marshalCursor encodes the key values of a cursor.
+func marshalCursor(v ...interface{}) ([]byte, error) {
+	buf, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	s := make([]byte, base64.RawURLEncoding.EncodedLen(len(buf)))
+	base64.RawURLEncoding.Encode(s, buf)
+	return s, nil
+}
+
+// unmarshalCursor decodes the key values of a cursor encoded by marshalCursor.
+func unmarshalCursor(s []byte, v ...interface{}) error {
+	buf := make([]byte, base64.RawURLEncoding.DecodedLen(len(s)))
+	n, err := base64.RawURLEncoding.Decode(buf, s)
+	if err != nil {
+		return ErrInvalidCursor(err.Error())
+	}
+	var keys []json.RawMessage
+	if err := json.Unmarshal(buf[:n], &keys); err != nil {
+		return ErrInvalidCursor(err.Error())
+	}
+	if len(keys) != len(v) {
+		return ErrInvalidCursor(fmt.Sprintf("expected %d keys, got: %d", len(v), len(keys)))
+	}
+	for i, key := range keys {
+		if err := json.Unmarshal(key, v[i]); err != nil {
+			return ErrInvalidCursor(err.Error())
+		}
+	}
+	return nil
+}
+
+{{ end -}}
 {{ if driver "sqlite3" -}}
 // ErrInvalidTime is the invalid Time error.
 type ErrInvalidTime string
@@ -192,6 +712,18 @@ func (t *Time) Scan(v interface{}) error {
 	return ErrInvalidTime(fmt.Sprintf("%T", v))
 }
 
+{{ if paginate -}}
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (t Time) MarshalText() ([]byte, error) {
+	return t.time.MarshalText()
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (t *Time) UnmarshalText(buf []byte) error {
+	return t.time.UnmarshalText(buf)
+}
+
+{{ end -}}
 // Parse attempts to Parse string s to t.
 func (t *Time) Parse(s string) error {
 	if s == "" {
@@ -227,3 +759,440 @@ var TimestampFormats = []string{
 }
 {{- end }}
 {{- end }}
+
+{{ define "querier" }}
+//xo:querier
+{{ end }}
+
+{{ define "prepare" }}
+// Preparer is a DB that can prepare statements.
+//
+// This works with both database/sql.DB and database/sql.Tx.
+type Preparer interface {
+	DB
+{{- if context }}
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+{{- else }}
+	Prepare(string) (*sql.Stmt, error)
+{{- end }}
+}
+
+// PreparedDB is a DB using prepared statements for the statements of the
+// generated funcs, falling back to the wrapped DB for other statements.
+type PreparedDB struct {
+	DB
+	stmts map[string]*sql.Stmt
+}
+
+// Prepare prepares the statements of the generated funcs on db, returning a
+// PreparedDB using them. The PreparedDB should be closed when no longer
+// needed.
+{{ if context -}}
+func Prepare(ctx context.Context, db Preparer) (*PreparedDB, error) {
+{{- else -}}
+func Prepare(db Preparer) (*PreparedDB, error) {
+{{- end }}
+	p := &PreparedDB{
+		DB:    db,
+		stmts: make(map[string]*sql.Stmt, len(statements)),
+	}
+	for _, sqlstr := range statements {
+{{- if context }}
+		stmt, err := db.PrepareContext(ctx, sqlstr)
+{{- else }}
+		stmt, err := db.Prepare(sqlstr)
+{{- end }}
+		if err != nil {
+			p.Close()
+			return nil, logerror(err)
+		}
+		p.stmts[sqlstr] = stmt
+	}
+	return p, nil
+}
+
+// Tx returns a PreparedDB using the prepared statements in the transaction.
+{{ if context -}}
+func (p *PreparedDB) Tx(ctx context.Context, tx *sql.Tx) *PreparedDB {
+{{- else -}}
+func (p *PreparedDB) Tx(tx *sql.Tx) *PreparedDB {
+{{- end }}
+	stmts := make(map[string]*sql.Stmt, len(p.stmts))
+	for sqlstr, stmt := range p.stmts {
+{{- if context }}
+		stmts[sqlstr] = tx.StmtContext(ctx, stmt)
+{{- else }}
+		stmts[sqlstr] = tx.Stmt(stmt)
+{{- end }}
+	}
+	return &PreparedDB{
+		DB:    tx,
+		stmts: stmts,
+	}
+}
+
+// Close closes the prepared statements.
+func (p *PreparedDB) Close() error {
+	var err error
+	for _, stmt := range p.stmts {
+		if e := stmt.Close(); e != nil && err == nil {
+			err = e
+		}
+	}
+	return err
+}
+{{ if context }}
+// ExecContext satisfies the DB interface.
+func (p *PreparedDB) ExecContext(ctx context.Context, sqlstr string, args ...interface{}) (sql.Result, error) {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.ExecContext(ctx, args...)
+	}
+	return p.DB.ExecContext(ctx, sqlstr, args...)
+}
+
+// QueryContext satisfies the DB interface.
+func (p *PreparedDB) QueryContext(ctx context.Context, sqlstr string, args ...interface{}) (*sql.Rows, error) {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.QueryContext(ctx, args...)
+	}
+	return p.DB.QueryContext(ctx, sqlstr, args...)
+}
+
+// QueryRowContext satisfies the DB interface.
+func (p *PreparedDB) QueryRowContext(ctx context.Context, sqlstr string, args ...interface{}) *sql.Row {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.QueryRowContext(ctx, args...)
+	}
+	return p.DB.QueryRowContext(ctx, sqlstr, args...)
+}
+{{ end -}}
+{{ if or context_both context_disable }}
+// Exec satisfies the DB interface.
+func (p *PreparedDB) Exec(sqlstr string, args ...interface{}) (sql.Result, error) {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.Exec(args...)
+	}
+	return p.DB.Exec(sqlstr, args...)
+}
+
+// Query satisfies the DB interface.
+func (p *PreparedDB) Query(sqlstr string, args ...interface{}) (*sql.Rows, error) {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.Query(args...)
+	}
+	return p.DB.Query(sqlstr, args...)
+}
+
+// QueryRow satisfies the DB interface.
+func (p *PreparedDB) QueryRow(sqlstr string, args ...interface{}) *sql.Row {
+	if stmt, ok := p.stmts[sqlstr]; ok {
+		return stmt.QueryRow(args...)
+	}
+	return p.DB.QueryRow(sqlstr, args...)
+}
+{{ end }}
+// statements are the statements of the generated funcs.
+//xo:statements
+{{ end }}
+
+{{ define "spatial" }}
+// Geometry is a spatial value, with its spatial reference system identifier
+// (SRID). A Geometry with a nil orb.Geometry is NULL.
+{{- if driver "mysql" }}
+//
+// Geometry values are stored using MySQL's internal format (the SRID
+// followed by the WKB).
+{{- else }}
+//
+// Geometry values are stored as (hex encoded) EWKB, as used by PostGIS.
+{{- end }}
+type Geometry struct {
+	orb.Geometry
+	SRID int
+}
+
+// Value satisfies the driver.Valuer interface.
+func (g Geometry) Value() (driver.Value, error) {
+	if g.Geometry == nil {
+		return nil, nil
+	}
+{{- if driver "mysql" }}
+	return ewkb.ValuePrefixSRID(g.Geometry, g.SRID).Value()
+{{- else }}
+	return ewkb.MarshalToHex(g.Geometry, g.SRID)
+{{- end }}
+}
+
+// Scan satisfies the sql.Scanner interface.
+func (g *Geometry) Scan(v interface{}) error {
+	switch x := v.(type) {
+	case nil:
+		*g = Geometry{}
+		return nil
+	case string:
+		// scanned as text, when the driver has no codec for the type
+		v = []byte(x)
+	}
+{{- if driver "mysql" }}
+	s := ewkb.ScannerPrefixSRID(nil)
+{{- else }}
+	s := ewkb.Scanner(nil)
+{{- end }}
+	if err := s.Scan(v); err != nil {
+		return err
+	}
+	g.Geometry, g.SRID = s.Geometry, s.SRID
+	return nil
+}
+{{ end }}
+
+{{ define "pgtypes" }}
+// Range is a range of T, as stored by a range type.
+type Range[T any] struct {
+	// Lower and Upper are the bounds of the range.
+	Lower, Upper T
+	// LowerInclusive and UpperInclusive are true if the bound is included
+	// in the range.
+	LowerInclusive, UpperInclusive bool
+	// LowerUnbounded and UpperUnbounded are true if the range has no lower
+	// or upper bound.
+	LowerUnbounded, UpperUnbounded bool
+	// Empty is true if the range is empty.
+	Empty bool
+	// Valid is true if the range is not null.
+	Valid bool
+}
+
+// NewRange creates a range from lower (inclusive) to upper (exclusive).
+func NewRange[T any](lower, upper T) Range[T] {
+	return Range[T]{
+		Lower:          lower,
+		Upper:          upper,
+		LowerInclusive: true,
+		Valid:          true,
+	}
+}
+
+// Value satisfies the driver.Valuer interface.
+func (r Range[T]) Value() (driver.Value, error) {
+	switch {
+	case !r.Valid:
+		return nil, nil
+	case r.Empty:
+		return "empty", nil
+	}
+	buf, err := []byte{'('}, error(nil)
+	if r.LowerInclusive {
+		buf[0] = '['
+	}
+	if !r.LowerUnbounded {
+		if buf, err = appendText(buf, r.Lower); err != nil {
+			return nil, err
+		}
+	}
+	buf = append(buf, ',')
+	if !r.UpperUnbounded {
+		if buf, err = appendText(buf, r.Upper); err != nil {
+			return nil, err
+		}
+	}
+	if r.UpperInclusive {
+		return string(append(buf, ']')), nil
+	}
+	return string(append(buf, ')')), nil
+}
+
+// Scan satisfies the sql.Scanner interface.
+func (r *Range[T]) Scan(v interface{}) error {
+	var s string
+	switch x := v.(type) {
+	case nil:
+		*r = Range[T]{}
+		return nil
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return fmt.Errorf("cannot scan %T into Range", v)
+	}
+	if s == "empty" {
+		*r = Range[T]{Empty: true, Valid: true}
+		return nil
+	}
+	n := len(s)
+	if n < 3 || (s[0] != '[' && s[0] != '(') || (s[n-1] != ']' && s[n-1] != ')') {
+		return fmt.Errorf("invalid range %q", s)
+	}
+	fields := splitText(s[1 : n-1])
+	if len(fields) != 2 {
+		return fmt.Errorf("invalid range %q", s)
+	}
+	res := Range[T]{
+		LowerInclusive: s[0] == '[',
+		UpperInclusive: s[n-1] == ']',
+		LowerUnbounded: fields[0] == nil,
+		UpperUnbounded: fields[1] == nil,
+		Valid:          true,
+	}
+	if fields[0] != nil {
+		if err := scanText(&res.Lower, *fields[0]); err != nil {
+			return err
+		}
+	}
+	if fields[1] != nil {
+		if err := scanText(&res.Upper, *fields[1]); err != nil {
+			return err
+		}
+	}
+	*r = res
+	return nil
+}
+
+// parseComposite parses the text representation of a composite value with n
+// attributes. Null attributes are returned as nil.
+func parseComposite(v interface{}, n int) ([]*string, error) {
+	var s string
+	switch x := v.(type) {
+	case []byte:
+		s = string(x)
+	case string:
+		s = x
+	default:
+		return nil, fmt.Errorf("cannot scan %T into composite", v)
+	}
+	if len(s) < 2 || s[0] != '(' || s[len(s)-1] != ')' {
+		return nil, fmt.Errorf("invalid composite %q", s)
+	}
+	fields := splitText(s[1 : len(s)-1])
+	if len(fields) != n {
+		return nil, fmt.Errorf("invalid composite %q: expected %d attributes, got: %d", s, n, len(fields))
+	}
+	return fields, nil
+}
+
+// splitText splits the comma separated, and optionally quoted, fields of the
+// text representation of a composite or range value. Empty unquoted fields
+// are returned as nil.
+func splitText(s string) []*string {
+	var fields []*string
+	var sb strings.Builder
+	quoted, inQuote := false, false
+	add := func() {
+		if str := sb.String(); quoted || str != "" {
+			fields = append(fields, &str)
+		} else {
+			fields = append(fields, nil)
+		}
+		sb.Reset()
+		quoted = false
+	}
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; {
+		case c == '\\' && i+1 < len(s):
+			i++
+			sb.WriteByte(s[i])
+		case c == '"' && inQuote && i+1 < len(s) && s[i+1] == '"':
+			i++
+			sb.WriteByte('"')
+		case c == '"':
+			quoted, inQuote = true, !inQuote
+		case c == ',' && !inQuote:
+			add()
+		default:
+			sb.WriteByte(c)
+		}
+	}
+	add()
+	return fields
+}
+
+// scanText scans the text representation of a composite attribute or range
+// bound into dest.
+func scanText(dest interface{}, s string) error {
+	var err error
+	switch d := dest.(type) {
+	case *string:
+		*d = s
+	case *[]byte:
+		*d, err = hex.DecodeString(strings.TrimPrefix(s, `\x`))
+	case *bool:
+		*d = s == "t" || s == "true"
+	case *int:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 0)
+		*d = int(i)
+	case *int16:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 16)
+		*d = int16(i)
+	case *int32:
+		var i int64
+		i, err = strconv.ParseInt(s, 10, 32)
+		*d = int32(i)
+	case *int64:
+		*d, err = strconv.ParseInt(s, 10, 64)
+	case *float32:
+		var f float64
+		f, err = strconv.ParseFloat(s, 32)
+		*d = float32(f)
+	case *float64:
+		*d, err = strconv.ParseFloat(s, 64)
+	case *time.Time:
+		*d, err = parseTextTime(s)
+	case *sql.NullTime:
+		d.Time, err = parseTextTime(s)
+		d.Valid = err == nil
+	case sql.Scanner:
+		err = d.Scan([]byte(s))
+	default:
+		err = fmt.Errorf("unsupported type %T", dest)
+	}
+	return err
+}
+
+// parseTextTime parses the text representation of a date or timestamp.
+func parseTextTime(s string) (time.Time, error) {
+	for _, layout := range []string{
+		"2006-01-02 15:04:05.999999999Z07:00",
+		"2006-01-02 15:04:05.999999999Z07",
+		"2006-01-02 15:04:05.999999999",
+		"2006-01-02",
+	} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid time %q", s)
+}
+
+// appendText appends the quoted text representation of v, as a composite
+// attribute or range bound, to buf. Nothing is appended when v is null.
+func appendText(buf []byte, v interface{}) ([]byte, error) {
+	dv, err := driver.DefaultParameterConverter.ConvertValue(v)
+	if err != nil {
+		return nil, err
+	}
+	var s string
+	switch x := dv.(type) {
+	case nil:
+		return buf, nil
+	case string:
+		s = x
+	case []byte:
+		s = `\x` + hex.EncodeToString(x)
+	case bool:
+		s = strconv.FormatBool(x)
+	case int64:
+		s = strconv.FormatInt(x, 10)
+	case float64:
+		s = strconv.FormatFloat(x, 'g', -1, 64)
+	case time.Time:
+		s = x.Format("2006-01-02 15:04:05.999999999Z07:00")
+	default:
+		return nil, fmt.Errorf("unsupported type %T", dv)
+	}
+	s = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s)
+	return append(append(append(buf, '"'), s...), '"'), nil
+}
+{{ end }}
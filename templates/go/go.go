@@ -7,15 +7,22 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"io"
 	"io/fs"
 	"io/ioutil"
 	"os"
+	"path"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"text/template"
+	"unicode"
 
 	"github.com/kenshaw/inflector"
 	"github.com/kenshaw/snaker"
@@ -61,6 +68,7 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 		"pq.Int32Array":   true,
 		"pq.StringArray":  true,
 		"pq.GenericArray": true,
+		"Geometry":        true,
 	}
 	shorts := map[string]string{
 		"bool":            "b",
@@ -94,6 +102,7 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 		"pq.Int32Array":   "a",
 		"pq.StringArray":  "a",
 		"pq.GenericArray": "a",
+		"Geometry":        "g",
 	}
 	f(xo.TemplateType{
 		Modes: []string{"query", "schema"},
@@ -213,6 +222,115 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 				Default:    "ora",
 				Enums:      []string{"ora", "godror"},
 			},
+			{
+				ContextKey: BulkKey,
+				Type:       "bool",
+				Desc:       "enable bulk insert and upsert funcs",
+				Default:    "false",
+			},
+			{
+				ContextKey: StreamKey,
+				Type:       "bool",
+				Desc:       "enable streaming funcs for queries returning multiple rows",
+				Default:    "false",
+			},
+			{
+				ContextKey: PaginateKey,
+				Type:       "bool",
+				Desc:       "enable keyset pagination funcs",
+				Default:    "false",
+			},
+			{
+				ContextKey: FilterKey,
+				Type:       "bool",
+				Desc:       "enable filter builder funcs over indexed columns",
+				Default:    "false",
+			},
+			{
+				ContextKey: RLSKey,
+				Type:       "bool",
+				Desc:       "enable funcs setting the settings read by row level security policies",
+				Default:    "false",
+			},
+			{
+				ContextKey: QuerierKey,
+				Type:       "bool",
+				Desc:       "enable Querier interface of the repository methods (implies --go-repository)",
+				Default:    "false",
+			},
+			{
+				ContextKey: MockKey,
+				Type:       "bool",
+				Desc:       "enable MockQuerier implementation of the Querier interface (implies --go-querier)",
+				Default:    "false",
+			},
+			{
+				ContextKey: PrepareKey,
+				Type:       "bool",
+				Desc:       "enable Prepare func caching prepared statements for the generated queries",
+				Default:    "false",
+			},
+			{
+				ContextKey: HooksKey,
+				Type:       "bool",
+				Desc:       "enable QueryHook instrumentation of the generated queries",
+				Default:    "false",
+			},
+			{
+				ContextKey: OtelKey,
+				Type:       "bool",
+				Desc:       "enable QueryHook creating OpenTelemetry spans for the generated queries (implies --go-hooks)",
+				Default:    "false",
+			},
+			{
+				ContextKey: PgxKey,
+				Type:       "bool",
+				Desc:       "enable pgx mode, using github.com/jackc/pgx/v5 instead of database/sql (postgres)",
+				Default:    "false",
+			},
+			{
+				ContextKey: IntEnumsKey,
+				Type:       "bool",
+				Desc:       "store enums in the database by their ordinal values (mysql)",
+				Default:    "false",
+			},
+			{
+				ContextKey: RepositoryKey,
+				Type:       "bool",
+				Desc:       "enable transaction-scoped repository type",
+				Default:    "false",
+			},
+			{
+				ContextKey: SoftDeleteKey,
+				Type:       "string",
+				Desc:       "soft delete column name (ie, deleted_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: VersionKey,
+				Type:       "string",
+				Desc:       "optimistic locking version column name (ie, version)",
+				Default:    "",
+			},
+			{
+				ContextKey: CreatedKey,
+				Type:       "string",
+				Desc:       "audit column set to the current time on insert (ie, created_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: UpdatedKey,
+				Type:       "string",
+				Desc:       "audit column set to the current time on insert and update (ie, updated_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: SchemaLayoutKey,
+				Type:       "string",
+				Desc:       "output layout for multiple schemas (flat prefixes names, dir writes a package per schema)",
+				Default:    "flat",
+				Enums:      []string{"flat", "dir"},
+			},
 		},
 		Funcs: func(ctx context.Context, _ string) (template.FuncMap, error) {
 			funcs, err := NewFuncs(ctx)
@@ -227,15 +345,16 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 		NewContext: func(ctx context.Context, _ string) context.Context {
 			ctx = context.WithValue(ctx, KnownTypesKey, knownTypes)
 			ctx = context.WithValue(ctx, ShortsKey, shorts)
+			ctx = context.WithValue(ctx, FirstsKey, make(map[string]string))
 			return ctx
 		},
 		Order: func(ctx context.Context, mode string) []string {
-			base := []string{"header", "db"}
+			base := []string{"header", "db", "querier", "prepare", "spatial", "pgtypes"}
 			switch mode {
 			case "query":
 				return append(base, "typedef", "query")
 			case "schema":
-				return append(base, "enum", "proc", "typedef", "query", "index", "foreignkey")
+				return append(base, "enum", "composite", "range", "proc", "typedef", "bulk", "page", "filter", "query", "index", "refresh", "setting", "foreignkey", "factory", "testdb", "roundtrip")
 			}
 			return nil
 		},
@@ -243,6 +362,18 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 			if err := addInitialisms(ctx); err != nil {
 				return err
 			}
+			if err := checkPgx(ctx); err != nil {
+				return err
+			}
+			if err := checkTenant(ctx); err != nil {
+				return err
+			}
+			switch {
+			case Hooks(ctx) && Context(ctx) == "disable":
+				return errors.New("--go-hooks requires context mode")
+			case Hooks(ctx) && (Repository(ctx) || Querier(ctx)):
+				return errors.New("--go-hooks is not supported with --go-repository, as the queries run on a Tx are not hooked")
+			}
 			files, err := fileNames(ctx, mode, set)
 			if err != nil {
 				return err
@@ -250,13 +381,73 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 			// If -2 is provided, skip package template outputs as requested.
 			// If -a is provided, skip to avoid duplicating the template.
 			if !NotFirst(ctx) && !Append(ctx) {
-				emit(xo.Template{
-					Partial: "db",
-					Dest:    "db.xo.go",
-				})
-				// If --single is provided, don't generate header for db.xo.go.
-				if xo.Single(ctx) == "" {
-					files["db.xo.go"] = true
+				// In dir layout, each schema's package has its own db.xo.go.
+				pkgs := []string{""}
+				if mode == "schema" && SchemaLayout(ctx) == "dir" {
+					pkgs = nil
+					for _, schema := range set.Schemas {
+						pkgs = append(pkgs, schema.Name)
+					}
+				}
+				for _, schema := range pkgs {
+					dest := "db.xo.go"
+					if schema != "" {
+						dest = schema + "/" + dest
+					}
+					emit(xo.Template{
+						Partial: "db",
+						Dest:    dest,
+						Data:    schema,
+					})
+					// If --single is provided, don't generate header for db.xo.go.
+					if xo.Single(ctx) == "" {
+						files[dest] = schema
+					}
+					// The Querier interface is built from the generated
+					// repository methods in Post.
+					if Querier(ctx) && xo.Single(ctx) == "" {
+						dest := strings.TrimSuffix(dest, "db.xo.go") + querierFile
+						emit(xo.Template{
+							Partial: "querier",
+							Dest:    dest,
+							Data:    schema,
+						})
+						files[dest] = schema
+					}
+					// The prepared statements are collected from the
+					// generated queries in Post.
+					if Prepare(ctx) && xo.Single(ctx) == "" {
+						dest := strings.TrimSuffix(dest, "db.xo.go") + prepareFile
+						emit(xo.Template{
+							Partial: "prepare",
+							Dest:    dest,
+							Data:    schema,
+						})
+						files[dest] = schema
+					}
+					// The Geometry type is only generated for packages
+					// with spatial columns.
+					if hasColumnType(ctx, mode, set, schema, isGeometry) {
+						dest := strings.TrimSuffix(dest, "db.xo.go") + spatialFile
+						emit(xo.Template{
+							Partial: "spatial",
+							Dest:    dest,
+							Data:    schema,
+						})
+						files[dest] = schema
+					}
+					// The Range type and the text encoding of composite
+					// and range values are only generated for packages
+					// using them.
+					if hasPgTypes(ctx, mode, set, schema) {
+						dest := strings.TrimSuffix(dest, "db.xo.go") + pgtypesFile
+						emit(xo.Template{
+							Partial: "pgtypes",
+							Dest:    dest,
+							Data:    schema,
+						})
+						files[dest] = schema
+					}
 				}
 			}
 			if Append(ctx) {
@@ -282,11 +473,21 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 					delete(files, filename)
 				}
 			}
-			for filename := range files {
+			firsts := Firsts(ctx)
+			for filename, schema := range files {
 				emit(xo.Template{
 					Partial: "header",
 					Dest:    filename,
+					Data:    schema,
 				})
+				// The package comment is added to the first file of each
+				// package, other than test files.
+				if strings.HasSuffix(filename, "_test.go") {
+					continue
+				}
+				if first, ok := firsts[schema]; !ok || filename < first {
+					firsts[schema] = filename
+				}
 			}
 			return nil
 		},
@@ -307,20 +508,56 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 			return nil
 		},
 		Post: func(ctx context.Context, mode string, files map[string][]byte, emit func(string, []byte)) error {
-			for file, content := range files {
-				// Run goimports.
-				buf, err := imports.Process("", content, nil)
-				if err != nil {
-					return fmt.Errorf("%s:%w", file, err)
+			var names []string
+			for file := range files {
+				names = append(names, file)
+			}
+			sort.Strings(names)
+			// Build the Querier interface from the Tx methods, and the
+			// prepared statements from the queries, of the files in the
+			// same package.
+			for _, file := range names {
+				var buf []byte
+				var err error
+				switch path.Base(file) {
+				case querierFile:
+					buf, err = buildQuerier(files, file, Mock(ctx))
+				case prepareFile:
+					buf, err = buildStatements(files, file)
+				default:
+					continue
 				}
-				// Run gofumpt.
-				formatted, err := format.Source(buf, format.Options{
-					ExtraRules: true,
-				})
 				if err != nil {
 					return err
 				}
-				emit(file, formatted)
+				files[file] = buf
+			}
+			// Format files in parallel.
+			formatted := make([][]byte, len(names))
+			errs := make([]error, len(names))
+			ch := make(chan int)
+			var wg sync.WaitGroup
+			for j := 0; j < xo.Jobs(ctx); j++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+					for i := range ch {
+						buf, err := formatFile(names[i], files[names[i]])
+						formatted[i] = buf
+						errs[i] = err
+					}
+				}()
+			}
+			for i := range names {
+				ch <- i
+			}
+			close(ch)
+			wg.Wait()
+			for i, file := range names {
+				if errs[i] != nil {
+					return errs[i]
+				}
+				emit(file, formatted[i])
 			}
 			return nil
 		},
@@ -328,47 +565,260 @@ func Init(ctx context.Context, f func(xo.TemplateType)) error {
 	return nil
 }
 
+// querierMarker is replaced with the Querier interface by buildQuerier.
+const querierMarker = "//xo:querier\n"
+
+// buildQuerier replaces the marker in the querier file with the Querier
+// interface of the Tx methods generated in the other files of the package,
+// and optionally a MockQuerier implementing it.
+func buildQuerier(files map[string][]byte, file string, mock bool) ([]byte, error) {
+	type method struct {
+		name, params, results string
+		args                  []string
+	}
+	var methods []method
+	dir := path.Dir(file)
+	for name, src := range files {
+		if name == file || path.Dir(name) != dir || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		fset := token.NewFileSet()
+		f, err := parser.ParseFile(fset, name, src, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", name, err)
+		}
+		text := func(start, end token.Pos) string {
+			return string(src[fset.Position(start).Offset:fset.Position(end).Offset])
+		}
+		for _, decl := range f.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Recv == nil || text(fn.Recv.List[0].Type.Pos(), fn.Recv.List[0].Type.End()) != "*Tx" {
+				continue
+			}
+			m := method{
+				name:   fn.Name.Name,
+				params: text(fn.Type.Params.Opening+1, fn.Type.Params.Closing),
+			}
+			if res := fn.Type.Results; res != nil {
+				m.results = " " + text(res.Pos(), res.End())
+			}
+			for _, field := range fn.Type.Params.List {
+				for _, n := range field.Names {
+					arg := n.Name
+					if _, ok := field.Type.(*ast.Ellipsis); ok {
+						arg += "..."
+					}
+					m.args = append(m.args, arg)
+				}
+			}
+			methods = append(methods, m)
+		}
+	}
+	sort.Slice(methods, func(i, j int) bool {
+		return methods[i].name < methods[j].name
+	})
+	buf := new(bytes.Buffer)
+	buf.WriteString("// Querier is the interface of the funcs for the package's types, as\n")
+	buf.WriteString("// implemented by Tx. Services can depend on Querier instead of Tx, so that\n")
+	buf.WriteString("// they can be tested without a database.\n")
+	buf.WriteString("type Querier interface {\n")
+	for _, m := range methods {
+		fmt.Fprintf(buf, "\t%s(%s)%s\n", m.name, m.params, m.results)
+	}
+	buf.WriteString("}\n\n// Tx satisfies the Querier interface.\nvar _ Querier = (*Tx)(nil)\n")
+	if mock {
+		buf.WriteString("\n// MockQuerier is a mock Querier for tests. Each method calls the func field\n")
+		buf.WriteString("// of the same name with a Func suffix, and panics when it is not set.\n")
+		buf.WriteString("type MockQuerier struct {\n")
+		for _, m := range methods {
+			fmt.Fprintf(buf, "\t%sFunc func(%s)%s\n", m.name, m.params, m.results)
+		}
+		buf.WriteString("}\n\n// MockQuerier satisfies the Querier interface.\nvar _ Querier = (*MockQuerier)(nil)\n")
+		// the receiver must not clash with the params copied from Tx, such as
+		// the m *Member of a Member's Delete
+		recv := "mq"
+		for taken := true; taken; {
+			taken = false
+			for _, m := range methods {
+				for _, arg := range m.args {
+					taken = taken || strings.TrimSuffix(arg, "...") == recv
+				}
+			}
+			if taken {
+				recv += "q"
+			}
+		}
+		for _, m := range methods {
+			fmt.Fprintf(buf, "\n// %s calls %sFunc.\n", m.name, m.name)
+			fmt.Fprintf(buf, "func (%s *MockQuerier) %s(%s)%s {\n", recv, m.name, m.params, m.results)
+			fmt.Fprintf(buf, "\tif %s.%sFunc == nil {\n\t\tpanic(\"MockQuerier.%sFunc is not set\")\n\t}\n", recv, m.name, m.name)
+			ret := "return "
+			if m.results == "" {
+				ret = ""
+			}
+			fmt.Fprintf(buf, "\t%s%s.%sFunc(%s)\n}\n", ret, recv, m.name, strings.Join(m.args, ", "))
+		}
+	}
+	return bytes.Replace(files[file], []byte(querierMarker), buf.Bytes(), 1), nil
+}
+
+// statementsMarker is replaced with the statements by buildStatements.
+const statementsMarker = "//xo:statements\n"
+
+// buildStatements replaces the marker in the prepare file with the constant
+// sqlstr queries of the generated funcs in the other files of the package.
+// Queries built at runtime (ie, bulk, filter and pagination queries) are not
+// prepared.
+func buildStatements(files map[string][]byte, file string) ([]byte, error) {
+	seen := make(map[string]bool)
+	var stmts []string
+	dir := path.Dir(file)
+	for name, src := range files {
+		if name == file || path.Dir(name) != dir || strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+		f, err := parser.ParseFile(token.NewFileSet(), name, src, 0)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%w", name, err)
+		}
+		ast.Inspect(f, func(n ast.Node) bool {
+			decl, ok := n.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
+				return true
+			}
+			for _, spec := range decl.Specs {
+				vs, ok := spec.(*ast.ValueSpec)
+				if !ok || len(vs.Names) != 1 || vs.Names[0].Name != "sqlstr" || len(vs.Values) != 1 {
+					continue
+				}
+				if s, ok := constString(vs.Values[0]); ok && !seen[s] {
+					seen[s] = true
+					stmts = append(stmts, s)
+				}
+			}
+			return false
+		})
+	}
+	sort.Strings(stmts)
+	buf := new(bytes.Buffer)
+	buf.WriteString("var statements = []string{\n")
+	for _, s := range stmts {
+		fmt.Fprintf(buf, "\t%s,\n", strconv.Quote(s))
+	}
+	buf.WriteString("}\n")
+	return bytes.Replace(files[file], []byte(statementsMarker), buf.Bytes(), 1), nil
+}
+
+// constString evaluates a constant string expression made of concatenated
+// string literals.
+func constString(expr ast.Expr) (string, bool) {
+	switch x := expr.(type) {
+	case *ast.BasicLit:
+		if x.Kind != token.STRING {
+			return "", false
+		}
+		s, err := strconv.Unquote(x.Value)
+		return s, err == nil
+	case *ast.BinaryExpr:
+		if x.Op != token.ADD {
+			return "", false
+		}
+		l, ok := constString(x.X)
+		if !ok {
+			return "", false
+		}
+		r, ok := constString(x.Y)
+		if !ok {
+			return "", false
+		}
+		return l + r, true
+	case *ast.ParenExpr:
+		return constString(x.X)
+	}
+	return "", false
+}
+
+// formatFile runs goimports and gofumpt on the file's content.
+func formatFile(file string, content []byte) ([]byte, error) {
+	// Run goimports.
+	buf, err := imports.Process("", content, nil)
+	if err != nil {
+		return nil, fmt.Errorf("%s:%w", file, err)
+	}
+	// Run gofumpt.
+	return format.Source(buf, format.Options{
+		ExtraRules: true,
+	})
+}
+
 // fileNames returns a list of file names that will be generated by the
-// template based on the parameters and schema.
-func fileNames(ctx context.Context, mode string, set *xo.Set) (map[string]bool, error) {
+// template based on the parameters and schema, mapped to the name of the
+// schema whose package the file belongs to (only used with dir layout).
+func fileNames(ctx context.Context, mode string, set *xo.Set) (map[string]string, error) {
 	// In single mode, only the specified file be generated.
 	singleFile := xo.Single(ctx)
 	if singleFile != "" {
-		return map[string]bool{
-			singleFile: true,
+		return map[string]string{
+			singleFile: "",
 		}, nil
 	}
 	// Otherwise, infer filenames from set.
-	files := make(map[string]bool)
-	addFile := func(filename string) {
+	files := make(map[string]string)
+	add := func(schema, filename string) {
+		dir, _ := schemaNames(ctx, schema)
+		if dir == "" {
+			schema = ""
+		}
+		files[dir+filename] = schema
+	}
+	addFile := func(schema, filename string) {
 		// Filenames are always lowercase.
-		filename = strings.ToLower(filename)
-		files[filename+ext] = true
+		add(schema, strings.ToLower(filename)+ext)
 	}
 	switch mode {
 	case "schema":
+		naming := xo.Naming(ctx)
 		for _, schema := range set.Schemas {
+			_, prefix := schemaNames(ctx, schema.Name)
 			for _, e := range schema.Enums {
-				addFile(camelExport(e.Name))
+				addFile(schema.Name, camelExport(prefix+e.Name))
+			}
+			for _, c := range schema.Composites {
+				addFile(schema.Name, camelExport(prefix+c.Name))
+			}
+			for _, r := range schema.Ranges {
+				addFile(schema.Name, camelExport(prefix+r.Name))
 			}
 			for _, p := range schema.Procs {
-				goName := camelExport(p.Name)
+				goName := camelExport(prefix + p.Name)
 				if p.Type == "function" {
-					addFile("sf_" + goName)
+					addFile(schema.Name, "sf_"+goName)
 				} else {
-					addFile("sp_" + goName)
+					addFile(schema.Name, "sp_"+goName)
 				}
 			}
 			for _, t := range schema.Tables {
-				addFile(camelExport(singularize(t.Name)))
+				addFile(schema.Name, camelExport(prefix+naming.Type(t.Name)))
 			}
 			for _, v := range schema.Views {
-				addFile(camelExport(singularize(v.Name)))
+				addFile(schema.Name, camelExport(prefix+naming.Type(v.Name)))
+			}
+			for _, v := range schema.MatViews {
+				addFile(schema.Name, camelExport(prefix+naming.Type(v.Name)))
+			}
+			if xo.Factories(ctx) && len(schema.Tables) != 0 {
+				addFile(schema.Name, "factory")
+			}
+			if RLS(ctx) && len(convertSettings(ctx, schema)) != 0 {
+				addFile(schema.Name, "settings")
+			}
+			if xo.Tests(ctx) && len(schema.Tables) != 0 {
+				add(schema.Name, testFile)
 			}
 		}
 	case "query":
 		for _, query := range set.Queries {
-			addFile(query.Type)
+			addFile("", query.Type)
 			if query.Exec {
 				// Single mode is handled at the start of the function but it
 				// must be used for Exec queries.
@@ -384,9 +834,17 @@ func fileNames(ctx context.Context, mode string, set *xo.Set) (map[string]bool,
 // emitQuery emits the query.
 func emitQuery(ctx context.Context, query xo.Query, emit func(xo.Template)) error {
 	var table Table
+	var sets []QuerySet
+	var err error
 	// build type if needed
-	if !query.Exec {
-		var err error
+	switch {
+	case len(query.ResultSets) != 0 && Pgx(ctx):
+		return errors.New("multiple result sets are not supported in pgx mode")
+	case len(query.ResultSets) != 0:
+		if table, sets, err = buildQuerySets(ctx, query); err != nil {
+			return err
+		}
+	case !query.Exec:
 		if table, err = buildQueryType(ctx, query); err != nil {
 			return err
 		}
@@ -400,6 +858,15 @@ func emitQuery(ctx context.Context, query xo.Query, emit func(xo.Template)) erro
 			SortName: query.Name,
 			Data:     table,
 		})
+		for i, set := range sets {
+			emit(xo.Template{
+				Partial:  "typedef",
+				Dest:     strings.ToLower(table.GoName) + ext,
+				SortType: query.Type,
+				SortName: fmt.Sprintf("%s_%03d", query.Name, i+1),
+				Data:     set.Type,
+			})
+		}
 	}
 	// build query params
 	var params []QueryParam
@@ -412,24 +879,38 @@ func emitQuery(ctx context.Context, query xo.Query, emit func(xo.Template)) erro
 		})
 	}
 	// emit query
+	q := Query{
+		Name:        buildQueryName(query),
+		Query:       query.Query,
+		Comments:    query.Comments,
+		Params:      params,
+		One:         query.Exec || query.Flat || query.One,
+		Flat:        query.Flat,
+		Exec:        query.Exec,
+		Interpolate: query.Interpolate,
+		Type:        table,
+		Sets:        sets,
+		Comment:     query.Comment,
+	}
 	emit(xo.Template{
 		Partial:  "query",
 		Dest:     strings.ToLower(table.GoName) + ext,
 		SortType: query.Type,
 		SortName: query.Name,
-		Data: Query{
-			Name:        buildQueryName(query),
-			Query:       query.Query,
-			Comments:    query.Comments,
-			Params:      params,
-			One:         query.Exec || query.Flat || query.One,
-			Flat:        query.Flat,
-			Exec:        query.Exec,
-			Interpolate: query.Interpolate,
-			Type:        table,
-			Comment:     query.Comment,
-		},
+		Data:     q,
 	})
+	// emit streaming variant
+	if Stream(ctx) && !q.One && len(q.Sets) == 0 {
+		q.Name += "Each"
+		q.Stream = true
+		emit(xo.Template{
+			Partial:  "query",
+			Dest:     strings.ToLower(table.GoName) + ext,
+			SortType: query.Type,
+			SortName: query.Name + "_each",
+			Data:     q,
+		})
+	}
 	return nil
 }
 
@@ -463,6 +944,42 @@ func buildQueryType(ctx context.Context, query xo.Query) (Table, error) {
 	}, nil
 }
 
+// buildQuerySets builds the result set types for a query returning multiple
+// result sets, and the query type aggregating the result sets.
+func buildQuerySets(ctx context.Context, query xo.Query) (Table, []QuerySet, error) {
+	var sets []QuerySet
+	var fields []Field
+	for i, z := range query.ResultSets {
+		name := fmt.Sprintf("Set%d", i+1)
+		q := query
+		q.Type, q.TypeComment, q.Fields = query.Type+name, "", z
+		typ, err := buildQueryType(ctx, q)
+		if err != nil {
+			return Table{}, nil, err
+		}
+		typ.Comment = fmt.Sprintf("%s represents a row from result set %d of %s.", typ.GoName, i+1, buildQueryName(query))
+		sets = append(sets, QuerySet{
+			Name: name,
+			Type: typ,
+		})
+		fields = append(fields, Field{
+			GoName:  name,
+			SQLName: snake(name),
+			Type:    "[]*" + typ.GoName,
+		})
+	}
+	comment := query.TypeComment
+	if comment == "" {
+		comment = fmt.Sprintf("%s holds the result sets of %s.", query.Type, buildQueryName(query))
+	}
+	return Table{
+		GoName:  query.Type,
+		SQLName: snake(query.Type),
+		Fields:  fields,
+		Comment: comment,
+	}, sets, nil
+}
+
 // buildQueryName builds a name for the query.
 func buildQueryName(query xo.Query) string {
 	if query.Name != "" {
@@ -487,16 +1004,47 @@ func buildQueryName(query xo.Query) string {
 
 // emitSchema emits the xo schema for the template set.
 func emitSchema(ctx context.Context, schema xo.Schema, emit func(xo.Template)) error {
+	dir, _ := schemaNames(ctx, schema.Name)
+	if dir != "" {
+		// in dir layout, types are named relative to their own schema
+		ctx = context.WithValue(ctx, xo.SchemaKey, schema.Name)
+	}
 	// emit enums
 	for _, e := range schema.Enums {
-		enum := convertEnum(e)
+		enum := convertEnum(ctx, schema.Name, e)
 		emit(xo.Template{
 			Partial:  "enum",
-			Dest:     strings.ToLower(enum.GoName) + ext,
+			Dest:     dir + strings.ToLower(enum.GoName) + ext,
 			SortName: enum.GoName,
 			Data:     enum,
 		})
 	}
+	// emit composite types
+	for _, c := range schema.Composites {
+		composite, err := convertComposite(ctx, schema.Name, c)
+		if err != nil {
+			return err
+		}
+		emit(xo.Template{
+			Partial:  "composite",
+			Dest:     dir + strings.ToLower(composite.GoName) + ext,
+			SortName: composite.GoName,
+			Data:     composite,
+		})
+	}
+	// emit range types
+	for _, r := range schema.Ranges {
+		rng, err := convertRange(ctx, schema.Name, r)
+		if err != nil {
+			return err
+		}
+		emit(xo.Template{
+			Partial:  "range",
+			Dest:     dir + strings.ToLower(rng.GoName) + ext,
+			SortName: rng.GoName,
+			Data:     rng,
+		})
+	}
 	// build procs
 	overloadMap := make(map[string][]Proc)
 	// procOrder ensures procs are always emitted in alphabetic order for
@@ -504,7 +1052,7 @@ func emitSchema(ctx context.Context, schema xo.Schema, emit func(xo.Template)) e
 	var procOrder []string
 	for _, p := range schema.Procs {
 		var err error
-		if procOrder, err = convertProc(ctx, overloadMap, procOrder, p); err != nil {
+		if procOrder, err = convertProc(ctx, schema.Name, overloadMap, procOrder, p); err != nil {
 			return err
 		}
 	}
@@ -520,86 +1068,503 @@ func emitSchema(ctx context.Context, schema xo.Schema, emit func(xo.Template)) e
 			procs[i].Overloaded = len(procs) > 1
 		}
 		emit(xo.Template{
-			Dest:     prefix + strings.ToLower(name) + ext,
-			Partial:  "procs",
-			SortName: prefix + name,
-			Data:     procs,
+			Dest:     dir + prefix + strings.ToLower(name) + ext,
+			Partial:  "procs",
+			SortName: prefix + name,
+			Data:     procs,
+		})
+	}
+	// emit tables
+	for _, t := range append(append(schema.Tables, schema.Views...), schema.MatViews...) {
+		table, err := convertTable(ctx, schema.Name, t)
+		if err != nil {
+			return err
+		}
+		emit(xo.Template{
+			Dest:     dir + strings.ToLower(table.GoName) + ext,
+			Partial:  "typedef",
+			SortType: table.Type,
+			SortName: table.GoName,
+			Data:     table,
+		})
+		// emit bulk funcs
+		if Bulk(ctx) && t.Type == "table" && len(table.PrimaryKeys) != 0 {
+			bulks := []BulkFunc{{
+				GoName: "InsertMany" + inflector.Pluralize(table.GoName),
+				Table:  table,
+			}}
+			if len(table.Fields) != len(table.PrimaryKeys) {
+				bulks = append(bulks, BulkFunc{
+					GoName: "UpsertMany" + inflector.Pluralize(table.GoName),
+					Upsert: true,
+					Table:  table,
+				})
+			}
+			for _, bulk := range bulks {
+				// skip tables with only generated fields
+				if len(bulkFields(bulk)) == 0 {
+					continue
+				}
+				emit(xo.Template{
+					Dest:     dir + strings.ToLower(table.GoName) + ext,
+					Partial:  "bulk",
+					SortType: table.Type,
+					SortName: bulk.GoName,
+					Data:     bulk,
+				})
+			}
+		}
+		// emit page func
+		if Paginate(ctx) && t.Type == "table" {
+			page, ok, err := convertPage(ctx, table, t)
+			switch {
+			case err != nil:
+				return err
+			case ok:
+				emit(xo.Template{
+					Dest:     dir + strings.ToLower(table.GoName) + ext,
+					Partial:  "page",
+					SortType: table.Type,
+					SortName: page.GoName,
+					Data:     page,
+				})
+			}
+		}
+		// emit filter func
+		if Filter(ctx) && t.Type == "table" {
+			if filter, ok := convertFilter(table, t); ok {
+				emit(xo.Template{
+					Dest:     dir + strings.ToLower(table.GoName) + ext,
+					Partial:  "filter",
+					SortType: table.Type,
+					SortName: filter.GoName,
+					Data:     filter,
+				})
+			}
+		}
+		// emit refresh funcs
+		if t.Type == "matview" {
+			for _, refresh := range convertRefresh(table, t) {
+				emit(xo.Template{
+					Dest:     dir + strings.ToLower(table.GoName) + ext,
+					Partial:  "refresh",
+					SortType: table.Type,
+					SortName: refresh.GoName,
+					Data:     refresh,
+				})
+			}
+		}
+		// emit indexes
+		for _, i := range t.Indexes {
+			// expression indexes cannot be looked up by column equality
+			if len(i.Expressions) != 0 || len(i.Fields) == 0 {
+				continue
+			}
+			index, err := convertIndex(ctx, table, i)
+			if err != nil {
+				return err
+			}
+			indexes := []Index{index}
+			// variant that does not filter soft deleted rows, unless the
+			// partial index predicate already filters them
+			if table.SoftDelete != nil && !index.SoftDeletePredicate {
+				v := index
+				v.Func += "IncludeDeleted"
+				v.IncludeDeleted = true
+				indexes = append(indexes, v)
+			}
+			// streaming variants
+			if Stream(ctx) && !index.IsUnique {
+				for _, v := range indexes[:len(indexes):len(indexes)] {
+					v.Func += "Each"
+					v.Stream = true
+					indexes = append(indexes, v)
+				}
+			}
+			for _, v := range indexes {
+				sortName := v.SQLName
+				if v.IncludeDeleted {
+					sortName += "_include_deleted"
+				}
+				if v.Stream {
+					sortName += "_each"
+				}
+				emit(xo.Template{
+					Dest:     dir + strings.ToLower(table.GoName) + ext,
+					Partial:  "index",
+					SortType: table.Type,
+					SortName: sortName,
+					Data:     v,
+				})
+			}
+		}
+		// emit fkeys
+		for _, fk := range t.ForeignKeys {
+			// in dir layout, the referenced type is in a different package
+			if dir != "" && fk.RefSchema != "" {
+				continue
+			}
+			fkey, err := convertFKey(ctx, table, fk)
+			if err != nil {
+				return err
+			}
+			emit(xo.Template{
+				Dest:     dir + strings.ToLower(table.GoName) + ext,
+				Partial:  "foreignkey",
+				SortType: table.Type,
+				SortName: fkey.SQLName,
+				Data:     fkey,
+			})
+		}
+	}
+	// emit row level security setting funcs
+	if RLS(ctx) {
+		for _, setting := range convertSettings(ctx, schema) {
+			emit(xo.Template{
+				Partial:  "setting",
+				Dest:     dir + "settings" + ext,
+				SortName: setting.GoName,
+				Data:     setting,
+			})
+		}
+	}
+	// emit factories
+	if xo.Factories(ctx) && len(schema.Tables) != 0 {
+		if err := emitFactories(ctx, schema, dir, emit); err != nil {
+			return err
+		}
+	}
+	// emit round trip tests
+	if xo.Tests(ctx) && len(schema.Tables) != 0 {
+		if err := emitTests(ctx, schema, dir, emit); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitFactories emits the factory funcs for the schema's tables.
+//
+// Factories create the rows referenced by not null foreign keys before
+// inserting a row, except for foreign keys that are part of a reference
+// cycle, which cannot be satisfied.
+func emitFactories(ctx context.Context, schema xo.Schema, dir string, emit func(xo.Template)) error {
+	// first value of each enum
+	enums := make(map[string]string)
+	for _, e := range schema.Enums {
+		if enum := convertEnum(ctx, schema.Name, e); len(enum.Values) != 0 {
+			enums[enum.GoName] = enum.GoName + enum.Values[0].GoName
+		}
+	}
+	// range types from their subtype
+	for _, r := range schema.Ranges {
+		rng, err := convertRange(ctx, schema.Name, r)
+		if err != nil {
+			return err
+		}
+		if v := fakeValue(Field{Type: "Range[" + rng.Type + "]"}, enums); v != "" {
+			enums[rng.GoName] = v
+		}
+	}
+	// tables that can be inserted
+	tables := make(map[string]xo.Table)
+	for _, t := range schema.Tables {
+		if len(t.PrimaryKeys) != 0 {
+			tables[t.Name] = t
+		}
+	}
+	// refs returns the not null foreign keys of a table to tables that can
+	// be inserted
+	refs := func(t xo.Table) []xo.ForeignKey {
+		var fks []xo.ForeignKey
+		for _, fk := range t.ForeignKeys {
+			if _, ok := tables[fk.RefTable]; !ok || fk.RefSchema != "" && fk.RefSchema != schema.Name {
+				continue
+			}
+			nullable := false
+			for _, z := range fk.Fields {
+				nullable = nullable || z.Type.Nullable
+			}
+			if !nullable {
+				fks = append(fks, fk)
+			}
+		}
+		return fks
+	}
+	// a factory cannot create the rows it refers to in a reference cycle of
+	// not null foreign keys, as the referenced factory would refer back
+	graph := xo.Schema{Name: schema.Name}
+	for _, t := range schema.Tables {
+		if _, ok := tables[t.Name]; ok {
+			graph.Tables = append(graph.Tables, xo.Table{Name: t.Name, ForeignKeys: refs(t)})
+		}
+	}
+	cycle := make(map[string]int)
+	for i, names := range graph.ReferenceCycles() {
+		for _, name := range names {
+			cycle[name] = i + 1
+		}
+	}
+	for _, t := range schema.Tables {
+		table, err := convertTable(ctx, schema.Name, t)
+		if err != nil {
+			return err
+		}
+		factory := FactoryFunc{
+			GoName: "Create" + table.GoName,
+			Table:  table,
+		}
+		// foreign keys
+		fkFields := make(map[string]bool)
+		for _, fk := range t.ForeignKeys {
+			for _, z := range fk.Fields {
+				fkFields[z.Name] = true
+			}
+		}
+		if len(t.PrimaryKeys) != 0 {
+			for _, fk := range refs(t) {
+				if n := cycle[t.Name]; n != 0 && cycle[fk.RefTable] == n {
+					continue
+				}
+				ref, err := convertFKey(ctx, table, fk)
+				if err != nil {
+					return err
+				}
+				factory.Refs = append(factory.Refs, ref)
+			}
+		}
+		// fake values
+		for i, z := range t.Columns {
+			if z.Type.Nullable || z.IsSequence || z.IsGenerated || fkFields[z.Name] {
+				continue
+			}
+			if v := fakeValue(table.Fields[i], enums); v != "" {
+				factory.Values = append(factory.Values, FactoryValue{
+					Field: table.Fields[i],
+					Value: v,
+				})
+				factory.Seq = factory.Seq || seqRE.MatchString(v)
+			}
+		}
+		emit(xo.Template{
+			Dest:     dir + "factory" + ext,
+			Partial:  "factory",
+			SortName: table.GoName,
+			Data:     factory,
 		})
 	}
-	// emit tables
-	for _, t := range append(schema.Tables, schema.Views...) {
-		table, err := convertTable(ctx, t)
+	return nil
+}
+
+// emitTests emits the round trip tests for the schema's tables.
+//
+// Tests are only generated for tables with a primary key, as rows are
+// inserted using the table's factory.
+func emitTests(ctx context.Context, schema xo.Schema, dir string, emit func(xo.Template)) error {
+	emit(xo.Template{
+		Dest:    dir + testFile,
+		Partial: "testdb",
+		Data:    schema.Name,
+	})
+	for _, t := range schema.Tables {
+		if len(t.PrimaryKeys) == 0 {
+			continue
+		}
+		table, err := convertTable(ctx, schema.Name, t)
 		if err != nil {
 			return err
 		}
-		emit(xo.Template{
-			Dest:     strings.ToLower(table.GoName) + ext,
-			Partial:  "typedef",
-			SortType: table.Type,
-			SortName: table.GoName,
-			Data:     table,
-		})
-		// emit indexes
+		test := RoundTrip{
+			GoName: "Test" + table.GoName + "RoundTrip",
+			Table:  table,
+		}
+		// get by primary key
 		for _, i := range t.Indexes {
-			index, err := convertIndex(ctx, table, i)
-			if err != nil {
-				return err
+			if !i.IsPrimary || len(i.Expressions) != 0 || len(i.Fields) == 0 {
+				continue
 			}
-			emit(xo.Template{
-				Dest:     strings.ToLower(table.GoName) + ext,
-				Partial:  "index",
-				SortType: table.Type,
-				SortName: index.SQLName,
-				Data:     index,
-			})
-		}
-		// emit fkeys
-		for _, fk := range t.ForeignKeys {
-			fkey, err := convertFKey(ctx, table, fk)
+			index, err := convertIndex(ctx, table, i)
 			if err != nil {
 				return err
 			}
-			emit(xo.Template{
-				Dest:     strings.ToLower(table.GoName) + ext,
-				Partial:  "foreignkey",
-				SortType: table.Type,
-				SortName: fkey.SQLName,
-				Data:     fkey,
-			})
+			test.Lookup = index
+			break
 		}
+		emit(xo.Template{
+			Dest:     dir + testFile,
+			Partial:  "roundtrip",
+			SortName: table.GoName,
+			Data:     test,
+		})
 	}
 	return nil
 }
 
+// testDriver returns the database/sql driver name and package used by the
+// round trip tests.
+func testDriver(driver, oracleType string) (string, string) {
+	switch driver {
+	case "postgres":
+		return "postgres", "github.com/lib/pq"
+	case "mysql":
+		return "mysql", "github.com/go-sql-driver/mysql"
+	case "sqlite3":
+		return "sqlite3", "github.com/mattn/go-sqlite3"
+	case "sqlserver":
+		return "sqlserver", "github.com/microsoft/go-mssqldb"
+	case "oracle":
+		if oracleType == "godror" {
+			return "godror", "github.com/godror/godror"
+		}
+		return "oracle", "github.com/sijms/go-ora/v2"
+	}
+	return driver, ""
+}
+
+// fakeValue returns the Go expression of a fake value for the field, using
+// the factory sequence n. Returns an empty string for unsupported types.
+func fakeValue(f Field, enums map[string]string) string {
+	// ranges from the fake value of the lower bound
+	if isRange(f.Type) {
+		lower := fakeValue(Field{SQLName: f.SQLName, Type: f.Type[len("Range[") : len(f.Type)-1]}, enums)
+		if lower == "" {
+			return ""
+		}
+		return fmt.Sprintf("%s{Lower: %s, LowerInclusive: true, UpperUnbounded: true, Valid: true}", f.Type, lower)
+	}
+	switch f.Type {
+	case "int", "int8", "int16", "int32", "int64",
+		"uint", "uint8", "uint16", "uint32", "uint64",
+		"float32", "float64":
+		if f.Type == "int" {
+			return "n"
+		}
+		return f.Type + "(n)"
+	case "bool":
+		return "n%2 == 0"
+	case "string":
+		return fmt.Sprintf("fmt.Sprintf(%q, n)", f.SQLName+"-%d")
+	case "[]byte":
+		return fmt.Sprintf("[]byte(fmt.Sprintf(%q, n))", f.SQLName+"-%d")
+	case "time.Time":
+		return "factoryTime(n)"
+	case "Time":
+		return "NewTime(factoryTime(n))"
+	case "Geometry":
+		var g string
+		switch f.Geometry {
+		case "geometry", "geography", "point":
+			g = "orb.Point{float64(n), float64(n)}"
+		case "linestring":
+			g = "orb.LineString{{0, 0}, {float64(n), float64(n)}}"
+		case "polygon":
+			g = "orb.Polygon{{{0, 0}, {float64(n), 0}, {float64(n), float64(n)}, {0, 0}}}"
+		default:
+			return ""
+		}
+		return fmt.Sprintf("Geometry{Geometry: %s, SRID: %d}", g, f.SRID)
+	}
+	return enums[f.Type]
+}
+
+// seqRE matches the use of the factory sequence n in a fake value. Enum
+// values do not use it.
+var seqRE = regexp.MustCompile(`\bn\b`)
+
+// schemaNames returns the output directory and Go name prefix for types
+// generated from the schema.
+//
+// In dir layout, each schema is written to a directory (and package) of the
+// same name. In flat layout, all schemas are written to the same package and
+// types from schemas other than the primary schema are prefixed with the
+// schema name.
+func schemaNames(ctx context.Context, schema string) (string, string) {
+	_, _, primary := xo.DriverDbSchema(ctx)
+	switch {
+	case SchemaLayout(ctx) == "dir":
+		return schema + "/", ""
+	case schema != "" && schema != primary:
+		return "", schema + "_"
+	}
+	return "", ""
+}
+
+// schemaPkg returns the package name for the schema in dir layout.
+func schemaPkg(schema string) string {
+	return strings.ToLower(snaker.ForceCamelIdentifier(schema))
+}
+
 // convertEnum converts a xo.Enum.
-func convertEnum(e xo.Enum) Enum {
+func convertEnum(ctx context.Context, schema string, e xo.Enum) Enum {
 	var vals []EnumValue
-	goName := camelExport(e.Name)
+	_, prefix := schemaNames(ctx, schema)
+	goName := camelExport(prefix + e.Name)
 	for _, v := range e.Values {
-		name := camelExport(strings.ToLower(v.Name))
+		alias := v.Alias
+		if alias == "" {
+			alias = xo.EnumAlias(v.Name)
+		}
+		name := camelExport(alias)
 		if strings.HasSuffix(name, goName) && goName != name {
 			name = strings.TrimSuffix(name, goName)
 		}
 		vals = append(vals, EnumValue{
 			GoName:     name,
 			SQLName:    v.Name,
+			Alias:      alias,
 			ConstValue: *v.ConstValue,
 		})
 	}
 	return Enum{
 		GoName:  goName,
 		SQLName: e.Name,
+		Schema:  schema,
 		Values:  vals,
+		Int:     IntEnums(ctx),
+	}
+}
+
+// convertComposite converts a xo.Composite.
+func convertComposite(ctx context.Context, schema string, c xo.Composite) (Composite, error) {
+	_, prefix := schemaNames(ctx, schema)
+	composite := Composite{
+		GoName:  camelExport(prefix + c.Name),
+		SQLName: c.Name,
+		Schema:  schema,
+	}
+	for _, z := range c.Fields {
+		f, err := convertField(ctx, camelExport, z)
+		if err != nil {
+			return Composite{}, err
+		}
+		composite.Fields = append(composite.Fields, f)
+	}
+	return composite, nil
+}
+
+// convertRange converts a xo.Range.
+func convertRange(ctx context.Context, schema string, r xo.Range) (Range, error) {
+	_, prefix := schemaNames(ctx, schema)
+	typ, _, err := goType(ctx, r.SubType)
+	if err != nil {
+		return Range{}, err
 	}
+	return Range{
+		GoName:  camelExport(prefix + r.Name),
+		SQLName: r.Name,
+		Schema:  schema,
+		Type:    typ,
+	}, nil
 }
 
 // convertProc converts a xo.Proc.
-func convertProc(ctx context.Context, overloadMap map[string][]Proc, order []string, p xo.Proc) ([]string, error) {
-	_, _, schema := xo.DriverDbSchema(ctx)
+func convertProc(ctx context.Context, schema string, overloadMap map[string][]Proc, order []string, p xo.Proc) ([]string, error) {
+	_, prefix := schemaNames(ctx, schema)
 	proc := Proc{
 		Type:      p.Type,
-		GoName:    camelExport(p.Name),
+		GoName:    camelExport(prefix + p.Name),
 		SQLName:   p.Name,
+		Schema:    schema,
 		Signature: fmt.Sprintf("%s.%s", schema, p.Name),
 		Void:      p.Void,
 	}
@@ -644,10 +1609,13 @@ func convertProc(ctx context.Context, overloadMap map[string][]Proc, order []str
 }
 
 // convertTable converts a xo.Table to a Table.
-func convertTable(ctx context.Context, t xo.Table) (Table, error) {
+func convertTable(ctx context.Context, schema string, t xo.Table) (Table, error) {
+	_, prefix := schemaNames(ctx, schema)
+	naming := xo.Naming(ctx)
 	var cols, pkCols []Field
-	for _, z := range t.Columns {
-		f, err := convertField(ctx, camelExport, z)
+	softDeleteIdx, versionIdx, createdIdx, updatedIdx, sequenceIdx := -1, -1, -1, -1, -1
+	for i, z := range t.Columns {
+		f, err := convertField(ctx, columnName(ctx, t.Name), z)
 		if err != nil {
 			return Table{}, err
 		}
@@ -655,40 +1623,250 @@ func convertTable(ctx context.Context, t xo.Table) (Table, error) {
 		if z.IsPrimary {
 			pkCols = append(pkCols, f)
 		}
+		if name := SoftDelete(ctx); name != "" && z.Name == name && !z.IsPrimary {
+			softDeleteIdx = i
+		}
+		// version must be incremented in the generated code
+		if name := Version(ctx); name != "" && z.Name == name && !z.IsPrimary && !z.IsGenerated {
+			if z.Type.Nullable || !isIntType(f.Type) {
+				return Table{}, fmt.Errorf("version column %s.%s must be a non-nullable integer, got: %s", t.Name, z.Name, f.Type)
+			}
+			versionIdx = i
+		}
+		// audit columns must be set in the generated code
+		if name := Created(ctx); name != "" && z.Name == name && !z.IsPrimary && !z.IsGenerated && isAuditType(f.Type) {
+			createdIdx = i
+		}
+		if name := Updated(ctx); name != "" && z.Name == name && !z.IsPrimary && !z.IsGenerated && isAuditType(f.Type) {
+			updatedIdx = i
+		}
+		// prefer a primary key sequence as the field returned on insert
+		if z.IsSequence && (sequenceIdx == -1 || (z.IsPrimary && !t.Columns[sequenceIdx].IsPrimary)) {
+			sequenceIdx = i
+		}
+	}
+	// the fields are referenced by index, as the interpreter reuses the
+	// loop's variables
+	var softDelete, version, created, updated, sequence *Field
+	if softDeleteIdx != -1 {
+		softDelete = &cols[softDeleteIdx]
+	}
+	if versionIdx != -1 {
+		version = &cols[versionIdx]
+	}
+	if createdIdx != -1 {
+		created = &cols[createdIdx]
+	}
+	if updatedIdx != -1 {
+		updated = &cols[updatedIdx]
+	}
+	var insertTriggers []string
+	for _, trigger := range t.Triggers {
+		if trigger.Timing == "BEFORE" && strings.Contains(trigger.Event, "INSERT") {
+			insertTriggers = append(insertTriggers, trigger.Name)
+		}
+	}
+	manual := t.Manual
+	if sequenceIdx != -1 {
+		sequence = &cols[sequenceIdx]
+	} else {
+		// the sequence column may have been excluded
+		manual = true
 	}
 	return Table{
-		GoName:      camelExport(singularize(t.Name)),
-		SQLName:     t.Name,
-		Fields:      cols,
-		PrimaryKeys: pkCols,
-		Manual:      t.Manual,
+		GoName:         camelExport(prefix + naming.Type(t.Name)),
+		SQLName:        t.Name,
+		Schema:         schema,
+		Fields:         cols,
+		PrimaryKeys:    pkCols,
+		SoftDelete:     softDelete,
+		Version:        version,
+		Created:        created,
+		Updated:        updated,
+		Sequence:       sequence,
+		Manual:         manual,
+		PartitionOf:    t.PartitionOf,
+		InsertTriggers: insertTriggers,
 	}, nil
 }
 
 func convertIndex(ctx context.Context, t Table, i xo.Index) (Index, error) {
+	_, prefix := schemaNames(ctx, t.Schema)
 	var fields []Field
 	for _, z := range i.Fields {
-		f, err := convertField(ctx, camelExport, z)
+		f, err := convertField(ctx, columnName(ctx, t.SQLName), z)
 		if err != nil {
 			return Index{}, err
 		}
 		fields = append(fields, f)
 	}
 	return Index{
-		SQLName:   i.Name,
-		Func:      camelExport(i.Func),
-		Table:     t,
-		Fields:    fields,
-		IsUnique:  i.IsUnique,
-		IsPrimary: i.IsPrimary,
+		SQLName:             i.Name,
+		Func:                camelExport(prefix + i.Func),
+		Table:               t,
+		Fields:              fields,
+		Predicate:           i.Predicate,
+		SoftDeletePredicate: t.SoftDelete != nil && predicateRefs(i.Predicate, t.SoftDelete.SQLName),
+		IsUnique:            i.IsUnique,
+		IsPrimary:           i.IsPrimary,
 	}, nil
 }
 
+// predicateRefs returns true when the partial index predicate refers to the
+// column.
+func predicateRefs(predicate, column string) bool {
+	for _, s := range strings.FieldsFunc(predicate, func(r rune) bool {
+		return r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.EqualFold(s, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertPage builds the keyset pagination func for a table, ordering by the
+// primary key or, when the table has no primary key, the first unique index
+// on non-nullable columns. Returns false when the table has no suitable key.
+func convertPage(ctx context.Context, t Table, table xo.Table) (PageFunc, bool, error) {
+	page := PageFunc{
+		GoName: "List" + inflector.Pluralize(t.GoName),
+		Cursor: t.GoName + "Cursor",
+		Table:  t,
+		Fields: t.PrimaryKeys,
+	}
+	if len(page.Fields) != 0 {
+		return page, true, nil
+	}
+	for _, i := range table.Indexes {
+		if !i.IsUnique || len(i.Expressions) != 0 || len(i.Fields) == 0 || i.Predicate != "" {
+			continue
+		}
+		nullable := false
+		for _, z := range i.Fields {
+			nullable = nullable || z.Type.Nullable
+		}
+		if nullable {
+			continue
+		}
+		index, err := convertIndex(ctx, t, i)
+		if err != nil {
+			return PageFunc{}, false, err
+		}
+		page.Fields = index.Fields
+		return page, true, nil
+	}
+	return PageFunc{}, false, nil
+}
+
+// convertRefresh builds the refresh funcs for a materialized view. A func
+// refreshing the view concurrently is included when the view has a unique
+// index on its columns, as required by postgres.
+func convertRefresh(t Table, table xo.Table) []RefreshFunc {
+	refreshes := []RefreshFunc{{
+		GoName: "Refresh" + t.GoName,
+		Table:  t,
+	}}
+	for _, i := range table.Indexes {
+		if i.IsUnique && len(i.Expressions) == 0 && len(i.Fields) != 0 && i.Predicate == "" {
+			return append(refreshes, RefreshFunc{
+				GoName:       "Refresh" + t.GoName + "Concurrently",
+				Table:        t,
+				Concurrently: true,
+			})
+		}
+	}
+	return refreshes
+}
+
+// convertSettings builds the funcs setting the settings read by the schema's
+// row level security policies.
+func convertSettings(ctx context.Context, schema xo.Schema) []SettingFunc {
+	_, prefix := schemaNames(ctx, schema.Name)
+	var settings []SettingFunc
+	idx := make(map[string]int)
+	for _, t := range schema.Tables {
+		for _, p := range t.Policies {
+			for _, name := range p.Settings {
+				i, ok := idx[name]
+				if !ok {
+					i = len(settings)
+					idx[name] = i
+					settings = append(settings, SettingFunc{
+						GoName: camelExport(prefix + "set_" + settingNameRE.ReplaceAllString(name, "_")),
+						Name:   name,
+					})
+				}
+				if n := len(settings[i].Tables); n == 0 || settings[i].Tables[n-1] != t.Name {
+					settings[i].Tables = append(settings[i].Tables, t.Name)
+				}
+			}
+		}
+	}
+	return settings
+}
+
+// settingNameRE matches the characters of a setting name that are not valid
+// in an identifier.
+var settingNameRE = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// convertFilter builds the filter builder func for a table, filtering on the
+// table's primary key and indexed columns. Returns false when the table has
+// no such columns.
+func convertFilter(t Table, table xo.Table) (FilterFunc, bool) {
+	indexed := make(map[string]bool)
+	for _, z := range table.PrimaryKeys {
+		indexed[z.Name] = true
+	}
+	for _, i := range table.Indexes {
+		for _, z := range i.Fields {
+			indexed[z.Name] = true
+		}
+	}
+	nullable := make(map[string]bool)
+	for _, z := range table.Columns {
+		nullable[z.Name] = z.Type.Nullable
+	}
+	var fields []FilterField
+	for _, z := range t.Fields {
+		if indexed[z.SQLName] {
+			fields = append(fields, FilterField{
+				Field:    z,
+				Nullable: nullable[z.SQLName],
+			})
+		}
+	}
+	if len(fields) == 0 {
+		return FilterFunc{}, false
+	}
+	return FilterFunc{
+		GoName: "Find" + inflector.Pluralize(t.GoName),
+		Filter: t.GoName + "Filter",
+		Table:  t,
+		Fields: fields,
+		Ops:    filterOps,
+	}, true
+}
+
+// filterOps are the comparison filters generated for each filter field.
+var filterOps = []FilterOp{
+	{"Eq", "="},
+	{"Lt", "<"},
+	{"Lte", "<="},
+	{"Gt", ">"},
+	{"Gte", ">="},
+}
+
 func convertFKey(ctx context.Context, t Table, fk xo.ForeignKey) (ForeignKey, error) {
+	refSchema := t.Schema
+	if fk.RefSchema != "" {
+		refSchema = fk.RefSchema
+	}
+	_, prefix := schemaNames(ctx, refSchema)
 	var fields, refFields []Field
 	// convert fields
 	for _, f := range fk.Fields {
-		field, err := convertField(ctx, camelExport, f)
+		field, err := convertField(ctx, columnName(ctx, t.SQLName), f)
 		if err != nil {
 			return ForeignKey{}, err
 		}
@@ -696,20 +1874,21 @@ func convertFKey(ctx context.Context, t Table, fk xo.ForeignKey) (ForeignKey, er
 	}
 	// convert ref fields
 	for _, f := range fk.RefFields {
-		refField, err := convertField(ctx, camelExport, f)
+		refField, err := convertField(ctx, columnName(ctx, fk.RefTable), f)
 		if err != nil {
 			return ForeignKey{}, err
 		}
 		refFields = append(refFields, refField)
 	}
 	return ForeignKey{
-		GoName:    camelExport(fk.Func),
+		GoName:    camelExport(prefix + fk.Func),
 		SQLName:   fk.Name,
 		Table:     t,
 		Fields:    fields,
-		RefTable:  camelExport(singularize(fk.RefTable)),
+		RefSchema: refSchema,
+		RefTable:  camelExport(prefix + xo.Naming(ctx).Type(fk.RefTable)),
 		RefFields: refFields,
-		RefFunc:   camelExport(fk.RefFunc),
+		RefFunc:   camelExport(prefix + fk.RefFunc),
 	}, nil
 }
 
@@ -734,18 +1913,49 @@ func overloadedName(sqlTypes []string, proc Proc) string {
 	return fmt.Sprintf("%sBy%sAnd%s", proc.GoName, front, last)
 }
 
+// columnName returns a transform func for the names of the table's columns,
+// applying renames from the naming convention.
+func columnName(ctx context.Context, table string) transformFunc {
+	naming := xo.Naming(ctx)
+	return func(names ...string) string {
+		return camelExport(naming.Column(table, strings.Join(names, "_")))
+	}
+}
+
 func convertField(ctx context.Context, tf transformFunc, f xo.Field) (Field, error) {
 	typ, zero, err := goType(ctx, f.Type)
 	if err != nil {
 		return Field{}, err
 	}
+	// spatial subtype and srid (the type name for mysql)
+	var geometry string
+	if typ == "Geometry" {
+		geometry = f.Type.Geometry
+		if geometry == "" {
+			geometry = f.Type.Type
+		}
+	}
+	// json column with a concrete type
+	switch {
+	case f.JSONType != "" && f.Type.Nullable:
+		typ, zero = "*"+f.JSONType, "nil"
+	case f.JSONType != "":
+		typ, zero = f.JSONType, f.JSONType+"{}"
+	}
 	return Field{
-		Type:       typ,
-		GoName:     tf(f.Name),
-		SQLName:    f.Name,
-		Zero:       zero,
-		IsPrimary:  f.IsPrimary,
-		IsSequence: f.IsSequence,
+		Type:        typ,
+		GoName:      tf(f.Name),
+		SQLName:     f.Name,
+		Zero:        zero,
+		IsPrimary:   f.IsPrimary,
+		IsSequence:  f.IsSequence,
+		IsIdentity:  f.IsIdentity,
+		IsGenerated: f.IsGenerated,
+		Geometry:    geometry,
+		SRID:        f.Type.SRID,
+		Prec:        f.Type.Prec,
+		Scale:       f.Type.Scale,
+		Sensitive:   f.Sensitive,
 	}, nil
 }
 
@@ -758,6 +1968,10 @@ func goType(ctx context.Context, typ xo.Type) (string, string, error) {
 	case "oracle":
 		f = loader.OracleGoType
 	case "postgres":
+		if Pgx(ctx) {
+			f = loader.PgxPostgresGoType
+			break
+		}
 		switch mode := ArrayMode(ctx); mode {
 		case "stdlib":
 			f = loader.StdlibPostgresGoType
@@ -792,12 +2006,79 @@ func camelExport(names ...string) string {
 
 const ext = ".xo.go"
 
+// querierFile is the name of the file containing the Querier interface.
+const querierFile = "querier.xo.go"
+
+// prepareFile is the name of the file containing the prepared statements.
+const prepareFile = "prepare.xo.go"
+
+// spatialFile is the name of the file containing the Geometry type.
+const spatialFile = "spatial.xo.go"
+
+// pgtypesFile is the name of the file containing the Range type and the text
+// encoding of composite and range values.
+const pgtypesFile = "pgtypes.xo.go"
+
+// hasColumnType determines if the tables or views of the schema's package
+// have columns whose Go type matches. In flat layout, all schemas are in the
+// same package.
+func hasColumnType(ctx context.Context, mode string, set *xo.Set, pkg string, match func(string) bool) bool {
+	if mode != "schema" {
+		return false
+	}
+	for _, schema := range set.Schemas {
+		if pkg != "" && schema.Name != pkg {
+			continue
+		}
+		for _, tables := range [][]xo.Table{schema.Tables, schema.Views, schema.MatViews} {
+			for _, table := range tables {
+				for _, col := range table.Columns {
+					if typ, _, err := goType(ctx, col.Type); err == nil && match(typ) {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
+
+// hasPgTypes determines if the schema's package has composite or range types,
+// or columns of a built-in range type.
+func hasPgTypes(ctx context.Context, mode string, set *xo.Set, pkg string) bool {
+	if mode != "schema" {
+		return false
+	}
+	for _, schema := range set.Schemas {
+		if (pkg == "" || schema.Name == pkg) && (len(schema.Composites) != 0 || len(schema.Ranges) != 0) {
+			return true
+		}
+	}
+	return hasColumnType(ctx, mode, set, pkg, isRange)
+}
+
+// isGeometry determines if typ is the generated Geometry type.
+func isGeometry(typ string) bool {
+	return typ == "Geometry"
+}
+
+// isRange determines if typ is the generated Range type.
+func isRange(typ string) bool {
+	return strings.HasPrefix(typ, "Range[")
+}
+
+// testFile is the name of the file containing the round trip tests.
+const testFile = "roundtrip.xo_test.go"
+
 // Funcs is a set of template funcs.
 type Funcs struct {
 	driver     string
 	schema     string
+	tenant     string
 	nth        func(int) string
 	first      bool
+	firsts     map[string]string
+	seen       map[string]bool
 	pkg        string
 	tags       []string
 	imports    []string
@@ -810,11 +2091,21 @@ type Funcs struct {
 	context    string
 	inject     string
 	oracleType string
+	bulk       bool
+	paginate   bool
+	filter     bool
+	factories  bool
+	repository bool
+	pgx        bool
+	hooks      bool
+	otel       bool
 	// knownTypes is the collection of known Go types.
 	knownTypes map[string]bool
 	// shorts is the collection of Go style short names for types, mainly
 	// used for use with declaring a func receiver on a type.
 	shorts map[string]string
+	// mu guards seen and shorts, as templates may be executed in parallel.
+	mu sync.Mutex
 }
 
 // NewFuncs creates custom template funcs for the context.
@@ -841,8 +2132,11 @@ func NewFuncs(ctx context.Context) (template.FuncMap, error) {
 	}
 	funcs := &Funcs{
 		first:      first,
+		firsts:     Firsts(ctx),
+		seen:       make(map[string]bool),
 		driver:     driver,
 		schema:     schema,
+		tenant:     xo.Tenant(ctx),
 		nth:        nth,
 		pkg:        Pkg(ctx),
 		tags:       Tags(ctx),
@@ -856,9 +2150,19 @@ func NewFuncs(ctx context.Context) (template.FuncMap, error) {
 		context:    Context(ctx),
 		inject:     inject,
 		oracleType: OracleType(ctx),
+		bulk:       Bulk(ctx),
+		paginate:   Paginate(ctx),
+		filter:     Filter(ctx),
+		factories:  xo.Factories(ctx),
+		repository: Repository(ctx) || Querier(ctx),
+		pgx:        Pgx(ctx),
+		hooks:      Hooks(ctx),
+		otel:       Otel(ctx),
 		knownTypes: KnownTypes(ctx),
 		shorts:     Shorts(ctx),
 	}
+	// add funcs once, as the field tag template may be executed in parallel
+	funcs.fieldtag = funcs.fieldtag.Funcs(funcs.FuncMap())
 	return funcs.FuncMap(), nil
 }
 
@@ -869,6 +2173,7 @@ func (f *Funcs) FuncMap() template.FuncMap {
 		"first":   f.firstfn,
 		"driver":  f.driverfn,
 		"schema":  f.schemafn,
+		"qualify": f.qualify,
 		"pkg":     f.pkgfn,
 		"tags":    f.tagsfn,
 		"imports": f.importsfn,
@@ -877,6 +2182,10 @@ func (f *Funcs) FuncMap() template.FuncMap {
 		"context":         f.contextfn,
 		"context_both":    f.context_both,
 		"context_disable": f.context_disable,
+		"pgx":             f.pgxfn,
+		"tenant":          f.tenantfn,
+		"hooks":           f.hooksfn,
+		"otel":            f.otelfn,
 		// func and query
 		"func_name_context":   f.func_name_context,
 		"func_name":           f.func_name_none,
@@ -894,27 +2203,68 @@ func (f *Funcs) FuncMap() template.FuncMap {
 		"logf":                f.logf,
 		"logf_pkeys":          f.logf_pkeys,
 		"logf_update":         f.logf_update,
+		"hook":                f.hook,
+		"audit":               f.audit,
 		// type
-		"names":        f.names,
-		"names_all":    f.names_all,
-		"names_ignore": f.names_ignore,
-		"params":       f.params,
-		"zero":         f.zero,
-		"type":         f.typefn,
-		"field":        f.field,
-		"short":        f.short,
+		"names":           f.names,
+		"names_all":       f.names_all,
+		"names_ignore":    f.names_ignore,
+		"sequence_fields": sequenceFields,
+		"params":          f.params,
+		"zero":            f.zero,
+		"type":            f.typefn,
+		"field":           f.field,
+		"short":           f.short,
+		// redact funcs
+		"sensitive":       sensitiveFields,
+		"redacted_string": f.redacted_string,
+		"redacted_field":  f.redacted_field,
 		// sqlstr funcs
 		"querystr": f.querystr,
 		"sqlstr":   f.sqlstr,
+		// bulk funcs
+		"bulk":        f.bulkfn,
+		"bulk_fields": bulkFields,
+		"bulk_param":  f.bulk_param,
+		"bulkstr":     f.bulkstr,
+		// repo funcs
+		"repo":       f.repofn,
+		"repo_recv":  f.repo_recv,
+		"repository": f.repositoryfn,
+		// page funcs
+		"paginate":  f.paginatefn,
+		"page_keys": f.page_keys,
+		"pagestr":   f.pagestr,
+		// filter funcs
+		"filter":       f.filterfn,
+		"filter_col":   f.filter_col,
+		"filter_where": f.filter_where,
+		"filterstr":    f.filterstr,
+		// factory funcs
+		"factories":     f.factoriesfn,
+		"factory_unset": f.factory_unset,
+		"test_driver":   f.test_driver,
+		"test_import":   f.test_import,
 		// helpers
 		"check_name": checkName,
 		"eval":       eval,
 	}
 }
 
-func (f *Funcs) firstfn() bool {
-	if f.first {
-		f.first = false
+// firstfn returns true for the first file of the package (when the package
+// file is enabled). When file is not provided, returns true the first time it
+// is called for the package.
+func (f *Funcs) firstfn(pkg string, file ...string) bool {
+	switch {
+	case !f.first:
+		return false
+	case len(file) != 0:
+		return f.firsts[pkg] == file[0]
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if !f.seen[pkg] {
+		f.seen[pkg] = true
 		return true
 	}
 	return false
@@ -932,7 +2282,15 @@ func (f *Funcs) driverfn(drivers ...string) bool {
 
 // schemafn takes a series of names and joins them with the schema name.
 func (f *Funcs) schemafn(names ...string) string {
-	s := f.schema
+	return f.qualify(f.schema, names...)
+}
+
+// qualify takes a schema and a series of names and joins them, using the
+// schema name when the schema is empty.
+func (f *Funcs) qualify(s string, names ...string) string {
+	if s == "" {
+		s = f.schema
+	}
 	// escape table names
 	if f.escTable {
 		for i, name := range names {
@@ -944,7 +2302,7 @@ func (f *Funcs) schemafn(names ...string) string {
 	case s == "" && n == "":
 		return ""
 	case f.driver == "sqlite3" && n == "":
-		return f.schema
+		return s
 	case f.driver == "sqlite3":
 		return n
 	case s != "" && n != "":
@@ -956,8 +2314,28 @@ func (f *Funcs) schemafn(names ...string) string {
 	return s + n
 }
 
-// pkgfn returns the package name.
-func (f *Funcs) pkgfn() string {
+// qualifySQL is qualify for names in SQL statements, using the tenant
+// placeholder in place of the canonical tenant schema.
+func (f *Funcs) qualifySQL(s string, names ...string) string {
+	if s == "" {
+		s = f.schema
+	}
+	if f.tenant != "" && s == f.tenant {
+		s = tenantPlaceholder
+	}
+	return f.qualify(s, names...)
+}
+
+// tenantPlaceholder is the placeholder for the tenant schema in the generated
+// SQL statements, replaced by the generated TenantDB.
+const tenantPlaceholder = "{tenant}"
+
+// pkgfn returns the package name, or the package name for the schema when
+// using dir layout.
+func (f *Funcs) pkgfn(schema ...string) string {
+	if len(schema) != 0 && schema[0] != "" {
+		return schemaPkg(schema[0])
+	}
 	return f.pkg
 }
 
@@ -997,6 +2375,26 @@ func (f *Funcs) context_disable() bool {
 	return f.context == "disable"
 }
 
+// pgxfn returns true when pgx mode is enabled.
+func (f *Funcs) pgxfn() bool {
+	return f.pgx
+}
+
+// tenantfn returns true when generating for tenants.
+func (f *Funcs) tenantfn() bool {
+	return f.tenant != ""
+}
+
+// hooksfn returns true when QueryHook instrumentation is enabled.
+func (f *Funcs) hooksfn() bool {
+	return f.hooks
+}
+
+// otelfn returns true when the OpenTelemetry QueryHook is enabled.
+func (f *Funcs) otelfn() bool {
+	return f.otel
+}
+
 // injectfn returns the injected content provided from args.
 func (f *Funcs) injectfn() string {
 	return f.inject
@@ -1021,6 +2419,18 @@ func (f *Funcs) func_name_none(v interface{}) string {
 		return n
 	case Index:
 		return x.Func
+	case BulkFunc:
+		return x.GoName
+	case PageFunc:
+		return x.GoName
+	case FilterFunc:
+		return x.GoName
+	case FactoryFunc:
+		return x.GoName
+	case RefreshFunc:
+		return x.GoName
+	case SettingFunc:
+		return x.GoName
 	}
 	return fmt.Sprintf("[[ UNSUPPORTED TYPE 1: %T ]]", v)
 }
@@ -1044,17 +2454,41 @@ func (f *Funcs) func_name_context(v interface{}) string {
 		return nameContext(f.context_both(), n)
 	case Index:
 		return nameContext(f.context_both(), x.Func)
+	case BulkFunc:
+		return nameContext(f.context_both(), x.GoName)
+	case PageFunc:
+		return nameContext(f.context_both(), x.GoName)
+	case FilterFunc:
+		return nameContext(f.context_both(), x.GoName)
+	case FactoryFunc:
+		return nameContext(f.context_both(), x.GoName)
+	case RefreshFunc:
+		return nameContext(f.context_both(), x.GoName)
+	case SettingFunc:
+		return nameContext(f.context_both(), x.GoName)
 	}
 	return fmt.Sprintf("[[ UNSUPPORTED TYPE 2: %T ]]", v)
 }
 
 // funcfn builds a func definition.
 func (f *Funcs) funcfn(name string, context bool, v interface{}) string {
-	var p, r []string
+	var p []string
 	if context {
 		p = append(p, "ctx context.Context")
 	}
 	p = append(p, "db DB")
+	params, r, ok := f.signature(v)
+	if !ok {
+		return fmt.Sprintf("[[ UNSUPPORTED TYPE 3: %T ]]", v)
+	}
+	p = append(p, params...)
+	return fmt.Sprintf("func %s(%s) (%s)", name, strings.Join(p, ", "), strings.Join(r, ", "))
+}
+
+// signature returns the params (excluding the context and db) and returns of
+// the func for v.
+func (f *Funcs) signature(v interface{}) ([]string, []string, bool) {
+	var p, r []string
 	switch x := v.(type) {
 	case Query:
 		// params
@@ -1063,20 +2497,26 @@ func (f *Funcs) funcfn(name string, context bool, v interface{}) string {
 		}
 		// returns
 		switch {
+		case x.Stream: // results are passed to fn
+			p = append(p, "fn func(*"+f.typefn(x.Type.GoName)+") error")
+		case x.Exec && f.pgx:
+			r = append(r, "pgconn.CommandTag")
 		case x.Exec:
 			r = append(r, "sql.Result")
 		case x.Flat:
 			for _, z := range x.Type.Fields {
 				r = append(r, f.typefn(z.Type))
 			}
-		case x.One:
+		case x.One || len(x.Sets) != 0:
 			r = append(r, "*"+x.Type.GoName)
 		default:
 			r = append(r, "[]*"+x.Type.GoName)
 		}
 	case Proc:
 		// params
-		p = append(p, f.params(x.Params, true))
+		for _, z := range x.Params {
+			p = append(p, f.param(z, true))
+		}
 		// returns
 		if !x.Void {
 			for _, ret := range x.Returns {
@@ -1085,18 +2525,48 @@ func (f *Funcs) funcfn(name string, context bool, v interface{}) string {
 		}
 	case Index:
 		// params
-		p = append(p, f.params(x.Fields, true))
+		for _, z := range x.Fields {
+			p = append(p, f.param(z, true))
+		}
 		// returns
 		rt := "*" + x.Table.GoName
-		if !x.IsUnique {
-			rt = "[]" + rt
+		switch {
+		case x.Stream: // results are passed to fn
+			p = append(p, "fn func("+rt+") error")
+		case !x.IsUnique:
+			r = append(r, "[]"+rt)
+		default:
+			r = append(r, rt)
 		}
-		r = append(r, rt)
+	case BulkFunc:
+		// params
+		p = append(p, "rows ...*"+x.Table.GoName)
+	case PageFunc:
+		// params
+		p = append(p, "cursor "+x.Cursor, "limit int")
+		// returns
+		r = append(r, "[]*"+x.Table.GoName, x.Cursor)
+	case FilterFunc:
+		// params
+		p = append(p, "filters ..."+x.Filter)
+		// returns
+		r = append(r, "[]*"+x.Table.GoName)
+	case FactoryFunc:
+		// params
+		p = append(p, "fns ...func(*"+x.Table.GoName+")")
+		// returns
+		r = append(r, "*"+x.Table.GoName)
+	case RefreshFunc:
+		// no params
+		return nil, []string{"error"}, true
+	case SettingFunc:
+		// params
+		p = append(p, "value string", "local bool")
 	default:
-		return fmt.Sprintf("[[ UNSUPPORTED TYPE 3: %T ]]", v)
+		return nil, nil, false
 	}
 	r = append(r, "error")
-	return fmt.Sprintf("func %s(%s) (%s)", name, strings.Join(p, ", "), strings.Join(r, ", "))
+	return p, r, true
 }
 
 // func_context generates a func signature for v with context determined by the
@@ -1105,9 +2575,65 @@ func (f *Funcs) func_context(v interface{}) string {
 	return f.funcfn(f.func_name_context(v), f.contextfn(), v)
 }
 
-// func_none genarates a func signature for v without context.
-func (f *Funcs) func_none(v interface{}) string {
-	return f.funcfn(f.func_name_none(v), false, v)
+// func_none genarates a func signature for v without context.
+func (f *Funcs) func_none(v interface{}) string {
+	return f.funcfn(f.func_name_none(v), false, v)
+}
+
+// repofn generates the Tx methods calling the func for v using the
+// transaction.
+func (f *Funcs) repofn(v interface{}) string {
+	p, r, ok := f.signature(v)
+	if !ok {
+		return fmt.Sprintf("[[ UNSUPPORTED TYPE 33: %T ]]", v)
+	}
+	// build args
+	var args []string
+	for _, z := range p {
+		name := z[:strings.Index(z, " ")]
+		if strings.HasPrefix(z[len(name)+1:], "...") {
+			name += "..."
+		}
+		args = append(args, name)
+	}
+	var methods []string
+	if f.contextfn() {
+		name := f.func_name_context(v)
+		call := fmt.Sprintf("%s(%s)", name, strings.Join(append([]string{"ctx", "tx"}, args...), ", "))
+		methods = append(methods, repoMethod(name, name, true, p, r, call))
+	}
+	if f.context_both() || f.context_disable() {
+		name := f.func_name_none(v)
+		call := fmt.Sprintf("%s(%s)", name, strings.Join(append([]string{"tx"}, args...), ", "))
+		methods = append(methods, repoMethod(name, name, false, p, r, call))
+	}
+	return strings.Join(methods, "\n\n")
+}
+
+// repo_recv generates the Tx methods calling the receiver func name of the
+// table using the transaction.
+func (f *Funcs) repo_recv(t Table, name string) string {
+	short := f.short(t)
+	p, r := []string{short + " *" + t.GoName}, []string{"error"}
+	var methods []string
+	if f.contextfn() {
+		recv := f.func_name_context(name)
+		call := fmt.Sprintf("%s.%s(ctx, tx)", short, recv)
+		methods = append(methods, repoMethod(nameContext(f.context_both(), name+t.GoName), t.GoName+"."+recv, true, p, r, call))
+	}
+	if f.context_both() || f.context_disable() {
+		call := fmt.Sprintf("%s.%s(tx)", short, name)
+		methods = append(methods, repoMethod(name+t.GoName, t.GoName+"."+name, false, p, r, call))
+	}
+	return strings.Join(methods, "\n\n")
+}
+
+// repoMethod builds a Tx method returning the result of call.
+func repoMethod(name, target string, context bool, p, r []string, call string) string {
+	if context {
+		p = append([]string{"ctx context.Context"}, p...)
+	}
+	return fmt.Sprintf("// %s calls %s using the transaction.\nfunc (tx *Tx) %s(%s) (%s) {\n\treturn %s\n}", name, target, name, strings.Join(p, ", "), strings.Join(r, ", "), call)
 }
 
 // recv builds a receiver func definition.
@@ -1179,12 +2705,26 @@ func (f *Funcs) foreign_key_none(v interface{}) string {
 	return fmt.Sprintf("%s(%s)", name, strings.Join(p, ", "))
 }
 
-// db generates a db.<name>Context(ctx, sqlstr, ...)
+// db generates a db.<name>Context(ctx, sqlstr, ...), or a db.<name>(ctx,
+// sqlstr, ...) in pgx mode.
+//
+// The Queue name generates a batch.Queue(sqlstr, ...) adding the query to a
+// pgx.Batch. With hooks, the QueryRow name generates a queryRow(ctx, db,
+// sqlstr, ...) calling the hook once the row is scanned, as a *sql.Row cannot
+// be wrapped.
 func (f *Funcs) db(name string, v ...interface{}) string {
+	switch {
+	case name == "Queue":
+		return fmt.Sprintf("batch.Queue(%s)", f.names("", append([]interface{}{"sqlstr"}, v...)...))
+	case name == "QueryRow" && f.hooks && !f.pgx && f.contextfn():
+		return fmt.Sprintf("queryRow(%s)", f.names("", append([]interface{}{"ctx", "db", "sqlstr"}, v...)...))
+	}
 	// params
 	var p []interface{}
 	if f.contextfn() {
-		name += "Context"
+		if !f.pgx {
+			name += "Context"
+		}
 		p = append(p, "ctx")
 	}
 	p = append(p, "sqlstr")
@@ -1204,12 +2744,10 @@ func (f *Funcs) db_prefix(name string, skip bool, vs ...interface{}) string {
 			params = append(params, x)
 		case Table:
 			prefix = f.short(x.GoName) + "."
-			// skip primary keys
-			if skip {
-				for _, field := range x.Fields {
-					if field.IsSequence {
-						ignore = append(ignore, field.GoName)
-					}
+			// skip generated fields, and sequences when skip is true
+			for _, field := range x.Fields {
+				if field.IsGenerated || (skip && field.IsSequence) {
+					ignore = append(ignore, field.GoName)
 				}
 			}
 			p := f.names_ignore(prefix, v, ignore...)
@@ -1232,10 +2770,8 @@ func (f *Funcs) db_update(name string, v interface{}) string {
 	switch x := v.(type) {
 	case Table:
 		prefix := f.short(x.GoName) + "."
-		for _, pk := range x.PrimaryKeys {
-			ignore = append(ignore, pk.GoName)
-		}
-		p = append(p, f.names_ignore(prefix, x, ignore...), f.names(prefix, x.PrimaryKeys))
+		ignore = generatedNames(x, createdFields(x, versionFields(x, x.PrimaryKeys...)...)...)
+		p = append(p, f.names_ignore(prefix, x, ignore...), f.names(prefix, versionFields(x, x.PrimaryKeys...)))
 	default:
 		return fmt.Sprintf("[[ UNSUPPORTED TYPE 9: %T ]]", v)
 	}
@@ -1273,7 +2809,7 @@ func (f *Funcs) logf_pkeys(v interface{}) string {
 	p := []string{"sqlstr"}
 	switch x := v.(type) {
 	case Table:
-		p = append(p, f.names(f.short(x.GoName)+".", x.PrimaryKeys))
+		p = append(p, f.lognames(f.short(x.GoName)+".", x.PrimaryKeys, nil))
 	}
 	return fmt.Sprintf("logf(%s)", strings.Join(p, ", "))
 }
@@ -1299,7 +2835,16 @@ func (f *Funcs) logf(v interface{}, ignore ...interface{}) string {
 	// add fields
 	switch x := v.(type) {
 	case Table:
-		p = append(p, f.names_ignore(f.short(x.GoName)+".", x, ignoreNames...))
+		ignoreNames = append(ignoreNames, generatedNames(x)...)
+		p = append(p, f.lognames(f.short(x.GoName)+".", x.Fields, ignoreNames))
+	case Index:
+		for _, field := range x.Fields {
+			if field.Sensitive {
+				p = append(p, redacted)
+			} else {
+				p = append(p, f.param(field, false))
+			}
+		}
 	default:
 		return fmt.Sprintf("[[ UNSUPPORTED TYPE 12: %T ]]", v)
 	}
@@ -1312,16 +2857,176 @@ func (f *Funcs) logf_update(v interface{}) string {
 	switch x := v.(type) {
 	case Table:
 		prefix := f.short(x.GoName) + "."
-		for _, pk := range x.PrimaryKeys {
-			ignore = append(ignore, pk.GoName)
-		}
-		p = append(p, f.names_ignore(prefix, x, ignore...), f.names(prefix, x.PrimaryKeys))
+		ignore = generatedNames(x, createdFields(x, versionFields(x, x.PrimaryKeys...)...)...)
+		p = append(p, f.lognames(prefix, x.Fields, ignore), f.lognames(prefix, versionFields(x, x.PrimaryKeys...), nil))
 	default:
 		return fmt.Sprintf("[[ UNSUPPORTED TYPE 13: %T ]]", v)
 	}
 	return fmt.Sprintf("logf(%s)", strings.Join(p, ", "))
 }
 
+// versionFields returns the fields followed by the table's version field, if
+// any.
+func versionFields(t Table, fields ...Field) []Field {
+	if t.Version == nil {
+		return fields
+	}
+	return append(append([]Field{}, fields...), *t.Version)
+}
+
+// createdFields returns the fields followed by the table's created field, if
+// any.
+func createdFields(t Table, fields ...Field) []Field {
+	if t.Created == nil {
+		return fields
+	}
+	return append(append([]Field{}, fields...), *t.Created)
+}
+
+// isCreated returns true when field is the table's created field, which is
+// not changed after insert.
+func isCreated(t Table, field Field) bool {
+	return t.Created != nil && field.SQLName == t.Created.SQLName
+}
+
+// isIntType returns true when the Go type is an integer type.
+func isIntType(typ string) bool {
+	switch typ {
+	case "int", "int8", "int16", "int32", "int64", "uint", "uint8", "uint16", "uint32", "uint64":
+		return true
+	}
+	return false
+}
+
+// isAuditType returns true when the Go type can be set by audit.
+func isAuditType(typ string) bool {
+	_, _, ok := auditValue(typ)
+	return ok
+}
+
+// auditValue returns the value expression (using now) and the unset
+// condition format for an audit field of the Go type.
+func auditValue(typ string) (string, string, bool) {
+	switch typ {
+	case "time.Time":
+		return "now", "%s.IsZero()", true
+	case "*time.Time":
+		return "&now", "%s == nil", true
+	case "sql.NullTime":
+		return "sql.NullTime{Time: now, Valid: true}", "!%s.Valid", true
+	case "pgtype.Timestamptz", "pgtype.Timestamp":
+		return typ + "{Time: now, Valid: true}", "!%s.Valid", true
+	case "Time":
+		return "NewTime(now)", "%s.Time().IsZero()", true
+	case "*Time":
+		return "&Time{time: now}", "%s == nil", true
+	}
+	return "", "", false
+}
+
+// audit generates the statements setting the table's audit fields to the
+// current time, for an insert, update or upsert. The created field is only
+// set when unset.
+func (f *Funcs) audit(v interface{}, op string) string {
+	var t Table
+	switch x := v.(type) {
+	case Table:
+		t = x
+	case BulkFunc:
+		t = x.Table
+	default:
+		return fmt.Sprintf("[[ UNSUPPORTED TYPE 37: %T ]]", v)
+	}
+	var lines []string
+	if z := t.Created; z != nil && op != "update" {
+		name := f.short(t) + "." + z.GoName
+		value, unset, _ := auditValue(z.Type)
+		lines = append(lines, "if "+fmt.Sprintf(unset, name)+" {", "\t"+name+" = "+value, "}")
+	}
+	if z := t.Updated; z != nil {
+		value, _, _ := auditValue(z.Type)
+		lines = append(lines, f.short(t)+"."+z.GoName+" = "+value)
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	lines = append([]string{"// set audit fields", "now := time.Now()"}, lines...)
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// hook generates a withQuery(ctx, ...) adding the name, table, and operation
+// of the query run by the func for v to the context, for use by the QueryHook
+// of a HookedDB. The name of a table's method (ie, Insert) is prefixed with
+// the type's name, and the operation of a custom query is its first keyword.
+func (f *Funcs) hook(v interface{}, name, op string) string {
+	if !f.hooks {
+		return ""
+	}
+	var table string
+	switch x := v.(type) {
+	case Table:
+		name, table = x.GoName+"."+name, x.SQLName
+		if op == "delete" && x.SoftDelete != nil {
+			op = "update"
+		}
+	case Index:
+		name, table = x.Func, x.Table.SQLName
+	case Proc:
+		name, table = x.GoName, x.SQLName
+		if x.Overloaded {
+			name = x.OverloadedName
+		}
+	case Query:
+		name = x.Name
+		if fields := strings.Fields(strings.Join(x.Query, " ")); len(fields) != 0 {
+			op = strings.ToLower(fields[0])
+		}
+	case BulkFunc:
+		name, table = x.GoName, x.Table.SQLName
+		if x.Upsert {
+			op = "upsert"
+		}
+	case PageFunc:
+		name, table = x.GoName, x.Table.SQLName
+	case FilterFunc:
+		name, table = x.GoName, x.Table.SQLName
+	case RefreshFunc:
+		name, table = x.GoName, x.Table.SQLName
+	case SettingFunc:
+		name = x.GoName
+	default:
+		return fmt.Sprintf("[[ UNSUPPORTED TYPE 38: %T ]]", v)
+	}
+	return fmt.Sprintf("ctx = withQuery(ctx, %q, %q, %q)\n\t", name, table, op)
+}
+
+// generatedNames returns the names of the table's generated fields, along with
+// the names of any additional fields.
+func generatedNames(t Table, fields ...Field) []string {
+	var names []string
+	for _, field := range t.Fields {
+		if field.IsGenerated {
+			names = append(names, field.GoName)
+		}
+	}
+	for _, field := range fields {
+		names = append(names, field.GoName)
+	}
+	return names
+}
+
+// sequenceFields returns the table's sequence fields, which are not set on
+// insert.
+func sequenceFields(t Table) []Field {
+	var fields []Field
+	for _, field := range t.Fields {
+		if field.IsSequence {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
 // names generates a list of names.
 func (f *Funcs) namesfn(all bool, prefix string, z ...interface{}) string {
 	var names []string
@@ -1357,6 +3062,27 @@ func (f *Funcs) namesfn(all bool, prefix string, z ...interface{}) string {
 	return strings.Join(names, ", ")
 }
 
+// lognames generates a list of names for logging, ignoring fields that match
+// the value in ignore, and redacting sensitive fields.
+func (f *Funcs) lognames(prefix string, fields []Field, ignore []string) string {
+	m := make(map[string]bool)
+	for _, n := range ignore {
+		m[n] = true
+	}
+	var names []string
+	for _, field := range fields {
+		switch {
+		case m[field.GoName]:
+			// ignored
+		case field.Sensitive:
+			names = append(names, redacted)
+		default:
+			names = append(names, prefix+checkName(field.GoName))
+		}
+	}
+	return strings.Join(names, ", ")
+}
+
 // names generates a list of names (excluding certain ones such as interpolated
 // names).
 func (f *Funcs) names(prefix string, z ...interface{}) string {
@@ -1441,6 +3167,10 @@ func (f *Funcs) sqlstr(typ string, v interface{}) string {
 		lines = f.sqlstr_upsert(v)
 	case "delete":
 		lines = f.sqlstr_delete(v)
+	case "refresh":
+		lines = f.sqlstr_refresh(v)
+	case "setting":
+		lines = f.sqlstr_setting(v)
 	case "proc":
 		lines = f.sqlstr_proc(v)
 	case "index":
@@ -1452,7 +3182,8 @@ func (f *Funcs) sqlstr(typ string, v interface{}) string {
 }
 
 // sqlstr_insert_base builds an INSERT query
-// If not all, sequence columns are skipped.
+// If not all, sequence columns are skipped. Generated columns are always
+// skipped.
 func (f *Funcs) sqlstr_insert_base(all bool, v interface{}) []string {
 	switch x := v.(type) {
 	case Table:
@@ -1460,14 +3191,14 @@ func (f *Funcs) sqlstr_insert_base(all bool, v interface{}) []string {
 		var n int
 		var fields, vals []string
 		for _, z := range x.Fields {
-			if z.IsSequence && !all {
+			if z.IsGenerated || (z.IsSequence && !all) {
 				continue
 			}
 			fields, vals = append(fields, f.colname(z)), append(vals, f.nth(n))
 			n++
 		}
 		return []string{
-			"INSERT INTO " + f.schemafn(x.SQLName) + " (",
+			"INSERT INTO " + f.qualifySQL(x.Schema, x.SQLName) + " (",
 			strings.Join(fields, ", "),
 			") VALUES (",
 			strings.Join(vals, ", "),
@@ -1482,17 +3213,19 @@ func (f *Funcs) sqlstr_insert_manual(v interface{}) []string {
 	return f.sqlstr_insert_base(true, v)
 }
 
-// sqlstr_insert builds an INSERT query, skipping the sequence field with
-// applicable RETURNING clause for generated primary key fields.
+// sqlstr_insert builds an INSERT query, skipping sequence fields with
+// applicable RETURNING clause for the table's sequence field (or, for
+// postgres, all of the table's sequence fields).
 func (f *Funcs) sqlstr_insert(v interface{}) []string {
 	switch x := v.(type) {
 	case Table:
-		var seq Field
+		if x.Sequence == nil {
+			return []string{fmt.Sprintf("[[ NO SEQUENCE FIELD: %s ]]", x.SQLName)}
+		}
+		seq := *x.Sequence
 		var count int
 		for _, field := range x.Fields {
-			if field.IsSequence {
-				seq = field
-			} else {
+			if !field.IsSequence && !field.IsGenerated {
 				count++
 			}
 		}
@@ -1509,7 +3242,12 @@ func (f *Funcs) sqlstr_insert(v interface{}) []string {
 				return []string{fmt.Sprintf("[[ UNSUPPORTED ORACLE TYPE: %s]]", f.oracleType)}
 			}
 		case "postgres":
-			lines[len(lines)-1] += ` RETURNING ` + f.colname(seq)
+			// return every sequence field
+			var names []string
+			for _, z := range sequenceFields(x) {
+				names = append(names, f.colname(z))
+			}
+			lines[len(lines)-1] += ` RETURNING ` + strings.Join(names, ", ")
 		case "sqlserver":
 			lines[len(lines)-1] += "; SELECT ID = CONVERT(BIGINT, SCOPE_IDENTITY())"
 		}
@@ -1533,7 +3271,7 @@ func (f *Funcs) sqlstr_update_base(prefix string, v interface{}) (int, []string)
 		var n int
 		var list []string
 		for _, z := range x.Fields {
-			if z.IsPrimary {
+			if z.IsPrimary || z.IsGenerated || (prefix == "" && x.Version != nil && z.SQLName == x.Version.SQLName) || isCreated(x, z) {
 				continue
 			}
 			name, param := f.colname(z), f.nth(n)
@@ -1545,7 +3283,12 @@ func (f *Funcs) sqlstr_update_base(prefix string, v interface{}) (int, []string)
 		}
 		name := ""
 		if prefix == "" {
-			name = f.schemafn(x.SQLName) + " "
+			name = f.qualifySQL(x.Schema, x.SQLName) + " "
+			// increment version
+			if x.Version != nil {
+				col := f.colname(*x.Version)
+				list = append(list, fmt.Sprintf("%s = %s + 1", col, col))
+			}
 		}
 		return n, []string{
 			"UPDATE " + name + "SET ",
@@ -1563,7 +3306,7 @@ func (f *Funcs) sqlstr_update(v interface{}) []string {
 	case Table:
 		var list []string
 		n, lines := f.sqlstr_update_base("", v)
-		for i, z := range x.PrimaryKeys {
+		for i, z := range versionFields(x, x.PrimaryKeys...) {
 			list = append(list, fmt.Sprintf("%s = %s", f.colname(z), f.nth(n+i)))
 		}
 		return append(lines, "WHERE "+strings.Join(list, " AND "))
@@ -1603,93 +3346,384 @@ func (f *Funcs) sqlstr_upsert_postgres_sqlite(v interface{}) []string {
 		_, update := f.sqlstr_update_base("EXCLUDED.", v)
 		return append(lines, update...)
 	}
-	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 22: %T ]]", v)}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 22: %T ]]", v)}
+}
+
+// sqlstr_upsert_mysql builds an uspert query for mysql
+//
+// INSERT (..) VALUES (..) ON DUPLICATE KEY UPDATE SET ...
+func (f *Funcs) sqlstr_upsert_mysql(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		lines := []string{" ON DUPLICATE KEY UPDATE "}
+		var list []string
+		i := len(x.Fields)
+		for _, z := range x.Fields {
+			if z.IsSequence || z.IsGenerated || isCreated(x, z) {
+				continue
+			}
+			name := f.colname(z)
+			list = append(list, fmt.Sprintf("%s = VALUES(%s)", name, name))
+			i++
+		}
+		return append(lines, strings.Join(list, ", "))
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 23: %T ]]", v)}
+}
+
+// sqlstr_upsert_sqlserver_oracle builds an upsert query for sqlserver
+//
+// MERGE [table] AS target USING (SELECT [pkeys]) AS source ...
+func (f *Funcs) sqlstr_upsert_sqlserver_oracle(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		var lines []string
+		// merge [table]...
+		switch f.driver {
+		case "sqlserver":
+			lines = []string{"MERGE " + f.qualifySQL(x.Schema, x.SQLName) + " AS t "}
+		case "oracle":
+			lines = []string{"MERGE " + f.qualifySQL(x.Schema, x.SQLName) + "t "}
+		}
+		// using (select ..)
+		var n int
+		var fields, predicate []string
+		for _, field := range x.Fields {
+			if field.IsGenerated {
+				continue
+			}
+			fields = append(fields, fmt.Sprintf("%s %s", f.nth(n), field.SQLName))
+			n++
+		}
+		for _, field := range x.PrimaryKeys {
+			predicate = append(predicate, fmt.Sprintf("s.%s = t.%s", field.SQLName, field.SQLName))
+		}
+		// closing part for select
+		var closing string
+		switch f.driver {
+		case "sqlserver":
+			closing = `) AS s `
+		case "oracle":
+			closing = `FROM DUAL ) s `
+		}
+		lines = append(lines, `USING (`,
+			`SELECT `+strings.Join(fields, ", ")+" ",
+			closing,
+			`ON `+strings.Join(predicate, " AND ")+" ")
+		// build param lists
+		var updateParams, insertParams, insertVals []string
+		for _, field := range x.Fields {
+			// sequences and generated columns are always managed by db
+			if field.IsSequence || field.IsGenerated {
+				continue
+			}
+			// primary keys and the created field are not updated
+			if !field.IsPrimary && !isCreated(x, field) {
+				updateParams = append(updateParams, fmt.Sprintf("t.%s = s.%s", field.SQLName, field.SQLName))
+			}
+			insertParams = append(insertParams, field.SQLName)
+			insertVals = append(insertVals, "s."+field.SQLName)
+		}
+		// when matched then update...
+		lines = append(lines,
+			`WHEN MATCHED THEN `, `UPDATE SET `,
+			strings.Join(updateParams, ", ")+" ",
+			`WHEN NOT MATCHED THEN `,
+			`INSERT (`,
+			strings.Join(insertParams, ", "),
+			`) VALUES (`,
+			strings.Join(insertVals, ", "),
+			`);`,
+		)
+		return lines
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 24: %T ]]", v)}
+}
+
+// bulkfn returns true when bulk funcs are enabled.
+func (f *Funcs) bulkfn() bool {
+	return f.bulk
+}
+
+// bulkFields returns the fields set by a bulk insert or upsert.
+func bulkFields(v BulkFunc) []Field {
+	var fields []Field
+	for _, z := range v.Table.Fields {
+		if z.IsGenerated || (z.IsSequence && !v.Upsert && !v.Table.Manual) {
+			continue
+		}
+		fields = append(fields, z)
+	}
+	return fields
+}
+
+// bulk_param returns the Go expression for the nth placeholder, where n is a
+// Go expression for the 1-based parameter number.
+func (f *Funcs) bulk_param(n string) string {
+	if f.nth(0) == f.nth(1) {
+		return strconv.Quote(f.nth(0))
+	}
+	// nth returns 1-based placeholders (ie, $1, :1, @p1)
+	return fmt.Sprintf("fmt.Sprintf(%q, %s)", strings.Replace(f.nth(0), "1", "%d", 1), n)
+}
+
+// bulkstr builds the statement for a bulk insert or upsert of the rows in
+// batch.
+//
+// Rows are added to the statement at runtime using bulkParams, as the number
+// of rows is not known ahead of time.
+func (f *Funcs) bulkstr(v interface{}) string {
+	switch x := v.(type) {
+	case BulkFunc:
+		fields := bulkFields(x)
+		var names, params, inserts, values, updates, predicate []string
+		for _, z := range fields {
+			name := f.colname(z)
+			names, params = append(names, name), append(params, "%s")
+			// sequences are always managed by db when merging
+			if !z.IsSequence {
+				inserts, values = append(inserts, name), append(values, "s."+name)
+			}
+			if !z.IsPrimary {
+				updates = append(updates, fmt.Sprintf("t.%s = s.%s", name, name))
+			}
+		}
+		for _, z := range x.Table.PrimaryKeys {
+			predicate = append(predicate, fmt.Sprintf("s.%[1]s = t.%[1]s", f.colname(z)))
+		}
+		table := f.qualifySQL(x.Table.Schema, x.Table.SQLName)
+		row, sep := "("+strings.Join(params, ", ")+")", ", "
+		var prefix, suffix string
+		switch {
+		case f.driver == "oracle" && x.Upsert:
+			// MERGE INTO [table] t USING (SELECT ... FROM dual UNION ALL ...) s ...
+			for i, name := range names {
+				params[i] += " " + name
+			}
+			prefix = "MERGE INTO " + table + " t USING ("
+			row, sep = "SELECT "+strings.Join(params, ", ")+" FROM dual", " UNION ALL "
+			suffix = ") s ON (" + strings.Join(predicate, " AND ") + ") "
+		case f.driver == "oracle":
+			// INSERT ALL INTO [table] (...) VALUES (...) ... SELECT 1 FROM dual
+			prefix = "INSERT ALL "
+			row, sep = "INTO "+table+" ("+strings.Join(names, ", ")+") VALUES "+row, " "
+			suffix = " SELECT 1 FROM dual"
+		case f.driver == "sqlserver" && x.Upsert:
+			// MERGE [table] AS t USING (VALUES (...), ...) AS s (...) ...
+			prefix = "MERGE " + table + " AS t USING (VALUES "
+			suffix = ") AS s (" + strings.Join(names, ", ") + ") ON " + strings.Join(predicate, " AND ") + " "
+		default:
+			prefix = "INSERT INTO " + table + " (" + strings.Join(names, ", ") + ") VALUES "
+			switch {
+			case x.Upsert && f.driver == "mysql":
+				suffix = strings.Join(f.sqlstr_upsert_mysql(x.Table), "")
+			case x.Upsert:
+				suffix = strings.Join(f.sqlstr_upsert_postgres_sqlite(x.Table), "")
+			}
+		}
+		// when matched then update...
+		if x.Upsert && (f.driver == "sqlserver" || f.driver == "oracle") {
+			suffix += "WHEN MATCHED THEN UPDATE SET " + strings.Join(updates, ", ") + " " +
+				"WHEN NOT MATCHED THEN INSERT (" + strings.Join(inserts, ", ") + ") " +
+				"VALUES (" + strings.Join(values, ", ") + ")"
+			if f.driver == "sqlserver" {
+				suffix += ";"
+			}
+		}
+		sqlstr := strconv.Quote(prefix) + " + bulkParams(" + strconv.Quote(row) + ", " + strconv.Quote(sep) + fmt.Sprintf(", len(batch), %d)", len(fields))
+		if suffix != "" {
+			sqlstr += " + " + strconv.Quote(strings.TrimRight(suffix, " "))
+		}
+		return "sqlstr := " + sqlstr
+	}
+	return fmt.Sprintf("[[ UNSUPPORTED TYPE 31: %T ]]", v)
+}
+
+// repositoryfn returns true when the transaction-scoped repository is enabled.
+func (f *Funcs) repositoryfn() bool {
+	return f.repository
+}
+
+// paginatefn returns true when keyset pagination funcs are enabled.
+func (f *Funcs) paginatefn() bool {
+	return f.paginate
+}
+
+// filterfn returns true when filter builder funcs are enabled.
+func (f *Funcs) filterfn() bool {
+	return f.filter
+}
+
+// filter_col returns the quoted column name of a filter field.
+func (f *Funcs) filter_col(z FilterField) string {
+	return strconv.Quote(f.colname(z.Field))
+}
+
+// filter_where declares the filter for the table, excluding soft deleted
+// rows.
+func (f *Funcs) filter_where(t Table) string {
+	z := t.SoftDelete
+	if z == nil {
+		return "var where filter"
+	}
+	cond := f.colname(*z) + " IS NULL"
+	if isBool(*z) {
+		cond = fmt.Sprintf("%s = %s", f.colname(*z), f.boolLiteral(false))
+	}
+	return "where := filter{conds: []string{" + strconv.Quote(cond) + "}}"
+}
+
+// filterstr builds the query for a filter builder func. The WHERE clause is
+// added at runtime from the filter's conditions.
+func (f *Funcs) filterstr(v interface{}) string {
+	switch x := v.(type) {
+	case FilterFunc:
+		var fields []string
+		for _, z := range x.Table.Fields {
+			fields = append(fields, f.colname(z))
+		}
+		lines := []string{
+			"SELECT ",
+			strings.Join(fields, ", ") + " ",
+			"FROM " + f.qualifySQL(x.Table.Schema, x.Table.SQLName),
+		}
+		return fmt.Sprintf("sqlstr := `%s` + where.clause()", strings.Join(lines, "` +\n\t\t`"))
+	}
+	return fmt.Sprintf("[[ UNSUPPORTED TYPE 34: %T ]]", v)
+}
+
+// factoriesfn returns true when factory funcs are enabled.
+func (f *Funcs) factoriesfn() bool {
+	return f.factories
+}
+
+// factory_unset builds the condition for the foreign key fields of the row
+// created by a factory being unset.
+func (f *Funcs) factory_unset(fk ForeignKey) string {
+	short := f.short(fk.Table)
+	var conds []string
+	for _, z := range fk.Fields {
+		zero := z.Zero
+		if strings.Contains(zero, "{") {
+			zero = "(" + zero + ")"
+		}
+		conds = append(conds, fmt.Sprintf("%s.%s == %s", short, z.GoName, zero))
+	}
+	return strings.Join(conds, " && ")
+}
+
+// test_driver returns the database/sql driver name used by the round trip
+// tests.
+func (f *Funcs) test_driver() string {
+	name, _ := testDriver(f.driver, f.oracleType)
+	return name
+}
+
+// test_import returns the driver package imported by the round trip tests
+// when the file is a test file. In pgx mode, returns the pgx package.
+func (f *Funcs) test_import(file string) string {
+	switch {
+	case !strings.HasSuffix(file, "_test.go"):
+		return ""
+	case f.pgx:
+		return "github.com/jackc/pgx/v5"
+	}
+	_, pkg := testDriver(f.driver, f.oracleType)
+	return pkg
+}
+
+// page_keys returns the key fields for the parameters of the query for the
+// page after a cursor, in order.
+//
+// Drivers without row value comparisons expand (a, b) > (x, y) to
+// a > x OR (a = x AND b > y), which requires key fields to be repeated.
+func (f *Funcs) page_keys(v PageFunc) []Field {
+	if f.driver != "sqlserver" && f.driver != "oracle" {
+		return v.Fields
+	}
+	var fields []Field
+	for i := range v.Fields {
+		fields = append(fields, v.Fields[:i+1]...)
+	}
+	return fields
 }
 
-// sqlstr_upsert_mysql builds an uspert query for mysql
-//
-// INSERT (..) VALUES (..) ON DUPLICATE KEY UPDATE SET ...
-func (f *Funcs) sqlstr_upsert_mysql(v interface{}) []string {
+// pagestr builds the query for a page of rows, after the cursor when after is
+// true.
+func (f *Funcs) pagestr(after bool, v interface{}) string {
 	switch x := v.(type) {
-	case Table:
-		lines := []string{" ON DUPLICATE KEY UPDATE "}
-		var list []string
-		i := len(x.Fields)
+	case PageFunc:
+		// build table fieldnames
+		var fields []string
+		for _, z := range x.Table.Fields {
+			fields = append(fields, f.colname(z))
+		}
+		var keys []string
 		for _, z := range x.Fields {
-			if z.IsSequence {
-				continue
+			keys = append(keys, f.colname(z))
+		}
+		var n int
+		var list []string
+		// keys after cursor
+		switch {
+		case !after:
+		case f.driver == "sqlserver" || f.driver == "oracle":
+			var or []string
+			for i := range keys {
+				var and []string
+				for j, key := range keys[:i+1] {
+					op := "="
+					if j == i {
+						op = ">"
+					}
+					and = append(and, fmt.Sprintf("%s %s %s", key, op, f.nth(n)))
+					n++
+				}
+				or = append(or, strings.Join(and, " AND "))
 			}
-			name := f.colname(z)
-			list = append(list, fmt.Sprintf("%s = VALUES(%s)", name, name))
-			i++
+			if len(or) == 1 {
+				list = append(list, or[0])
+			} else {
+				list = append(list, "(("+strings.Join(or, ") OR (")+"))")
+			}
+		case len(keys) == 1:
+			list = append(list, fmt.Sprintf("%s > %s", keys[0], f.nth(0)))
+			n++
+		default:
+			var params []string
+			for ; n < len(keys); n++ {
+				params = append(params, f.nth(n))
+			}
+			list = append(list, "("+strings.Join(keys, ", ")+") > ("+strings.Join(params, ", ")+")")
 		}
-		return append(lines, strings.Join(list, ", "))
-	}
-	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 23: %T ]]", v)}
-}
-
-// sqlstr_upsert_sqlserver_oracle builds an upsert query for sqlserver
-//
-// MERGE [table] AS target USING (SELECT [pkeys]) AS source ...
-func (f *Funcs) sqlstr_upsert_sqlserver_oracle(v interface{}) []string {
-	switch x := v.(type) {
-	case Table:
-		var lines []string
-		// merge [table]...
-		switch f.driver {
-		case "sqlserver":
-			lines = []string{"MERGE " + f.schemafn(x.SQLName) + " AS t "}
-		case "oracle":
-			lines = []string{"MERGE " + f.schemafn(x.SQLName) + "t "}
+		// exclude soft deleted rows
+		if z := x.Table.SoftDelete; z != nil {
+			if isBool(*z) {
+				list = append(list, fmt.Sprintf("%s = %s", f.colname(*z), f.boolLiteral(false)))
+			} else {
+				list = append(list, f.colname(*z)+" IS NULL")
+			}
 		}
-		// using (select ..)
-		var fields, predicate []string
-		for i, field := range x.Fields {
-			fields = append(fields, fmt.Sprintf("%s %s", f.nth(i), field.SQLName))
+		lines := []string{
+			"SELECT ",
+			strings.Join(fields, ", ") + " ",
+			"FROM " + f.qualifySQL(x.Table.Schema, x.Table.SQLName) + " ",
 		}
-		for _, field := range x.PrimaryKeys {
-			predicate = append(predicate, fmt.Sprintf("s.%s = t.%s", field.SQLName, field.SQLName))
+		if len(list) != 0 {
+			lines = append(lines, "WHERE "+strings.Join(list, " AND ")+" ")
 		}
-		// closing part for select
-		var closing string
+		lines = append(lines, "ORDER BY "+strings.Join(keys, ", ")+" ")
 		switch f.driver {
 		case "sqlserver":
-			closing = `) AS s `
+			lines = append(lines, "OFFSET 0 ROWS FETCH NEXT "+f.nth(n)+" ROWS ONLY")
 		case "oracle":
-			closing = `FROM DUAL ) s `
-		}
-		lines = append(lines, `USING (`,
-			`SELECT `+strings.Join(fields, ", ")+" ",
-			closing,
-			`ON `+strings.Join(predicate, " AND ")+" ")
-		// build param lists
-		var updateParams, insertParams, insertVals []string
-		for _, field := range x.Fields {
-			// sequences are always managed by db
-			if field.IsSequence {
-				continue
-			}
-			// primary keys
-			if !field.IsPrimary {
-				updateParams = append(updateParams, fmt.Sprintf("t.%s = s.%s", field.SQLName, field.SQLName))
-			}
-			insertParams = append(insertParams, field.SQLName)
-			insertVals = append(insertVals, "s."+field.SQLName)
+			lines = append(lines, "FETCH FIRST "+f.nth(n)+" ROWS ONLY")
+		default:
+			lines = append(lines, "LIMIT "+f.nth(n))
 		}
-		// when matched then update...
-		lines = append(lines,
-			`WHEN MATCHED THEN `, `UPDATE SET `,
-			strings.Join(updateParams, ", ")+" ",
-			`WHEN NOT MATCHED THEN `,
-			`INSERT (`,
-			strings.Join(insertParams, ", "),
-			`) VALUES (`,
-			strings.Join(insertVals, ", "),
-			`);`,
-		)
-		return lines
+		return fmt.Sprintf("sqlstr = `%s`", strings.Join(lines, "` +\n\t\t\t`"))
 	}
-	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 24: %T ]]", v)}
+	return fmt.Sprintf("[[ UNSUPPORTED TYPE 32: %T ]]", v)
 }
 
 // sqlstr_delete builds a DELETE query for the primary keys.
@@ -1701,14 +3735,91 @@ func (f *Funcs) sqlstr_delete(v interface{}) []string {
 		for i, z := range x.PrimaryKeys {
 			list = append(list, fmt.Sprintf("%s = %s", f.colname(z), f.nth(i)))
 		}
+		// soft delete
+		if z := x.SoftDelete; z != nil {
+			value := "CURRENT_TIMESTAMP"
+			if isBool(*z) {
+				value = f.boolLiteral(true)
+			}
+			return []string{
+				"UPDATE " + f.qualifySQL(x.Schema, x.SQLName) + " ",
+				"SET " + f.colname(*z) + " = " + value + " ",
+				"WHERE " + strings.Join(list, " AND "),
+			}
+		}
 		return []string{
-			"DELETE FROM " + f.schemafn(x.SQLName) + " ",
+			"DELETE FROM " + f.qualifySQL(x.Schema, x.SQLName) + " ",
 			"WHERE " + strings.Join(list, " AND "),
 		}
 	}
 	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 25: %T ]]", v)}
 }
 
+// sqlstr_refresh builds a REFRESH MATERIALIZED VIEW query.
+func (f *Funcs) sqlstr_refresh(v interface{}) []string {
+	switch x := v.(type) {
+	case RefreshFunc:
+		if x.Concurrently {
+			return []string{"REFRESH MATERIALIZED VIEW CONCURRENTLY " + f.qualifySQL(x.Table.Schema, x.Table.SQLName)}
+		}
+		return []string{"REFRESH MATERIALIZED VIEW " + f.qualifySQL(x.Table.Schema, x.Table.SQLName)}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 35: %T ]]", v)}
+}
+
+// sqlstr_setting builds a query setting a setting with set_config.
+func (f *Funcs) sqlstr_setting(v interface{}) []string {
+	switch x := v.(type) {
+	case SettingFunc:
+		name := "'" + strings.ReplaceAll(x.Name, "'", "''") + "'"
+		return []string{"SELECT set_config(" + name + ", " + f.nth(0) + ", " + f.nth(1) + ")"}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 36: %T ]]", v)}
+}
+
+// boolLiteral returns the SQL literal for a bool value.
+func (f *Funcs) boolLiteral(b bool) string {
+	switch {
+	case f.driver == "postgres":
+		return strconv.FormatBool(b)
+	case b:
+		return "1"
+	}
+	return "0"
+}
+
+// isBool returns true when the field is a bool.
+func isBool(field Field) bool {
+	return field.Type == "bool" || field.Type == "sql.NullBool" || field.Type == "pgtype.Bool"
+}
+
+// nullValue returns the value field and Go type of the value of a nullable
+// type (ie, sql.NullInt64 or pgtype.Int8).
+func nullValue(typ string) (string, string, bool) {
+	if strings.HasPrefix(typ, "sql.Null") {
+		return typ[8:], strings.ToLower(typ[8:]), true
+	}
+	if v, ok := pgtypeValues[typ]; ok {
+		return v[0], v[1], true
+	}
+	return "", "", false
+}
+
+// pgtypeValues are the value fields and Go types of the values of the pgtype
+// nullable types.
+var pgtypeValues = map[string][2]string{
+	"pgtype.Bool":        {"Bool", "bool"},
+	"pgtype.Text":        {"String", "string"},
+	"pgtype.Int2":        {"Int16", "int16"},
+	"pgtype.Int4":        {"Int32", "int32"},
+	"pgtype.Int8":        {"Int64", "int64"},
+	"pgtype.Float4":      {"Float32", "float32"},
+	"pgtype.Float8":      {"Float64", "float64"},
+	"pgtype.Date":        {"Time", "time.Time"},
+	"pgtype.Timestamp":   {"Time", "time.Time"},
+	"pgtype.Timestamptz": {"Time", "time.Time"},
+}
+
 // sqlstr_index builds a index fields.
 func (f *Funcs) sqlstr_index(v interface{}) []string {
 	switch x := v.(type) {
@@ -1723,10 +3834,22 @@ func (f *Funcs) sqlstr_index(v interface{}) []string {
 		for i, z := range x.Fields {
 			list = append(list, fmt.Sprintf("%s = %s", f.colname(z), f.nth(i)))
 		}
+		// partial index predicate
+		if x.Predicate != "" {
+			list = append(list, "("+strings.ReplaceAll(x.Predicate, "`", "` + \"`\" + `")+")")
+		}
+		// exclude soft deleted rows, when not excluded by the predicate
+		if z := x.Table.SoftDelete; z != nil && !x.IncludeDeleted && !x.SoftDeletePredicate {
+			if isBool(*z) {
+				list = append(list, fmt.Sprintf("%s = %s", f.colname(*z), f.boolLiteral(false)))
+			} else {
+				list = append(list, f.colname(*z)+" IS NULL")
+			}
+		}
 		return []string{
 			"SELECT ",
 			strings.Join(fields, ", ") + " ",
-			"FROM " + f.schemafn(x.Table.SQLName) + " ",
+			"FROM " + f.qualifySQL(x.Table.Schema, x.Table.SQLName) + " ",
 			"WHERE " + strings.Join(list, " AND "),
 		}
 	}
@@ -1764,7 +3887,7 @@ func (f *Funcs) sqlstr_proc(v interface{}) []string {
 			list = append(list, s)
 		}
 		// dont prefix with schema for oracle
-		name := f.schemafn(x.SQLName)
+		name := f.qualifySQL(x.Schema, x.SQLName)
 		if f.driver == "oracle" {
 			name = x.SQLName
 		}
@@ -1795,7 +3918,7 @@ func (f *Funcs) sqlstr_func(v interface{}) []string {
 			list = append(list, f.nth(i))
 		}
 		return []string{
-			fmt.Sprintf(format, f.schemafn(x.SQLName), strings.Join(list, ", ")),
+			fmt.Sprintf(format, f.qualifySQL(x.Schema, x.SQLName), strings.Join(list, ", ")),
 		}
 	}
 	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE 28: %T ]]", v)}
@@ -1816,9 +3939,8 @@ func (f *Funcs) convertTypes(fkey ForeignKey) string {
 		}
 		// convert types
 		typ, refType := field.Type, refField.Type
-		if strings.HasPrefix(typ, "sql.Null") {
-			expr = expr + "." + typ[8:]
-			typ = strings.ToLower(typ[8:])
+		if name, base, ok := nullValue(typ); ok {
+			expr, typ = expr+"."+name, base
 		}
 		if strings.ToLower(refType) != typ {
 			expr = refType + "(" + expr + ")"
@@ -1892,13 +4014,16 @@ func (f *Funcs) zero(z ...interface{}) string {
 
 // typefn generates the Go type, prefixing the custom package name if applicable.
 func (f *Funcs) typefn(typ string) string {
-	if strings.Contains(typ, ".") {
+	if strings.Contains(typ, ".") || isRange(typ) {
 		return typ
 	}
 	var prefix string
-	for strings.HasPrefix(typ, "[]") {
-		typ = typ[2:]
-		prefix += "[]"
+	for strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "*") {
+		n := 1
+		if typ[0] == '[' {
+			n = 2
+		}
+		prefix, typ = prefix+typ[:n], typ[n:]
 	}
 	if _, ok := f.knownTypes[typ]; ok || f.custom == "" {
 		return prefix + typ
@@ -1909,7 +4034,7 @@ func (f *Funcs) typefn(typ string) string {
 // field generates a field definition for a struct.
 func (f *Funcs) field(field Field) (string, error) {
 	buf := new(bytes.Buffer)
-	if err := f.fieldtag.Funcs(f.FuncMap()).Execute(buf, field); err != nil {
+	if err := f.fieldtag.Execute(buf, field); err != nil {
 		return "", err
 	}
 	var tag string
@@ -1919,6 +4044,52 @@ func (f *Funcs) field(field Field) (string, error) {
 	return fmt.Sprintf("\t%s %s%s // %s", field.GoName, f.typefn(field.Type), tag, field.SQLName), nil
 }
 
+// redacted is the value used in place of sensitive fields.
+const redacted = `"[redacted]"`
+
+// sensitiveFields returns the sensitive fields of a table or fields.
+func sensitiveFields(v interface{}) []Field {
+	var fields, sensitive []Field
+	switch x := v.(type) {
+	case Table:
+		fields = x.Fields
+	case []Field:
+		fields = x
+	}
+	for _, field := range fields {
+		if field.Sensitive {
+			sensitive = append(sensitive, field)
+		}
+	}
+	return sensitive
+}
+
+// redacted_string generates an expression formatting the table's fields,
+// redacting the sensitive fields.
+func (f *Funcs) redacted_string(t Table) string {
+	var fields, args []string
+	for _, field := range t.Fields {
+		if field.Sensitive {
+			fields = append(fields, field.GoName+": [redacted]")
+			continue
+		}
+		fields = append(fields, field.GoName+": %v")
+		args = append(args, f.short(t)+"."+checkName(field.GoName))
+	}
+	s := fmt.Sprintf("%q", t.GoName+"{"+strings.Join(fields, ", ")+"}")
+	if len(args) == 0 {
+		return s
+	}
+	return fmt.Sprintf("fmt.Sprintf(%s, %s)", s, strings.Join(args, ", "))
+}
+
+// redacted_field generates a string field, with the same name and tag as the
+// sensitive field, for holding the redacted value.
+func (f *Funcs) redacted_field(field Field) (string, error) {
+	field.Type = "string"
+	return f.field(field)
+}
+
 // short generates a safe Go identifier for typ. typ is first checked
 // against shorts, and if not found, then the value is calculated and
 // stored in the shorts for future use.
@@ -1944,6 +4115,8 @@ func (f *Funcs) short(v interface{}) string {
 		return fmt.Sprintf("[[ UNSUPPORTED TYPE 30: %T ]]", v)
 	}
 	// check short name map
+	f.mu.Lock()
+	defer f.mu.Unlock()
 	name, ok := f.shorts[n]
 	if !ok {
 		// calc the short name
@@ -2083,27 +4256,46 @@ func nameContext(context bool, name string) string {
 
 // Context keys.
 var (
-	AppendKey     xo.ContextKey = "append"
-	KnownTypesKey xo.ContextKey = "known-types"
-	ShortsKey     xo.ContextKey = "shorts"
-	NotFirstKey   xo.ContextKey = "not-first"
-	Int32Key      xo.ContextKey = "int32"
-	Uint32Key     xo.ContextKey = "uint32"
-	ArrayModeKey  xo.ContextKey = "array-mode"
-	PkgKey        xo.ContextKey = "pkg"
-	TagKey        xo.ContextKey = "tag"
-	ImportKey     xo.ContextKey = "import"
-	UUIDKey       xo.ContextKey = "uuid"
-	CustomKey     xo.ContextKey = "custom"
-	ConflictKey   xo.ContextKey = "conflict"
-	InitialismKey xo.ContextKey = "initialism"
-	EscKey        xo.ContextKey = "esc"
-	FieldTagKey   xo.ContextKey = "field-tag"
-	ContextKey    xo.ContextKey = "context"
-	InjectKey     xo.ContextKey = "inject"
-	InjectFileKey xo.ContextKey = "inject-file"
-	LegacyKey     xo.ContextKey = "legacy"
-	OracleTypeKey xo.ContextKey = "oracle-type"
+	AppendKey       xo.ContextKey = "append"
+	KnownTypesKey   xo.ContextKey = "known-types"
+	ShortsKey       xo.ContextKey = "shorts"
+	FirstsKey       xo.ContextKey = "firsts"
+	NotFirstKey     xo.ContextKey = "not-first"
+	Int32Key        xo.ContextKey = "int32"
+	Uint32Key       xo.ContextKey = "uint32"
+	ArrayModeKey    xo.ContextKey = "array-mode"
+	PkgKey          xo.ContextKey = "pkg"
+	TagKey          xo.ContextKey = "tag"
+	ImportKey       xo.ContextKey = "import"
+	UUIDKey         xo.ContextKey = "uuid"
+	CustomKey       xo.ContextKey = "custom"
+	ConflictKey     xo.ContextKey = "conflict"
+	InitialismKey   xo.ContextKey = "initialism"
+	EscKey          xo.ContextKey = "esc"
+	FieldTagKey     xo.ContextKey = "field-tag"
+	ContextKey      xo.ContextKey = "context"
+	InjectKey       xo.ContextKey = "inject"
+	InjectFileKey   xo.ContextKey = "inject-file"
+	LegacyKey       xo.ContextKey = "legacy"
+	OracleTypeKey   xo.ContextKey = "oracle-type"
+	SchemaLayoutKey xo.ContextKey = "schema-layout"
+	SoftDeleteKey   xo.ContextKey = "soft-delete-column"
+	VersionKey      xo.ContextKey = "version-column"
+	CreatedKey      xo.ContextKey = "created-column"
+	UpdatedKey      xo.ContextKey = "updated-column"
+	BulkKey         xo.ContextKey = "bulk"
+	PaginateKey     xo.ContextKey = "paginate"
+	FilterKey       xo.ContextKey = "filter"
+	RLSKey          xo.ContextKey = "rls"
+	StreamKey       xo.ContextKey = "stream"
+	RepositoryKey   xo.ContextKey = "repository"
+	IntEnumsKey     xo.ContextKey = "int-enums"
+	QuerierKey      xo.ContextKey = "querier"
+	MockKey         xo.ContextKey = "mock"
+	PrepareKey      xo.ContextKey = "prepare"
+	HooksKey        xo.ContextKey = "hooks"
+	OtelKey         xo.ContextKey = "otel"
+	PgxKey          xo.ContextKey = "pgx"
 )
 
 // Append returns append from the context.
@@ -2124,6 +4316,12 @@ func Shorts(ctx context.Context) map[string]string {
 	return m
 }
 
+// Firsts returns the first file of each package from the context.
+func Firsts(ctx context.Context) map[string]string {
+	m, _ := ctx.Value(FirstsKey).(map[string]string)
+	return m
+}
+
 // NotFirst returns not-first from the context.
 func NotFirst(ctx context.Context) bool {
 	b, _ := ctx.Value(NotFirstKey).(bool)
@@ -2241,6 +4439,144 @@ func OracleType(ctx context.Context) string {
 	return s
 }
 
+// SchemaLayout returns schema-layout from the context.
+func SchemaLayout(ctx context.Context) string {
+	s, _ := ctx.Value(SchemaLayoutKey).(string)
+	return s
+}
+
+// SoftDelete returns soft-delete-column from the context.
+func SoftDelete(ctx context.Context) string {
+	s, _ := ctx.Value(SoftDeleteKey).(string)
+	return s
+}
+
+// Version returns version-column from the context.
+func Version(ctx context.Context) string {
+	s, _ := ctx.Value(VersionKey).(string)
+	return s
+}
+
+// Created returns created-column from the context.
+func Created(ctx context.Context) string {
+	s, _ := ctx.Value(CreatedKey).(string)
+	return s
+}
+
+// Updated returns updated-column from the context.
+func Updated(ctx context.Context) string {
+	s, _ := ctx.Value(UpdatedKey).(string)
+	return s
+}
+
+// Bulk returns bulk from the context.
+func Bulk(ctx context.Context) bool {
+	b, _ := ctx.Value(BulkKey).(bool)
+	return b
+}
+
+// Stream returns stream from the context.
+func Stream(ctx context.Context) bool {
+	b, _ := ctx.Value(StreamKey).(bool)
+	return b
+}
+
+// Repository returns repository from the context.
+func Repository(ctx context.Context) bool {
+	b, _ := ctx.Value(RepositoryKey).(bool)
+	return b
+}
+
+// Querier returns querier from the context, which is implied by mock.
+func Querier(ctx context.Context) bool {
+	b, _ := ctx.Value(QuerierKey).(bool)
+	return b || Mock(ctx)
+}
+
+// Mock returns mock from the context.
+func Mock(ctx context.Context) bool {
+	b, _ := ctx.Value(MockKey).(bool)
+	return b
+}
+
+// Prepare returns prepare from the context.
+func Prepare(ctx context.Context) bool {
+	b, _ := ctx.Value(PrepareKey).(bool)
+	return b
+}
+
+// Hooks returns hooks from the context.
+func Hooks(ctx context.Context) bool {
+	b, _ := ctx.Value(HooksKey).(bool)
+	return b || Otel(ctx)
+}
+
+// Otel returns otel from the context.
+func Otel(ctx context.Context) bool {
+	b, _ := ctx.Value(OtelKey).(bool)
+	return b
+}
+
+// Pgx returns pgx from the context.
+func Pgx(ctx context.Context) bool {
+	b, _ := ctx.Value(PgxKey).(bool)
+	return b
+}
+
+// checkPgx checks that the options in the context are supported in pgx mode.
+func checkPgx(ctx context.Context) error {
+	driver, _, _ := xo.DriverDbSchema(ctx)
+	switch {
+	case !Pgx(ctx):
+		return nil
+	case driver != "postgres":
+		return fmt.Errorf("pgx mode is not supported for %s", driver)
+	case Context(ctx) != "only":
+		return errors.New("pgx mode requires context mode only")
+	case Prepare(ctx):
+		return errors.New("--go-prepare is not supported in pgx mode, as pgx caches prepared statements")
+	}
+	return nil
+}
+
+// checkTenant checks that the options in the context are supported when
+// generating for tenants.
+func checkTenant(ctx context.Context) error {
+	switch {
+	case xo.Tenant(ctx) == "":
+		return nil
+	case Prepare(ctx):
+		return errors.New("--go-prepare is not supported with --tenant, as the statements depend on the tenant schema")
+	case Repository(ctx) || Querier(ctx):
+		return errors.New("--go-repository is not supported with --tenant, use the generated TenantDB instead")
+	}
+	return nil
+}
+
+// Paginate returns paginate from the context.
+func Paginate(ctx context.Context) bool {
+	b, _ := ctx.Value(PaginateKey).(bool)
+	return b
+}
+
+// Filter returns filter from the context.
+func Filter(ctx context.Context) bool {
+	b, _ := ctx.Value(FilterKey).(bool)
+	return b
+}
+
+// RLS returns rls from the context.
+func RLS(ctx context.Context) bool {
+	b, _ := ctx.Value(RLSKey).(bool)
+	return b
+}
+
+// IntEnums returns int-enums from the context.
+func IntEnums(ctx context.Context) bool {
+	b, _ := ctx.Value(IntEnumsKey).(bool)
+	return b
+}
+
 // addInitialisms adds snaker initialisms from the context.
 func addInitialisms(ctx context.Context) error {
 	z := ctx.Value(InitialismKey)
@@ -2264,18 +4600,11 @@ func contains(v []string, s string) bool {
 	return false
 }
 
-// singularize singularizes s.
-func singularize(s string) string {
-	if i := strings.LastIndex(s, "_"); i != -1 {
-		return s[:i+1] + inflector.Singularize(s[i+1:])
-	}
-	return inflector.Singularize(s)
-}
-
 // EnumValue is a enum value template.
 type EnumValue struct {
 	GoName     string
 	SQLName    string
+	Alias      string
 	ConstValue int
 }
 
@@ -2283,16 +4612,35 @@ type EnumValue struct {
 type Enum struct {
 	GoName  string
 	SQLName string
+	Schema  string
 	Values  []EnumValue
+	Int     bool
 	Comment string
 }
 
+// Composite is a composite type template.
+type Composite struct {
+	GoName  string
+	SQLName string
+	Schema  string
+	Fields  []Field
+}
+
+// Range is a range type template.
+type Range struct {
+	GoName  string
+	SQLName string
+	Schema  string
+	Type    string // Go type of the bounds
+}
+
 // Proc is a stored procedure template.
 type Proc struct {
 	Type           string
 	GoName         string
 	OverloadedName string
 	SQLName        string
+	Schema         string
 	Signature      string
 	Params         []Field
 	Returns        []Field
@@ -2303,13 +4651,21 @@ type Proc struct {
 
 // Table is a type (ie, table/view/custom query) template.
 type Table struct {
-	Type        string
-	GoName      string
-	SQLName     string
-	PrimaryKeys []Field
-	Fields      []Field
-	Manual      bool
-	Comment     string
+	Type           string
+	GoName         string
+	SQLName        string
+	Schema         string
+	PrimaryKeys    []Field
+	Fields         []Field
+	SoftDelete     *Field
+	Version        *Field
+	Created        *Field // audit field set on insert
+	Updated        *Field // audit field set on insert and update
+	Sequence       *Field // field returned on insert, when not manual
+	Manual         bool
+	PartitionOf    string
+	Comment        string
+	InsertTriggers []string // BEFORE INSERT trigger names
 }
 
 // ForeignKey is a foreign key template.
@@ -2318,32 +4674,121 @@ type ForeignKey struct {
 	SQLName   string
 	Table     Table
 	Fields    []Field
+	RefSchema string
 	RefTable  string
 	RefFields []Field
 	RefFunc   string
 	Comment   string
 }
 
+// PageFunc is a keyset pagination func template.
+type PageFunc struct {
+	GoName  string
+	Cursor  string
+	Table   Table
+	Fields  []Field
+	Comment string
+}
+
+// FilterFunc is a filter builder func template.
+type FilterFunc struct {
+	GoName  string
+	Filter  string
+	Table   Table
+	Fields  []FilterField
+	Ops     []FilterOp
+	Comment string
+}
+
+// RefreshFunc is a materialized view refresh func template.
+type RefreshFunc struct {
+	GoName       string
+	Table        Table
+	Concurrently bool
+}
+
+// SettingFunc is a func template setting a setting read by row level security
+// policies.
+type SettingFunc struct {
+	GoName string
+	Name   string
+	Tables []string
+}
+
+// FilterField is a field of a filter builder func template.
+type FilterField struct {
+	Field
+	Nullable bool
+}
+
+// FilterOp is a comparison filter.
+type FilterOp struct {
+	Name string
+	Op   string
+}
+
+// FactoryFunc is a factory func template.
+type FactoryFunc struct {
+	GoName  string
+	Table   Table
+	Values  []FactoryValue
+	Refs    []ForeignKey
+	Seq     bool
+	Comment string
+}
+
+// FactoryValue is a fake value for a field set by a factory func.
+type FactoryValue struct {
+	Field Field
+	Value string
+}
+
+// RoundTrip is a round trip test template.
+type RoundTrip struct {
+	GoName string
+	Table  Table
+	Lookup Index
+}
+
+// BulkFunc is a bulk insert or upsert func template.
+type BulkFunc struct {
+	GoName  string
+	Upsert  bool
+	Table   Table
+	Comment string
+}
+
 // Index is an index template.
 type Index struct {
-	SQLName   string
-	Func      string
-	Table     Table
-	Fields    []Field
-	IsUnique  bool
-	IsPrimary bool
-	Comment   string
+	SQLName             string
+	Func                string
+	Table               Table
+	Fields              []Field
+	Predicate           string
+	SoftDeletePredicate bool // predicate refers to the soft delete column
+	IsUnique            bool
+	IsPrimary           bool
+	IncludeDeleted      bool
+	Stream              bool
+	Comment             string
 }
 
 // Field is a field template.
 type Field struct {
-	GoName     string
-	SQLName    string
-	Type       string
-	Zero       string
-	IsPrimary  bool
-	IsSequence bool
-	Comment    string
+	GoName      string
+	SQLName     string
+	Type        string
+	Zero        string
+	IsPrimary   bool
+	IsSequence  bool
+	IsIdentity  bool
+	IsGenerated bool
+	Comment     string
+	Geometry    string // spatial subtype
+	SRID        int
+	Prec        int  // declared precision (ie, numeric digits)
+	Scale       int  // declared scale
+	Sensitive   bool // redacted when formatted, marshaled or logged
 }
 
 // QueryParam is a custom query parameter template.
@@ -2364,10 +4809,18 @@ type Query struct {
 	Flat        bool
 	Exec        bool
 	Interpolate bool
+	Stream      bool
 	Type        Table
+	Sets        []QuerySet
 	Comment     string
 }
 
+// QuerySet is a result set of a query returning multiple result sets.
+type QuerySet struct {
+	Name string
+	Type Table
+}
+
 // PackageImport holds information about a Go package import.
 type PackageImport struct {
 	Alias string
@@ -2683,9 +5136,8 @@ func addLegacyFuncs(ctx context.Context, funcs template.FuncMap) {
 			return expr
 		}
 		ft := f.Type
-		if strings.HasPrefix(ft, "sql.Null") {
-			expr = expr + "." + f.Type[8:]
-			ft = strings.ToLower(f.Type[8:])
+		if name, base, ok := nullValue(ft); ok {
+			expr, ft = expr+"."+name, base
 		}
 		if t.Type != ft {
 			expr = t.Type + "(" + expr + ")"
@@ -5,28 +5,52 @@
 //go:build{{ range $tags }} {{ . }}{{ end }}
 
 {{ end -}}
-{{- if first -}}
-// Package {{ pkg }} contains generated code for schema '{{ schema }}'.
+{{- if first .Data .Dest -}}
+// Package {{ pkg .Data }} contains generated code for schema '{{ qualify .Data }}'.
 {{ end -}}
-package {{ pkg }}
+package {{ pkg .Data }}
 
 // Code generated by xo. DO NOT EDIT.
+{{- with provenance "// " }}
+{{ . }}
+{{- end }}
 
 import (
 	"context"
 	"database/sql"
 	"database/sql/driver"
 	"encoding/csv"
+	"encoding/hex"
 	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
 	"regexp"
+	"strconv"
 	"strings"
+	"sync/atomic"
 	"time"
-{{- if driver "postgres" }}
+{{- with test_import .Dest }}
+	"os"
+	"testing"
+{{ if not pgx }}
+	_ "{{ . }}"
+{{ end }}
+{{- end }}
+{{- if pgx }}
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgtype"
+{{ else if driver "postgres" }}
 	"github.com/lib/pq"
 	"github.com/lib/pq/hstore"
+{{ end }}{{ if or (driver "postgres") (driver "mysql") }}
+	"github.com/paulmach/orb"
+	"github.com/paulmach/orb/encoding/ewkb"
+{{ end }}{{ if otel }}
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 {{ end }}{{ range imports }}
 	{{ with .Alias }}{{ . }} {{ end }}{{ .Pkg }}
 {{ end }}
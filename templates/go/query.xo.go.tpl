@@ -3,12 +3,16 @@
 {{- if $q.Comment -}}
 // {{ $q.Comment | eval (func_name_context $q) }}
 {{- else -}}
-// {{ func_name_context $q }} runs a custom query{{ if $q.Exec }} as a sql.Result{{ else if not $q.Flat }}, returning results as {{ $q.Type.GoName }}{{ end }}.
+// {{ func_name_context $q }} runs a custom query{{ if $q.Exec }} as a {{ if pgx }}pgconn.CommandTag{{ else }}sql.Result{{ end }}{{ else if $q.Stream }}, passing each result to fn as a {{ $q.Type.GoName }}{{ else if not $q.Flat }}, returning results as {{ $q.Type.GoName }}{{ end }}.
+{{- end }}
+{{- if $q.Stream }}
+//
+// Iteration stops at the first error returned by fn, which is returned.
 {{- end }}
 {{ func_context $q }} {
 	// query
 	{{ querystr $q }}
-	// run
+	{{ hook $q "" "" }}// run
 	logf({{ names "" "sqlstr" $q }})
 {{ if $q.Exec -}}
 	return {{ db "Exec" $q }}
@@ -20,12 +24,63 @@
 		return {{ zero $q.Type.Fields "logerror(err)" }}
 	}
 	return {{ names "" $q.Type "nil" }}
+{{- else if $q.Sets -}}
+	rows, err := {{ db "Query" $q }}
+	if err != nil {
+		return nil, logerror(err)
+	}
+	defer rows.Close()
+	// load result sets
+	var res {{ type $q.Type.GoName }}
+{{- range $i, $s := $q.Sets }}
+{{- if $i }}
+	if !rows.NextResultSet() {
+		if err := rows.Err(); err != nil {
+			return nil, logerror(err)
+		}
+		return nil, logerror(errors.New("missing result set {{ $s.Name }}"))
+	}
+{{- end }}
+	for rows.Next() {
+		var {{ short $s.Type }} {{ type $s.Type.GoName }}
+		// scan
+		if err := rows.Scan({{ names (print "&" (short $s.Type) ".") $s.Type.Fields }}); err != nil {
+			return nil, logerror(err)
+		}
+		res.{{ $s.Name }} = append(res.{{ $s.Name }}, &{{ short $s.Type }})
+	}
+{{- end }}
+	if err := rows.Err(); err != nil {
+		return nil, logerror(err)
+	}
+	return &res, nil
 {{- else if $q.One -}}
 	var {{ short $q.Type }} {{ type $q.Type.GoName }}
 	if err := {{ db "QueryRow" $q }}.Scan({{ names (print "&" (short $q.Type) ".") $q.Type.Fields }}); err != nil {
 		return nil, logerror(err)
 	}
 	return &{{ short $q.Type }}, nil
+{{- else if $q.Stream -}}
+	rows, err := {{ db "Query" $q }}
+	if err != nil {
+		return logerror(err)
+	}
+	defer rows.Close()
+	// process results
+	for rows.Next() {
+		var {{ short $q.Type}} {{ type $q.Type.GoName }}
+		// scan
+		if err := rows.Scan({{ names (print "&" (short $q.Type) ".") $q.Type.Fields }}); err != nil {
+			return logerror(err)
+		}
+		if err := fn(&{{ short $q.Type }}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return logerror(err)
+	}
+	return nil
 {{- else -}}
 	rows, err := {{ db "Query" $q }}
 	if err != nil {
@@ -53,12 +108,19 @@
 {{- if $q.Comment -}}
 // {{ $q.Comment | eval (func_name $q) }}
 {{- else -}}
-// {{ func_name $q }} runs a custom query{{ if $q.Exec }} as a sql.Result{{ else if not $q.Flat }}, returning results as {{ $q.Type.GoName }}{{ end }}.
+// {{ func_name $q }} runs a custom query{{ if $q.Exec }} as a sql.Result{{ else if $q.Stream }}, passing each result to fn as a {{ $q.Type.GoName }}{{ else if not $q.Flat }}, returning results as {{ $q.Type.GoName }}{{ end }}.
 {{- end }}
 {{ func $q }} {
+{{- if $q.Stream }}
+	return {{ func_name_context $q }}({{ names_all "" "context.Background()" "db" $q "fn" }})
+{{- else }}
 	return {{ func_name_context $q }}({{ names_all "" "context.Background()" "db" $q }})
+{{- end }}
 }
 {{- end }}
+{{ if repository }}
+{{ repo $q }}
+{{ end }}
 {{ end }}
 
 {{ define "typedef" }}
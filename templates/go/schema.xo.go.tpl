@@ -1,6 +1,6 @@
 {{ define "enum" }}
 {{- $e := .Data -}}
-// {{ $e.GoName }} is the '{{ $e.SQLName }}' enum type from schema '{{ schema }}'.
+// {{ $e.GoName }} is the '{{ $e.SQLName }}' enum type from schema '{{ qualify $e.Schema }}'.
 type {{ $e.GoName }} uint16
 
 // {{ $e.GoName }} values.
@@ -41,21 +41,43 @@ func ({{ short $e.GoName }} *{{ $e.GoName }}) UnmarshalText(buf []byte) error {
 }
 
 // Value satisfies the driver.Valuer interface.
+{{- if $e.Int }}
+//
+// The {{ $e.GoName }} is stored by its ordinal value.
+{{- end }}
 func ({{ short $e.GoName }} {{ $e.GoName }}) Value() (driver.Value, error) {
+{{- if $e.Int }}
+	return int64({{ short $e.GoName }}), nil
+{{- else }}
 	return {{ short $e.GoName }}.String(), nil
+{{- end }}
 }
 
 // Scan satisfies the sql.Scanner interface.
 func ({{ short $e.GoName }} *{{ $e.GoName }}) Scan(v interface{}) error {
+{{- if $e.Int }}
+	switch x := v.(type) {
+	case []byte:
+		return {{ short $e.GoName }}.UnmarshalText(x)
+	case int64:
+		switch val := {{ $e.GoName }}(x); val {
+		case {{ range $i, $v := $e.Values }}{{ if $i }}, {{ end }}{{ $e.GoName }}{{ $v.GoName }}{{ end }}:
+			*{{ short $e.GoName }} = val
+			return nil
+		}
+		return ErrInvalid{{ $e.GoName }}(fmt.Sprintf("%d", x))
+	}
+{{- else }}
 	if buf, ok := v.([]byte); ok {
 		return {{ short $e.GoName }}.UnmarshalText(buf)
 	}
+{{- end }}
 	return ErrInvalid{{ $e.GoName }}(fmt.Sprintf("%T", v))
 }
 
 {{ $nullName := (printf "%s%s" "Null" $e.GoName) -}}
 {{- $nullShort := (short $nullName) -}}
-// {{ $nullName }} represents a null '{{ $e.SQLName }}' enum for schema '{{ schema }}'.
+// {{ $nullName }} represents a null '{{ $e.SQLName }}' enum for schema '{{ qualify $e.Schema }}'.
 type {{ $nullName }} struct {
 	{{ $e.GoName }} {{ $e.GoName }}
 	// Valid is true if {{ $e.GoName }} is not null.
@@ -90,6 +112,89 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 }
 {{ end }}
 
+{{ define "composite" }}
+{{- $c := .Data -}}
+{{- $short := (short $c.GoName) -}}
+// {{ $c.GoName }} is the '{{ $c.SQLName }}' composite type from schema '{{ qualify $c.Schema }}'.
+type {{ $c.GoName }} struct {
+{{ range $c.Fields -}}
+	{{ field . }}
+{{ end -}}
+}
+
+// Value satisfies the driver.Valuer interface.
+func ({{ $short }} {{ $c.GoName }}) Value() (driver.Value, error) {
+	buf := []byte{'('}
+	for i, v := range []interface{}{ {{- range $i, $f := $c.Fields }}{{ if $i }}, {{ end }}{{ $short }}.{{ $f.GoName }}{{ end -}} } {
+		if i != 0 {
+			buf = append(buf, ',')
+		}
+		var err error
+		if buf, err = appendText(buf, v); err != nil {
+			return nil, err
+		}
+	}
+	return string(append(buf, ')')), nil
+}
+
+// Scan satisfies the sql.Scanner interface.
+func ({{ $short }} *{{ $c.GoName }}) Scan(v interface{}) error {
+	fields, err := parseComposite(v, {{ len $c.Fields }})
+	if err != nil {
+		return err
+	}
+	var res {{ $c.GoName }}
+{{- range $i, $f := $c.Fields }}
+	if fields[{{ $i }}] != nil {
+		if err := scanText(&res.{{ $f.GoName }}, *fields[{{ $i }}]); err != nil {
+			return err
+		}
+	}
+{{- end }}
+	*{{ $short }} = res
+	return nil
+}
+
+{{ $nullName := (printf "%s%s" "Null" $c.GoName) -}}
+{{- $nullShort := (short $nullName) -}}
+// {{ $nullName }} represents a null '{{ $c.SQLName }}' composite for schema '{{ qualify $c.Schema }}'.
+type {{ $nullName }} struct {
+	{{ $c.GoName }} {{ $c.GoName }}
+	// Valid is true if {{ $c.GoName }} is not null.
+	Valid bool
+}
+
+// Value satisfies the driver.Valuer interface.
+func ({{ $nullShort }} {{ $nullName }}) Value() (driver.Value, error) {
+	if !{{ $nullShort }}.Valid {
+		return nil, nil
+	}
+	return {{ $nullShort }}.{{ $c.GoName }}.Value()
+}
+
+// Scan satisfies the sql.Scanner interface.
+func ({{ $nullShort }} *{{ $nullName }}) Scan(v interface{}) error {
+	if v == nil {
+		{{ $nullShort }}.{{ $c.GoName }}, {{ $nullShort }}.Valid = {{ $c.GoName }}{}, false
+		return nil
+	}
+	err := {{ $nullShort }}.{{ $c.GoName }}.Scan(v)
+	{{ $nullShort }}.Valid = err == nil
+	return err
+}
+{{ end }}
+
+{{ define "range" }}
+{{- $r := .Data -}}
+// {{ $r.GoName }} is the '{{ $r.SQLName }}' range type from schema '{{ qualify $r.Schema }}'.
+type {{ $r.GoName }} = Range[{{ $r.Type }}]
+
+// Null{{ $r.GoName }} represents a null '{{ $r.SQLName }}' range for schema '{{ qualify $r.Schema }}'.
+//
+// A null range is not Valid.
+type Null{{ $r.GoName }} = Range[{{ $r.Type }}]
+{{ end }}
+
 {{ define "foreignkey" }}
 {{- $k := .Data -}}
 // {{ func_name_context $k }} returns the {{ $k.RefTable }} associated with the {{ $k.Table.GoName }}'s ({{ names "" $k.Fields }}).
@@ -109,16 +214,346 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 {{- end }}
 {{ end }}
 
+{{ define "bulk" }}
+{{- $b := .Data -}}
+{{- $t := $b.Table -}}
+{{- $n := len (bulk_fields $b) -}}
+{{- if $b.Upsert -}}
+// {{ func_name_context $b }} performs an upsert for multiple {{ $t.GoName }} rows,
+// in batches.
+{{- with $t.Version }}
+//
+// Unlike Update, the upsert does not check '{{ .SQLName }}' for conflicts, and
+// writes the row's '{{ .SQLName }}' as is.
+{{- end }}
+{{- else -}}
+// {{ func_name_context $b }} inserts multiple {{ $t.GoName }} rows to the database,
+// in batches.
+{{- if not $t.Manual }}
+//
+// Primary keys generated by the database are not set on the rows.
+{{- end }}
+{{- end }}
+{{ func_context $b }} {
+	{{ hook $b "" "insert" }}for n := bulkSize({{ $n }}); len(rows) != 0; {
+		batch := rows
+		if len(batch) > n {
+			batch = batch[:n]
+		}
+		rows = rows[len(batch):]
+		// {{ if $b.Upsert }}upsert{{ else }}insert{{ end }}
+		{{ bulkstr $b }}
+		args := make([]interface{}, 0, len(batch)*{{ $n }})
+		for _, {{ short $t }} := range batch {
+{{ with audit $b "insert" }}{{ . }}{{ end -}}
+			args = append(args, {{ names (print (short $t) ".") (bulk_fields $b) }})
+		}
+		// run
+{{- if sensitive $t }}
+		logf(sqlstr) // args contain sensitive fields
+{{- else }}
+		logf(sqlstr, args...)
+{{- end }}
+		if _, err := {{ db "Exec" "args..." }}; err != nil {
+			return logerror(err)
+		}
+{{- if or $b.Upsert $t.Manual }}
+		// set exists
+		for _, {{ short $t }} := range batch {
+			{{ short $t }}._exists = true
+		}
+{{- end }}
+	}
+	return nil
+}
+
+{{ if context_both -}}
+{{- if $b.Upsert -}}
+// {{ func_name $b }} performs an upsert for multiple {{ $t.GoName }} rows, in batches.
+{{- else -}}
+// {{ func_name $b }} inserts multiple {{ $t.GoName }} rows to the database, in batches.
+{{- end }}
+{{ func $b }} {
+	return {{ func_name_context $b }}(context.Background(), db, rows...)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $b }}
+{{ end }}
+{{ end }}
+
+{{ define "page" }}
+{{- $p := .Data -}}
+{{- $t := $p.Table -}}
+// {{ $p.Cursor }} is an opaque cursor for paging through {{ $t.GoName }} rows ordered by
+// ({{ range $i, $f := $p.Fields }}{{ if $i }}, {{ end }}{{ $f.SQLName }}{{ end }}). The zero value starts at the first row.
+type {{ $p.Cursor }} struct {
+	key *{{ $t.GoName }}
+}
+
+// MarshalText satisfies the encoding.TextMarshaler interface.
+func (c {{ $p.Cursor }}) MarshalText() ([]byte, error) {
+	if c.key == nil {
+		return []byte{}, nil
+	}
+	return marshalCursor({{ names "c.key." $p.Fields }})
+}
+
+// UnmarshalText satisfies the encoding.TextUnmarshaler interface.
+func (c *{{ $p.Cursor }}) UnmarshalText(buf []byte) error {
+	if len(buf) == 0 {
+		c.key = nil
+		return nil
+	}
+	var key {{ $t.GoName }}
+	if err := unmarshalCursor(buf, {{ names "&key." $p.Fields }}); err != nil {
+		return err
+	}
+	c.key = &key
+	return nil
+}
+
+// {{ func_name_context $p }} retrieves up to limit rows from '{{ qualify $t.Schema $t.SQLName }}' after cursor,
+// ordered by ({{ range $i, $f := $p.Fields }}{{ if $i }}, {{ end }}{{ $f.SQLName }}{{ end }}), and the cursor for the next page.
+//
+// Fewer than limit rows are returned on the last page.
+{{- with $t.SoftDelete }} Excludes rows soft deleted by '{{ .SQLName }}'.{{ end }}
+{{ func_context $p }} {
+	var sqlstr string
+	var args []interface{}
+	if c := cursor.key; c == nil {
+		// query
+		{{ pagestr false $p }}
+		args = []interface{}{limit}
+	} else {
+		// query after cursor
+		{{ pagestr true $p }}
+		args = []interface{}{ {{- names "c." (page_keys $p) }}, limit}
+	}
+	{{ hook $p "" "select" }}// run
+{{- if sensitive $p.Fields }}
+	logf(sqlstr) // args contain sensitive fields
+{{- else }}
+	logf(sqlstr, args...)
+{{- end }}
+	rows, err := {{ db "Query" "args..." }}
+	if err != nil {
+		return nil, cursor, logerror(err)
+	}
+	defer rows.Close()
+	// process
+	var res []*{{ $t.GoName }}
+	for rows.Next() {
+		{{ short $t }} := {{ $t.GoName }}{
+		{{- if $t.PrimaryKeys }}
+			_exists: true,
+		{{ end -}}
+		}
+		// scan
+		if err := rows.Scan({{ names (print "&" (short $t) ".") $t }}); err != nil {
+			return nil, cursor, logerror(err)
+		}
+		res = append(res, &{{ short $t }})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, cursor, logerror(err)
+	}
+	// next cursor
+	if len(res) != 0 {
+		cursor = {{ $p.Cursor }}{key: &{{ $t.GoName }}{
+		{{- range $p.Fields }}
+			{{ .GoName }}: res[len(res)-1].{{ .GoName }},
+		{{- end }}
+		}}
+	}
+	return res, cursor, nil
+}
+
+{{ if context_both -}}
+// {{ func_name $p }} retrieves up to limit rows from '{{ qualify $t.Schema $t.SQLName }}' after cursor,
+// ordered by ({{ range $i, $f := $p.Fields }}{{ if $i }}, {{ end }}{{ $f.SQLName }}{{ end }}), and the cursor for the next page.
+{{ func $p }} {
+	return {{ func_name_context $p }}(context.Background(), db, cursor, limit)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $p }}
+{{ end }}
+{{ end }}
+
+{{ define "filter" }}
+{{- $f := .Data -}}
+{{- $t := $f.Table -}}
+// {{ $f.Filter }} is a filter on '{{ qualify $t.Schema $t.SQLName }}' rows, for use with {{ func_name_context $f }}.
+type {{ $f.Filter }} func(*filter)
+{{ range $z := $f.Fields }}
+{{- range $f.Ops }}
+// {{ $t.GoName }}{{ $z.GoName }}{{ .Name }} filters rows where {{ $z.SQLName }} {{ .Op }} v.
+func {{ $t.GoName }}{{ $z.GoName }}{{ .Name }}(v {{ type $z.Type }}) {{ $f.Filter }} {
+	return func(f *filter) {
+		f.add({{ filter_col $z }}, "{{ .Op }}", v)
+	}
+}
+{{ end }}
+// {{ $t.GoName }}{{ $z.GoName }}In filters rows where {{ $z.SQLName }} is any of v.
+func {{ $t.GoName }}{{ $z.GoName }}In(v ...{{ type $z.Type }}) {{ $f.Filter }} {
+	return func(f *filter) {
+		args := make([]interface{}, len(v))
+		for i := range v {
+			args[i] = v[i]
+		}
+		f.add({{ filter_col $z }}, "IN", args...)
+	}
+}
+{{ if $z.Nullable }}
+// {{ $t.GoName }}{{ $z.GoName }}IsNull filters rows where {{ $z.SQLName }} is NULL.
+func {{ $t.GoName }}{{ $z.GoName }}IsNull() {{ $f.Filter }} {
+	return func(f *filter) {
+		f.add({{ filter_col $z }}, "IS NULL")
+	}
+}
+
+// {{ $t.GoName }}{{ $z.GoName }}IsNotNull filters rows where {{ $z.SQLName }} is not NULL.
+func {{ $t.GoName }}{{ $z.GoName }}IsNotNull() {{ $f.Filter }} {
+	return func(f *filter) {
+		f.add({{ filter_col $z }}, "IS NOT NULL")
+	}
+}
+{{ end }}
+{{- end }}
+// {{ func_name_context $f }} retrieves rows from '{{ qualify $t.Schema $t.SQLName }}' matching all of the filters.
+{{- with $t.SoftDelete }} Excludes rows soft deleted by '{{ .SQLName }}'.{{ end }}
+{{ func_context $f }} {
+	{{ filter_where $t }}
+	for _, f := range filters {
+		f(&where)
+	}
+	// query
+	{{ filterstr $f }}
+	{{ hook $f "" "select" }}// run
+{{- if sensitive $t }}
+	logf(sqlstr) // args may contain sensitive fields
+{{- else }}
+	logf(sqlstr, where.args...)
+{{- end }}
+	rows, err := {{ db "Query" "where.args..." }}
+	if err != nil {
+		return nil, logerror(err)
+	}
+	defer rows.Close()
+	// process
+	var res []*{{ $t.GoName }}
+	for rows.Next() {
+		{{ short $t }} := {{ $t.GoName }}{
+		{{- if $t.PrimaryKeys }}
+			_exists: true,
+		{{ end -}}
+		}
+		// scan
+		if err := rows.Scan({{ names (print "&" (short $t) ".") $t }}); err != nil {
+			return nil, logerror(err)
+		}
+		res = append(res, &{{ short $t }})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, logerror(err)
+	}
+	return res, nil
+}
+
+{{ if context_both -}}
+// {{ func_name $f }} retrieves rows from '{{ qualify $t.Schema $t.SQLName }}' matching all of the filters.
+{{ func $f }} {
+	return {{ func_name_context $f }}(context.Background(), db, filters...)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $f }}
+{{ end }}
+{{ end }}
+
+{{ define "refresh" }}
+{{- $r := .Data -}}
+{{- $t := $r.Table -}}
+// {{ func_name_context $r }} refreshes the contents of the '{{ qualify $t.Schema $t.SQLName }}' materialized view
+{{- if $r.Concurrently }}, without
+// locking out concurrent selects on the view{{ end }}.
+{{ func_context $r }} {
+	// query
+	{{ sqlstr "refresh" $r }}
+	{{ hook $r "" "refresh" }}// run
+	logf(sqlstr)
+	if _, err := {{ db "Exec" }}; err != nil {
+		return logerror(err)
+	}
+	return nil
+}
+
+{{ if context_both -}}
+// {{ func_name $r }} refreshes the contents of the '{{ qualify $t.Schema $t.SQLName }}' materialized view
+{{- if $r.Concurrently }}, without
+// locking out concurrent selects on the view{{ end }}.
+{{ func $r }} {
+	return {{ func_name_context $r }}(context.Background(), db)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $r }}
+{{ end }}
+{{ end }}
+
+{{ define "setting" }}
+{{- $s := .Data -}}
+// {{ func_name_context $s }} sets the '{{ $s.Name }}' setting, read by the row level
+// security policies of {{ range $i, $t := $s.Tables }}{{ if $i }}, {{ end }}'{{ $t }}'{{ end }}.
+//
+// When local is true, the setting only applies to the current transaction.
+{{ func_context $s }} {
+	// query
+	{{ sqlstr "setting" $s }}
+	{{ hook $s "" "set" }}// run
+	logf(sqlstr, value, local)
+	if _, err := {{ db "Exec" "value" "local" }}; err != nil {
+		return logerror(err)
+	}
+	return nil
+}
+
+{{ if context_both -}}
+// {{ func_name $s }} sets the '{{ $s.Name }}' setting, read by the row level
+// security policies of {{ range $i, $t := $s.Tables }}{{ if $i }}, {{ end }}'{{ $t }}'{{ end }}.
+//
+// When local is true, the setting only applies to the current transaction.
+{{ func $s }} {
+	return {{ func_name_context $s }}(context.Background(), db, value, local)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $s }}
+{{ end }}
+{{ end }}
+
 {{ define "index" }}
 {{- $i := .Data -}}
-// {{ func_name_context $i }} retrieves a row from '{{ schema $i.Table.SQLName }}' as a {{ $i.Table.GoName }}.
+{{- if $i.Stream -}}
+// {{ func_name_context $i }} retrieves rows from '{{ qualify $i.Table.Schema $i.Table.SQLName }}', passing each row to fn as a {{ $i.Table.GoName }}.
+// Iteration stops at the first error returned by fn, which is returned.
+{{- else -}}
+// {{ func_name_context $i }} retrieves a row from '{{ qualify $i.Table.Schema $i.Table.SQLName }}' as a {{ $i.Table.GoName }}.
+{{- end }}
 //
-// Generated from index '{{ $i.SQLName }}'.
+// Generated from {{ if $i.Predicate }}partial {{ end }}index '{{ $i.SQLName }}'.
+{{- with $i.Table.SoftDelete }}{{ if $i.IncludeDeleted }} Includes{{ else }} Excludes{{ end }} rows soft deleted by '{{ .SQLName }}'.{{ end }}
 {{ func_context $i }} {
 	// query
 	{{ sqlstr "index" $i }}
-	// run
-	logf(sqlstr, {{ params $i.Fields false }})
+	{{ hook $i "" "select" }}// run
+	{{ logf $i }}
 {{- if $i.IsUnique }}
 	{{ short $i.Table }} := {{ $i.Table.GoName }}{
 	{{- if $i.Table.PrimaryKeys }}
@@ -129,6 +564,31 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 		return nil, logerror(err)
 	}
 	return &{{ short $i.Table }}, nil
+{{- else if $i.Stream }}
+	rows, err := {{ db "Query" $i }}
+	if err != nil {
+		return logerror(err)
+	}
+	defer rows.Close()
+	// process
+	for rows.Next() {
+		{{ short $i.Table }} := {{ $i.Table.GoName }}{
+		{{- if $i.Table.PrimaryKeys }}
+			_exists: true,
+		{{ end -}}
+		}
+		// scan
+		if err := rows.Scan({{ names_ignore (print "&" (short $i.Table) ".")  $i.Table }}); err != nil {
+			return logerror(err)
+		}
+		if err := fn(&{{ short $i.Table }}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return logerror(err)
+	}
+	return nil
 {{- else }}
 	rows, err := {{ db "Query" $i }}
 	if err != nil {
@@ -157,14 +617,26 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 }
 
 {{ if context_both -}}
-// {{ func_name $i }} retrieves a row from '{{ schema $i.Table.SQLName }}' as a {{ $i.Table.GoName }}.
+{{- if $i.Stream -}}
+// {{ func_name $i }} retrieves rows from '{{ qualify $i.Table.Schema $i.Table.SQLName }}', passing each row to fn as a {{ $i.Table.GoName }}.
+{{- else -}}
+// {{ func_name $i }} retrieves a row from '{{ qualify $i.Table.Schema $i.Table.SQLName }}' as a {{ $i.Table.GoName }}.
+{{- end }}
 //
-// Generated from index '{{ $i.SQLName }}'.
+// Generated from {{ if $i.Predicate }}partial {{ end }}index '{{ $i.SQLName }}'.
+{{- with $i.Table.SoftDelete }}{{ if $i.IncludeDeleted }} Includes{{ else }} Excludes{{ end }} rows soft deleted by '{{ .SQLName }}'.{{ end }}
 {{ func $i }} {
+{{- if $i.Stream }}
+	return {{ func_name_context $i }}({{ names "" "context.Background()" "db" $i "fn" }})
+{{- else }}
 	return {{ func_name_context $i }}({{ names "" "context.Background()" "db" $i }})
+{{- end }}
 }
 {{- end }}
 
+{{ if repository }}
+{{ repo $i }}
+{{ end }}
 {{end}}
 
 {{ define "procs" }}
@@ -177,9 +649,9 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 	// with out parameters
 	return {{ zero $p.Returns }}, fmt.Errorf("unsupported")
 {{- else }}
-	// call {{ schema $p.SQLName }}
+	// call {{ qualify $p.Schema $p.SQLName }}
 	{{ sqlstr "proc" $p }}
-	// run
+	{{ hook $p "" "call" }}// run
 {{- if not $p.Void }}
 {{- range $p.Returns }}
 	var {{ check_name .GoName }} {{ type .Type }}
@@ -213,6 +685,10 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 	return {{ func_name_context $p }}({{ names_all "" "context.Background()" "db" $p.Params }})
 }
 {{- end -}}
+{{ if repository }}
+
+{{ repo $p }}
+{{ end -}}
 {{- end }}
 {{ end }}
 
@@ -221,7 +697,11 @@ func (err ErrInvalid{{ $e.GoName }}) Error() string {
 {{- if $t.Comment -}}
 // {{ $t.Comment | eval $t.GoName }}
 {{- else -}}
-// {{ $t.GoName }} represents a row from '{{ schema $t.SQLName }}'.
+// {{ $t.GoName }} represents a row from '{{ qualify $t.Schema $t.SQLName }}'.
+{{- end }}
+{{- if $t.PartitionOf }}
+//
+// '{{ $t.SQLName }}' is a partition of '{{ qualify $t.Schema $t.PartitionOf }}'.
 {{- end }}
 type {{ $t.GoName }} struct {
 {{ range $t.Fields -}}
@@ -233,6 +713,31 @@ type {{ $t.GoName }} struct {
 {{ end -}}
 }
 
+{{ if sensitive $t -}}
+// String satisfies the fmt.Stringer interface, redacting the sensitive fields
+// of the {{ $t.GoName }}.
+func ({{ short $t }} {{ $t.GoName }}) String() string {
+	return {{ redacted_string $t }}
+}
+
+// MarshalJSON satisfies the json.Marshaler interface, redacting the sensitive
+// fields of the {{ $t.GoName }}.
+func ({{ short $t }} {{ $t.GoName }}) MarshalJSON() ([]byte, error) {
+	type redacted {{ $t.GoName }}
+	return json.Marshal(struct {
+		redacted
+{{ range sensitive $t -}}
+	{{ redacted_field . }}
+{{ end -}}
+	}{
+		redacted: redacted({{ short $t }}),
+{{- range sensitive $t }}
+		{{ .GoName }}: "[redacted]",
+{{- end }}
+	})
+}
+
+{{ end -}}
 {{ if $t.PrimaryKeys -}}
 // Exists returns true when the {{ $t.GoName }} exists in the database.
 func ({{ short $t }} *{{ $t.GoName }}) Exists() bool {
@@ -246,6 +751,11 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 }
 
 // {{ func_name_context "Insert" }} inserts the {{ $t.GoName }} to the database.
+{{- with $t.InsertTriggers }}
+//
+// The {{ range $i, $n := . }}{{ if $i }}, {{ end }}'{{ $n }}'{{ end }} BEFORE INSERT trigger(s) may modify the inserted
+// row, which is not reflected in the {{ $t.GoName }}.
+{{- end }}
 {{ recv_context $t "Insert" }} {
 	switch {
 	case {{ short $t }}._exists: // already exists
@@ -253,10 +763,11 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	case {{ short $t }}._deleted: // deleted
 		return logerror(&ErrInsertFailed{ErrMarkedForDeletion})
 	}
+{{ with audit $t "insert" }}{{ . }}{{ end -}}
 {{ if $t.Manual -}}
 	// insert (manual)
 	{{ sqlstr "insert_manual" $t }}
-	// run
+	{{ hook $t "Insert" "insert" }}// run
 	{{ logf $t }}
 	if _, err := {{ db_prefix "Exec" false $t }}; err != nil {
 		return logerror(err)
@@ -264,10 +775,10 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 {{- else -}}
 	// insert (primary key generated and returned by database)
 	{{ sqlstr "insert" $t }}
-	// run
-	{{ logf $t $t.PrimaryKeys }}
+	{{ hook $t "Insert" "insert" }}// run
+	{{ logf $t (sequence_fields $t) }}
 {{ if (driver "postgres") -}}
-	if err := {{ db_prefix "QueryRow" true $t }}.Scan(&{{ short $t }}.{{ (index $t.PrimaryKeys 0).GoName }}); err != nil {
+	if err := {{ db_prefix "QueryRow" true $t }}.Scan({{ names (print "&" (short $t) ".") (sequence_fields $t) }}); err != nil {
 		return logerror(err)
 	}
 {{- else if (driver "sqlserver") -}}
@@ -304,7 +815,7 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 {{- end -}}
 {{ if not (driver "postgres") -}}
 	// set primary key
-	{{ short $t }}.{{ (index $t.PrimaryKeys 0).GoName }} = {{ (index $t.PrimaryKeys 0).Type }}(id)
+	{{ short $t }}.{{ $t.Sequence.GoName }} = {{ $t.Sequence.Type }}(id)
 {{- end }}
 {{- end }}
 	// set exists
@@ -318,12 +829,20 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	return {{ short $t }}.InsertContext(context.Background(), db)
 }
 {{- end }}
+{{ if repository }}
+{{ repo_recv $t "Insert" }}
+{{ end }}
 
 
 {{ if eq (len $t.Fields) (len $t.PrimaryKeys) -}}
 // ------ NOTE: Update statements omitted due to lack of fields other than primary key ------
 {{- else -}}
 // {{ func_name_context "Update" }} updates a {{ $t.GoName }} in the database.
+{{- with $t.Version }}
+//
+// The update fails with ErrVersionConflict when '{{ .SQLName }}' has been
+// changed since the {{ $t.GoName }} was retrieved.
+{{- end }}
 {{ recv_context $t "Update" }} {
 	switch {
 	case !{{ short $t }}._exists: // doesn't exist
@@ -331,13 +850,35 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	case {{ short $t }}._deleted: // deleted
 		return logerror(&ErrUpdateFailed{ErrMarkedForDeletion})
 	}
+{{ with audit $t "update" }}{{ . }}{{ end -}}
 	// update with {{ if driver "postgres" }}composite {{ end }}primary key
 	{{ sqlstr "update" $t }}
-	// run
+	{{ hook $t "Update" "update" }}// run
 	{{ logf_update $t }}
+{{- with $t.Version }}
+	res, err := {{ db_update "Exec" $t }}
+	if err != nil {
+		return logerror(err)
+	}
+	// check version
+{{- if pgx }}
+	if res.RowsAffected() == 0 {
+		return logerror(&ErrUpdateFailed{ErrVersionConflict})
+	}
+{{- else }}
+	switch n, err := res.RowsAffected(); {
+	case err != nil:
+		return logerror(err)
+	case n == 0:
+		return logerror(&ErrUpdateFailed{ErrVersionConflict})
+	}
+{{- end }}
+	{{ short $t }}.{{ .GoName }}++
+{{- else }}
 	if _, err := {{ db_update "Exec" $t }}; err != nil {
 		return logerror(err)
 	}
+{{- end }}
 	return nil
 }
 
@@ -347,6 +888,9 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	return {{ short $t }}.UpdateContext(context.Background(), db)
 }
 {{- end }}
+{{ if repository }}
+{{ repo_recv $t "Update" }}
+{{ end }}
 
 // {{ func_name_context "Save" }} saves the {{ $t.GoName }} to the database.
 {{ recv_context $t "Save" }} {
@@ -365,16 +909,25 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	return {{ short $t }}.InsertContext(context.Background(), db)
 }
 {{- end }}
+{{ if repository }}
+{{ repo_recv $t "Save" }}
+{{ end }}
 
 // {{ func_name_context "Upsert" }} performs an upsert for {{ $t.GoName }}.
+{{- with $t.Version }}
+//
+// Unlike Update, the upsert does not check '{{ .SQLName }}' for conflicts, and
+// writes the {{ $t.GoName }}'s '{{ .SQLName }}' as is.
+{{- end }}
 {{ recv_context $t "Upsert" }} {
 	switch {
 	case {{ short $t }}._deleted: // deleted
 		return logerror(&ErrUpsertFailed{ErrMarkedForDeletion})
 	}
+{{ with audit $t "upsert" }}{{ . }}{{ end -}}
 	// upsert
 	{{ sqlstr "upsert" $t }}
-	// run
+	{{ hook $t "Upsert" "upsert" }}// run
 	{{ logf $t }}
 	if _, err := {{ db_prefix "Exec" false $t }}; err != nil {
 		return logerror(err)
@@ -390,9 +943,12 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 	return {{ short $t }}.UpsertContext(context.Background(), db)
 }
 {{- end -}}
+{{ if repository }}
+{{ repo_recv $t "Upsert" }}
+{{ end }}
 {{- end }}
 
-// {{ func_name_context "Delete" }} deletes the {{ $t.GoName }} from the database.
+// {{ func_name_context "Delete" }} {{ with $t.SoftDelete }}soft deletes the {{ $t.GoName }} from the database by setting '{{ .SQLName }}'{{ else }}deletes the {{ $t.GoName }} from the database{{ end }}.
 {{ recv_context $t "Delete" }} {
 	switch {
 	case !{{ short $t }}._exists: // doesn't exist
@@ -403,7 +959,7 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 {{ if eq (len $t.PrimaryKeys) 1 -}}
 	// delete with single primary key
 	{{ sqlstr "delete" $t }}
-	// run
+	{{ hook $t "Delete" "delete" }}// run
 	{{ logf_pkeys $t }}
 	if _, err := {{ db "Exec" (print (short $t) "." (index $t.PrimaryKeys 0).GoName) }}; err != nil {
 		return logerror(err)
@@ -411,7 +967,7 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 {{- else -}}
 	// delete with composite primary key
 	{{ sqlstr "delete" $t }}
-	// run
+	{{ hook $t "Delete" "delete" }}// run
 	{{ logf_pkeys $t }}
 	if _, err := {{ db "Exec" (names (print (short $t) ".") $t.PrimaryKeys) }}; err != nil {
 		return logerror(err)
@@ -423,10 +979,249 @@ func ({{ short $t }} *{{ $t.GoName }}) Deleted() bool {
 }
 
 {{ if context_both -}}
-// Delete deletes the {{ $t.GoName }} from the database.
+// Delete {{ with $t.SoftDelete }}soft deletes the {{ $t.GoName }} from the database by setting '{{ .SQLName }}'{{ else }}deletes the {{ $t.GoName }} from the database{{ end }}.
 {{ recv $t "Delete" }} {
 	return {{ short $t }}.DeleteContext(context.Background(), db)
 }
 {{- end -}}
+{{ if repository }}
+{{ repo_recv $t "Delete" }}
+{{ end }}
+{{- if pgx }}
+// QueueInsert queues inserting the {{ $t.GoName }} to the database in the batch.
+// The {{ $t.GoName }} exists once the batch results have been read.
+func ({{ short $t }} *{{ $t.GoName }}) QueueInsert(batch *pgx.Batch) error {
+	switch {
+	case {{ short $t }}._exists: // already exists
+		return logerror(&ErrInsertFailed{ErrAlreadyExists})
+	case {{ short $t }}._deleted: // deleted
+		return logerror(&ErrInsertFailed{ErrMarkedForDeletion})
+	}
+{{ with audit $t "insert" }}{{ . }}{{ end -}}
+{{ if $t.Manual -}}
+	// insert (manual)
+	{{ sqlstr "insert_manual" $t }}
+	// queue
+	{{ logf $t }}
+	{{ db_prefix "Queue" false $t }}.Exec(func(pgconn.CommandTag) error {
+		{{ short $t }}._exists = true
+		return nil
+	})
+{{- else -}}
+	// insert (primary key generated and returned by database)
+	{{ sqlstr "insert" $t }}
+	// queue
+	{{ logf $t (sequence_fields $t) }}
+	{{ db_prefix "Queue" true $t }}.QueryRow(func(row pgx.Row) error {
+		if err := row.Scan({{ names (print "&" (short $t) ".") (sequence_fields $t) }}); err != nil {
+			return logerror(err)
+		}
+		{{ short $t }}._exists = true
+		return nil
+	})
+{{- end }}
+	return nil
+}
+
+{{ if ne (len $t.Fields) (len $t.PrimaryKeys) -}}
+// QueueUpdate queues updating the {{ $t.GoName }} in the database in the batch.
+func ({{ short $t }} *{{ $t.GoName }}) QueueUpdate(batch *pgx.Batch) error {
+	switch {
+	case !{{ short $t }}._exists: // doesn't exist
+		return logerror(&ErrUpdateFailed{ErrDoesNotExist})
+	case {{ short $t }}._deleted: // deleted
+		return logerror(&ErrUpdateFailed{ErrMarkedForDeletion})
+	}
+{{ with audit $t "update" }}{{ . }}{{ end -}}
+	// update with primary key
+	{{ sqlstr "update" $t }}
+	// queue
+	{{ logf_update $t }}
+	{{ db_update "Queue" $t }}.Exec(func({{ if $t.Version }}res {{ end }}pgconn.CommandTag) error {
+{{- with $t.Version }}
+		// check version
+		if res.RowsAffected() == 0 {
+			return logerror(&ErrUpdateFailed{ErrVersionConflict})
+		}
+		{{ short $t }}.{{ .GoName }}++
+{{- end }}
+		return nil
+	})
+	return nil
+}
+
+{{ end -}}
+// QueueDelete queues {{ with $t.SoftDelete }}soft deleting the {{ $t.GoName }} from the database by setting '{{ .SQLName }}'{{ else }}deleting the {{ $t.GoName }} from the database{{ end }}
+// in the batch. The {{ $t.GoName }} is marked for deletion once the batch results
+// have been read.
+func ({{ short $t }} *{{ $t.GoName }}) QueueDelete(batch *pgx.Batch) {
+	switch {
+	case !{{ short $t }}._exists: // doesn't exist
+		return
+	case {{ short $t }}._deleted: // deleted
+		return
+	}
+	// delete
+	{{ sqlstr "delete" $t }}
+	// queue
+	{{ logf_pkeys $t }}
+	{{ db "Queue" (names (print (short $t) ".") $t.PrimaryKeys) }}.Exec(func(pgconn.CommandTag) error {
+		{{ short $t }}._deleted = true
+		return nil
+	})
+}
+{{ end }}
+{{- end }}
+{{ end }}
+
+{{ define "factory" }}
+{{- $f := .Data -}}
+{{- $t := $f.Table -}}
+// Fake{{ $t.GoName }} returns a {{ $t.GoName }} with fake data, for use in tests.
+//
+// Nullable, generated, and foreign key fields are left unset.
+func Fake{{ $t.GoName }}() *{{ $t.GoName }} {
+{{- if $f.Seq }}
+	n := nextFactorySeq()
+{{- end }}
+	return &{{ $t.GoName }}{
+{{- range $f.Values }}
+		{{ .Field.GoName }}: {{ .Value }},
+{{- end }}
+	}
+}
+{{ if $t.PrimaryKeys }}
+// {{ func_name_context $f }} inserts a {{ $t.GoName }} with fake data to the database, for
+// use in tests. fns are applied to the {{ $t.GoName }} before it is inserted.
+{{- if $f.Refs }}
+//
+// Rows referenced by foreign keys that are left unset are created first.
+{{- end }}
+{{ func_context $f }} {
+	{{ short $t }} := Fake{{ $t.GoName }}()
+	for _, fn := range fns {
+		fn({{ short $t }})
+	}
+{{- range $r := $f.Refs }}
+	// create referenced {{ $r.RefTable }}
+	if {{ factory_unset $r }} {
+		ref, err := {{ func_name_context (print "Create" $r.RefTable) }}({{ if context }}ctx, {{ end }}db)
+		if err != nil {
+			return nil, err
+		}
+{{- range $i, $z := $r.Fields }}
+		{{ short $t }}.{{ $z.GoName }} = ref.{{ (index $r.RefFields $i).GoName }}
+{{- end }}
+	}
+{{- end }}
+	if err := {{ short $t }}.{{ func_name_context "Insert" }}({{ if context }}ctx, {{ end }}db); err != nil {
+		return nil, err
+	}
+	return {{ short $t }}, nil
+}
+
+{{ if context_both -}}
+// {{ func_name $f }} inserts a {{ $t.GoName }} with fake data to the database, for
+// use in tests. fns are applied to the {{ $t.GoName }} before it is inserted.
+{{ func $f }} {
+	return {{ func_name_context $f }}(context.Background(), db, fns...)
+}
+{{- end }}
+
+{{ if repository }}
+{{ repo $f }}
+{{ end }}
+{{- end }}
+{{ end }}
+
+{{ define "testdb" }}
+// testDB returns a transaction for round trip tests, that is rolled back when
+// the test completes.
+//
+// The test is skipped when the XO_TEST_DSN environment variable is not set.
+{{ if pgx -}}
+func testDB(t *testing.T) pgx.Tx {
+	t.Helper()
+	dsn := os.Getenv("XO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("XO_TEST_DSN is not set")
+	}
+	ctx := context.Background()
+	conn, err := pgx.Connect(ctx, dsn)
+	if err != nil {
+		t.Fatalf("expected no error connecting to database, got: %v", err)
+	}
+	t.Cleanup(func() { conn.Close(ctx) })
+	tx, err := conn.Begin(ctx)
+	if err != nil {
+		t.Fatalf("expected no error beginning transaction, got: %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback(ctx) })
+	return tx
+}
+{{- else -}}
+func testDB(t *testing.T) *sql.Tx {
+	t.Helper()
+	dsn := os.Getenv("XO_TEST_DSN")
+	if dsn == "" {
+		t.Skip("XO_TEST_DSN is not set")
+	}
+	db, err := sql.Open("{{ test_driver }}", dsn)
+	if err != nil {
+		t.Fatalf("expected no error opening database, got: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("expected no error beginning transaction, got: %v", err)
+	}
+	t.Cleanup(func() { tx.Rollback() })
+	return tx
+}
+{{- end }}
+{{ end }}
+
+{{ define "roundtrip" }}
+{{- $r := .Data -}}
+{{- $t := $r.Table -}}
+// {{ $r.GoName }} inserts, gets, updates and deletes a {{ $t.GoName }}.
+func {{ $r.GoName }}(t *testing.T) {
+	db := testDB(t)
+{{- if context }}
+	ctx := context.Background()
+{{- end }}
+	// insert
+	v, err := {{ func_name_context (print "Create" $t.GoName) }}({{ if context }}ctx, {{ end }}db)
+	if err != nil {
+		t.Fatalf("expected no error inserting, got: %v", err)
+	}
+	if !v.Exists() {
+		t.Errorf("expected inserted {{ $t.GoName }} to exist")
+	}
+{{- if $r.Lookup.Func }}
+	// get
+	if _, err := {{ func_name_context $r.Lookup }}({{ if context }}ctx, {{ end }}db{{ range $r.Lookup.Fields }}, v.{{ .GoName }}{{ end }}); err != nil {
+		t.Fatalf("expected no error getting, got: %v", err)
+	}
+{{- end }}
+{{- if ne (len $t.Fields) (len $t.PrimaryKeys) }}
+	// update
+	if err := v.{{ func_name_context "Update" }}({{ if context }}ctx, {{ end }}db); err != nil {
+		t.Fatalf("expected no error updating, got: %v", err)
+	}
 {{- end }}
+	// delete
+	if err := v.{{ func_name_context "Delete" }}({{ if context }}ctx, {{ end }}db); err != nil {
+		t.Fatalf("expected no error deleting, got: %v", err)
+	}
+	if !v.Deleted() {
+		t.Errorf("expected deleted {{ $t.GoName }} to be marked for deletion")
+	}
+{{- if $r.Lookup.Func }}
+{{- $noRows := "sql.ErrNoRows" }}{{ if pgx }}{{ $noRows = "pgx.ErrNoRows" }}{{ end }}
+	if _, err := {{ func_name_context $r.Lookup }}({{ if context }}ctx, {{ end }}db{{ range $r.Lookup.Fields }}, v.{{ .GoName }}{{ end }}); !errors.Is(err, {{ $noRows }}) {
+		t.Errorf("expected {{ $noRows }} getting deleted {{ $t.GoName }}, got: %v", err)
+	}
+{{- end }}
+}
 {{ end }}
@@ -0,0 +1,1055 @@
+package templates_test
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/xo/xo/cmd"
+	"github.com/xo/xo/templates/templatetest"
+	xo "github.com/xo/xo/types"
+)
+
+func TestGolden(t *testing.T) {
+	for _, name := range []string{"go", "createdb", "json", "yaml", "dot", "python"} {
+		ts, err := cmd.NewTemplateSet(context.Background(), "", name)
+		if err != nil {
+			t.Fatalf("template %s expected no error, got: %v", name, err)
+		}
+		templatetest.Golden(t, ts, filepath.Join("testdata", "golden", name))
+	}
+}
+
+func TestGoldenSoftDelete(t *testing.T) {
+	for _, name := range []string{"go", "python"} {
+		ts, err := cmd.NewTemplateSet(context.Background(), "", name)
+		if err != nil {
+			t.Fatalf("template %s expected no error, got: %v", name, err)
+		}
+		fixture := templatetest.Fixtures()[4]
+		templatetest.GoldenFixture(t, ts, filepath.Join("testdata", "golden", name+"_soft_delete"), fixture, "--"+name+"-soft-delete-column=deleted_at")
+	}
+}
+
+func TestGoldenFactories(t *testing.T) {
+	ts, err := cmd.NewTemplateSet(context.Background(), "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the enums fixture's factories only set enum fields
+	fixture := templatetest.Fixtures()[0]
+	templatetest.GoldenFixture(t, ts, filepath.Join("testdata", "golden", "go_factories"), fixture, "--with-factories")
+}
+
+func TestGoldenMock(t *testing.T) {
+	ts, err := cmd.NewTemplateSet(context.Background(), "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the composite_keys fixture's Member has a DeleteMember(ctx, m *Member)
+	fixture := templatetest.Fixtures()[2]
+	templatetest.GoldenFixture(t, ts, filepath.Join("testdata", "golden", "go_mock"), fixture, "--go-mock")
+}
+
+func TestPartialIndexSoftDelete(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[4]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-soft-delete-column=deleted_at")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["user.xo.go"])
+	tests := []struct {
+		exp  string
+		want bool
+	}{
+		// predicate on the soft delete column
+		{"WHERE email = $1 AND ((deleted_at IS NULL))`", true},
+		{"func UserByEmailIncludeDeleted(", false},
+		// predicate on another column
+		{"WHERE team_id = $1 AND ((team_id IS NOT NULL)) AND deleted_at IS NULL`", true},
+		{"WHERE team_id = $1 AND ((team_id IS NOT NULL))`", true},
+		{"func UsersByTeamIDIncludeDeleted(", true},
+	}
+	for i, test := range tests {
+		if strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d expected contains %q to be %t, got:\n%s", i, test.exp, test.want, s)
+		}
+	}
+	if n := strings.Count(s, "deleted_at IS NULL"); n != 3 {
+		t.Errorf("expected deleted_at IS NULL 3 times, got: %d", n)
+	}
+}
+
+func TestVersion(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[4]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-version-column=version")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["user.xo.go"])
+	tests := []struct {
+		exp  string
+		want bool
+	}{
+		{"version = version + 1 ", true},
+		{"WHERE user_id = $4 AND version = $5", true},
+		{"return logerror(&ErrUpdateFailed{ErrVersionConflict})", true},
+		// upsert is exempt from the version check
+		{"// Unlike Update, the upsert does not check 'version' for conflicts", true},
+		{"version = EXCLUDED.version", true},
+	}
+	for i, test := range tests {
+		if strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d expected contains %q to be %t, got:\n%s", i, test.exp, test.want, s)
+		}
+	}
+	// non-integer and nullable version columns
+	for i, column := range []string{"email", "team_id"} {
+		fixture := templatetest.Fixtures()[4]
+		if _, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-version-column="+column); err == nil {
+			t.Errorf("test %d (%s) expected error, got nil", i, column)
+		}
+	}
+}
+
+func TestFactoryCycles(t *testing.T) {
+	ctx := context.Background()
+	// table returns a table with a primary key, and a not null foreign key
+	// for each of refs
+	table := func(name string, refs ...string) xo.Table {
+		id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+		t := xo.Table{Type: "table", Name: name, Columns: []xo.Field{id}, PrimaryKeys: []xo.Field{id}}
+		for _, ref := range refs {
+			f := xo.Field{Name: ref + "_id", Type: xo.Type{Type: "integer"}}
+			t.Columns = append(t.Columns, f)
+			t.ForeignKeys = append(t.ForeignKeys, xo.ForeignKey{
+				Name:      name + "_" + ref + "_id_fkey",
+				Fields:    []xo.Field{f},
+				RefTable:  ref,
+				RefFields: []xo.Field{id},
+				Func:      ref + "_by_id",
+				RefFunc:   ref + "_by_id",
+			})
+		}
+		return t
+	}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Tables: []xo.Table{
+			table("a", "b"),
+			table("b", "a"),
+			table("c", "c"),
+			table("d", "a", "c"),
+		},
+	}}}
+	tests := []struct {
+		template string
+		file     string
+		create   string
+	}{
+		{"go", "factory.xo.go", "ref, err := Create%s(ctx, db)"},
+		{"python", "factory.py", "ref = create_%s(db, ctx=ctx)"},
+	}
+	for i, test := range tests {
+		ts, err := cmd.NewTemplateSet(ctx, "", test.template)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.template, err)
+		}
+		files, err := templatetest.Generate(ctx, ts, "postgres", set, "--with-factories")
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.template, err)
+		}
+		s := string(files[test.file])
+		for _, ref := range []struct {
+			name string
+			want bool
+		}{
+			{"a", true},
+			{"b", false},
+			{"c", true},
+		} {
+			name := ref.name
+			if test.template == "go" {
+				name = strings.ToUpper(name)
+			}
+			if exp := fmt.Sprintf(test.create, name); strings.Contains(s, exp) != ref.want {
+				t.Errorf("test %d (%s) expected contains %q to be %t, got:\n%s", i, test.template, exp, ref.want, s)
+			}
+		}
+		// only d refers to a and c
+		if n := strings.Count(s, strings.SplitN(test.create, "%s", 2)[0]); n != 2 {
+			t.Errorf("test %d (%s) expected 2 referenced rows created, got: %d\n%s", i, test.template, n, s)
+		}
+	}
+}
+
+func TestSequences(t *testing.T) {
+	ctx := context.Background()
+	id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true, IsIdentity: true, IsIdentityAlways: true}
+	ticket := xo.Field{Name: "ticket", Type: xo.Type{Type: "integer"}, IsSequence: true, SequenceName: "orders_ticket_seq"}
+	number := xo.Field{Name: "number", Type: xo.Type{Type: "bigint"}, IsSequence: true, IsIdentity: true}
+	set := func() *xo.Set {
+		return &xo.Set{Schemas: []xo.Schema{{
+			Driver: "postgres",
+			Name:   "public",
+			Tables: []xo.Table{{
+				Type:        "table",
+				Name:        "orders",
+				Columns:     []xo.Field{id, ticket, number, {Name: "note", Type: xo.Type{Type: "text"}}},
+				PrimaryKeys: []xo.Field{id},
+			}},
+		}}}
+	}
+	tests := []struct {
+		template string
+		file     string
+		exp      string
+	}{
+		{"go", "order.xo.go", "`INSERT INTO public.orders (` +\n\t\t`note` +\n\t\t`) VALUES (` +\n\t\t`$1` +\n\t\t`) RETURNING id, ticket, number`"},
+		{"go", "order.xo.go", ".Scan(&o.ID, &o.Ticket, &o.Number); err != nil {"},
+		{"python", "order.py", "\"INSERT INTO public.orders (\"\n            \"note\"\n            \") VALUES (\"\n            \"%s\"\n            \") RETURNING id, ticket, number\""},
+		{"python", "order.py", "self.id, self.ticket, self.number = row"},
+		{"createdb", "xo.xo.sql", "id INTEGER GENERATED ALWAYS AS IDENTITY,"},
+		{"createdb", "xo.xo.sql", "ticket SERIAL,"},
+		{"createdb", "xo.xo.sql", "number BIGINT GENERATED BY DEFAULT AS IDENTITY,"},
+	}
+	for i, test := range tests {
+		ts, err := cmd.NewTemplateSet(ctx, "", test.template)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		files, err := templatetest.Generate(ctx, ts, "postgres", set())
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := string(files[test.file]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d (%s) expected %s to contain %q, got:\n%s", i, test.template, test.file, test.exp, s)
+		}
+	}
+}
+
+func TestGeneratedColumns(t *testing.T) {
+	ctx := context.Background()
+	id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+	set := func() *xo.Set {
+		return &xo.Set{Schemas: []xo.Schema{{
+			Driver: "postgres",
+			Name:   "public",
+			Tables: []xo.Table{{
+				Type: "table",
+				Name: "items",
+				Columns: []xo.Field{
+					id,
+					{Name: "price", Type: xo.Type{Type: "integer"}},
+					{Name: "total", Type: xo.Type{Type: "integer"}, IsGenerated: true},
+				},
+				PrimaryKeys: []xo.Field{id},
+			}},
+		}}}
+	}
+	tests := []struct {
+		template string
+		file     string
+		exp      string
+		want     bool
+	}{
+		{"go", "item.xo.go", "`price` +", true},
+		{"go", "item.xo.go", "total = ", false},
+		{"python", "item.py", "\"price\"\n", true},
+		{"python", "item.py", "args = (self.price,)", true},
+		{"python", "item.py", "total = ", false},
+		{"python", "item.py", "total=row[2],", true},
+	}
+	for i, test := range tests {
+		ts, err := cmd.NewTemplateSet(ctx, "", test.template)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		files, err := templatetest.Generate(ctx, ts, "postgres", set())
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		if s := string(files[test.file]); strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d (%s) expected %s contains %q to be %t, got:\n%s", i, test.template, test.file, test.exp, test.want, s)
+		}
+	}
+}
+
+func TestAudit(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[1]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-created-column=created_at", "--go-updated-column=updated_at")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["book.xo.go"])
+	tests := []struct {
+		exp  string
+		want bool
+	}{
+		{"if b.CreatedAt.IsZero() {\n\t\tb.CreatedAt = now\n\t}", true},
+		{"b.UpdatedAt = sql.NullTime{Time: now, Valid: true}", true},
+		{"published = $4, updated_at = $5 ", true},
+		{"created_at = $", false},
+		{"created_at = EXCLUDED.created_at", false},
+	}
+	for i, test := range tests {
+		if strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d expected contains %q to be %t, got:\n%s", i, test.exp, test.want, s)
+		}
+	}
+	if n := strings.Count(s, "now := time.Now()"); n != 3 {
+		t.Errorf("expected audit fields set in insert, update and upsert, got: %d", n)
+	}
+}
+
+func TestPythonAudit(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for driver, typ := range map[string]string{
+		"postgres":  "timestamp with time zone",
+		"mysql":     "datetime",
+		"sqlserver": "datetime2",
+	} {
+		fixture := templatetest.Fixtures()[1]
+		for _, table := range fixture.Set.Schemas[0].Tables {
+			for i := range table.Columns {
+				if table.Columns[i].Type.Type == "timestamp with time zone" {
+					table.Columns[i].Type.Type = typ
+				}
+			}
+		}
+		files, err := templatetest.Generate(ctx, ts, driver, fixture.Set, "--python-created-column=created_at", "--python-updated-column=updated_at")
+		if err != nil {
+			t.Fatalf("expected no error, got: %v", err)
+		}
+		s := string(files["book.py"])
+		tests := []struct {
+			exp  string
+			want bool
+		}{
+			{"        if self.created_at == datetime.datetime.min:\n            self.created_at = now\n", true},
+			{"        self.updated_at = now\n", true},
+			{", created_at = ", false},
+			{"\"created_at = ", false},
+			{"created_at = EXCLUDED.created_at", false},
+			{"created_at = VALUES(created_at)", false},
+			{"t.created_at = s.created_at", false},
+		}
+		for i, test := range tests {
+			if strings.Contains(s, test.exp) != test.want {
+				t.Errorf("%s test %d expected contains %q to be %t, got:\n%s", driver, i, test.exp, test.want, s)
+			}
+		}
+		if n := strings.Count(s, "now = datetime.datetime.now(datetime.timezone.utc)"); n != 3 {
+			t.Errorf("%s expected audit fields set in insert, update and upsert, got: %d", driver, n)
+		}
+		if n := strings.Count(s, "self.created_at = now"); n != 2 {
+			t.Errorf("%s expected created field set in insert and upsert, got: %d", driver, n)
+		}
+	}
+}
+
+func TestTenant(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[1]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--tenant=public")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		file string
+		exp  string
+		want bool
+	}{
+		{"author.xo.go", "// Author represents a row from 'public.authors'.", true},
+		{"author.xo.go", "INSERT INTO {tenant}.authors (", true},
+		{"author.xo.go", "INSERT INTO public.authors (", false},
+		{"book.xo.go", "FROM {tenant}.books ", true},
+		{"db.xo.go", "func Tenant(db DB, schema string) (*TenantDB, error) {", true},
+	}
+	for i, test := range tests {
+		if s := string(files[test.file]); strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d (%s) expected contains %q to be %t, got:\n%s", i, test.file, test.exp, test.want, s)
+		}
+	}
+	if _, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--tenant=public", "--go-prepare"); err == nil {
+		t.Errorf("expected error with --go-prepare, got nil")
+	}
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[1]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-otel")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		file string
+		exp  string
+	}{
+		{"author.xo.go", `ctx = withQuery(ctx, "Author.Insert", "authors", "insert")`},
+		{"author.xo.go", `ctx = withQuery(ctx, "AuthorByAuthorID", "authors", "select")`},
+		{"author.xo.go", "if err := queryRow(ctx, db, sqlstr, authorID).Scan("},
+		{"db.xo.go", "func Hooked(db DB, hook QueryHook) *HookedDB {"},
+		{"db.xo.go", "func NewOtelHook(tracer trace.Tracer) *OtelHook {"},
+		{"db.xo.go", `attribute.String("db.system", "postgresql")`},
+	}
+	for i, test := range tests {
+		if s := string(files[test.file]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d (%s) expected to contain %q, got:\n%s", i, test.file, test.exp, s)
+		}
+	}
+	if _, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-hooks", "--go-context=disable"); err == nil {
+		t.Errorf("expected error with --go-context=disable, got nil")
+	}
+	if _, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--go-hooks", "--go-repository"); err == nil {
+		t.Errorf("expected error with --go-repository, got nil")
+	}
+}
+
+func TestPythonHooks(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[4]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--python-hooks", "--python-soft-delete-column=deleted_at")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		file string
+		exp  string
+	}{
+		{"user.py", `with cursor(db, ctx, QueryInfo("User.insert", "users", "insert", sqlstr)) as cur:`},
+		{"user.py", `with cursor(db, ctx, QueryInfo("User.delete", "users", "update", sqlstr)) as cur:`},
+		{"user.py", `with cursor(db, ctx, QueryInfo("user_by_user_id", "users", "select", sqlstr)) as cur:`},
+		{"user.py", "MarkedForDeletionError, QueryInfo, cursor, logf\n"},
+		{"utils.py", "class QueryHook(Protocol):\n"},
+		{"utils.py", "def _cursor(db: DB, ctx: Optional[Context] = None) -> Iterator[Cursor]:\n"},
+		{"utils.py", "def cursor(db: DB, ctx: Optional[Context] = None, q: Optional[QueryInfo] = None) -> Iterator[Cursor]:\n"},
+	}
+	for i, test := range tests {
+		if s := string(files[test.file]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d (%s) expected to contain %q, got:\n%s", i, test.file, test.exp, s)
+		}
+	}
+}
+
+func TestPost(t *testing.T) {
+	for _, name := range []string{"tr", "false"} {
+		if _, err := exec.LookPath(name); err != nil {
+			t.Skipf("%s not available: %v", name, err)
+		}
+	}
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[1]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--post=.py=tr a-z A-Z")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for _, file := range []string{"author.py", "book.py", "utils.py"} {
+		if s := string(files[file]); s == "" || s != strings.ToUpper(s) {
+			t.Errorf("expected %s to be post processed, got:\n%s", file, s)
+		}
+	}
+	_, err = templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--post=.py=false")
+	// an error is reported for each file
+	if err == nil || err.Error() != "4 errors encountered" {
+		t.Errorf("expected 4 errors, got: %v", err)
+	}
+}
+
+func TestDeterministic(t *testing.T) {
+	tests := []struct {
+		template string
+		args     []string
+	}{
+		{"go", nil},
+		{"go", []string{"--single=models.xo.go"}},
+		{"go", []string{"--go-querier", "--go-prepare"}},
+		{"createdb", nil},
+		{"json", nil},
+		{"yaml", nil},
+		{"dot", nil},
+		{"python", nil},
+		{"python", []string{"--single=models.py"}},
+		{"python", []string{"--python-paginate", "--python-stream", "--python-filter", "--python-repository", "--with-tests"}},
+	}
+	ctx := context.Background()
+	for i, test := range tests {
+		ts, err := cmd.NewTemplateSet(ctx, "", test.template)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.template, err)
+		}
+		// generate each fixture twice, from new sets
+		for j := range templatetest.Fixtures() {
+			var prev map[string][]byte
+			for n := 0; n < 2; n++ {
+				fixture := templatetest.Fixtures()[j]
+				files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, append([]string{"--jobs=4"}, test.args...)...)
+				if err != nil {
+					t.Fatalf("test %d (%s) fixture %s expected no error, got: %v", i, test.template, fixture.Name, err)
+				}
+				if prev != nil && !reflect.DeepEqual(files, prev) {
+					t.Errorf("test %d (%s) fixture %s expected identical output for consecutive runs", i, test.template, fixture.Name)
+				}
+				prev = files
+			}
+		}
+	}
+}
+
+func TestPythonCompile(t *testing.T) {
+	if _, err := exec.LookPath("python3"); err != nil {
+		t.Skipf("python3 not available: %v", err)
+	}
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	args := []string{"--python-paginate", "--python-stream", "--python-filter", "--python-repository", "--python-rls", "--python-hooks", "--python-created-column=created_at", "--python-updated-column=updated_at", "--with-tests"}
+	for _, driver := range []string{"postgres", "mysql", "sqlite3", "sqlserver", "oracle"} {
+		for _, fixture := range templatetest.Fixtures() {
+			files, err := templatetest.Generate(ctx, ts, driver, fixture.Set, args...)
+			if err != nil {
+				t.Fatalf("%s fixture %s expected no error, got: %v", driver, fixture.Name, err)
+			}
+			dir := t.TempDir()
+			if err := writeFiles(dir, files); err != nil {
+				t.Fatalf("%s fixture %s expected no error, got: %v", driver, fixture.Name, err)
+			}
+			cmd := exec.Command("python3", "-m", "compileall", "-q", dir)
+			if out, err := cmd.CombinedOutput(); err != nil {
+				t.Errorf("%s fixture %s expected no error, got: %v\n%s", driver, fixture.Name, err, out)
+			}
+		}
+	}
+}
+
+func TestGoVet(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skipf("go not available: %v", err)
+	}
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "go")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	// the output is written inside the module, so that it can import the
+	// module's dependencies
+	dir, err := os.MkdirTemp("testdata", "vet")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"default", nil},
+		{"factories", []string{"--with-factories"}},
+		{"tests", []string{"--with-tests"}},
+		{"querier", []string{"--go-querier", "--go-prepare"}},
+		{"mock", []string{"--go-mock"}},
+		{"repository", []string{"--go-repository"}},
+		{"dir", []string{"--go-schema-layout=dir"}},
+	}
+	for _, test := range tests {
+		for _, fixture := range templatetest.Fixtures() {
+			files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, test.args...)
+			if err != nil {
+				t.Fatalf("%s fixture %s expected no error, got: %v", test.name, fixture.Name, err)
+			}
+			if err := writeFiles(filepath.Join(dir, test.name, fixture.Name), files); err != nil {
+				t.Fatalf("%s fixture %s expected no error, got: %v", test.name, fixture.Name, err)
+			}
+		}
+	}
+	cmd := exec.Command("go", "vet", "./...")
+	cmd.Dir = dir
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Errorf("expected no error, got: %v\n%s", err, out)
+	}
+}
+
+// writeFiles writes the files to dir.
+func writeFiles(dir string, files map[string][]byte) error {
+	for name, buf := range files {
+		name = filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, buf, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestQuerySets(t *testing.T) {
+	field := func(name, typ string) xo.Field {
+		return xo.Field{Name: name, Type: xo.Type{Type: typ}}
+	}
+	set := func(driver string) *xo.Set {
+		return &xo.Set{
+			Queries: []xo.Query{{
+				Driver: driver,
+				Type:   "AuthorBooks",
+				Params: []xo.Field{field("authorID", "int")},
+				Query: []string{
+					"SELECT author_id, name FROM authors WHERE author_id = $1;",
+					"SELECT book_id, title FROM books WHERE author_id = $1",
+				},
+				ResultSets: [][]xo.Field{
+					{field("author_id", "integer"), field("name", "text")},
+					{field("book_id", "integer"), field("title", "text")},
+				},
+			}},
+		}
+	}
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	files, err := templatetest.GenerateQuery(ctx, ts, "postgres", set("postgres"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["author_books.py"])
+	for i, exp := range []string{
+		"class AuthorBooksSet1:",
+		"set1: list[AuthorBooksSet1] = field(default_factory=list)",
+		"def author_books_by_author_id(db: DB, author_id: int, *, ctx: Optional[Context] = None) -> AuthorBooks:",
+		"    res = AuthorBooks()\n",
+		"        res.set1 = [AuthorBooksSet1._from_row(row) for row in cur.fetchall()]\n" +
+			"        if not cur.nextset():\n" +
+			"            raise Error(\"missing result set set2\")\n" +
+			"        res.set2 = [AuthorBooksSet2._from_row(row) for row in cur.fetchall()]\n" +
+			"    return res\n",
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("test %d expected author_books.py to contain %q, got:\n%s", i, exp, s)
+		}
+	}
+	if _, err := templatetest.GenerateQuery(ctx, ts, "sqlite3", set("sqlite3")); err == nil {
+		t.Errorf("expected error with sqlite3, got nil")
+	}
+}
+
+func TestPythonFilter(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[4]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--python-filter", "--python-soft-delete-column=deleted_at")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["user.py"])
+	tests := []struct {
+		exp  string
+		want bool
+	}{
+		{"def find_users(\n    db: DB,\n    *,\n", true},
+		{"    email__in: Optional[Iterable[str]] = None,\n", true},
+		// only nullable columns are filtered by NULL
+		{"    team_id__is_null: Optional[bool] = None,\n", true},
+		{"    email__is_null:", false},
+		// unindexed columns are not filtered
+		{"    version: Optional[int] = None,\n", false},
+		{"    where = FilterClause(\"deleted_at IS NULL\")\n", true},
+		{"    where.add(\"email\", \"IN\", email__in)\n", true},
+		{"    ) + where.clause()\n", true},
+	}
+	for i, test := range tests {
+		if strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d expected contains %q to be %t, got:\n%s", i, test.exp, test.want, s)
+		}
+	}
+	if !strings.Contains(string(files["utils.py"]), "class FilterClause:") {
+		t.Errorf("expected utils.py to contain FilterClause")
+	}
+}
+
+func TestPythonSensitive(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	fixture := templatetest.Fixtures()[1]
+	files, err := templatetest.Generate(ctx, ts, fixture.Driver, fixture.Set, "--python-filter", "--python-paginate")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		exp  string
+		want bool
+	}{
+		{"author.py", "        return f\"Author(author_id={self.author_id!r}, name='[redacted]')\"\n", true},
+		{"author.py", "        logf(sqlstr, \"[redacted]\", args[1])\n", true},
+		{"author.py", "    logf(sqlstr, \"[redacted]\")\n", true},
+		// filter args are not logged
+		{"author.py", "    logf(sqlstr)  # args may contain sensitive fields\n", true},
+		// pages are ordered by the primary key, which is not sensitive
+		{"author.py", "    logf(sqlstr)  # args contain sensitive fields\n", false},
+		{"book.py", "def __repr__(", false},
+	}
+	for i, test := range tests {
+		if s := string(files[test.name]); strings.Contains(s, test.exp) != test.want {
+			t.Errorf("test %d expected %s contains %q to be %t, got:\n%s", i, test.name, test.exp, test.want, s)
+		}
+	}
+}
+
+func TestPythonJSONType(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	set := func(typ string) *xo.Set {
+		id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+		return &xo.Set{Schemas: []xo.Schema{{
+			Driver: "postgres",
+			Name:   "public",
+			Tables: []xo.Table{{
+				Type:        "table",
+				Name:        "users",
+				PrimaryKeys: []xo.Field{id},
+				Columns: []xo.Field{
+					id,
+					{Name: "settings", Type: xo.Type{Type: "jsonb"}, JSONType: typ},
+					{Name: "extra", Type: xo.Type{Type: "jsonb", Nullable: true}, JSONType: "Extra"},
+				},
+			}},
+		}}}
+	}
+	files, err := templatetest.Generate(ctx, ts, "postgres", set("myapp.prefs.Settings"), "--python-json-module=myapp.types")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	s := string(files["user.py"])
+	for i, exp := range []string{
+		"from myapp.prefs import Settings\nfrom myapp.types import Extra\n",
+		"    settings: Settings = field(default_factory=Settings)\n",
+		"    extra: Optional[Extra] = None\n",
+		"args = (encode_json(self.settings), encode_json(self.extra) if self.extra is not None else None)",
+		"            settings=decode_json(Settings, row[1]),\n",
+		"            extra=decode_json(Extra, row[2]) if row[2] is not None else None,\n",
+	} {
+		if !strings.Contains(s, exp) {
+			t.Errorf("test %d expected user.py to contain %q, got:\n%s", i, exp, s)
+		}
+	}
+	if !strings.Contains(string(files["utils.py"]), "def decode_json(") {
+		t.Errorf("expected utils.py to contain decode_json")
+	}
+	// unqualified types need a module
+	if _, err := templatetest.Generate(ctx, ts, "postgres", set("Settings")); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestPythonSpatial(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Tables: []xo.Table{{
+			Type:        "table",
+			Name:        "places",
+			PrimaryKeys: []xo.Field{id},
+			Columns: []xo.Field{
+				id,
+				{Name: "location", Type: xo.Type{Type: "geometry", Geometry: "point", SRID: 4326}},
+				{Name: "area", Type: xo.Type{Type: "geography", Nullable: true}},
+			},
+		}},
+	}}}
+	files, err := templatetest.Generate(ctx, ts, "postgres", set, "--with-factories")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []struct {
+		name string
+		exp  string
+	}{
+		{"place.py", "from .utils import DB, AlreadyExistsError, Context, DoesNotExistError, Geometry,"},
+		{"place.py", "    location: Geometry = field(default_factory=Geometry)\n"},
+		{"place.py", "    area: Optional[Geometry] = None\n"},
+		{"place.py", "args = (self.location.to_db(), self.area.to_db() if self.area is not None else None)"},
+		{"place.py", "            location=Geometry.from_db(row[1]),\n"},
+		{"place.py", "            area=Geometry.from_db(row[2]) if row[2] is not None else None,\n"},
+		{"factory.py", "        location=Geometry.point(float(n), float(n), 4326),\n"},
+		{"utils.py", "class Geometry:\n"},
+	} {
+		if s := string(files[test.name]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected %s to contain %q, got:\n%s", i, test.name, test.exp, s)
+		}
+	}
+	// no spatial columns
+	files, err = templatetest.Generate(ctx, ts, "postgres", templatetest.Fixtures()[0].Set)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := string(files["utils.py"]); strings.Contains(s, "class Geometry") {
+		t.Errorf("expected utils.py to not contain Geometry")
+	}
+}
+
+func TestPythonPgTypes(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Composites: []xo.Composite{{Name: "address", Fields: []xo.Field{
+			{Name: "street", Type: xo.Type{Type: "text", Nullable: true}},
+			{Name: "since", Type: xo.Type{Type: "date", Nullable: true}},
+		}}},
+		Ranges: []xo.Range{{Name: "floatrange", SubType: xo.Type{Type: "double precision"}}},
+		Tables: []xo.Table{{
+			Type:        "table",
+			Name:        "bookings",
+			PrimaryKeys: []xo.Field{id},
+			Columns: []xo.Field{
+				id,
+				{Name: "during", Type: xo.Type{Type: "tstzrange"}},
+				{Name: "weights", Type: xo.Type{Type: "floatrange", Nullable: true}},
+				{Name: "address", Type: xo.Type{Type: "address"}},
+			},
+		}},
+	}}}
+	files, err := templatetest.Generate(ctx, ts, "postgres", set, "--with-factories")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []struct {
+		name string
+		exp  string
+	}{
+		{"address.py", "    since: Optional[datetime.date] = None\n"},
+		{"address.py", "            since=parse_text(datetime.date, fields[1]),\n"},
+		{"address.py", "        return format_composite(self.street, self.since)\n"},
+		{"floatrange.py", "Floatrange = Range[float]\n"},
+		{"booking.py", "from .address import Address\n"},
+		{"booking.py", "    during: Range[datetime.datetime] = field(default_factory=Range)\n"},
+		{"booking.py", "    weights: Optional[Range[float]] = None\n"},
+		{"booking.py", "args = (self.during.to_db(), self.weights.to_db() if self.weights is not None else None, self.address.to_db())"},
+		{"booking.py", "            during=Range.from_db(row[1], datetime.datetime),\n"},
+		{"booking.py", "            address=Address.from_db(row[3]),\n"},
+		{"factory.py", "        during=Range(_factory_time + datetime.timedelta(minutes=n)),\n"},
+		{"utils.py", "class Range(Generic[T]):\n"},
+	} {
+		if s := string(files[test.name]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected %s to contain %q, got:\n%s", i, test.name, test.exp, s)
+		}
+	}
+}
+
+func TestPythonRefresh(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	bookID := xo.Field{Name: "book_id", Type: xo.Type{Type: "integer"}}
+	view := func(name string, indexes ...xo.Index) xo.Table {
+		return xo.Table{
+			Type: "matview",
+			Name: name,
+			Columns: []xo.Field{
+				bookID,
+				{Name: "reviews", Type: xo.Type{Type: "bigint"}},
+			},
+			Indexes: indexes,
+		}
+	}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		MatViews: []xo.Table{
+			view("book_stats", xo.Index{Name: "book_stats_book_id_idx", Func: "BookStatByBookID", Fields: []xo.Field{bookID}, IsUnique: true}),
+			view("review_stats"),
+		},
+	}}}
+	files, err := templatetest.Generate(ctx, ts, "postgres", set, "--python-repository")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		name string
+		exp  string
+		not  bool
+	}{
+		{"book_stat.py", "def refresh_book_stat(db: DB, *, ctx: Optional[Context] = None) -> None:\n", false},
+		{"book_stat.py", `sqlstr = "REFRESH MATERIALIZED VIEW public.book_stats"`, false},
+		{"book_stat.py", `sqlstr = "REFRESH MATERIALIZED VIEW CONCURRENTLY public.book_stats"`, false},
+		{"review_stat.py", "def refresh_review_stat(", false},
+		{"review_stat.py", "def refresh_review_stat_concurrently(", true},
+		{"repository.py", "    def refresh_book_stat_concurrently(self, *, ctx: Optional[Context] = None) -> None:\n", false},
+		{"repository.py", "        refresh_book_stat_concurrently(self.db, ctx=ctx)\n", false},
+	}
+	for i, test := range tests {
+		if s := string(files[test.name]); strings.Contains(s, test.exp) == test.not {
+			t.Errorf("test %d expected %s to contain %q (%t), got:\n%s", i, test.name, test.exp, !test.not, s)
+		}
+	}
+}
+
+func TestPythonRLS(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	id := xo.Field{Name: "id", Type: xo.Type{Type: "integer"}, IsPrimary: true}
+	table := func(name string, settings ...string) xo.Table {
+		return xo.Table{
+			Type:        "table",
+			Name:        name,
+			Columns:     []xo.Field{id},
+			PrimaryKeys: []xo.Field{id},
+			Policies:    []xo.Policy{{Name: name + "_isolation", Settings: settings}},
+			RowSecurity: true,
+		}
+	}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Tables: []xo.Table{
+			table("accounts", "app.current_tenant"),
+			table("users", "app.current_tenant", "app.user_id"),
+		},
+	}}}
+	for i, test := range []struct {
+		args []string
+		exp  []string
+	}{
+		{nil, nil},
+		{
+			[]string{"--python-rls"},
+			[]string{
+				"def set_app_current_tenant(db: DB, value: str, local: bool = False, *, ctx: Optional[Context] = None) -> None:\n",
+				"    'public.accounts', 'public.users'.\n",
+				`sqlstr = "SELECT set_config('app.current_tenant', %s, %s)"`,
+				"def set_app_user_id(",
+			},
+		},
+	} {
+		files, err := templatetest.Generate(ctx, ts, "postgres", set, test.args...)
+		if err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		s, ok := files["settings.py"]
+		if ok != (test.exp != nil) {
+			t.Fatalf("test %d expected settings.py %t, got: %t", i, test.exp != nil, ok)
+		}
+		for j, exp := range test.exp {
+			if !strings.Contains(string(s), exp) {
+				t.Errorf("test %d.%d expected settings.py to contain %q, got:\n%s", i, j, exp, s)
+			}
+		}
+	}
+}
+
+func TestPythonSqlite3Types(t *testing.T) {
+	ctx := context.Background()
+	ts, err := cmd.NewTemplateSet(ctx, "", "python")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	id := xo.Field{Name: "event_id", Type: xo.Type{Type: "integer"}, IsPrimary: true, IsSequence: true}
+	set := &xo.Set{Schemas: []xo.Schema{{
+		Driver: "sqlite3",
+		Name:   "main",
+		Tables: []xo.Table{{
+			Type:        "table",
+			Name:        "events",
+			PrimaryKeys: []xo.Field{id},
+			Columns: []xo.Field{
+				id,
+				{Name: "day", Type: xo.Type{Type: "date"}},
+				{Name: "created", Type: xo.Type{Type: "datetime", Nullable: true}},
+				{Name: "done", Type: xo.Type{Type: "boolean"}},
+				{Name: "weight", Type: xo.Type{Type: "double precision"}},
+			},
+		}},
+	}}}
+	files, err := templatetest.Generate(ctx, ts, "sqlite3", set, "--with-factories")
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	for i, test := range []struct {
+		name string
+		exp  string
+	}{
+		{"event.py", "    day: datetime.date = datetime.date.min\n"},
+		{"event.py", "    created: Optional[datetime.datetime] = None\n"},
+		{"event.py", "    done: bool = False\n"},
+		{"event.py", "    weight: float = 0.0\n"},
+		{"event.py", "args = (encode_time(self.day), encode_time(self.created) if self.created is not None else None, self.done, self.weight)"},
+		{"event.py", "            day=decode_time(datetime.date, row[1]),\n"},
+		{"event.py", "            done=bool(row[3]),\n"},
+		{"factory.py", "        day=_factory_time.date() + datetime.timedelta(days=n),\n"},
+		{"utils.py", "def decode_time(typ: Any, v: Any) -> Any:\n"},
+	} {
+		if s := string(files[test.name]); !strings.Contains(s, test.exp) {
+			t.Errorf("test %d expected %s to contain %q, got:\n%s", i, test.name, test.exp, s)
+		}
+	}
+}
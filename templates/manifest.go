@@ -0,0 +1,301 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io/fs"
+	"strings"
+	"text/template"
+
+	"github.com/Masterminds/sprig/v3"
+	"github.com/goccy/go-yaml"
+	xo "github.com/xo/xo/types"
+)
+
+// ManifestFile is the name of the manifest file of a template bundle.
+const ManifestFile = "manifest.yaml"
+
+// Manifest describes a template bundle that uses only text/template, and
+// does not require any Go code.
+//
+// Example:
+//
+//	name: kotlin
+//	modes: [schema]
+//	flags:
+//	  - name: package
+//	    type: string
+//	    desc: package name
+//	    default: models
+//	funcs:
+//	  ktType: '{{ if eq .Type "integer" }}Long{{ else }}String{{ end }}'
+//	templates:
+//	  - mode: schema
+//	    each: table
+//	    partial: table
+//	    dest: '{{ camelcase .Name }}.kt'
+type Manifest struct {
+	// Name is the template name.
+	Name string `json:"name,omitempty"`
+	// Modes are the command modes the template is available for.
+	Modes []string `json:"modes,omitempty"`
+	// Flags are additional template flags.
+	Flags []ManifestFlag `json:"flags,omitempty"`
+	// Funcs are additional template funcs, defined as templates executed with
+	// the func's argument.
+	Funcs map[string]string `json:"funcs,omitempty"`
+	// Templates are the templates to emit.
+	Templates []ManifestTemplate `json:"templates,omitempty"`
+}
+
+// ManifestFlag is a template bundle flag.
+type ManifestFlag struct {
+	Name    string   `json:"name,omitempty"`
+	Type    string   `json:"type,omitempty"`
+	Desc    string   `json:"desc,omitempty"`
+	Default string   `json:"default,omitempty"`
+	Short   string   `json:"short,omitempty"`
+	Enums   []string `json:"enums,omitempty"`
+}
+
+// ManifestTemplate is a template emitted by a template bundle.
+type ManifestTemplate struct {
+	// Mode is the command mode the template is emitted for.
+	Mode string `json:"mode,omitempty"`
+	// Each is the type the template is emitted for. One of set, schema,
+	// enum, proc, table, view, matview, or query.
+	Each string `json:"each,omitempty"`
+	// Partial is the name of the template (defined in a .tpl file) to
+	// execute.
+	Partial string `json:"partial,omitempty"`
+	// Dest is a template for the destination file, executed with the same
+	// data as the partial.
+	Dest string `json:"dest,omitempty"`
+}
+
+// LoadManifest loads the manifest for the template bundle in src.
+func LoadManifest(src fs.FS) (*Manifest, error) {
+	buf, err := fs.ReadFile(src, ManifestFile)
+	if err != nil {
+		return nil, err
+	}
+	m := new(Manifest)
+	if err := yaml.UnmarshalWithOptions(buf, m, yaml.Strict()); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFile, err)
+	}
+	return m, nil
+}
+
+// HasManifest determines if src contains a template bundle manifest.
+func HasManifest(src fs.FS) bool {
+	_, err := fs.Stat(src, ManifestFile)
+	return err == nil
+}
+
+// AddManifest adds a target from the template bundle in src to the template
+// set. See Manifest for the format of the bundle's manifest.
+func (ts *Set) AddManifest(ctx context.Context, name string, src fs.FS) (string, error) {
+	m, err := LoadManifest(src)
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	if m.Name != "" {
+		name = m.Name
+	}
+	typ, err := m.TemplateType()
+	if err != nil {
+		return "", fmt.Errorf("%s: %w", name, err)
+	}
+	return ts.addTarget(&Target{
+		Name: name,
+		Type: typ,
+		Src:  src,
+	})
+}
+
+// TemplateType builds the template type for the manifest.
+func (m *Manifest) TemplateType() (xo.TemplateType, error) {
+	modes := m.Modes
+	if len(modes) == 0 {
+		modes = []string{"query", "schema"}
+	}
+	var flags []xo.Flag
+	for _, f := range m.Flags {
+		typ := f.Type
+		if typ == "" {
+			typ = "string"
+		}
+		// check type and default
+		switch typ {
+		case "bool", "int", "string", "[]string", "glob":
+		default:
+			return xo.TemplateType{}, fmt.Errorf("flag %q: unknown type %s", f.Name, typ)
+		}
+		if f.Default != "" {
+			if err := xo.NewValue(typ, "", f.Desc, f.Enums...).Set(f.Default); err != nil {
+				return xo.TemplateType{}, fmt.Errorf("flag %q: invalid default %q: %w", f.Name, f.Default, err)
+			}
+		}
+		flags = append(flags, xo.Flag{
+			ContextKey: xo.ContextKey(f.Name),
+			Type:       typ,
+			Desc:       f.Desc,
+			Default:    f.Default,
+			Short:      f.Short,
+			Enums:      f.Enums,
+		})
+	}
+	// check funcs
+	funcs, err := manifestFuncs(context.Background(), m.Funcs)
+	if err != nil {
+		return xo.TemplateType{}, err
+	}
+	// parse dests
+	dests := make([]*template.Template, len(m.Templates))
+	for i, t := range m.Templates {
+		switch {
+		case t.Partial == "":
+			return xo.TemplateType{}, fmt.Errorf("template %d: partial not specified", i)
+		case t.Dest == "":
+			return xo.TemplateType{}, fmt.Errorf("template %d (%s): dest not specified", i, t.Partial)
+		}
+		switch t.Each {
+		case "", "set", "schema", "enum", "proc", "table", "view", "matview", "query":
+		default:
+			return xo.TemplateType{}, fmt.Errorf("template %d (%s): invalid each %q", i, t.Partial, t.Each)
+		}
+		if dests[i], err = template.New(t.Partial).Funcs(funcs).Parse(t.Dest); err != nil {
+			return xo.TemplateType{}, fmt.Errorf("template %d (%s): invalid dest: %w", i, t.Partial, err)
+		}
+	}
+	return xo.TemplateType{
+		Name:  m.Name,
+		Modes: modes,
+		Flags: flags,
+		Order: func(ctx context.Context, mode string) []string {
+			var order []string
+			for _, t := range m.Templates {
+				if t.Mode == "" || t.Mode == mode {
+					order = append(order, t.Partial)
+				}
+			}
+			return order
+		},
+		Funcs: func(ctx context.Context, _ string) (template.FuncMap, error) {
+			return manifestFuncs(ctx, m.Funcs)
+		},
+		Process: func(ctx context.Context, mode string, set *xo.Set, emit func(xo.Template)) error {
+			funcs, err := manifestFuncs(ctx, m.Funcs)
+			if err != nil {
+				return err
+			}
+			for i, t := range m.Templates {
+				if t.Mode != "" && t.Mode != mode {
+					continue
+				}
+				tpl, err := dests[i].Clone()
+				if err != nil {
+					return err
+				}
+				tpl = tpl.Funcs(funcs)
+				for _, v := range manifestData(set, t.Each) {
+					buf := new(bytes.Buffer)
+					if err := tpl.Execute(buf, v); err != nil {
+						return fmt.Errorf("template %d (%s): unable to build dest: %w", i, t.Partial, err)
+					}
+					dest := strings.TrimSpace(buf.String())
+					emit(xo.Template{
+						Partial:  t.Partial,
+						Dest:     dest,
+						SortName: dest,
+						Data:     v,
+					})
+				}
+			}
+			return nil
+		},
+	}, nil
+}
+
+// manifestData returns the data for each template emitted for the type.
+func manifestData(set *xo.Set, each string) []interface{} {
+	var v []interface{}
+	switch each {
+	case "", "set":
+		v = append(v, set)
+	case "query":
+		for _, q := range set.Queries {
+			v = append(v, q)
+		}
+	}
+	for _, s := range set.Schemas {
+		switch each {
+		case "schema":
+			v = append(v, s)
+		case "enum":
+			for _, e := range s.Enums {
+				v = append(v, e)
+			}
+		case "proc":
+			for _, p := range s.Procs {
+				v = append(v, p)
+			}
+		case "table":
+			for _, t := range s.Tables {
+				v = append(v, t)
+			}
+		case "view":
+			for _, t := range s.Views {
+				v = append(v, t)
+			}
+		case "matview":
+			for _, t := range s.MatViews {
+				v = append(v, t)
+			}
+		}
+	}
+	return v
+}
+
+// manifestFuncs returns the template funcs for a template bundle, adding the
+// funcs defined in the manifest.
+//
+// Includes the sprig text funcs, as well as:
+//
+//	driver - the database driver name
+//	schema - the database schema name
+//	flag   - the value of a template flag
+func manifestFuncs(ctx context.Context, defs map[string]string) (template.FuncMap, error) {
+	driver, _, schema := xo.DriverDbSchema(ctx)
+	funcs := sprig.TxtFuncMap()
+	funcs["driver"] = func() string {
+		return driver
+	}
+	funcs["schema"] = func() string {
+		return schema
+	}
+	funcs["flag"] = func(name string) interface{} {
+		return ctx.Value(xo.ContextKey(name))
+	}
+	// manifest funcs are defined after all funcs have been added, so that
+	// they can call each other
+	tpls := make(map[string]*template.Template, len(defs))
+	for name := range defs {
+		name := name
+		funcs[name] = func(v interface{}) (string, error) {
+			buf := new(bytes.Buffer)
+			if err := tpls[name].Execute(buf, v); err != nil {
+				return "", err
+			}
+			return buf.String(), nil
+		}
+	}
+	for name, src := range defs {
+		var err error
+		if tpls[name], err = template.New(name).Funcs(funcs).Parse(src); err != nil {
+			return nil, fmt.Errorf("func %s: %w", name, err)
+		}
+	}
+	return funcs, nil
+}
@@ -0,0 +1,143 @@
+package templates
+
+import (
+	"context"
+	"testing"
+	"testing/fstest"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestManifest(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		mode     string
+		exp      []string
+		err      bool
+	}{
+		{
+			name:     "set",
+			manifest: "templates:\n  - partial: all\n    dest: all.txt\n",
+			mode:     "schema",
+			exp:      []string{"all:all.txt"},
+		},
+		{
+			name:     "each table",
+			manifest: "templates:\n  - each: table\n    partial: table\n    dest: '{{ camelcase .Name }}.kt'\n",
+			mode:     "schema",
+			exp:      []string{"table:Authors.kt", "table:BookTags.kt"},
+		},
+		{
+			name:     "manifest func",
+			manifest: "funcs:\n  file: '{{ snakecase . }}.py'\ntemplates:\n  - each: view\n    partial: view\n    dest: '{{ file .Name }}'\n",
+			mode:     "schema",
+			exp:      []string{"view:author_books.py"},
+		},
+		{
+			name:     "each matview",
+			manifest: "templates:\n  - each: matview\n    partial: matview\n    dest: '{{ .Name }}.sql'\n",
+			mode:     "schema",
+			exp:      []string{"matview:book_stats.sql"},
+		},
+		{
+			name:     "mode",
+			manifest: "templates:\n  - mode: query\n    each: query\n    partial: query\n    dest: '{{ .Name }}.sql'\n  - mode: schema\n    each: schema\n    partial: schema\n    dest: schema.txt\n",
+			mode:     "schema",
+			exp:      []string{"schema:schema.txt"},
+		},
+		{
+			name:     "invalid each",
+			manifest: "templates:\n  - each: column\n    partial: column\n    dest: column.txt\n",
+			err:      true,
+		},
+		{
+			name:     "no dest",
+			manifest: "templates:\n  - partial: all\n",
+			err:      true,
+		},
+		{
+			name:     "invalid func",
+			manifest: "funcs:\n  bad: '{{ if }}'\n",
+			err:      true,
+		},
+		{
+			name:     "flags",
+			manifest: "flags:\n  - name: package\n    default: models\n  - name: nullable\n    type: bool\n    default: 'true'\n  - name: indent\n    type: int\n    default: '2'\n  - name: case\n    default: snake\n    enums: [snake, camel]\n",
+			mode:     "schema",
+		},
+		{
+			name:     "invalid bool default",
+			manifest: "flags:\n  - name: nullable\n    type: bool\n    default: 'yes'\n",
+			err:      true,
+		},
+		{
+			name:     "invalid int default",
+			manifest: "flags:\n  - name: indent\n    type: int\n    default: two\n",
+			err:      true,
+		},
+		{
+			name:     "invalid enum default",
+			manifest: "flags:\n  - name: case\n    default: kebab\n    enums: [snake, camel]\n",
+			err:      true,
+		},
+		{
+			name:     "unknown flag type",
+			manifest: "flags:\n  - name: ratio\n    type: float\n",
+			err:      true,
+		},
+		{
+			name:     "unknown field",
+			manifest: "template:\n  - partial: all\n",
+			err:      true,
+		},
+	}
+	set := &xo.Set{
+		Schemas: []xo.Schema{{
+			Name: "public",
+			Tables: []xo.Table{
+				{Type: "table", Name: "authors"},
+				{Type: "table", Name: "book_tags"},
+			},
+			Views: []xo.Table{
+				{Type: "view", Name: "AuthorBooks"},
+			},
+			MatViews: []xo.Table{
+				{Type: "matview", Name: "book_stats"},
+			},
+		}},
+	}
+	for i, test := range tests {
+		src := fstest.MapFS{
+			ManifestFile: &fstest.MapFile{Data: []byte(test.manifest)},
+		}
+		m, err := LoadManifest(src)
+		var typ xo.TemplateType
+		if err == nil {
+			typ, err = m.TemplateType()
+		}
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%s) expected error, got nil", i, test.name)
+			continue
+		case test.err:
+			continue
+		case err != nil:
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		var files []string
+		if err := typ.Process(context.Background(), test.mode, set, func(tpl xo.Template) {
+			files = append(files, tpl.Partial+":"+tpl.Dest)
+		}); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		if len(files) != len(test.exp) {
+			t.Fatalf("test %d (%s) expected %v, got: %v", i, test.name, test.exp, files)
+		}
+		for j, file := range files {
+			if file != test.exp[j] {
+				t.Errorf("test %d (%s) expected %q, got: %q", i, test.name, test.exp[j], file)
+			}
+		}
+	}
+}
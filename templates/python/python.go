@@ -0,0 +1,3401 @@
+//go:build xotpl
+
+package pytpl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"github.com/kenshaw/inflector"
+	"github.com/kenshaw/snaker"
+	"github.com/xo/xo/loader"
+	xo "github.com/xo/xo/types"
+)
+
+// ErrNoSingle is the error for missing --single in query exec mode.
+var ErrNoSingle = errors.New("in query exec mode, the --single or -S must be provided")
+
+// Init registers the template.
+func Init(ctx context.Context, f func(xo.TemplateType)) error {
+	f(xo.TemplateType{
+		Modes: []string{"query", "schema"},
+		Flags: []xo.Flag{
+			{
+				ContextKey: NotFirstKey,
+				Type:       "bool",
+				Desc:       "disable package files (ie. not first generated file)",
+				Short:      "2",
+				Default:    "false",
+			},
+			{
+				ContextKey: StreamKey,
+				Type:       "bool",
+				Desc:       "enable streaming funcs for queries returning multiple rows",
+				Default:    "false",
+			},
+			{
+				ContextKey: PaginateKey,
+				Type:       "bool",
+				Desc:       "enable keyset pagination funcs",
+				Default:    "false",
+			},
+			{
+				ContextKey: FilterKey,
+				Type:       "bool",
+				Desc:       "enable filter builder funcs over indexed columns",
+				Default:    "false",
+			},
+			{
+				ContextKey: RLSKey,
+				Type:       "bool",
+				Desc:       "enable funcs setting the settings read by row level security policies",
+				Default:    "false",
+			},
+			{
+				ContextKey: HooksKey,
+				Type:       "bool",
+				Desc:       "enable QueryHook instrumentation of the generated queries",
+				Default:    "false",
+			},
+			{
+				ContextKey: SoftDeleteKey,
+				Type:       "string",
+				Desc:       "soft delete column name (ie, deleted_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: CreatedKey,
+				Type:       "string",
+				Desc:       "audit column set to the current time on insert (ie, created_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: UpdatedKey,
+				Type:       "string",
+				Desc:       "audit column set to the current time on insert and update (ie, updated_at)",
+				Default:    "",
+			},
+			{
+				ContextKey: EscKey,
+				Type:       "[]string",
+				Desc:       "escape fields",
+				Default:    "none",
+				Enums:      []string{"none", "schema", "table", "column", "all"},
+			},
+			{
+				ContextKey: RepositoryKey,
+				Type:       "bool",
+				Desc:       "enable transaction-scoped repository type",
+				Default:    "false",
+			},
+			{
+				ContextKey: JSONModuleKey,
+				Type:       "string",
+				Desc:       "module of unqualified json column types (ie, myapp.types)",
+				Default:    "",
+			},
+		},
+		Funcs: func(ctx context.Context, _ string) (template.FuncMap, error) {
+			return NewFuncs(ctx)
+		},
+		Order: func(ctx context.Context, mode string) []string {
+			return []string{"header", "package", "utils", "enum", "composite", "range", "typedef", "page", "filter", "index", "refresh", "setting", "query", "repository", "factories", "factory", "fixture", "testdb", "roundtrip"}
+		},
+		Process: func(ctx context.Context, mode string, set *xo.Set, emit func(xo.Template)) error {
+			e := newEmitter(ctx, mode, emit)
+			if !NotFirst(ctx) {
+				e.emitPackage(mode, set)
+			}
+			if mode == "query" {
+				for _, query := range set.Queries {
+					if err := e.emitQuery(query); err != nil {
+						return err
+					}
+				}
+			} else {
+				types := userTypes(ctx, set)
+				for _, schema := range set.Schemas {
+					if err := e.emitSchema(schema, types); err != nil {
+						return err
+					}
+				}
+			}
+			e.emitRepository()
+			e.emitHeaders()
+			return nil
+		},
+		Post: func(ctx context.Context, mode string, files map[string][]byte, emit func(string, []byte)) error {
+			for file, content := range files {
+				emit(file, cleanEnd(blankRE.ReplaceAll(content, []byte("\n\n\n"))))
+			}
+			return nil
+		},
+	})
+	return nil
+}
+
+// blankRE matches more than two blank lines.
+var blankRE = regexp.MustCompile(`\n{4,}`)
+
+// cleanEnd trims the end of any spaces, ensuring it ends with exactly one
+// newline.
+func cleanEnd(buf []byte) []byte {
+	return append(bytes.TrimRightFunc(buf, unicode.IsSpace), '\n')
+}
+
+// emitter emits the templates of the modules, collecting the imports of each
+// module for its header.
+type emitter struct {
+	ctx     context.Context
+	emit    func(xo.Template)
+	imports map[string]*Imports
+	schemas map[string]string
+	repo    *Repo
+	// factories and tests are true once the factory and test modules are
+	// emitted
+	factories bool
+	tests     bool
+}
+
+// newEmitter creates a emitter.
+func newEmitter(ctx context.Context, mode string, emit func(xo.Template)) *emitter {
+	e := &emitter{
+		ctx:     ctx,
+		emit:    emit,
+		imports: make(map[string]*Imports),
+		schemas: make(map[string]string),
+	}
+	if Repository(ctx) {
+		_, _, schema := xo.DriverDbSchema(ctx)
+		e.repo = &Repo{
+			Name:   "Repository",
+			Module: "repository",
+			Schema: schema,
+		}
+		if mode == "query" {
+			e.repo.Name, e.repo.Module, e.repo.Query = "QueryRepository", "query_repository", true
+		}
+	}
+	return e
+}
+
+// dest returns the file name of the module.
+func (e *emitter) dest(module string) string {
+	if s := xo.Single(e.ctx); s != "" {
+		return s
+	}
+	return module + ext
+}
+
+// module returns the imports of the module's file, adding it when not
+// already present.
+func (e *emitter) module(module, schema string) *Imports {
+	dest := e.dest(module)
+	if e.imports[dest] == nil {
+		e.imports[dest] = newImports()
+		e.schemas[dest] = schema
+	}
+	return e.imports[dest]
+}
+
+// add emits the partial to the module.
+func (e *emitter) add(module, partial, sortType, sortName string, data interface{}) {
+	e.emit(xo.Template{
+		Partial:  partial,
+		Dest:     e.dest(module),
+		SortType: sortType,
+		SortName: sortName,
+		Data:     data,
+	})
+}
+
+// same returns true when the modules are generated to the same file. When
+// not first, the utils module was previously generated to its own file.
+func (e *emitter) same(module, from string) bool {
+	if from == "utils" && NotFirst(e.ctx) {
+		return false
+	}
+	return e.dest(module) == e.dest(from)
+}
+
+// local adds the import of name from the generated module to the module,
+// unless both are in the same file.
+func (e *emitter) local(imports *Imports, module, from, name string) {
+	if !e.same(module, from) {
+		imports.add(imports.Local, "."+from, name)
+	}
+}
+
+// checking adds the import of name from the generated module to the module,
+// only when type checking, unless both are in the same file.
+func (e *emitter) checking(imports *Imports, module, from, name string) {
+	if !e.same(module, from) {
+		imports.add(imports.Std, "typing", "TYPE_CHECKING")
+		imports.add(imports.Checking, "."+from, name)
+	}
+}
+
+// emitPackage emits the package and utils modules.
+func (e *emitter) emitPackage(mode string, set *xo.Set) {
+	_, _, schema := xo.DriverDbSchema(e.ctx)
+	if mode == "schema" && len(set.Schemas) != 0 {
+		schema = set.Schemas[0].Name
+	}
+	// in single mode, the package docstring is not generated
+	if xo.Single(e.ctx) == "" {
+		e.module("__init__", schema)
+		e.add("__init__", "package", "", "", schema)
+	}
+	imports := e.module("utils", schema)
+	imports.add(imports.Std, "contextlib", "")
+	imports.add(imports.Std, "typing", "Any")
+	imports.add(imports.Std, "typing", "Callable")
+	imports.add(imports.Std, "typing", "Iterator")
+	imports.add(imports.Std, "typing", "Optional")
+	imports.add(imports.Std, "typing", "Protocol")
+	imports.add(imports.Std, "threading", "")
+	imports.add(imports.Std, "time", "")
+	if Filter(e.ctx) {
+		imports.add(imports.Std, "typing", "Iterable")
+	}
+	if Hooks(e.ctx) {
+		imports.add(imports.Std, "dataclasses", "dataclass")
+	}
+	// json columns with a concrete type
+	var jsonTypes bool
+	for _, s := range set.Schemas {
+		for _, t := range s.Tables {
+			for _, z := range t.Columns {
+				jsonTypes = jsonTypes || z.JSONType != ""
+			}
+		}
+	}
+	if Paginate(e.ctx) {
+		imports.add(imports.Std, "base64", "")
+	}
+	spatial := hasType(e.ctx, set, func(typ string) bool {
+		return typ == "Geometry"
+	})
+	if spatial {
+		imports.add(imports.Std, "dataclasses", "dataclass")
+		imports.add(imports.Std, "struct", "")
+	}
+	// sqlite3 dates and times
+	driver, _, _ := xo.DriverDbSchema(e.ctx)
+	times := driver == "sqlite3" && hasType(e.ctx, set, func(typ string) bool {
+		return strings.HasPrefix(typ, "datetime.")
+	})
+	if times {
+		imports.add(imports.Std, "datetime", "")
+	}
+	pgTypes := hasPgTypes(e.ctx, set)
+	if pgTypes {
+		imports.add(imports.Std, "dataclasses", "dataclass")
+		imports.add(imports.Std, "datetime", "")
+		imports.add(imports.Std, "enum", "")
+		imports.add(imports.Std, "typing", "Generic")
+		imports.add(imports.Std, "typing", "TypeVar")
+	}
+	if Paginate(e.ctx) || jsonTypes {
+		imports.add(imports.Std, "json", "")
+	}
+	e.add("utils", "utils", "", "", Utils{
+		Schema:  schema,
+		JSON:    jsonTypes,
+		Spatial: spatial,
+		PgTypes: pgTypes,
+		Times:   times,
+	})
+}
+
+// hasType returns true when the set has columns or query fields with a
+// matching Python type, without its nullability or array dimension.
+func hasType(ctx context.Context, set *xo.Set, match func(string) bool) bool {
+	var fields []xo.Field
+	for _, s := range set.Schemas {
+		for _, t := range append(append(s.Tables, s.Views...), s.MatViews...) {
+			fields = append(fields, t.Columns...)
+		}
+	}
+	for _, q := range set.Queries {
+		fields = append(append(fields, q.Fields...), q.Params...)
+	}
+	for _, z := range fields {
+		if typ, _, err := pyType(ctx, z.Type); err == nil && match(typ) {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPgTypes returns true when the set has composite or range types, or
+// columns of a built-in range type.
+func hasPgTypes(ctx context.Context, set *xo.Set) bool {
+	for _, s := range set.Schemas {
+		if len(s.Composites) != 0 || len(s.Ranges) != 0 {
+			return true
+		}
+		for _, t := range append(append(s.Tables, s.Views...), s.MatViews...) {
+			for _, z := range t.Columns {
+				if typ, _, err := pyType(ctx, z.Type); err == nil && strings.HasPrefix(typ, "Range[") {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
+
+// emitHeaders emits the headers of the modules.
+func (e *emitter) emitHeaders() {
+	for dest, imports := range e.imports {
+		// the utils imports are local imports from the utils module
+		for name := range imports.Utils[""] {
+			e.local(imports, strings.TrimSuffix(dest, ext), "utils", name)
+		}
+		if imports.Utils[""]["cursor"] && Hooks(e.ctx) {
+			e.local(imports, strings.TrimSuffix(dest, ext), "utils", "QueryInfo")
+		}
+		delete(imports.Utils, "")
+		e.emit(xo.Template{
+			Partial: "header",
+			Dest:    dest,
+			Data: Header{
+				Init:     dest == "__init__"+ext,
+				Schema:   e.schemas[dest],
+				Std:      imports.lines(imports.Std),
+				Third:    imports.lines(imports.Third),
+				Local:    imports.lines(imports.Local),
+				Checking: imports.lines(imports.Checking),
+			},
+		})
+	}
+}
+
+// emitSchema emits the xo schema for the template set.
+func (e *emitter) emitSchema(schema xo.Schema, types Types) error {
+	// emit enums
+	for _, z := range schema.Enums {
+		enum := convertEnum(e.ctx, schema.Name, z)
+		imports := e.module(enum.Module, schema.Name)
+		imports.add(imports.Std, "enum", "")
+		e.add(enum.Module, "enum", "", enum.Name, enum)
+	}
+	// emit composite types
+	for _, z := range schema.Composites {
+		composite, err := convertComposite(e.ctx, schema.Name, z, types)
+		if err != nil {
+			return err
+		}
+		imports := e.module(composite.Module, schema.Name)
+		imports.add(imports.Std, "dataclasses", "dataclass")
+		imports.add(imports.Std, "typing", "Any")
+		imports.add(imports.Utils, "", "format_composite")
+		imports.add(imports.Utils, "", "parse_composite")
+		imports.add(imports.Utils, "", "parse_text")
+		for _, f := range composite.Fields {
+			typeImports(imports, f.Type+" "+f.Default)
+			e.fieldImports(imports, composite.Module, f)
+		}
+		e.add(composite.Module, "composite", "", composite.Name, composite)
+	}
+	// emit range types
+	for _, z := range schema.Ranges {
+		rng, err := convertRange(e.ctx, schema.Name, z)
+		if err != nil {
+			return err
+		}
+		imports := e.module(rng.Module, schema.Name)
+		typeImports(imports, "Range["+rng.Type+"]")
+		e.add(rng.Module, "range", "", rng.Name, rng)
+	}
+	// emit tables
+	for _, t := range append(append(schema.Tables, schema.Views...), schema.MatViews...) {
+		table, err := convertTable(e.ctx, schema.Name, t, types)
+		if err != nil {
+			return err
+		}
+		for i, fk := range table.ForeignKeys {
+			if !e.same(table.Module, fk.RefModule) {
+				table.ForeignKeys[i].Import = "." + fk.RefModule
+			}
+		}
+		imports := e.module(table.Module, schema.Name)
+		e.tableImports(imports, table)
+		e.add(table.Module, "typedef", table.Type, table.Name, table)
+		e.repoTable(table)
+		// emit page func
+		if Paginate(e.ctx) && t.Type == "table" {
+			page, ok, err := convertPage(e.ctx, table, t, types)
+			switch {
+			case err != nil:
+				return err
+			case ok:
+				imports.add(imports.Std, "dataclasses", "dataclass")
+				imports.add(imports.Std, "typing", "Any")
+				imports.add(imports.Std, "typing", "Optional")
+				for _, name := range []string{"Context", "cursor", "decode_cursor", "encode_cursor", "logf"} {
+					e.local(imports, table.Module, "utils", name)
+				}
+				e.add(table.Module, "page", table.Type, page.Name, page)
+				e.repoPage(page)
+			}
+		}
+		// emit filter func
+		if Filter(e.ctx) && t.Type == "table" {
+			if filter, ok := convertFilter(table, t); ok {
+				imports.add(imports.Std, "typing", "Iterable")
+				imports.add(imports.Std, "typing", "Optional")
+				for _, name := range []string{"Context", "FilterClause", "cursor", "logf"} {
+					e.local(imports, table.Module, "utils", name)
+				}
+				e.add(table.Module, "filter", table.Type, filter.Name, filter)
+				e.repoFilter(filter)
+			}
+		}
+		// emit indexes
+		for _, i := range t.Indexes {
+			// expression indexes cannot be looked up by column equality
+			if len(i.Expressions) != 0 || len(i.Fields) == 0 {
+				continue
+			}
+			index, err := convertIndex(e.ctx, table, i, types)
+			if err != nil {
+				return err
+			}
+			imports.add(imports.Std, "typing", "Optional")
+			imports.add(imports.Utils, "", "Context")
+			imports.add(imports.Utils, "", "cursor")
+			imports.add(imports.Utils, "", "logf")
+			indexes := []Index{index}
+			// variant that does not filter soft deleted rows, unless the
+			// partial index predicate already filters them
+			if table.SoftDelete != nil && !index.SoftDeletePredicate {
+				v := index
+				v.Func += "_include_deleted"
+				v.IncludeDeleted = true
+				indexes = append(indexes, v)
+			}
+			// streaming variants
+			if Stream(e.ctx) && !index.IsUnique {
+				imports.add(imports.Std, "typing", "Iterator")
+				for _, v := range indexes[:len(indexes):len(indexes)] {
+					v.Func += "_iter"
+					v.Stream = true
+					indexes = append(indexes, v)
+				}
+			}
+			for _, v := range indexes {
+				sortName := v.SQLName
+				if v.IncludeDeleted {
+					sortName += "_include_deleted"
+				}
+				if v.Stream {
+					sortName += "_iter"
+				}
+				e.add(table.Module, "index", table.Type, sortName, v)
+				e.repoIndex(v)
+			}
+		}
+		// emit refresh funcs
+		if t.Type == "matview" {
+			imports.add(imports.Std, "typing", "Optional")
+			imports.add(imports.Utils, "", "Context")
+			imports.add(imports.Utils, "", "cursor")
+			imports.add(imports.Utils, "", "logf")
+			for _, refresh := range convertRefresh(table, t) {
+				e.add(table.Module, "refresh", table.Type, refresh.Name, refresh)
+				e.repoRefresh(refresh)
+			}
+		}
+	}
+	// emit row level security setting funcs
+	if RLS(e.ctx) {
+		for _, setting := range convertSettings(e.ctx, schema) {
+			imports := e.module("settings", schema.Name)
+			imports.add(imports.Std, "typing", "Optional")
+			for _, name := range []string{"DB", "Context", "cursor", "logf"} {
+				e.local(imports, "settings", "utils", name)
+			}
+			e.add("settings", "setting", "", setting.Name, setting)
+			e.repoSetting(setting)
+		}
+	}
+	// emit factories
+	if xo.Factories(e.ctx) && len(schema.Tables) != 0 {
+		if err := e.emitFactories(schema, types); err != nil {
+			return err
+		}
+	}
+	// emit round trip tests
+	if xo.Tests(e.ctx) && xo.Single(e.ctx) == "" && len(schema.Tables) != 0 {
+		if err := e.emitTests(schema, types); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emitFactories emits the factory funcs for the schema's tables and, unless
+// generating a single file, the pytest fixtures using them.
+//
+// Factories create the rows referenced by not null foreign keys before
+// inserting a row, except for foreign keys that are part of a reference
+// cycle, which cannot be satisfied.
+func (e *emitter) emitFactories(schema xo.Schema, types Types) error {
+	// tables that can be inserted
+	tables := make(map[string]bool)
+	for _, t := range schema.Tables {
+		if len(t.PrimaryKeys) != 0 {
+			tables[t.Name] = true
+		}
+	}
+	// refs returns the not null foreign keys of a table to tables that can
+	// be inserted
+	refs := func(t xo.Table) []xo.ForeignKey {
+		var fks []xo.ForeignKey
+		for _, fk := range t.ForeignKeys {
+			if !tables[fk.RefTable] || fk.RefSchema != "" && fk.RefSchema != schema.Name {
+				continue
+			}
+			nullable := false
+			for _, z := range fk.Fields {
+				nullable = nullable || z.Type.Nullable
+			}
+			if !nullable {
+				fks = append(fks, fk)
+			}
+		}
+		return fks
+	}
+	// a factory cannot create the rows it refers to in a reference cycle of
+	// not null foreign keys, as the referenced factory would refer back
+	graph := xo.Schema{Name: schema.Name}
+	for _, t := range schema.Tables {
+		if tables[t.Name] {
+			graph.Tables = append(graph.Tables, xo.Table{Name: t.Name, ForeignKeys: refs(t)})
+		}
+	}
+	cycle := make(map[string]int)
+	for i, names := range graph.ReferenceCycles() {
+		for _, name := range names {
+			cycle[name] = i + 1
+		}
+	}
+	const module, fixtures = "factory", "fixtures"
+	imports := e.module(module, schema.Name)
+	imports.add(imports.Std, "itertools", "")
+	imports.add(imports.Std, "datetime", "")
+	if !e.factories {
+		e.add(module, "factories", "", "", schema.Name)
+		e.factories = true
+	}
+	// fixtures are not generated to the single file, as they import pytest
+	single := xo.Single(e.ctx) != ""
+	for _, t := range schema.Tables {
+		table, err := convertTable(e.ctx, schema.Name, t, types)
+		if err != nil {
+			return err
+		}
+		factory := FactoryFunc{
+			Name:   funcName("Fake" + table.Name),
+			Create: funcName("Create" + table.Name),
+			Table:  table,
+		}
+		e.local(imports, module, table.Module, table.Name)
+		// foreign keys
+		fkFields := make(map[string]bool)
+		for _, fk := range t.ForeignKeys {
+			for _, z := range fk.Fields {
+				fkFields[z.Name] = true
+			}
+		}
+		if len(t.PrimaryKeys) != 0 {
+			imports.add(imports.Std, "dataclasses", "")
+			imports.add(imports.Std, "typing", "Any")
+			imports.add(imports.Std, "typing", "Optional")
+			e.local(imports, module, "utils", "Context")
+			e.local(imports, module, "utils", "DB")
+			for _, fk := range refs(t) {
+				if n := cycle[t.Name]; n != 0 && cycle[fk.RefTable] == n {
+					continue
+				}
+				ref, err := convertFKey(e.ctx, table, fk, types)
+				if err != nil {
+					return err
+				}
+				factory.Refs = append(factory.Refs, FactoryRef{
+					Key:    ref,
+					Create: funcName("Create" + ref.RefTable),
+				})
+			}
+		}
+		// fake values
+		for i, z := range t.Columns {
+			if z.Type.Nullable || z.IsSequence || z.IsGenerated || fkFields[z.Name] {
+				continue
+			}
+			if v := fakeValue(table.Fields[i]); v != "" {
+				typeImports(imports, v)
+				factory.Values = append(factory.Values, FactoryValue{
+					Field: table.Fields[i],
+					Value: v,
+				})
+			}
+		}
+		e.add(module, "factory", "", table.Name, factory)
+		if single || len(t.PrimaryKeys) == 0 {
+			continue
+		}
+		fixture := e.module(fixtures, schema.Name)
+		fixture.add(fixture.Std, "functools", "")
+		fixture.add(fixture.Std, "typing", "Callable")
+		fixture.add(fixture.Third, "pytest", "")
+		e.local(fixture, fixtures, "utils", "DB")
+		e.local(fixture, fixtures, module, factory.Create)
+		e.local(fixture, fixtures, table.Module, table.Name)
+		e.add(fixtures, "fixture", "", table.Name, factory)
+	}
+	return nil
+}
+
+// tableImports adds the imports of the table's type to the module.
+func (e *emitter) tableImports(imports *Imports, table Table) {
+	imports.add(imports.Std, "dataclasses", "dataclass")
+	e.local(imports, table.Module, "utils", "DB")
+	if len(table.Fields) != 0 {
+		imports.add(imports.Std, "typing", "Any")
+		imports.add(imports.Std, "typing", "Sequence")
+	}
+	for _, z := range table.Fields {
+		typeImports(imports, z.Type+" "+z.Default)
+		e.fieldImports(imports, table.Module, z)
+		if z.JSONType != "" {
+			imports.add(imports.Utils, "", "decode_json")
+			imports.add(imports.Utils, "", "encode_json")
+		}
+		if z.Sqlite3 != "" && z.Sqlite3 != "bool" {
+			imports.add(imports.Utils, "", "decode_time")
+			imports.add(imports.Utils, "", "encode_time")
+		}
+	}
+	if len(table.PrimaryKeys) != 0 {
+		imports.add(imports.Std, "dataclasses", "field")
+		imports.add(imports.Std, "typing", "Optional")
+		imports.add(imports.Utils, "", "Context")
+		imports.add(imports.Utils, "", "cursor")
+		imports.add(imports.Utils, "", "logf")
+		imports.add(imports.Utils, "", "AlreadyExistsError")
+		imports.add(imports.Utils, "", "MarkedForDeletionError")
+		if len(table.Fields) != len(table.PrimaryKeys) {
+			imports.add(imports.Utils, "", "DoesNotExistError")
+		}
+	}
+	for _, fk := range table.ForeignKeys {
+		imports.add(imports.Std, "typing", "Optional")
+		imports.add(imports.Utils, "", "Context")
+		e.checking(imports, table.Module, fk.RefModule, fk.RefTable)
+	}
+}
+
+// fieldImports adds the imports of the user defined or json type of the
+// field to the module.
+func (e *emitter) fieldImports(imports *Imports, module string, z Field) {
+	switch {
+	case z.Enum != "":
+		e.local(imports, module, snake(z.Enum), z.Enum)
+	case z.Composite != "":
+		e.local(imports, module, snake(z.Composite), z.Composite)
+	case z.JSONType != "":
+		imports.add(imports.Third, z.JSONModule, z.JSONType)
+	}
+}
+
+// emitQuery emits the query.
+func (e *emitter) emitQuery(query xo.Query) error {
+	if query.Exec && xo.Single(e.ctx) == "" {
+		return ErrNoSingle
+	}
+	driver, _, _ := xo.DriverDbSchema(e.ctx)
+	var table Table
+	var sets []QuerySet
+	var err error
+	switch {
+	case len(query.ResultSets) != 0 && driver == "sqlite3":
+		return errors.New("multiple result sets are not supported by sqlite3")
+	case len(query.ResultSets) != 0:
+		if table, sets, err = buildQuerySets(e.ctx, query); err != nil {
+			return err
+		}
+	case !query.Exec:
+		if table, err = buildQueryType(e.ctx, query); err != nil {
+			return err
+		}
+	}
+	q, err := convertQuery(e.ctx, query, table)
+	if err != nil {
+		return err
+	}
+	q.Sets = sets
+	module := table.Module
+	if query.Exec {
+		module = snake(q.Name)
+	}
+	imports := e.module(module, "")
+	for _, z := range q.Params {
+		typeImports(imports, z.Type)
+	}
+	imports.add(imports.Std, "typing", "Optional")
+	e.local(imports, module, "utils", "Context")
+	e.local(imports, module, "utils", "DB")
+	e.local(imports, module, "utils", "cursor")
+	e.local(imports, module, "utils", "logf")
+	if Hooks(e.ctx) {
+		e.local(imports, module, "utils", "QueryInfo")
+	}
+	switch {
+	case query.Exec:
+	case query.Flat:
+		for _, z := range table.Fields {
+			typeImports(imports, z.Type)
+		}
+	default:
+		e.tableImports(imports, table)
+		e.add(module, "typedef", query.Type, query.Name, table)
+		for i, set := range sets {
+			e.tableImports(imports, set.Type)
+			e.add(module, "typedef", query.Type, fmt.Sprintf("%s_%03d", query.Name, i+1), set.Type)
+		}
+		if len(sets) > 1 {
+			e.local(imports, module, "utils", "Error")
+		}
+	}
+	e.add(module, "query", query.Type, query.Name, q)
+	e.repoQuery(module, q)
+	// emit streaming variant
+	if Stream(e.ctx) && !q.One && len(q.Sets) == 0 {
+		imports.add(imports.Std, "typing", "Iterator")
+		q.Name += "_iter"
+		q.Stream = true
+		e.add(module, "query", query.Type, query.Name+"_iter", q)
+		e.repoQuery(module, q)
+	}
+	return nil
+}
+
+// repoImports returns the imports of the repository module, adding the
+// import of name from the generated module.
+func (e *emitter) repoImports(from, name string) *Imports {
+	imports := e.module(e.repo.Module, e.repo.Schema)
+	e.local(imports, e.repo.Module, from, name)
+	return imports
+}
+
+// repoFields adds the imports of the types of the fields to the repository
+// module, returning the Python parameters of the fields.
+func (e *emitter) repoFields(imports *Imports, fields []Field) string {
+	var list []string
+	for _, z := range fields {
+		typeImports(imports, z.Type)
+		e.fieldImports(imports, e.repo.Module, z)
+		list = append(list, z.Name+": "+z.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+// repoTable adds the methods of the table's type to the repository.
+func (e *emitter) repoTable(table Table) {
+	if e.repo == nil || len(table.PrimaryKeys) == 0 {
+		return
+	}
+	e.repoImports(table.Module, table.Name)
+	name := paramName(table.Module)
+	methods := []string{"insert"}
+	if len(table.Fields) != len(table.PrimaryKeys) {
+		methods = append(methods, "update", "save", "upsert")
+	}
+	for _, m := range append(methods, "delete") {
+		e.repo.Methods = append(e.repo.Methods, RepoMethod{
+			Name:    m + "_" + table.Module,
+			Params:  name + ": " + table.Name,
+			Returns: "None",
+			Call:    name + "." + m + "(self.db, ctx=ctx)",
+			Func:    table.Name + "." + m,
+		})
+	}
+}
+
+// repoPage adds the page func to the repository.
+func (e *emitter) repoPage(page PageFunc) {
+	if e.repo == nil {
+		return
+	}
+	e.repoImports(page.Table.Module, page.Name)
+	e.repoImports(page.Table.Module, page.Table.Name)
+	e.repoImports(page.Table.Module, page.Cursor)
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:    page.Name,
+		Params:  "after: " + page.Cursor + ", limit: int",
+		Returns: "tuple[list[" + page.Table.Name + "], " + page.Cursor + "]",
+		Call:    page.Name + "(self.db, after, limit, ctx=ctx)",
+		Func:    page.Name,
+	})
+}
+
+// repoFilter adds the filter func to the repository.
+func (e *emitter) repoFilter(filter FilterFunc) {
+	if e.repo == nil {
+		return
+	}
+	imports := e.repoImports(filter.Table.Module, filter.Name)
+	e.repoImports(filter.Table.Module, filter.Table.Name)
+	imports.add(imports.Std, "typing", "Iterable")
+	var keywords []string
+	args := []string{"self.db"}
+	for _, p := range filter.Params {
+		typeImports(imports, p.Type)
+		e.fieldImports(imports, e.repo.Module, p.Field)
+		keywords = append(keywords, p.Name+": "+p.Type+" = None")
+		args = append(args, p.Name+"="+p.Name)
+	}
+	indent := "\n" + strings.Repeat("    ", 3)
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:     filter.Name,
+		Keywords: keywords,
+		Returns:  "list[" + filter.Table.Name + "]",
+		Call:     filter.Name + "(" + indent + strings.Join(append(args, "ctx=ctx"), ","+indent) + ",\n        )",
+		Func:     filter.Name,
+	})
+}
+
+// repoIndex adds the index func to the repository.
+func (e *emitter) repoIndex(index Index) {
+	if e.repo == nil {
+		return
+	}
+	imports := e.repoImports(index.Table.Module, index.Func)
+	e.repoImports(index.Table.Module, index.Table.Name)
+	returns := "list[" + index.Table.Name + "]"
+	switch {
+	case index.IsUnique:
+		returns = "Optional[" + index.Table.Name + "]"
+	case index.Stream:
+		returns = "Iterator[" + index.Table.Name + "]"
+	}
+	args := []string{"self.db"}
+	for _, z := range index.Fields {
+		args = append(args, z.Name)
+	}
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:    index.Func,
+		Params:  e.repoFields(imports, index.Fields),
+		Returns: returns,
+		Call:    index.Func + "(" + strings.Join(append(args, "ctx=ctx"), ", ") + ")",
+		Func:    index.Func,
+	})
+}
+
+// repoRefresh adds the refresh func of a materialized view to the repository.
+func (e *emitter) repoRefresh(refresh RefreshFunc) {
+	if e.repo == nil {
+		return
+	}
+	e.repoImports(refresh.Table.Module, refresh.Name)
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:    refresh.Name,
+		Returns: "None",
+		Call:    refresh.Name + "(self.db, ctx=ctx)",
+		Func:    refresh.Name,
+	})
+}
+
+// repoSetting adds the func setting a row level security setting to the
+// repository.
+func (e *emitter) repoSetting(setting SettingFunc) {
+	if e.repo == nil {
+		return
+	}
+	e.repoImports("settings", setting.Name)
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:    setting.Name,
+		Params:  "value: str, local: bool = False",
+		Returns: "None",
+		Call:    setting.Name + "(self.db, value, local, ctx=ctx)",
+		Func:    setting.Name,
+	})
+}
+
+// repoQuery adds the query func of the module to the repository.
+func (e *emitter) repoQuery(module string, q Query) {
+	if e.repo == nil {
+		return
+	}
+	imports := e.repoImports(module, q.Name)
+	var returns string
+	switch {
+	case q.Exec:
+		returns = "int"
+	case q.Flat:
+		var types []string
+		for _, z := range q.Type.Fields {
+			typeImports(imports, z.Type)
+			types = append(types, z.Type)
+		}
+		returns = "Optional[tuple[" + strings.Join(types, ", ") + "]]"
+	case q.One:
+		e.repoImports(module, q.Type.Name)
+		returns = "Optional[" + q.Type.Name + "]"
+	case q.Stream:
+		e.repoImports(module, q.Type.Name)
+		returns = "Iterator[" + q.Type.Name + "]"
+	case len(q.Sets) != 0:
+		e.repoImports(module, q.Type.Name)
+		returns = q.Type.Name
+	default:
+		e.repoImports(module, q.Type.Name)
+		returns = "list[" + q.Type.Name + "]"
+	}
+	args := []string{"self.db"}
+	for _, z := range q.Params {
+		args = append(args, z.Name)
+	}
+	e.repo.Methods = append(e.repo.Methods, RepoMethod{
+		Name:    q.Name,
+		Params:  e.repoFields(imports, q.Params),
+		Returns: returns,
+		Call:    q.Name + "(" + strings.Join(append(args, "ctx=ctx"), ", ") + ")",
+		Func:    q.Name,
+	})
+}
+
+// emitRepository emits the repository, with the methods of the generated
+// types and funcs.
+func (e *emitter) emitRepository() {
+	if e.repo == nil {
+		return
+	}
+	imports := e.module(e.repo.Module, e.repo.Schema)
+	imports.add(imports.Std, "contextlib", "")
+	imports.add(imports.Std, "typing", "Iterator")
+	imports.add(imports.Std, "typing", "Optional")
+	e.local(imports, e.repo.Module, "utils", "Connection")
+	e.local(imports, e.repo.Module, "utils", "Context")
+	e.local(imports, e.repo.Module, "utils", "DB")
+	e.add(e.repo.Module, "repository", "", e.repo.Name, *e.repo)
+}
+
+// buildQueryType builds the result type of the query.
+func buildQueryType(ctx context.Context, query xo.Query) (Table, error) {
+	var fields []Field
+	for _, z := range query.Fields {
+		f, err := convertField(ctx, snake, z, Types{})
+		if err != nil {
+			return Table{}, err
+		}
+		// the types are provided by the user
+		if query.ManualFields {
+			typ, def := convertGoType(z.Type.Type, z.Type)
+			f = Field{
+				Name:    attrName(snake(z.Name)),
+				SQLName: snake(z.Name),
+				Type:    typ,
+				Default: def,
+			}
+		}
+		fields = append(fields, f)
+	}
+	return Table{
+		Type:    "query",
+		Name:    query.Type,
+		SQLName: snake(query.Type),
+		Module:  snake(query.Type),
+		Fields:  fields,
+		Comment: query.TypeComment,
+	}, nil
+}
+
+// buildQuerySets builds the result set types for a query returning multiple
+// result sets, and the query type aggregating the result sets.
+func buildQuerySets(ctx context.Context, query xo.Query) (Table, []QuerySet, error) {
+	var sets []QuerySet
+	var fields []Field
+	for i, z := range query.ResultSets {
+		name := fmt.Sprintf("Set%d", i+1)
+		q := query
+		q.Type, q.TypeComment, q.Fields = query.Type+name, "", z
+		typ, err := buildQueryType(ctx, q)
+		if err != nil {
+			return Table{}, nil, err
+		}
+		typ.Comment = fmt.Sprintf("%s represents a row from result set %d of %s.", typ.Name, i+1, funcName(buildQueryName(query)))
+		sets = append(sets, QuerySet{
+			Name: snake(name),
+			Type: typ,
+		})
+		fields = append(fields, Field{
+			Name:    snake(name),
+			SQLName: snake(name),
+			Type:    "list[" + typ.Name + "]",
+			Default: "field(default_factory=list)",
+		})
+	}
+	comment := query.TypeComment
+	if comment == "" {
+		comment = fmt.Sprintf("%s holds the result sets of %s.", query.Type, funcName(buildQueryName(query)))
+	}
+	return Table{
+		Type:       "query",
+		Name:       query.Type,
+		SQLName:    snake(query.Type),
+		Module:     snake(query.Type),
+		Fields:     fields,
+		Comment:    comment,
+		ResultSets: true,
+	}, sets, nil
+}
+
+// buildQueryName builds a name for the query.
+func buildQueryName(query xo.Query) string {
+	if query.Name != "" {
+		return query.Name
+	}
+	// generate name if not specified
+	name := query.Type
+	if !query.One {
+		name = inflector.Pluralize(name)
+	}
+	// add params
+	if len(query.Params) == 0 {
+		name = "Get" + name
+	} else {
+		name += "By"
+		for _, p := range query.Params {
+			name += camelExport(p.Name)
+		}
+	}
+	return name
+}
+
+// interpolateRE matches the interpolated params of a query line.
+var interpolateRE = regexp.MustCompile("` \\+ ([^`]*) \\+ `")
+
+// convertQuery converts a xo.Query, rewriting the driver's placeholders of
+// the query to the Python driver's placeholders.
+func convertQuery(ctx context.Context, query xo.Query, table Table) (Query, error) {
+	driver, _, _ := xo.DriverDbSchema(ctx)
+	nth, err := loader.NthParam(ctx)
+	if err != nil {
+		return Query{}, err
+	}
+	var params []Field
+	for _, z := range query.Params {
+		typ, _ := convertGoType(z.Type.Type, z.Type)
+		if z.Join {
+			typ = "list[str]"
+		}
+		params = append(params, Field{
+			Name:    paramName(snake(z.Name)),
+			SQLName: z.Name,
+			Type:    typ,
+		})
+	}
+	// the placeholders of the params, in the order of the params
+	placeholders := make(map[string]int)
+	for i := range query.Params {
+		placeholders[nth(i)] = i
+	}
+	sequential := nth(0) == nth(1)
+	var lines, args []string
+	var n int
+	var s scanner
+	for i, line := range query.Query {
+		var parts []string
+		for j, part := range splitInterpolated(line) {
+			// interpolated param
+			if j%2 == 1 {
+				name := part
+				if m := joinRE.FindStringSubmatch(part); m != nil {
+					name = m[1]
+				}
+				expr := "str(" + paramName(snake(name)) + ")"
+				if strings.HasPrefix(part, "strings.Join(") {
+					expr = `"\n".join(` + paramName(snake(name)) + ")"
+				}
+				if pyNth(driver, 0) == "%s" {
+					expr += `.replace("%", "%%")`
+				}
+				parts = append(parts, expr)
+				continue
+			}
+			// rewrite placeholders, outside of literals and comments
+			var sb strings.Builder
+			for _, t := range s.scan(part) {
+				if !t.placeholder {
+					sb.WriteString(escPct(driver, t.text))
+					continue
+				}
+				idx, ok := -1, false
+				switch {
+				case sequential:
+					idx, ok = n, n < len(params)
+				default:
+					if v, found := placeholders[t.text]; found {
+						idx, ok = v, true
+					}
+				}
+				if !ok {
+					sb.WriteString(escPct(driver, t.text))
+					continue
+				}
+				sb.WriteString(pyNth(driver, n))
+				args = append(args, params[idx].Name)
+				n++
+			}
+			if sb.Len() != 0 {
+				parts = append(parts, strconv.Quote(sb.String()))
+			}
+		}
+		if len(parts) == 0 {
+			parts = []string{`""`}
+		}
+		line := strings.Join(parts, " + ")
+		if i < len(query.Comments) {
+			if c := strings.TrimSpace(query.Comments[i]); c != "" {
+				line += "  # " + c
+			}
+		}
+		lines = append(lines, line)
+	}
+	return Query{
+		Name:    funcName(buildQueryName(query)),
+		Comment: query.Comment,
+		SQL:     lines,
+		Args:    args,
+		Params:  params,
+		One:     query.Exec || query.Flat || query.One,
+		Flat:    query.Flat,
+		Exec:    query.Exec,
+		Type:    table,
+	}, nil
+}
+
+// joinRE matches a joined interpolated param.
+var joinRE = regexp.MustCompile(`^strings\.Join\(([^,]+),`)
+
+// splitInterpolated splits the query line on its interpolated params. The
+// params are at the odd indexes.
+func splitInterpolated(line string) []string {
+	var parts []string
+	last := 0
+	for _, m := range interpolateRE.FindAllStringSubmatchIndex(line, -1) {
+		parts = append(parts, line[last:m[0]], line[m[2]:m[3]])
+		last = m[1]
+	}
+	return append(parts, line[last:])
+}
+
+// token is a part of a query, either a placeholder or text.
+type token struct {
+	text        string
+	placeholder bool
+}
+
+// scanner splits queries into placeholders and text, skipping string
+// literals, quoted identifiers and comments. The state is kept between
+// lines.
+type scanner struct {
+	quote   byte
+	comment bool
+}
+
+// placeholderRE matches the placeholders of the drivers.
+var placeholderRE = regexp.MustCompile(`^(\$\d+|@p\d+|:\d+|\?)`)
+
+// scan splits s into tokens.
+func (s *scanner) scan(str string) []token {
+	var tokens []token
+	start := 0
+	for i := 0; i < len(str); i++ {
+		c := str[i]
+		switch {
+		case s.comment:
+			if c == '*' && i+1 < len(str) && str[i+1] == '/' {
+				s.comment = false
+				i++
+			}
+		case s.quote != 0:
+			if c == s.quote {
+				s.quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			s.quote = c
+		case c == '-' && i+1 < len(str) && str[i+1] == '-':
+			// line comment
+			i = len(str)
+		case c == '/' && i+1 < len(str) && str[i+1] == '*':
+			s.comment = true
+			i++
+		case c == ':' && i+1 < len(str) && str[i+1] == ':':
+			// cast
+			i++
+		default:
+			m := placeholderRE.FindString(str[i:])
+			if m == "" || (i != 0 && c != '?' && isIdent(str[i-1])) {
+				continue
+			}
+			if start < i {
+				tokens = append(tokens, token{text: str[start:i]})
+			}
+			tokens = append(tokens, token{text: m, placeholder: true})
+			start = i + len(m)
+			i = start - 1
+		}
+	}
+	if start < len(str) {
+		tokens = append(tokens, token{text: str[start:]})
+	}
+	return tokens
+}
+
+// isIdent returns true when c is part of an identifier.
+func isIdent(c byte) bool {
+	return c == '_' || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z') || ('0' <= c && c <= '9')
+}
+
+// Types are the user defined types of a set, by their type name in the
+// columns.
+type Types struct {
+	Enums      map[string]Enum
+	Composites map[string]Composite
+	Ranges     map[string]Range
+}
+
+// userTypes returns the user defined types of the set. The fields of the
+// composites are not converted.
+func userTypes(ctx context.Context, set *xo.Set) Types {
+	_, _, primary := xo.DriverDbSchema(ctx)
+	types := Types{
+		Enums:      make(map[string]Enum),
+		Composites: make(map[string]Composite),
+		Ranges:     make(map[string]Range),
+	}
+	for _, schema := range set.Schemas {
+		prefixes := []string{schema.Name + "."}
+		if schema.Name == primary || len(set.Schemas) == 1 {
+			prefixes = append(prefixes, "")
+		}
+		for _, prefix := range prefixes {
+			for _, z := range schema.Enums {
+				types.Enums[prefix+z.Name] = convertEnum(ctx, schema.Name, z)
+			}
+			for _, z := range schema.Composites {
+				name := camelExport(schemaPrefix(ctx, schema.Name) + z.Name)
+				types.Composites[prefix+z.Name] = Composite{
+					Name:   name,
+					Module: snake(name),
+				}
+			}
+			for _, z := range schema.Ranges {
+				if r, err := convertRange(ctx, schema.Name, z); err == nil {
+					types.Ranges[prefix+z.Name] = r
+				}
+			}
+		}
+	}
+	return types
+}
+
+// schemaPrefix returns the prefix of the names of the schema's types.
+func schemaPrefix(ctx context.Context, schema string) string {
+	_, _, primary := xo.DriverDbSchema(ctx)
+	if schema != "" && schema != primary {
+		return schema + "_"
+	}
+	return ""
+}
+
+// convertEnum converts a xo.Enum.
+func convertEnum(ctx context.Context, schema string, e xo.Enum) Enum {
+	name := camelExport(schemaPrefix(ctx, schema) + e.Name)
+	var vals []EnumValue
+	for _, v := range e.Values {
+		alias := v.Alias
+		if alias == "" {
+			alias = xo.EnumAlias(v.Name)
+		}
+		vals = append(vals, EnumValue{
+			Name:       attrName(strings.ToUpper(snake(alias))),
+			SQLName:    v.Name,
+			ConstValue: *v.ConstValue,
+		})
+	}
+	return Enum{
+		Name:    name,
+		SQLName: e.Name,
+		Schema:  schema,
+		Module:  snake(name),
+		Values:  vals,
+	}
+}
+
+// convertComposite converts a xo.Composite.
+func convertComposite(ctx context.Context, schema string, c xo.Composite, types Types) (Composite, error) {
+	name := camelExport(schemaPrefix(ctx, schema) + c.Name)
+	var fields []Field
+	for _, z := range c.Fields {
+		f, err := convertField(ctx, snake, z, types)
+		if err != nil {
+			return Composite{}, err
+		}
+		fields = append(fields, f)
+	}
+	return Composite{
+		Name:    name,
+		SQLName: c.Name,
+		Schema:  schema,
+		Module:  snake(name),
+		Fields:  fields,
+	}, nil
+}
+
+// convertRange converts a xo.Range.
+func convertRange(ctx context.Context, schema string, r xo.Range) (Range, error) {
+	name := camelExport(schemaPrefix(ctx, schema) + r.Name)
+	typ, _, err := pyType(ctx, r.SubType)
+	if err != nil {
+		return Range{}, err
+	}
+	return Range{
+		Name:    name,
+		SQLName: r.Name,
+		Schema:  schema,
+		Module:  snake(name),
+		Type:    typ,
+	}, nil
+}
+
+// convertTable converts a xo.Table to a Table.
+func convertTable(ctx context.Context, schema string, t xo.Table, types Types) (Table, error) {
+	naming := xo.Naming(ctx)
+	name := camelExport(schemaPrefix(ctx, schema) + naming.Type(t.Name))
+	var cols, pkCols []Field
+	sequenceIdx, softDeleteIdx, createdIdx, updatedIdx := -1, -1, -1, -1
+	for i, z := range t.Columns {
+		f, err := convertField(ctx, columnName(ctx, t.Name), z, types)
+		if err != nil {
+			return Table{}, err
+		}
+		cols = append(cols, f)
+		if z.IsPrimary {
+			pkCols = append(pkCols, f)
+		}
+		if name := SoftDelete(ctx); name != "" && z.Name == name && !z.IsPrimary {
+			softDeleteIdx = i
+		}
+		// audit columns must be set in the generated code
+		if name := Created(ctx); name != "" && z.Name == name && !z.IsPrimary && !z.IsGenerated && isAuditType(f) {
+			createdIdx = i
+		}
+		if name := Updated(ctx); name != "" && z.Name == name && !z.IsPrimary && !z.IsGenerated && isAuditType(f) {
+			updatedIdx = i
+		}
+		// prefer a primary key sequence as the field returned on insert
+		if z.IsSequence && (sequenceIdx == -1 || (z.IsPrimary && !t.Columns[sequenceIdx].IsPrimary)) {
+			sequenceIdx = i
+		}
+	}
+	// the fields are referenced by index, as the interpreter reuses the
+	// loop's variables
+	var sequence, softDelete, created, updated *Field
+	if softDeleteIdx != -1 {
+		softDelete = &cols[softDeleteIdx]
+	}
+	if createdIdx != -1 {
+		created = &cols[createdIdx]
+	}
+	if updatedIdx != -1 {
+		updated = &cols[updatedIdx]
+	}
+	manual := t.Manual
+	if sequenceIdx != -1 {
+		sequence = &cols[sequenceIdx]
+	} else {
+		// the sequence column may have been excluded
+		manual = true
+	}
+	table := Table{
+		Type:        t.Type,
+		Name:        name,
+		SQLName:     t.Name,
+		Schema:      schema,
+		Module:      snake(name),
+		Fields:      cols,
+		PrimaryKeys: pkCols,
+		Sequence:    sequence,
+		SoftDelete:  softDelete,
+		Created:     created,
+		Updated:     updated,
+		Manual:      manual,
+	}
+	for _, fk := range t.ForeignKeys {
+		fkey, err := convertFKey(ctx, table, fk, types)
+		if err != nil {
+			return Table{}, err
+		}
+		table.ForeignKeys = append(table.ForeignKeys, fkey)
+	}
+	return table, nil
+}
+
+// convertIndex converts a xo.Index.
+func convertIndex(ctx context.Context, t Table, i xo.Index, types Types) (Index, error) {
+	var fields []Field
+	for _, z := range i.Fields {
+		f, err := convertField(ctx, columnName(ctx, t.SQLName), z, types)
+		if err != nil {
+			return Index{}, err
+		}
+		f.Name = paramName(f.Name)
+		fields = append(fields, f)
+	}
+	return Index{
+		Func:                funcName(schemaPrefix(ctx, t.Schema) + i.Func),
+		SQLName:             i.Name,
+		Table:               t,
+		Fields:              fields,
+		Predicate:           i.Predicate,
+		SoftDeletePredicate: t.SoftDelete != nil && predicateRefs(i.Predicate, t.SoftDelete.SQLName),
+		IsUnique:            i.IsUnique,
+		IsPrimary:           i.IsPrimary,
+	}, nil
+}
+
+// convertPage builds the keyset pagination func for a table, ordering by the
+// primary key or, when the table has no primary key, the first unique index
+// on non-nullable columns. Returns false when the table has no suitable key.
+func convertPage(ctx context.Context, t Table, table xo.Table, types Types) (PageFunc, bool, error) {
+	page := PageFunc{
+		Name:   funcName("List" + inflector.Pluralize(t.Name)),
+		Cursor: t.Name + "Cursor",
+		Table:  t,
+		Fields: t.PrimaryKeys,
+	}
+	if len(page.Fields) != 0 {
+		return page, true, nil
+	}
+	for _, i := range table.Indexes {
+		if !i.IsUnique || len(i.Expressions) != 0 || len(i.Fields) == 0 || i.Predicate != "" {
+			continue
+		}
+		nullable := false
+		for _, z := range i.Fields {
+			nullable = nullable || z.Type.Nullable
+		}
+		if nullable {
+			continue
+		}
+		var fields []Field
+		for _, z := range i.Fields {
+			f, err := convertField(ctx, columnName(ctx, t.SQLName), z, types)
+			if err != nil {
+				return PageFunc{}, false, err
+			}
+			fields = append(fields, f)
+		}
+		page.Fields = fields
+		return page, true, nil
+	}
+	return PageFunc{}, false, nil
+}
+
+// convertRefresh builds the refresh funcs for a materialized view. A func
+// refreshing the view concurrently is included when the view has a unique
+// index on its columns, as required by postgres.
+func convertRefresh(t Table, table xo.Table) []RefreshFunc {
+	refreshes := []RefreshFunc{{
+		Name:  funcName("Refresh" + t.Name),
+		Table: t,
+	}}
+	for _, i := range table.Indexes {
+		if i.IsUnique && len(i.Expressions) == 0 && len(i.Fields) != 0 && i.Predicate == "" {
+			return append(refreshes, RefreshFunc{
+				Name:         funcName("Refresh" + t.Name + "Concurrently"),
+				Table:        t,
+				Concurrently: true,
+			})
+		}
+	}
+	return refreshes
+}
+
+// convertSettings builds the funcs setting the settings read by the schema's
+// row level security policies.
+func convertSettings(ctx context.Context, schema xo.Schema) []SettingFunc {
+	var settings []SettingFunc
+	idx := make(map[string]int)
+	for _, t := range schema.Tables {
+		for _, p := range t.Policies {
+			for _, name := range p.Settings {
+				i, ok := idx[name]
+				if !ok {
+					i = len(settings)
+					idx[name] = i
+					settings = append(settings, SettingFunc{
+						Name:    funcName(schemaPrefix(ctx, schema.Name) + "set_" + settingNameRE.ReplaceAllString(name, "_")),
+						SQLName: name,
+						Schema:  schema.Name,
+					})
+				}
+				if n := len(settings[i].Tables); n == 0 || settings[i].Tables[n-1] != t.Name {
+					settings[i].Tables = append(settings[i].Tables, t.Name)
+				}
+			}
+		}
+	}
+	return settings
+}
+
+// settingNameRE matches the characters of a setting name that are not valid
+// in an identifier.
+var settingNameRE = regexp.MustCompile(`[^A-Za-z0-9_]+`)
+
+// convertFilter builds the filter builder func for a table, filtering on the
+// table's primary key and indexed columns. Returns false when the table has
+// no such columns. Array columns are not filtered.
+func convertFilter(t Table, table xo.Table) (FilterFunc, bool) {
+	indexed := make(map[string]bool)
+	for _, z := range table.PrimaryKeys {
+		indexed[z.Name] = true
+	}
+	for _, i := range table.Indexes {
+		for _, z := range i.Fields {
+			indexed[z.Name] = true
+		}
+	}
+	var fields []Field
+	var params []FilterParam
+	for _, z := range t.Fields {
+		if !indexed[z.SQLName] || z.IsArray {
+			continue
+		}
+		fields = append(fields, z)
+		name, typ := paramName(z.Name), strings.TrimSuffix(strings.TrimPrefix(z.Type, "Optional["), "]")
+		if !z.Nullable {
+			typ = z.Type
+		}
+		for _, op := range filterOps {
+			p := FilterParam{
+				Name:  name + op.Suffix,
+				Type:  "Optional[" + typ + "]",
+				Op:    op.Op,
+				Field: z,
+			}
+			switch op.Op {
+			case "IN":
+				p.Type = "Optional[Iterable[" + typ + "]]"
+			case "IS NULL":
+				if !z.Nullable {
+					continue
+				}
+				p.Type = "Optional[bool]"
+			}
+			params = append(params, p)
+		}
+	}
+	if len(fields) == 0 {
+		return FilterFunc{}, false
+	}
+	return FilterFunc{
+		Name:   funcName("Find" + inflector.Pluralize(t.Name)),
+		Table:  t,
+		Fields: fields,
+		Params: params,
+	}, true
+}
+
+// filterOps are the filters generated for each filter field, as the suffix
+// of the keyword arg and the comparison.
+var filterOps = []struct {
+	Suffix string
+	Op     string
+}{
+	{"", "="},
+	{"__in", "IN"},
+	{"__lt", "<"},
+	{"__lte", "<="},
+	{"__gt", ">"},
+	{"__gte", ">="},
+	{"__is_null", "IS NULL"},
+}
+
+// emitTests emits the round trip tests for the schema's tables, as a pytest
+// module.
+//
+// Tests are only generated for tables with a primary key, as rows are
+// inserted using the table's factory.
+func (e *emitter) emitTests(schema xo.Schema, types Types) error {
+	const module = "test_roundtrip"
+	driver, _, _ := xo.DriverDbSchema(e.ctx)
+	imports := e.module(module, schema.Name)
+	imports.add(imports.Std, "os", "")
+	imports.add(imports.Std, "typing", "Any")
+	imports.add(imports.Std, "typing", "Iterator")
+	imports.add(imports.Third, "pytest", "")
+	switch driver {
+	case "mysql", "sqlserver":
+		imports.add(imports.Std, "urllib.parse", "")
+	case "sqlite3":
+		imports.add(imports.Std, "sqlite3", "")
+	}
+	if !e.tests {
+		e.add(module, "testdb", "", "", schema.Name)
+		e.tests = true
+	}
+	for _, t := range schema.Tables {
+		if len(t.PrimaryKeys) == 0 {
+			continue
+		}
+		table, err := convertTable(e.ctx, schema.Name, t, types)
+		if err != nil {
+			return err
+		}
+		test := RoundTrip{
+			Name:   funcName("Test" + table.Name + "RoundTrip"),
+			Create: funcName("Create" + table.Name),
+			Table:  table,
+		}
+		e.local(imports, module, "factory", test.Create)
+		// get by primary key
+		for _, i := range t.Indexes {
+			if !i.IsPrimary || len(i.Expressions) != 0 || len(i.Fields) == 0 {
+				continue
+			}
+			index, err := convertIndex(e.ctx, table, i, types)
+			if err != nil {
+				return err
+			}
+			e.local(imports, module, table.Module, index.Func)
+			// the index's params are passed the row's attributes
+			args := []string{"db"}
+			for _, z := range index.Fields {
+				for _, f := range table.Fields {
+					if f.SQLName == z.SQLName {
+						args = append(args, "v."+f.Name)
+					}
+				}
+			}
+			test.Lookup = index.Func + "(" + strings.Join(args, ", ") + ")"
+			break
+		}
+		e.add(module, "roundtrip", "", table.Name, test)
+	}
+	return nil
+}
+
+// fakeValue returns the Python expression of a fake value for the field,
+// using the factory sequence n. Returns an empty string for unsupported
+// types. Enums are left to their default, the first value.
+func fakeValue(z Field) string {
+	if z.Enum != "" || z.IsArray {
+		return ""
+	}
+	// ranges from the fake value of the lower bound
+	if z.Range != "" {
+		if lower := fakeValue(Field{SQLName: z.SQLName, Type: z.Range}); lower != "" {
+			return "Range(" + lower + ")"
+		}
+		return ""
+	}
+	switch z.Type {
+	case "int":
+		return "n"
+	case "float":
+		return "float(n)"
+	case "bool":
+		return "n % 2 == 0"
+	case "str":
+		return strconv.Quote(z.SQLName+"-") + " + str(n)"
+	case "bytes":
+		return "(" + strconv.Quote(z.SQLName+"-") + " + str(n)).encode()"
+	case "decimal.Decimal":
+		return "decimal.Decimal(n)"
+	case "uuid.UUID":
+		return "uuid.UUID(int=n)"
+	case "datetime.datetime":
+		return "_factory_time + datetime.timedelta(minutes=n)"
+	case "datetime.date":
+		return "_factory_time.date() + datetime.timedelta(days=n)"
+	case "datetime.time":
+		return "(_factory_time + datetime.timedelta(minutes=n)).time()"
+	case "datetime.timedelta":
+		return "datetime.timedelta(minutes=n)"
+	case "Geometry":
+		switch z.Geometry {
+		case "geometry", "geography", "point":
+			return fmt.Sprintf("Geometry.point(float(n), float(n), %d)", z.SRID)
+		}
+	}
+	return ""
+}
+
+// predicateRefs returns true when the partial index predicate refers to the
+// column.
+func predicateRefs(predicate, column string) bool {
+	for _, s := range strings.FieldsFunc(predicate, func(r rune) bool {
+		return r != '_' && r != '$' && !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}) {
+		if strings.EqualFold(s, column) {
+			return true
+		}
+	}
+	return false
+}
+
+// convertFKey converts a xo.ForeignKey.
+func convertFKey(ctx context.Context, t Table, fk xo.ForeignKey, types Types) (ForeignKey, error) {
+	refSchema := t.Schema
+	if fk.RefSchema != "" {
+		refSchema = fk.RefSchema
+	}
+	prefix := schemaPrefix(ctx, refSchema)
+	var fields, refFields []Field
+	for _, z := range fk.Fields {
+		f, err := convertField(ctx, columnName(ctx, t.SQLName), z, types)
+		if err != nil {
+			return ForeignKey{}, err
+		}
+		fields = append(fields, f)
+	}
+	for _, z := range fk.RefFields {
+		f, err := convertField(ctx, columnName(ctx, fk.RefTable), z, types)
+		if err != nil {
+			return ForeignKey{}, err
+		}
+		refFields = append(refFields, f)
+	}
+	refTable := camelExport(prefix + xo.Naming(ctx).Type(fk.RefTable))
+	return ForeignKey{
+		Name:      attrName(funcName(prefix + fk.Func)),
+		SQLName:   fk.Name,
+		Table:     t.Name,
+		Fields:    fields,
+		RefTable:  refTable,
+		RefModule: snake(refTable),
+		RefFunc:   funcName(prefix + fk.RefFunc),
+		RefFields: refFields,
+	}, nil
+}
+
+// columnName returns a transform func for the names of the table's columns,
+// applying renames from the naming convention.
+func columnName(ctx context.Context, table string) transformFunc {
+	naming := xo.Naming(ctx)
+	return func(names ...string) string {
+		return snake(camelExport(naming.Column(table, strings.Join(names, "_"))))
+	}
+}
+
+// convertField converts a xo.Field.
+func convertField(ctx context.Context, tf transformFunc, f xo.Field, types Types) (Field, error) {
+	typ, def, err := pyType(ctx, f.Type)
+	if err != nil {
+		return Field{}, err
+	}
+	var enum, composite, rng string
+	if e, ok := types.Enums[f.Type.Type]; ok {
+		enum = e.Name
+		typ, def = e.Name, e.Name+"."+e.Values[0].Name
+	}
+	if c, ok := types.Composites[f.Type.Type]; ok {
+		composite = c.Name
+		typ, def = c.Name, "field(default_factory="+c.Name+")"
+	}
+	if r, ok := types.Ranges[f.Type.Type]; ok {
+		typ, def = "Range["+r.Type+"]", "field(default_factory=Range)"
+	}
+	if strings.HasPrefix(typ, "Range[") {
+		rng = typ[len("Range[") : len(typ)-1]
+	}
+	// spatial subtype and srid (the type name for mysql)
+	var geometry string
+	if typ == "Geometry" {
+		geometry = f.Type.Geometry
+		if geometry == "" {
+			geometry = f.Type.Type
+		}
+	}
+	// sqlite3 stores bools as integers, and dates and times as text (or
+	// integers)
+	var sqlite3 string
+	if driver, _, _ := xo.DriverDbSchema(ctx); driver == "sqlite3" {
+		switch typ {
+		case "bool", "datetime.datetime", "datetime.date", "datetime.time":
+			sqlite3 = typ
+		}
+	}
+	// json column with a concrete type, imported from its module
+	jsonType, jsonModule := f.JSONType, JSONModule(ctx)
+	if i := strings.LastIndex(f.JSONType, "."); i != -1 {
+		jsonType = f.JSONType[i+1:]
+		jsonModule = f.JSONType[:i]
+	}
+	switch {
+	case jsonType != "" && jsonModule == "":
+		return Field{}, fmt.Errorf("json type %q of column %q is not qualified by its module (see --python-json-module)", f.JSONType, f.Name)
+	case jsonType != "":
+		typ, def = jsonType, "field(default_factory="+jsonType+")"
+	default:
+		jsonModule = ""
+	}
+	switch {
+	case f.Type.IsArray && f.Type.Nullable:
+		typ, def = "Optional[list["+typ+"]]", "None"
+	case f.Type.IsArray:
+		typ, def = "list["+typ+"]", "field(default_factory=list)"
+	case f.Type.Nullable:
+		typ, def = "Optional["+typ+"]", "None"
+	}
+	return Field{
+		Name:        attrName(tf(f.Name)),
+		SQLName:     f.Name,
+		Type:        typ,
+		Default:     def,
+		Enum:        enum,
+		Composite:   composite,
+		Range:       rng,
+		JSONType:    jsonType,
+		JSONModule:  jsonModule,
+		Geometry:    geometry,
+		SRID:        f.Type.SRID,
+		Sqlite3:     sqlite3,
+		IsArray:     f.Type.IsArray,
+		Nullable:    f.Type.Nullable,
+		IsPrimary:   f.IsPrimary,
+		IsSequence:  f.IsSequence,
+		IsGenerated: f.IsGenerated,
+		Sensitive:   f.Sensitive,
+	}, nil
+}
+
+// pyType returns the Python type and default value of a type, without its
+// nullability or array dimension.
+func pyType(ctx context.Context, typ xo.Type) (string, string, error) {
+	driver, _, schema := xo.DriverDbSchema(ctx)
+	var f func(xo.Type, string, string, string) (string, string, error)
+	switch driver {
+	case "mysql":
+		f = loader.MysqlGoType
+	case "oracle":
+		f = loader.OracleGoType
+	case "postgres":
+		f = loader.StdlibPostgresGoType
+	case "sqlite3":
+		f = loader.Sqlite3GoType
+	case "sqlserver":
+		f = loader.SqlserverGoType
+	default:
+		return "", "", fmt.Errorf("unknown driver %q", driver)
+	}
+	t := typ
+	t.Nullable, t.IsArray = false, false
+	goType, _, err := f(t, schema, "int", "uint")
+	if err != nil {
+		return "", "", err
+	}
+	// sqlite3 dates and times are the generated Time type in Go
+	if goType == "Time" && driver == "sqlite3" {
+		goType = "time.Time"
+	}
+	// refine by the database type
+	base := strings.ToLower(typ.Type)
+	if i := strings.IndexAny(base, "( "); i != -1 && !strings.HasPrefix(base, "time") && !strings.HasPrefix(base, "double") {
+		base = base[:i]
+	}
+	switch {
+	case (goType == "float64" || goType == "float32") && (base == "numeric" || base == "decimal") && driver != "sqlite3":
+		return "decimal.Decimal", "decimal.Decimal(0)", nil
+	case goType == "[]byte" && (base == "json" || base == "jsonb"):
+		return "Any", "None", nil
+	case goType == "[]byte" && base == "interval":
+		return "datetime.timedelta", "datetime.timedelta()", nil
+	case goType == "time.Time" && base == "date":
+		return "datetime.date", "datetime.date.min", nil
+	case goType == "time.Time" && strings.HasPrefix(base, "time") && !strings.HasPrefix(base, "timestamp"):
+		return "datetime.time", "datetime.time()", nil
+	case strings.HasPrefix(goType, "Range[") && rangeTypes[base] != "":
+		return "Range[" + rangeTypes[base] + "]", "field(default_factory=Range)", nil
+	}
+	t2, def := convertGoType(goType, typ)
+	return t2, def, nil
+}
+
+// rangeTypes are the Python types of the bounds of the built-in range types.
+var rangeTypes = map[string]string{
+	"int4range": "int",
+	"int8range": "int",
+	"numrange":  "decimal.Decimal",
+	"daterange": "datetime.date",
+	"tsrange":   "datetime.datetime",
+	"tstzrange": "datetime.datetime",
+}
+
+// convertGoType returns the Python type and default value of a Go type.
+func convertGoType(goType string, typ xo.Type) (string, string) {
+	switch {
+	case goType == "bool":
+		return "bool", "False"
+	case goType == "string":
+		return "str", `""`
+	case goType == "byte" || goType == "rune" || intRE.MatchString(goType):
+		return "int", "0"
+	case goType == "float32" || goType == "float64":
+		return "float", "0.0"
+	case goType == "[]byte":
+		return "bytes", `b""`
+	case goType == "time.Time":
+		return "datetime.datetime", "datetime.datetime.min"
+	case goType == "uuid.UUID":
+		return "uuid.UUID", "uuid.UUID(int=0)"
+	case goType == "Geometry":
+		return "Geometry", "field(default_factory=Geometry)"
+	case goType == "hstore.Hstore":
+		return "dict[str, Optional[str]]", "field(default_factory=dict)"
+	}
+	return "Any", "None"
+}
+
+// intRE matches Go int types.
+var intRE = regexp.MustCompile(`^u?int(8|16|32|64)?$`)
+
+// typeImports adds the imports used by the Python type expression.
+func typeImports(imports *Imports, s string) {
+	for _, mod := range []string{"datetime", "decimal", "uuid"} {
+		if strings.Contains(s, mod+".") {
+			imports.add(imports.Std, mod, "")
+		}
+	}
+	for _, name := range []string{"Any", "Optional"} {
+		if regexp.MustCompile(`\b` + name + `\b`).MatchString(s) {
+			imports.add(imports.Std, "typing", name)
+		}
+	}
+	if strings.Contains(s, "field(") {
+		imports.add(imports.Std, "dataclasses", "field")
+	}
+	for _, name := range []string{"Geometry", "Range"} {
+		if regexp.MustCompile(`\b` + name + `\b`).MatchString(s) {
+			imports.add(imports.Utils, "", name)
+		}
+	}
+}
+
+type transformFunc func(...string) string
+
+// digitRE matches a digit followed by a upper case letter.
+var digitRE = regexp.MustCompile(`([0-9])([A-Z])`)
+
+// funcName returns the snake cased name of a func, separating the words
+// following digits (ie, ByKey1Key2 is by_key1_key2).
+func funcName(name string) string {
+	return snake(digitRE.ReplaceAllString(camelExport(name), "${1}_${2}"))
+}
+
+func snake(names ...string) string {
+	return snaker.CamelToSnake(strings.Join(names, "_"))
+}
+
+func camelExport(names ...string) string {
+	return snaker.ForceCamelIdentifier(strings.Join(names, "_"))
+}
+
+// attrName returns the name, with a suffix when it is a Python keyword or the
+// name of a generated method.
+func attrName(name string) string {
+	if pyReservedNames[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// paramName returns the name, with a suffix when it is a Python keyword or
+// the name of a generated func's variable.
+func paramName(name string) string {
+	if pyReservedNames[name] || localNames[name] {
+		return name + "_"
+	}
+	return name
+}
+
+// pyReservedNames are the Python keywords and the names of the generated
+// methods.
+var pyReservedNames = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true,
+	"assert": true, "async": true, "await": true, "break": true,
+	"class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true,
+	"global": true, "if": true, "import": true, "in": true, "is": true,
+	"lambda": true, "nonlocal": true, "not": true, "or": true, "pass": true,
+	"raise": true, "return": true, "try": true, "while": true, "with": true,
+	"yield": true,
+	// generated methods
+	"exists": true, "deleted": true, "insert": true, "update": true,
+	"save": true, "upsert": true, "delete": true,
+}
+
+// localNames are the names of the variables of the generated funcs.
+var localNames = map[string]bool{
+	"db":     true,
+	"ctx":    true,
+	"sqlstr": true,
+	"args":   true,
+	"cur":    true,
+	"row":    true,
+	"rows":   true,
+	"where":  true,
+	"self":   true,
+	"cls":    true,
+}
+
+// ext is the extension of the generated modules.
+const ext = ".py"
+
+// pyNth returns the nth placeholder for the Python driver of the driver.
+func pyNth(driver string, n int) string {
+	switch driver {
+	case "sqlite3":
+		return "?"
+	case "oracle":
+		return ":" + strconv.Itoa(n+1)
+	}
+	return "%s"
+}
+
+// escPct escapes the percent signs of s for drivers using the format
+// placeholders.
+func escPct(driver, s string) string {
+	if pyNth(driver, 0) == "%s" {
+		return strings.ReplaceAll(s, "%", "%%")
+	}
+	return s
+}
+
+// Funcs is a set of template funcs.
+type Funcs struct {
+	driver    string
+	schema    string
+	escSchema bool
+	escTable  bool
+	escColumn bool
+	hooks     bool
+}
+
+// NewFuncs creates custom template funcs for the context.
+func NewFuncs(ctx context.Context) (template.FuncMap, error) {
+	driver, _, schema := xo.DriverDbSchema(ctx)
+	funcs := &Funcs{
+		driver:    driver,
+		schema:    schema,
+		escSchema: Esc(ctx, "schema"),
+		escTable:  Esc(ctx, "table"),
+		escColumn: Esc(ctx, "column"),
+		hooks:     Hooks(ctx),
+	}
+	return template.FuncMap{
+		"driver":    funcs.driverfn,
+		"qualify":   funcs.qualify,
+		"pkg":       func() string { return Pkg(ctx) },
+		"sqlstr":    funcs.sqlstr,
+		"args":      funcs.args,
+		"logf":      funcs.logf,
+		"hook":      funcs.hook,
+		"params":    funcs.params,
+		"decode":    decode,
+		"text_type": textType,
+		"returns":   funcs.returns,
+		"audit":     audit,
+		"sensitive": sensitiveFields,
+		"repr":      repr,
+		"paginate": func() bool {
+			return Paginate(ctx)
+		},
+		"stream": func() bool {
+			return Stream(ctx)
+		},
+		"repository": func() bool {
+			return Repository(ctx)
+		},
+		"filter": func() bool {
+			return Filter(ctx)
+		},
+		"hooks": func() bool {
+			return Hooks(ctx)
+		},
+		"filter_col":    funcs.filter_col,
+		"filter_arg":    filter_arg,
+		"filter_where":  funcs.filter_where,
+		"filterstr":     funcs.filterstr,
+		"factory_unset": factory_unset,
+		"page_args":     funcs.page_args,
+		"page_key":      page_key,
+	}, nil
+}
+
+// driverfn returns true if the driver is any of the passed drivers.
+func (f *Funcs) driverfn(drivers ...string) bool {
+	for _, driver := range drivers {
+		if f.driver == driver {
+			return true
+		}
+	}
+	return false
+}
+
+// qualify returns the qualified name, using the schema when names are in
+// another schema.
+func (f *Funcs) qualify(s string, names ...string) string {
+	if s == "" {
+		s = f.schema
+	}
+	if f.escTable {
+		for i, name := range names {
+			names[i] = escfn(name)
+		}
+	}
+	n := strings.Join(names, ".")
+	switch {
+	case s == "" && n == "":
+		return ""
+	case f.driver == "sqlite3" && n == "":
+		return s
+	case f.driver == "sqlite3":
+		return n
+	case s != "" && n != "":
+		if f.escSchema {
+			s = escfn(s)
+		}
+		s += "."
+	}
+	return s + n
+}
+
+// colname returns the column name of the field.
+func (f *Funcs) colname(z Field) string {
+	if f.escColumn {
+		return escfn(z.SQLName)
+	}
+	return z.SQLName
+}
+
+// nth returns the nth placeholder.
+func (f *Funcs) nth(n int) string {
+	return pyNth(f.driver, n)
+}
+
+// sqlstr builds the Python assignment of a query to sqlstr. The lines after
+// the first are indented to the level.
+func (f *Funcs) sqlstr(typ string, v interface{}, level int) string {
+	var lines []string
+	switch typ {
+	case "insert_manual":
+		lines = f.sqlstr_insert_base(true, v)
+	case "insert":
+		lines = f.sqlstr_insert(v)
+	case "update":
+		lines = f.sqlstr_update(v)
+	case "upsert":
+		lines = f.sqlstr_upsert(v)
+	case "delete":
+		lines = f.sqlstr_delete(v)
+	case "index":
+		lines = f.sqlstr_index(v)
+	case "refresh":
+		lines = f.sqlstr_refresh(v)
+	case "setting":
+		lines = f.sqlstr_setting(v)
+	case "page":
+		lines = f.sqlstr_page(false, v)
+	case "page_after":
+		lines = f.sqlstr_page(true, v)
+	case "query":
+		lines = v.(Query).SQL
+	default:
+		return fmt.Sprintf(`sqlstr = "UNKNOWN QUERY TYPE: %s"`, typ)
+	}
+	if typ != "query" {
+		for i, line := range lines {
+			lines[i] = strconv.Quote(line)
+		}
+	}
+	if len(lines) == 1 {
+		return "sqlstr = " + lines[0]
+	}
+	indent := strings.Repeat("    ", level)
+	return "sqlstr = (\n" + indent + "    " + strings.Join(lines, "\n"+indent+"    ") + "\n" + indent + ")"
+}
+
+// sqlstr_refresh builds a REFRESH MATERIALIZED VIEW query.
+func (f *Funcs) sqlstr_refresh(v interface{}) []string {
+	switch x := v.(type) {
+	case RefreshFunc:
+		if x.Concurrently {
+			return []string{"REFRESH MATERIALIZED VIEW CONCURRENTLY " + f.qualify(x.Table.Schema, x.Table.SQLName)}
+		}
+		return []string{"REFRESH MATERIALIZED VIEW " + f.qualify(x.Table.Schema, x.Table.SQLName)}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_setting builds a query setting a setting with set_config.
+func (f *Funcs) sqlstr_setting(v interface{}) []string {
+	switch x := v.(type) {
+	case SettingFunc:
+		name := "'" + strings.ReplaceAll(escPct(f.driver, x.SQLName), "'", "''") + "'"
+		return []string{"SELECT set_config(" + name + ", " + f.nth(0) + ", " + f.nth(1) + ")"}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_insert_base builds an INSERT query. If not all, sequence columns are
+// skipped. Generated columns are always skipped.
+func (f *Funcs) sqlstr_insert_base(all bool, v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		var fields, vals []string
+		for _, z := range insertFields(all, x) {
+			fields, vals = append(fields, f.colname(z)), append(vals, f.nth(len(vals)))
+		}
+		return []string{
+			"INSERT INTO " + f.qualify(x.Schema, x.SQLName) + " (",
+			strings.Join(fields, ", "),
+			") VALUES (",
+			strings.Join(vals, ", "),
+			")",
+		}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_insert builds an INSERT query, skipping sequence fields, returning
+// the table's sequence field (or, for postgres, all of the table's sequence
+// fields).
+func (f *Funcs) sqlstr_insert(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		if x.Sequence == nil {
+			return []string{fmt.Sprintf("[[ NO SEQUENCE FIELD: %s ]]", x.SQLName)}
+		}
+		seq := *x.Sequence
+		lines := f.sqlstr_insert_base(false, v)
+		switch f.driver {
+		case "postgres":
+			var names []string
+			for _, z := range sequenceFields(x) {
+				names = append(names, f.colname(z))
+			}
+			lines[len(lines)-1] += " RETURNING " + strings.Join(names, ", ")
+		case "sqlserver":
+			lines[2] = ") OUTPUT INSERTED." + f.colname(seq) + " VALUES ("
+		case "oracle":
+			lines[len(lines)-1] += " RETURNING " + f.colname(seq) + " INTO " + f.nth(len(insertFields(false, x)))
+		}
+		return lines
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_update_base builds the SET clause of an UPDATE query. When prefix is
+// empty, the values are placeholders, otherwise prefixed column names.
+func (f *Funcs) sqlstr_update_base(prefix string, x Table) []string {
+	var list []string
+	for _, z := range updateFields(x) {
+		name, param := f.colname(z), f.nth(len(list))
+		if prefix != "" {
+			param = prefix + name
+		}
+		list = append(list, name+" = "+param)
+	}
+	name := ""
+	if prefix == "" {
+		name = f.qualify(x.Schema, x.SQLName) + " "
+	}
+	return []string{
+		"UPDATE " + name + "SET ",
+		strings.Join(list, ", ") + " ",
+	}
+}
+
+// sqlstr_update builds an UPDATE query, using primary key fields as the WHERE
+// clause.
+func (f *Funcs) sqlstr_update(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		n := len(updateFields(x))
+		var list []string
+		for i, z := range x.PrimaryKeys {
+			list = append(list, f.colname(z)+" = "+f.nth(n+i))
+		}
+		return append(f.sqlstr_update_base("", x), "WHERE "+strings.Join(list, " AND "))
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_upsert builds an upsert query.
+func (f *Funcs) sqlstr_upsert(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		switch f.driver {
+		case "postgres", "sqlite3":
+			var conflicts []string
+			for _, z := range x.PrimaryKeys {
+				conflicts = append(conflicts, f.colname(z))
+			}
+			lines := f.sqlstr_insert_base(true, x)
+			lines = append(lines, " ON CONFLICT ("+strings.Join(conflicts, ", ")+") DO ")
+			if len(updateFields(x)) == 0 {
+				return append(lines, "NOTHING")
+			}
+			return append(lines, f.sqlstr_update_base("EXCLUDED.", x)...)
+		case "mysql":
+			var list []string
+			for _, z := range x.Fields {
+				if z.IsSequence || z.IsGenerated || isCreated(x, z) {
+					continue
+				}
+				name := f.colname(z)
+				list = append(list, name+" = VALUES("+name+")")
+			}
+			lines := f.sqlstr_insert_base(true, x)
+			return append(lines, " ON DUPLICATE KEY UPDATE ", strings.Join(list, ", "))
+		case "sqlserver", "oracle":
+			return f.sqlstr_merge(x)
+		}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE %s: %T ]]", f.driver, v)}
+}
+
+// sqlstr_merge builds a MERGE query for sqlserver and oracle.
+func (f *Funcs) sqlstr_merge(x Table) []string {
+	var lines []string
+	switch f.driver {
+	case "sqlserver":
+		lines = []string{"MERGE " + f.qualify(x.Schema, x.SQLName) + " AS t "}
+	case "oracle":
+		lines = []string{"MERGE " + f.qualify(x.Schema, x.SQLName) + " t "}
+	}
+	var fields, predicate []string
+	for _, z := range insertFields(true, x) {
+		fields = append(fields, f.nth(len(fields))+" "+f.colname(z))
+	}
+	for _, z := range x.PrimaryKeys {
+		predicate = append(predicate, "s."+f.colname(z)+" = t."+f.colname(z))
+	}
+	closing := ") AS s "
+	if f.driver == "oracle" {
+		closing = "FROM DUAL ) s "
+	}
+	lines = append(lines, "USING (",
+		"SELECT "+strings.Join(fields, ", ")+" ",
+		closing,
+		"ON "+strings.Join(predicate, " AND ")+" ")
+	var updateParams, insertParams, insertVals []string
+	for _, z := range x.Fields {
+		// sequences and generated columns are always managed by db
+		if z.IsSequence || z.IsGenerated {
+			continue
+		}
+		// primary keys and the created field are not updated
+		if !z.IsPrimary && !isCreated(x, z) {
+			updateParams = append(updateParams, "t."+f.colname(z)+" = s."+f.colname(z))
+		}
+		insertParams = append(insertParams, f.colname(z))
+		insertVals = append(insertVals, "s."+f.colname(z))
+	}
+	return append(lines,
+		"WHEN MATCHED THEN ", "UPDATE SET ",
+		strings.Join(updateParams, ", ")+" ",
+		"WHEN NOT MATCHED THEN ",
+		"INSERT (",
+		strings.Join(insertParams, ", "),
+		") VALUES (",
+		strings.Join(insertVals, ", "),
+		");",
+	)
+}
+
+// sqlstr_delete builds a DELETE query, using the primary key fields as the
+// WHERE clause.
+func (f *Funcs) sqlstr_delete(v interface{}) []string {
+	switch x := v.(type) {
+	case Table:
+		var list []string
+		for i, z := range x.PrimaryKeys {
+			list = append(list, f.colname(z)+" = "+f.nth(i))
+		}
+		// soft delete
+		if z := x.SoftDelete; z != nil {
+			value := "CURRENT_TIMESTAMP"
+			if isBool(*z) {
+				value = f.boolLiteral(true)
+			}
+			return []string{
+				"UPDATE " + f.qualify(x.Schema, x.SQLName) + " ",
+				"SET " + f.colname(*z) + " = " + value + " ",
+				"WHERE " + strings.Join(list, " AND "),
+			}
+		}
+		return []string{
+			"DELETE FROM " + f.qualify(x.Schema, x.SQLName) + " ",
+			"WHERE " + strings.Join(list, " AND "),
+		}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// sqlstr_page builds the query for a page of rows, after the cursor when
+// after is true.
+func (f *Funcs) sqlstr_page(after bool, v interface{}) []string {
+	switch x := v.(type) {
+	case PageFunc:
+		var fields []string
+		for _, z := range x.Table.Fields {
+			fields = append(fields, f.colname(z))
+		}
+		var keys []string
+		for _, z := range x.Fields {
+			keys = append(keys, f.colname(z))
+		}
+		var n int
+		var list []string
+		// keys after cursor
+		switch {
+		case !after:
+		case f.driver == "sqlserver" || f.driver == "oracle":
+			var or []string
+			for i := range keys {
+				var and []string
+				for j, key := range keys[:i+1] {
+					op := "="
+					if j == i {
+						op = ">"
+					}
+					and = append(and, key+" "+op+" "+f.nth(n))
+					n++
+				}
+				or = append(or, strings.Join(and, " AND "))
+			}
+			if len(or) == 1 {
+				list = append(list, or[0])
+			} else {
+				list = append(list, "(("+strings.Join(or, ") OR (")+"))")
+			}
+		case len(keys) == 1:
+			list = append(list, keys[0]+" > "+f.nth(0))
+			n++
+		default:
+			var params []string
+			for ; n < len(keys); n++ {
+				params = append(params, f.nth(n))
+			}
+			list = append(list, "("+strings.Join(keys, ", ")+") > ("+strings.Join(params, ", ")+")")
+		}
+		// exclude soft deleted rows
+		if z := x.Table.SoftDelete; z != nil {
+			if isBool(*z) {
+				list = append(list, f.colname(*z)+" = "+f.boolLiteral(false))
+			} else {
+				list = append(list, f.colname(*z)+" IS NULL")
+			}
+		}
+		lines := []string{
+			"SELECT ",
+			strings.Join(fields, ", ") + " ",
+			"FROM " + f.qualify(x.Table.Schema, x.Table.SQLName) + " ",
+		}
+		if len(list) != 0 {
+			lines = append(lines, "WHERE "+strings.Join(list, " AND ")+" ")
+		}
+		lines = append(lines, "ORDER BY "+strings.Join(keys, ", ")+" ")
+		switch f.driver {
+		case "sqlserver":
+			lines = append(lines, "OFFSET 0 ROWS FETCH NEXT "+f.nth(n)+" ROWS ONLY")
+		case "oracle":
+			lines = append(lines, "FETCH FIRST "+f.nth(n)+" ROWS ONLY")
+		default:
+			lines = append(lines, "LIMIT "+f.nth(n))
+		}
+		return lines
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// page_args returns the Python tuple of the args of the query for the page
+// after the cursor's key.
+//
+// Drivers without row value comparisons expand (a, b) > (x, y) to
+// a > x OR (a = x AND b > y), which requires keys to be repeated.
+func (f *Funcs) page_args(v PageFunc, key string) string {
+	var list []string
+	for i := range v.Fields {
+		if f.driver != "sqlserver" && f.driver != "oracle" {
+			list = append(list, fmt.Sprintf("%s[%d]", key, i))
+			continue
+		}
+		for j := 0; j <= i; j++ {
+			list = append(list, fmt.Sprintf("%s[%d]", key, j))
+		}
+	}
+	return "(" + strings.Join(append(list, "limit"), ", ") + ")"
+}
+
+// page_key returns the Python tuple of the cursor key of the page's fields,
+// prefixed with prefix.
+func page_key(v PageFunc, prefix string) string {
+	var list []string
+	for _, z := range v.Fields {
+		list = append(list, encode(z, prefix+z.Name))
+	}
+	if len(list) == 1 {
+		return "(" + list[0] + ",)"
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// filter_col returns the quoted column name of a filter param.
+func (f *Funcs) filter_col(p FilterParam) string {
+	return strconv.Quote(f.colname(p.Field))
+}
+
+// filter_arg returns the Python expression of the database value of a filter
+// param.
+func filter_arg(p FilterParam) string {
+	z := p.Field
+	z.Nullable, z.IsArray = true, p.Op == "IN"
+	if p.Op == "IS NULL" {
+		return p.Name
+	}
+	return encode(z, p.Name)
+}
+
+// factory_unset builds the condition for the foreign key fields of the row
+// created by a factory being unset, ie their default.
+func factory_unset(fk ForeignKey) string {
+	var conds []string
+	for _, z := range fk.Fields {
+		conds = append(conds, "v."+z.Name+" == "+z.Default)
+	}
+	return strings.Join(conds, " and ")
+}
+
+// filter_where creates the filter clause for the table, excluding soft
+// deleted rows.
+func (f *Funcs) filter_where(t Table) string {
+	z := t.SoftDelete
+	if z == nil {
+		return "where = FilterClause()"
+	}
+	cond := f.colname(*z) + " IS NULL"
+	if isBool(*z) {
+		cond = f.colname(*z) + " = " + f.boolLiteral(false)
+	}
+	return "where = FilterClause(" + strconv.Quote(cond) + ")"
+}
+
+// filterstr builds the Python assignment of the query for a filter builder
+// func to sqlstr. The WHERE clause is added at runtime from the filter
+// clause.
+func (f *Funcs) filterstr(v interface{}) string {
+	switch x := v.(type) {
+	case FilterFunc:
+		var fields []string
+		for _, z := range x.Table.Fields {
+			fields = append(fields, f.colname(z))
+		}
+		return "sqlstr = (\n" +
+			"        " + strconv.Quote("SELECT ") + "\n" +
+			"        " + strconv.Quote(strings.Join(fields, ", ")+" ") + "\n" +
+			"        " + strconv.Quote("FROM "+f.qualify(x.Table.Schema, x.Table.SQLName)) + "\n" +
+			"    ) + where.clause()"
+	}
+	return fmt.Sprintf(`sqlstr = "[[ UNSUPPORTED TYPE: %T ]]"`, v)
+}
+
+// sqlstr_index builds a SELECT query for the index's fields.
+func (f *Funcs) sqlstr_index(v interface{}) []string {
+	switch x := v.(type) {
+	case Index:
+		var fields []string
+		for _, z := range x.Table.Fields {
+			fields = append(fields, f.colname(z))
+		}
+		var list []string
+		for i, z := range x.Fields {
+			list = append(list, f.colname(z)+" = "+f.nth(i))
+		}
+		// partial index predicate
+		if x.Predicate != "" {
+			list = append(list, "("+escPct(f.driver, x.Predicate)+")")
+		}
+		// exclude soft deleted rows, when not excluded by the predicate
+		if z := x.Table.SoftDelete; z != nil && !x.IncludeDeleted && !x.SoftDeletePredicate {
+			if isBool(*z) {
+				list = append(list, f.colname(*z)+" = "+f.boolLiteral(false))
+			} else {
+				list = append(list, f.colname(*z)+" IS NULL")
+			}
+		}
+		return []string{
+			"SELECT ",
+			strings.Join(fields, ", ") + " ",
+			"FROM " + f.qualify(x.Table.Schema, x.Table.SQLName) + " ",
+			"WHERE " + strings.Join(list, " AND "),
+		}
+	}
+	return []string{fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)}
+}
+
+// args returns the Python tuple of the query's args. Fields are prefixed with
+// prefix.
+func (f *Funcs) args(typ string, v interface{}, prefix string) string {
+	if x, ok := v.(Query); ok {
+		if len(x.Args) == 1 {
+			return "(" + x.Args[0] + ",)"
+		}
+		return "(" + strings.Join(x.Args, ", ") + ")"
+	}
+	var list []string
+	for _, z := range argFields(typ, v) {
+		list = append(list, encode(z, prefix+z.Name))
+	}
+	if len(list) == 1 {
+		return "(" + list[0] + ",)"
+	}
+	return "(" + strings.Join(list, ", ") + ")"
+}
+
+// argFields returns the fields of the args of a table or index query.
+func argFields(typ string, v interface{}) []Field {
+	switch x := v.(type) {
+	case Table:
+		switch typ {
+		case "insert":
+			return insertFields(false, x)
+		case "insert_manual", "upsert":
+			return insertFields(true, x)
+		case "update":
+			return append(updateFields(x), x.PrimaryKeys...)
+		case "delete", "pkeys":
+			return x.PrimaryKeys
+		}
+	case Index:
+		return x.Fields
+	}
+	return nil
+}
+
+// redacted is the value used in place of sensitive fields.
+const redacted = `"[redacted]"`
+
+// logf returns the Python call logging the query and its args, with the
+// values of sensitive fields redacted.
+func (f *Funcs) logf(typ string, v interface{}) string {
+	fields := argFields(typ, v)
+	if len(sensitiveFields(fields)) == 0 {
+		return "logf(sqlstr, *args)"
+	}
+	p := []string{"sqlstr"}
+	for i, z := range fields {
+		if z.Sensitive {
+			p = append(p, redacted)
+		} else {
+			p = append(p, fmt.Sprintf("args[%d]", i))
+		}
+	}
+	return "logf(" + strings.Join(p, ", ") + ")"
+}
+
+// hook returns the QueryInfo arg passed to cursor, with the name, table, and
+// operation of the query run by the func for v, for use by the QueryHook. The
+// name of a table's method (ie, insert) is prefixed with the type's name, and
+// the operation of a custom query is its first keyword.
+func (f *Funcs) hook(v interface{}, name, op string) string {
+	if !f.hooks {
+		return ""
+	}
+	var table string
+	switch x := v.(type) {
+	case Table:
+		name, table = x.Name+"."+name, x.SQLName
+		if op == "delete" && x.SoftDelete != nil {
+			op = "update"
+		}
+	case Index:
+		name, table = x.Func, x.Table.SQLName
+	case Query:
+		name = x.Name
+		if fields := strings.Fields(strings.Join(x.SQL, " ")); len(fields) != 0 {
+			op = strings.ToLower(fields[0])
+		}
+	case PageFunc:
+		name, table = x.Name, x.Table.SQLName
+	case FilterFunc:
+		name, table = x.Name, x.Table.SQLName
+	case RefreshFunc:
+		name, table = x.Name, x.Table.SQLName
+	case SettingFunc:
+		name = x.Name
+	default:
+		return fmt.Sprintf("[[ UNSUPPORTED TYPE: %T ]]", v)
+	}
+	return fmt.Sprintf(", QueryInfo(%s, %s, %s, sqlstr)", strconv.Quote(name), strconv.Quote(table), strconv.Quote(op))
+}
+
+// sensitiveFields returns the sensitive fields of a table or fields.
+func sensitiveFields(v interface{}) []Field {
+	var fields, sensitive []Field
+	switch x := v.(type) {
+	case Table:
+		fields = x.Fields
+	case []Field:
+		fields = x
+	}
+	for _, z := range fields {
+		if z.Sensitive {
+			sensitive = append(sensitive, z)
+		}
+	}
+	return sensitive
+}
+
+// repr returns the Python f-string representing the table's fields, with the
+// values of sensitive fields redacted.
+func repr(t Table) string {
+	var fields []string
+	for _, z := range t.Fields {
+		if z.Sensitive {
+			fields = append(fields, z.Name+"='[redacted]'")
+			continue
+		}
+		fields = append(fields, z.Name+"={self."+z.Name+"!r}")
+	}
+	return `f"` + t.Name + "(" + strings.Join(fields, ", ") + `)"`
+}
+
+// params returns the Python parameters of a func's fields.
+func (f *Funcs) params(v interface{}) string {
+	var fields []Field
+	switch x := v.(type) {
+	case Index:
+		fields = x.Fields
+	case Query:
+		fields = x.Params
+	}
+	var list []string
+	for _, z := range fields {
+		list = append(list, z.Name+": "+z.Type)
+	}
+	return strings.Join(list, ", ")
+}
+
+// returns returns the Python assignment of the fields returned by the insert
+// of the table from row.
+func (f *Funcs) returns(t Table) string {
+	fields := sequenceFields(t)
+	if f.driver != "postgres" {
+		fields = []Field{*t.Sequence}
+	}
+	var names []string
+	for _, z := range fields {
+		names = append(names, "self."+z.Name)
+	}
+	if len(names) == 1 {
+		return "(" + names[0] + ",) = row"
+	}
+	return strings.Join(names, ", ") + " = row"
+}
+
+// encode returns the Python expression of the database value of the field.
+func encode(z Field, expr string) string {
+	var f func(string) string
+	switch {
+	case z.Enum != "":
+		f = func(v string) string { return v + ".value" }
+	case z.JSONType != "":
+		f = func(v string) string { return "encode_json(" + v + ")" }
+	case z.Geometry != "" || z.Composite != "" || z.Range != "":
+		f = func(v string) string { return v + ".to_db()" }
+	case z.Sqlite3 != "" && z.Sqlite3 != "bool":
+		f = func(v string) string { return "encode_time(" + v + ")" }
+	default:
+		return expr
+	}
+	return convert(z, expr, f)
+}
+
+// decode returns the Python expression of the field's value from its
+// database value.
+func decode(z Field, expr string) string {
+	var f func(string) string
+	switch {
+	case z.Enum != "":
+		f = func(v string) string { return z.Enum + "(" + v + ")" }
+	case z.JSONType != "":
+		f = func(v string) string { return "decode_json(" + z.JSONType + ", " + v + ")" }
+	case z.Geometry != "":
+		f = func(v string) string { return "Geometry.from_db(" + v + ")" }
+	case z.Composite != "":
+		f = func(v string) string { return z.Composite + ".from_db(" + v + ")" }
+	case z.Range != "":
+		f = func(v string) string { return "Range.from_db(" + v + ", " + textType(z) + ")" }
+	case z.Sqlite3 == "bool":
+		f = func(v string) string { return "bool(" + v + ")" }
+	case z.Sqlite3 != "":
+		f = func(v string) string { return "decode_time(" + z.Sqlite3 + ", " + v + ")" }
+	default:
+		return expr
+	}
+	return convert(z, expr, f)
+}
+
+// textType returns the Python type parsing the text representation of the
+// field's values, as a composite attribute or range bound.
+func textType(z Field) string {
+	switch {
+	case z.Enum != "":
+		return z.Enum
+	case z.Composite != "":
+		return z.Composite
+	case z.Range != "":
+		return z.Range
+	}
+	switch typ := strings.TrimSuffix(strings.TrimPrefix(z.Type, "Optional["), "]"); typ {
+	case "bool", "int", "float", "str", "bytes", "decimal.Decimal", "uuid.UUID",
+		"datetime.datetime", "datetime.date", "datetime.time":
+		return typ
+	}
+	return "str"
+}
+
+// convert returns the Python expression converting the field's value expr
+// with f, for each value of arrays, and unless None for nullable fields.
+func convert(z Field, expr string, f func(string) string) string {
+	switch {
+	case z.IsArray && z.Nullable:
+		return "[" + f("v") + " for v in " + expr + "] if " + expr + " is not None else None"
+	case z.IsArray:
+		return "[" + f("v") + " for v in " + expr + "]"
+	case z.Nullable:
+		return f(expr) + " if " + expr + " is not None else None"
+	}
+	return f(expr)
+}
+
+// insertFields returns the fields written by an insert. If not all, sequence
+// fields are skipped. Generated fields are always skipped.
+func insertFields(all bool, t Table) []Field {
+	var fields []Field
+	for _, z := range t.Fields {
+		if z.IsGenerated || (z.IsSequence && !all) {
+			continue
+		}
+		fields = append(fields, z)
+	}
+	return fields
+}
+
+// updateFields returns the fields written by an update, skipping the primary
+// key, generated and created fields.
+func updateFields(t Table) []Field {
+	var fields []Field
+	for _, z := range t.Fields {
+		if z.IsPrimary || z.IsGenerated || isCreated(t, z) {
+			continue
+		}
+		fields = append(fields, z)
+	}
+	return fields
+}
+
+// isCreated returns true when z is the table's created field, which is not
+// changed after insert.
+func isCreated(t Table, z Field) bool {
+	return t.Created != nil && z.SQLName == t.Created.SQLName
+}
+
+// isAuditType returns true when the field's values can be set by audit.
+func isAuditType(z Field) bool {
+	if z.IsArray {
+		return false
+	}
+	switch strings.TrimSuffix(strings.TrimPrefix(z.Type, "Optional["), "]") {
+	case "datetime.datetime", "datetime.date":
+		return true
+	}
+	return false
+}
+
+// audit returns the Python statements setting the table's audit fields to the
+// current time, for an insert, update or upsert. The created field is only set
+// when unset.
+func audit(t Table, op string) string {
+	value := func(z Field) string {
+		if strings.Contains(z.Type, "datetime.date") && !strings.Contains(z.Type, "datetime.datetime") {
+			return "now.date()"
+		}
+		return "now"
+	}
+	var lines []string
+	if z := t.Created; z != nil && op != "update" {
+		unset := "self." + z.Name + " == " + z.Default
+		if z.Nullable {
+			unset = "self." + z.Name + " is None"
+		}
+		lines = append(lines, "if "+unset+":", "    self."+z.Name+" = "+value(*z))
+	}
+	if z := t.Updated; z != nil {
+		lines = append(lines, "self."+z.Name+" = "+value(*z))
+	}
+	if len(lines) == 0 {
+		return ""
+	}
+	lines = append([]string{"# set audit fields", "now = datetime.datetime.now(datetime.timezone.utc)"}, lines...)
+	return strings.Join(lines, "\n        ")
+}
+
+// sequenceFields returns the table's sequence fields. Postgres returns all
+// sequence fields on insert, other drivers only the table's sequence.
+func sequenceFields(t Table) []Field {
+	var fields []Field
+	for _, z := range t.Fields {
+		if z.IsSequence && !z.IsGenerated {
+			fields = append(fields, z)
+		}
+	}
+	return fields
+}
+
+// boolLiteral returns the SQL literal of a bool.
+func (f *Funcs) boolLiteral(b bool) string {
+	switch {
+	case f.driver == "postgres":
+		return strconv.FormatBool(b)
+	case b:
+		return "1"
+	}
+	return "0"
+}
+
+// isBool returns true when the field is a bool.
+func isBool(field Field) bool {
+	return field.Type == "bool" || field.Type == "Optional[bool]"
+}
+
+// escfn escapes s.
+func escfn(s string) string {
+	return `"` + s + `"`
+}
+
+// Imports are the imports of a module, by module and name. The empty name
+// imports the module.
+type Imports struct {
+	Std      map[string]map[string]bool
+	Third    map[string]map[string]bool
+	Utils    map[string]map[string]bool
+	Local    map[string]map[string]bool
+	Checking map[string]map[string]bool
+}
+
+// newImports creates imports.
+func newImports() *Imports {
+	return &Imports{
+		Std:      make(map[string]map[string]bool),
+		Third:    make(map[string]map[string]bool),
+		Utils:    make(map[string]map[string]bool),
+		Local:    make(map[string]map[string]bool),
+		Checking: make(map[string]map[string]bool),
+	}
+}
+
+// add adds the import of name from module to m.
+func (imports *Imports) add(m map[string]map[string]bool, module, name string) {
+	if m[module] == nil {
+		m[module] = make(map[string]bool)
+	}
+	m[module][name] = true
+}
+
+// lines returns the sorted import statements of m.
+func (imports *Imports) lines(m map[string]map[string]bool) []string {
+	var modules []string
+	for module := range m {
+		modules = append(modules, module)
+	}
+	sort.Strings(modules)
+	var lines, froms []string
+	for _, module := range modules {
+		var names []string
+		for name := range m[module] {
+			if name == "" {
+				lines = append(lines, "import "+module)
+				continue
+			}
+			names = append(names, name)
+		}
+		if len(names) == 0 {
+			continue
+		}
+		sort.Slice(names, func(i, j int) bool {
+			// constants first, then classes and funcs
+			a, b := names[i], names[j]
+			if isConst(a) != isConst(b) {
+				return isConst(a)
+			}
+			return a < b
+		})
+		froms = append(froms, "from "+module+" import "+strings.Join(names, ", "))
+	}
+	return append(lines, froms...)
+}
+
+// isConst returns true when the name is in upper case.
+func isConst(name string) bool {
+	return strings.ToUpper(name) == name
+}
+
+// Context keys.
+var (
+	NotFirstKey   xo.ContextKey = "not-first"
+	StreamKey     xo.ContextKey = "stream"
+	PaginateKey   xo.ContextKey = "paginate"
+	SoftDeleteKey xo.ContextKey = "soft-delete-column"
+	CreatedKey    xo.ContextKey = "created-column"
+	UpdatedKey    xo.ContextKey = "updated-column"
+	EscKey        xo.ContextKey = "esc"
+	RepositoryKey xo.ContextKey = "repository"
+	FilterKey     xo.ContextKey = "filter"
+	RLSKey        xo.ContextKey = "rls"
+	HooksKey      xo.ContextKey = "hooks"
+	JSONModuleKey xo.ContextKey = "json-module"
+)
+
+// NotFirst returns not-first from the context.
+func NotFirst(ctx context.Context) bool {
+	b, _ := ctx.Value(NotFirstKey).(bool)
+	return b
+}
+
+// Stream returns stream from the context.
+func Stream(ctx context.Context) bool {
+	b, _ := ctx.Value(StreamKey).(bool)
+	return b
+}
+
+// Paginate returns paginate from the context.
+func Paginate(ctx context.Context) bool {
+	b, _ := ctx.Value(PaginateKey).(bool)
+	return b
+}
+
+// SoftDelete returns soft-delete-column from the context.
+func SoftDelete(ctx context.Context) string {
+	s, _ := ctx.Value(SoftDeleteKey).(string)
+	return s
+}
+
+// Created returns created-column from the context.
+func Created(ctx context.Context) string {
+	s, _ := ctx.Value(CreatedKey).(string)
+	return s
+}
+
+// Updated returns updated-column from the context.
+func Updated(ctx context.Context) string {
+	s, _ := ctx.Value(UpdatedKey).(string)
+	return s
+}
+
+// Filter returns filter from the context.
+func Filter(ctx context.Context) bool {
+	b, _ := ctx.Value(FilterKey).(bool)
+	return b
+}
+
+// RLS returns rls from the context.
+func RLS(ctx context.Context) bool {
+	b, _ := ctx.Value(RLSKey).(bool)
+	return b
+}
+
+// Hooks returns hooks from the context.
+func Hooks(ctx context.Context) bool {
+	b, _ := ctx.Value(HooksKey).(bool)
+	return b
+}
+
+// Repository returns repository from the context.
+func Repository(ctx context.Context) bool {
+	b, _ := ctx.Value(RepositoryKey).(bool)
+	return b
+}
+
+// JSONModule returns json-module from the context.
+func JSONModule(ctx context.Context) string {
+	s, _ := ctx.Value(JSONModuleKey).(string)
+	return s
+}
+
+// Esc indicates if an escape mode is enabled.
+func Esc(ctx context.Context, esc string) bool {
+	v, _ := ctx.Value(EscKey).([]string)
+	for _, z := range v {
+		if z == esc || z == "all" {
+			return true
+		}
+	}
+	return false
+}
+
+// Pkg returns the package name, the base name of the output path.
+func Pkg(ctx context.Context) string {
+	return filepath.Base(xo.Out(ctx))
+}
+
+// Utils is the utils module template.
+type Utils struct {
+	Schema  string
+	JSON    bool // has json columns with a concrete type
+	Spatial bool // has spatial columns
+	PgTypes bool // has composite or range types
+	Times   bool // has sqlite3 dates and times
+}
+
+// Header is the header of a module.
+type Header struct {
+	Init     bool
+	Schema   string
+	Std      []string
+	Third    []string
+	Local    []string
+	Checking []string
+}
+
+// EnumValue is a enum value template.
+type EnumValue struct {
+	Name       string
+	SQLName    string
+	ConstValue int
+}
+
+// Enum is a enum type template.
+type Enum struct {
+	Name    string
+	SQLName string
+	Schema  string
+	Module  string
+	Values  []EnumValue
+}
+
+// Composite is a composite type template.
+type Composite struct {
+	Name    string
+	SQLName string
+	Schema  string
+	Module  string
+	Fields  []Field // attributes
+}
+
+// Range is a range type template.
+type Range struct {
+	Name    string
+	SQLName string
+	Schema  string
+	Module  string
+	Type    string // Python type of the bounds
+}
+
+// Table is a type (ie, table/view/custom query) template.
+type Table struct {
+	Type        string
+	Name        string
+	SQLName     string
+	Schema      string
+	Module      string
+	Fields      []Field
+	PrimaryKeys []Field
+	Sequence    *Field
+	SoftDelete  *Field
+	Created     *Field // audit field set on insert
+	Updated     *Field // audit field set on insert and update
+	Manual      bool
+	ForeignKeys []ForeignKey
+	Comment     string
+	ResultSets  bool // holds the result sets of a query
+}
+
+// ForeignKey is a foreign key template.
+type ForeignKey struct {
+	Name      string
+	SQLName   string
+	Table     string
+	Fields    []Field
+	RefTable  string
+	RefModule string
+	RefFunc   string
+	RefFields []Field
+	Import    string
+}
+
+// Index is an index template.
+type Index struct {
+	Func                string
+	SQLName             string
+	Table               Table
+	Fields              []Field
+	Predicate           string
+	SoftDeletePredicate bool // predicate refers to the soft delete column
+	IsUnique            bool
+	IsPrimary           bool
+	IncludeDeleted      bool
+	Stream              bool
+}
+
+// Repo is a transaction-scoped repository template.
+type Repo struct {
+	Name    string
+	Module  string
+	Schema  string
+	Query   bool
+	Methods []RepoMethod
+}
+
+// RepoMethod is a repository method template, calling a generated func
+// or method with the repository's connection.
+type RepoMethod struct {
+	Name     string
+	Params   string
+	Keywords []string
+	Returns  string
+	Call     string
+	Func     string
+}
+
+// RefreshFunc is a materialized view refresh func template.
+type RefreshFunc struct {
+	Name         string
+	Table        Table
+	Concurrently bool
+}
+
+// SettingFunc is a func template setting a setting read by row level security
+// policies.
+type SettingFunc struct {
+	Name    string
+	SQLName string
+	Schema  string
+	Tables  []string
+}
+
+// PageFunc is a keyset pagination func template.
+type PageFunc struct {
+	Name   string
+	Cursor string
+	Table  Table
+	Fields []Field
+}
+
+// FilterFunc is a filter builder func template.
+type FilterFunc struct {
+	Name   string
+	Table  Table
+	Fields []Field
+	Params []FilterParam
+}
+
+// FilterParam is a keyword arg of a filter builder func template.
+type FilterParam struct {
+	Name  string
+	Type  string
+	Op    string
+	Field Field
+}
+
+// FactoryFunc is a factory func template.
+type FactoryFunc struct {
+	Name   string
+	Create string
+	Table  Table
+	Values []FactoryValue
+	Refs   []FactoryRef
+}
+
+// FactoryRef is a row referenced by a foreign key, created by a factory func
+// with the referenced table's factory func.
+type FactoryRef struct {
+	Key    ForeignKey
+	Create string
+}
+
+// FactoryValue is a fake value for a field set by a factory func.
+type FactoryValue struct {
+	Field Field
+	Value string
+}
+
+// RoundTrip is a round trip test template.
+type RoundTrip struct {
+	Name   string
+	Create string
+	Table  Table
+	Lookup string
+}
+
+// Field is a field template.
+type Field struct {
+	Name        string
+	SQLName     string
+	Type        string
+	Default     string
+	Enum        string
+	Composite   string
+	Range       string // Python type of the range bounds
+	JSONType    string
+	JSONModule  string
+	Geometry    string // spatial subtype
+	SRID        int
+	Sqlite3     string // type converted from sqlite3's storage classes
+	IsArray     bool
+	Nullable    bool
+	IsPrimary   bool
+	IsSequence  bool
+	IsGenerated bool
+	Sensitive   bool // redacted when represented or logged
+}
+
+// Query is a custom query template.
+type Query struct {
+	Name    string
+	Comment string
+	SQL     []string
+	Args    []string
+	Params  []Field
+	One     bool
+	Flat    bool
+	Exec    bool
+	Stream  bool
+	Type    Table
+	Sets    []QuerySet
+}
+
+// QuerySet is a result set of a query returning multiple result sets.
+type QuerySet struct {
+	Name string
+	Type Table
+}
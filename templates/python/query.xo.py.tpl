@@ -0,0 +1,49 @@
+{{ define "query" }}
+{{- $q := .Data }}
+
+
+def {{ $q.Name }}(db: DB{{ with params $q }}, {{ . }}{{ end }}, *, ctx: Optional[Context] = None) -> {{ if $q.Exec }}int{{ else if $q.Sets }}{{ $q.Type.Name }}{{ else if $q.Flat }}Optional[tuple[{{ range $i, $f := $q.Type.Fields }}{{ if $i }}, {{ end }}{{ $f.Type }}{{ end }}]]{{ else if $q.One }}Optional[{{ $q.Type.Name }}]{{ else if $q.Stream }}Iterator[{{ $q.Type.Name }}]{{ else }}list[{{ $q.Type.Name }}]{{ end }}:
+{{- if $q.Comment }}
+    """{{ $q.Comment }}"""
+{{- else }}
+    """Runs a custom query{{ if $q.Exec }}, returning the number of affected rows{{ else if $q.Sets }}, returning the result sets as {{ $q.Type.Name }}{{ else if $q.Stream }}, yielding each result as a {{ $q.Type.Name }}{{ else if not $q.Flat }}, returning results as {{ $q.Type.Name }}{{ end }}."""
+{{- end }}
+    # query
+    {{ sqlstr "query" $q 1 }}
+    args = {{ args "query" $q "" }}
+    logf(sqlstr, *args)
+{{- if $q.Sets }}
+    res = {{ $q.Type.Name }}()
+{{- end }}
+    with cursor(db, ctx{{ hook $q "" "" }}) as cur:
+        cur.execute(sqlstr, args)
+{{- if $q.Exec }}
+        return cur.rowcount
+{{- else if $q.Sets }}
+{{- range $i, $s := $q.Sets }}
+{{- if $i }}
+        if not cur.nextset():
+            raise Error("missing result set {{ $s.Name }}")
+{{- end }}
+        res.{{ $s.Name }} = [{{ $s.Type.Name }}._from_row(row) for row in cur.fetchall()]
+{{- end }}
+    return res
+{{- else if $q.Flat }}
+        row = cur.fetchone()
+    if row is None:
+        return None
+    return tuple(row)
+{{- else if $q.One }}
+        row = cur.fetchone()
+    if row is None:
+        return None
+    return {{ $q.Type.Name }}._from_row(row)
+{{- else if $q.Stream }}
+        while rows := cur.fetchmany():
+            for row in rows:
+                yield {{ $q.Type.Name }}._from_row(row)
+{{- else }}
+        rows = cur.fetchall()
+    return [{{ $q.Type.Name }}._from_row(row) for row in rows]
+{{- end }}
+{{ end }}
@@ -0,0 +1,48 @@
+{{ define "repository" }}
+{{- $r := .Data }}
+
+
+class {{ $r.Name }}:
+{{- if $r.Query }}
+    """{{ $r.Name }} wraps a connection, exposing the custom query funcs as methods, so that
+    multiple queries can be composed in the same transaction.
+{{- else }}
+    """{{ $r.Name }} wraps a connection, exposing the funcs for types from schema '{{ qualify $r.Schema }}'
+    as methods, so that multiple operations can be composed in the same transaction.
+{{- end }}
+    """
+
+    def __init__(self, db: DB) -> None:
+        self.db = db
+
+    @classmethod
+    @contextlib.contextmanager
+    def transaction(cls, conn: Connection) -> Iterator[{{ $r.Name }}]:
+        """Yields a {{ $r.Name }} on conn, committing the transaction when the block
+        succeeds and rolling it back when the block raises.
+        """
+        try:
+            yield cls(conn)
+        except BaseException:
+            conn.rollback()
+            raise
+        conn.commit()
+{{- range $r.Methods }}
+{{- if .Keywords }}
+
+    def {{ .Name }}(
+        self,
+        *,
+{{- range .Keywords }}
+        {{ . }},
+{{- end }}
+        ctx: Optional[Context] = None,
+    ) -> {{ .Returns }}:
+{{- else }}
+
+    def {{ .Name }}(self{{ with .Params }}, {{ . }}{{ end }}, *, ctx: Optional[Context] = None) -> {{ .Returns }}:
+{{- end }}
+        """Calls {{ .Func }} with the repository's connection."""
+        {{ if ne .Returns "None" }}return {{ end }}{{ .Call }}
+{{- end }}
+{{ end }}
@@ -0,0 +1,519 @@
+{{ define "enum" }}
+{{- $e := .Data }}
+
+
+class {{ $e.Name }}(enum.Enum):
+    """{{ $e.Name }} is the '{{ $e.SQLName }}' enum type from schema '{{ qualify $e.Schema }}'."""
+{{ range $e.Values }}
+    {{ .Name }} = {{ printf "%q" .SQLName }}
+{{- end }}
+
+    def __str__(self) -> str:
+        return str(self.value)
+{{ end }}
+
+{{ define "composite" }}
+{{- $c := .Data }}
+
+
+@dataclass
+class {{ $c.Name }}:
+    """{{ $c.Name }} is the '{{ $c.SQLName }}' composite type from schema '{{ qualify $c.Schema }}'."""
+{{ range $c.Fields }}
+    {{ .Name }}: {{ .Type }} = {{ .Default }}
+{{- end }}
+
+    @classmethod
+    def from_db(cls, v: Any) -> {{ $c.Name }}:
+        """Creates a {{ $c.Name }} from its database value, the text representation
+        of the composite.
+        """
+        fields = parse_composite(v, {{ len $c.Fields }})
+        return cls(
+{{- range $i, $f := $c.Fields }}
+            {{ $f.Name }}=parse_text({{ text_type $f }}, fields[{{ $i }}]),
+{{- end }}
+        )
+
+    def to_db(self) -> str:
+        """Returns the database value of the {{ $c.Name }}."""
+        return format_composite({{ range $i, $f := $c.Fields }}{{ if $i }}, {{ end }}self.{{ $f.Name }}{{ end }})
+{{ end }}
+
+{{ define "range" }}
+{{- $r := .Data }}
+
+
+# {{ $r.Name }} is the '{{ $r.SQLName }}' range type from schema '{{ qualify $r.Schema }}'.
+{{ $r.Name }} = Range[{{ $r.Type }}]
+{{ end }}
+
+{{ define "typedef" }}
+{{- $t := .Data }}
+
+
+@dataclass
+class {{ $t.Name }}:
+{{- if $t.Comment }}
+    """{{ $t.Comment }}"""
+{{- else if eq $t.Type "query" }}
+    """{{ $t.Name }} represents a row from '{{ $t.SQLName }}'."""
+{{- else }}
+    """{{ $t.Name }} represents a row from '{{ qualify $t.Schema $t.SQLName }}'."""
+{{- end }}
+{{- if $t.Fields }}
+{{ range $t.Fields }}
+    {{ .Name }}: {{ .Type }} = {{ .Default }}
+{{- end }}
+{{- end }}
+{{- if $t.PrimaryKeys }}
+    # xo fields
+    _exists: bool = field(default=False, init=False, repr=False, compare=False)
+    _deleted: bool = field(default=False, init=False, repr=False, compare=False)
+
+    def exists(self) -> bool:
+        """Returns true when the {{ $t.Name }} exists in the database."""
+        return self._exists
+
+    def deleted(self) -> bool:
+        """Returns true when the {{ $t.Name }} has been marked for deletion from the database."""
+        return self._deleted
+
+    def insert(self, db: DB, *, ctx: Optional[Context] = None) -> None:
+        """Inserts the {{ $t.Name }} to the database."""
+        if self._exists:
+            raise AlreadyExistsError("insert")
+        if self._deleted:
+            raise MarkedForDeletionError("insert")
+{{- with audit $t "insert" }}
+        {{ . }}
+{{- end }}
+{{- if $t.Manual }}
+        # insert (manual)
+        {{ sqlstr "insert_manual" $t 2 }}
+        args = {{ args "insert_manual" $t "self." }}
+        {{ logf "insert_manual" $t }}
+        with cursor(db, ctx{{ hook $t "insert" "insert" }}) as cur:
+            cur.execute(sqlstr, args)
+{{- else }}
+        # insert (primary key generated and returned by database)
+        {{ sqlstr "insert" $t 2 }}
+        args = {{ args "insert" $t "self." }}
+        {{ logf "insert" $t }}
+        with cursor(db, ctx{{ hook $t "insert" "insert" }}) as cur:
+{{- if driver "oracle" }}
+            out = cur.var(int)
+            cur.execute(sqlstr, args + (out,))
+            self.{{ $t.Sequence.Name }} = out.getvalue()[0]
+{{- else if driver "mysql" "sqlite3" }}
+            cur.execute(sqlstr, args)
+            self.{{ $t.Sequence.Name }} = cur.lastrowid
+{{- else }}
+            cur.execute(sqlstr, args)
+            row = cur.fetchone()
+        {{ returns $t }}
+{{- end }}
+{{- end }}
+        self._exists = True
+{{ if ne (len $t.Fields) (len $t.PrimaryKeys) }}
+    def update(self, db: DB, *, ctx: Optional[Context] = None) -> None:
+        """Updates a {{ $t.Name }} in the database."""
+        if not self._exists:
+            raise DoesNotExistError("update")
+        if self._deleted:
+            raise MarkedForDeletionError("update")
+{{- with audit $t "update" }}
+        {{ . }}
+{{- end }}
+        # update with primary key
+        {{ sqlstr "update" $t 2 }}
+        args = {{ args "update" $t "self." }}
+        {{ logf "update" $t }}
+        with cursor(db, ctx{{ hook $t "update" "update" }}) as cur:
+            cur.execute(sqlstr, args)
+
+    def save(self, db: DB, *, ctx: Optional[Context] = None) -> None:
+        """Saves the {{ $t.Name }} to the database."""
+        if self._exists:
+            self.update(db, ctx=ctx)
+        else:
+            self.insert(db, ctx=ctx)
+
+    def upsert(self, db: DB, *, ctx: Optional[Context] = None) -> None:
+        """Performs an upsert for {{ $t.Name }}."""
+        if self._deleted:
+            raise MarkedForDeletionError("upsert")
+{{- with audit $t "upsert" }}
+        {{ . }}
+{{- end }}
+        # upsert
+        {{ sqlstr "upsert" $t 2 }}
+        args = {{ args "upsert" $t "self." }}
+        {{ logf "upsert" $t }}
+        with cursor(db, ctx{{ hook $t "upsert" "upsert" }}) as cur:
+            cur.execute(sqlstr, args)
+        self._exists = True
+{{ end }}
+    def delete(self, db: DB, *, ctx: Optional[Context] = None) -> None:
+        """{{ with $t.SoftDelete }}Soft deletes the {{ $t.Name }} from the database by setting '{{ .SQLName }}'{{ else }}Deletes the {{ $t.Name }} from the database{{ end }}."""
+        if not self._exists or self._deleted:
+            return
+        # delete with {{ if gt (len $t.PrimaryKeys) 1 }}composite {{ else }}single {{ end }}primary key
+        {{ sqlstr "delete" $t 2 }}
+        args = {{ args "delete" $t "self." }}
+        {{ logf "delete" $t }}
+        with cursor(db, ctx{{ hook $t "delete" "delete" }}) as cur:
+            cur.execute(sqlstr, args)
+        self._deleted = True
+{{- end }}
+{{- if sensitive $t }}
+
+    def __repr__(self) -> str:
+        """Returns the representation of the {{ $t.Name }}, redacting its sensitive fields."""
+        return {{ repr $t }}
+{{- end }}
+{{- range $t.ForeignKeys }}
+
+{{ render "foreignkey" . "    " }}
+{{- end }}
+{{- if and $t.Fields (not $t.ResultSets) }}
+
+    @classmethod
+    def _from_row(cls, row: Sequence[Any]) -> {{ $t.Name }}:
+        """Creates a {{ $t.Name }} from a database row."""
+        v = cls(
+{{- range $i, $f := $t.Fields }}
+            {{ $f.Name }}={{ decode $f (printf "row[%d]" $i) }},
+{{- end }}
+        )
+{{- if $t.PrimaryKeys }}
+        v._exists = True
+{{- end }}
+        return v
+{{- end }}
+{{ end }}
+
+{{ define "foreignkey" }}
+{{- $k := .Data -}}
+def {{ $k.Name }}(self, db: DB, *, ctx: Optional[Context] = None) -> Optional[{{ $k.RefTable }}]:
+    """Returns the {{ $k.RefTable }} associated with the {{ $k.Table }}'s ({{ range $i, $f := $k.Fields }}{{ if $i }}, {{ end }}{{ $f.Name }}{{ end }}).
+
+    Generated from foreign key '{{ $k.SQLName }}'.
+    """
+{{- with $k.Import }}
+    from {{ . }} import {{ $k.RefFunc }}
+{{ end }}
+{{- range $k.Fields }}{{ if .Nullable }}
+    if self.{{ .Name }} is None:
+        return None
+{{- end }}{{ end }}
+    return {{ $k.RefFunc }}(db{{ range $k.Fields }}, self.{{ .Name }}{{ end }}, ctx=ctx)
+{{- end }}
+
+{{ define "page" }}
+{{- $p := .Data }}
+{{- $t := $p.Table }}
+
+
+@dataclass(frozen=True)
+class {{ $p.Cursor }}:
+    """{{ $p.Cursor }} is an opaque cursor for paging through {{ $t.Name }} rows ordered by
+    ({{ range $i, $f := $p.Fields }}{{ if $i }}, {{ end }}{{ $f.SQLName }}{{ end }}). The zero value starts at the first row.
+    """
+
+    key: Optional[tuple[Any, ...]] = None
+
+    def __str__(self) -> str:
+        if self.key is None:
+            return ""
+        return encode_cursor(*self.key)
+
+    @classmethod
+    def parse(cls, s: str) -> {{ $p.Cursor }}:
+        """Parses a cursor from its string form."""
+        if not s:
+            return cls()
+        return cls(decode_cursor(s, {{ len $p.Fields }}))
+
+
+def {{ $p.Name }}(db: DB, after: {{ $p.Cursor }}, limit: int, *, ctx: Optional[Context] = None) -> tuple[list[{{ $t.Name }}], {{ $p.Cursor }}]:
+    """Retrieves up to limit rows from '{{ qualify $t.Schema $t.SQLName }}' after the cursor,
+    ordered by ({{ range $i, $f := $p.Fields }}{{ if $i }}, {{ end }}{{ $f.SQLName }}{{ end }}), and the cursor for the next page.
+
+    Fewer than limit rows are returned on the last page.
+{{- with $t.SoftDelete }} Excludes rows soft deleted by '{{ .SQLName }}'.{{ end }}
+    """
+    args: tuple[Any, ...]
+    if after.key is None:
+        # query
+        {{ sqlstr "page" $p 2 }}
+        args = (limit,)
+    else:
+        # query after cursor
+        {{ sqlstr "page_after" $p 2 }}
+        args = {{ page_args $p "after.key" }}
+{{- if sensitive $p.Fields }}
+    logf(sqlstr)  # args contain sensitive fields
+{{- else }}
+    logf(sqlstr, *args)
+{{- end }}
+    with cursor(db, ctx{{ hook $p "" "select" }}) as cur:
+        cur.execute(sqlstr, args)
+        rows = cur.fetchall()
+    res = [{{ $t.Name }}._from_row(row) for row in rows]
+    # next cursor
+    if res:
+        after = {{ $p.Cursor }}({{ page_key $p "res[-1]." }})
+    return res, after
+{{ end }}
+
+{{ define "filter" }}
+{{- $f := .Data }}
+{{- $t := $f.Table }}
+
+
+def {{ $f.Name }}(
+    db: DB,
+    *,
+{{- range $f.Params }}
+    {{ .Name }}: {{ .Type }} = None,
+{{- end }}
+    ctx: Optional[Context] = None,
+) -> list[{{ $t.Name }}]:
+    """Retrieves the rows from '{{ qualify $t.Schema $t.SQLName }}' matching all of the filters, as a list
+    of {{ $t.Name }}.
+
+    Filters are keyword args named after the indexed columns ({{ range $i, $z := $f.Fields }}{{ if $i }}, {{ end }}{{ $z.SQLName }}{{ end }}),
+    with a suffix for the comparison: none for equality, __in, __lt, __lte, __gt, __gte,
+    or __is_null for nullable columns. Filters that are None are ignored.
+{{- with $t.SoftDelete }} Excludes rows
+    soft deleted by '{{ .SQLName }}'.{{ end }}
+    """
+    {{ filter_where $t }}
+{{- range $f.Params }}
+    where.add({{ filter_col . }}, {{ printf "%q" .Op }}, {{ filter_arg . }})
+{{- end }}
+    # query
+    {{ filterstr $f }}
+    args = tuple(where.args)
+{{- if sensitive $t }}
+    logf(sqlstr)  # args may contain sensitive fields
+{{- else }}
+    logf(sqlstr, *args)
+{{- end }}
+    with cursor(db, ctx{{ hook $f "" "select" }}) as cur:
+        cur.execute(sqlstr, args)
+        rows = cur.fetchall()
+    return [{{ $t.Name }}._from_row(row) for row in rows]
+{{ end }}
+
+{{ define "index" }}
+{{- $i := .Data }}
+
+
+def {{ $i.Func }}(db: DB, {{ params $i }}, *, ctx: Optional[Context] = None) -> {{ if $i.IsUnique }}Optional[{{ $i.Table.Name }}]{{ else if $i.Stream }}Iterator[{{ $i.Table.Name }}]{{ else }}list[{{ $i.Table.Name }}]{{ end }}:
+{{- if $i.Stream }}
+    """Retrieves rows from '{{ qualify $i.Table.Schema $i.Table.SQLName }}', yielding each row as a {{ $i.Table.Name }}.
+{{- else }}
+    """Retrieves {{ if $i.IsUnique }}a row{{ else }}rows{{ end }} from '{{ qualify $i.Table.Schema $i.Table.SQLName }}' as {{ if $i.IsUnique }}a {{ $i.Table.Name }}{{ else }}a list of {{ $i.Table.Name }}{{ end }}.
+{{- end }}
+
+    Generated from {{ if $i.Predicate }}partial {{ end }}index '{{ $i.SQLName }}'.
+{{- with $i.Table.SoftDelete }}{{ if $i.IncludeDeleted }} Includes{{ else }} Excludes{{ end }} rows soft deleted by '{{ .SQLName }}'.{{ end }}
+    """
+    # query
+    {{ sqlstr "index" $i 1 }}
+    args = {{ args "index" $i "" }}
+    {{ logf "index" $i }}
+    with cursor(db, ctx{{ hook $i "" "select" }}) as cur:
+        cur.execute(sqlstr, args)
+{{- if $i.IsUnique }}
+        row = cur.fetchone()
+    if row is None:
+        return None
+    return {{ $i.Table.Name }}._from_row(row)
+{{- else if $i.Stream }}
+        while rows := cur.fetchmany():
+            for row in rows:
+                yield {{ $i.Table.Name }}._from_row(row)
+{{- else }}
+        rows = cur.fetchall()
+    return [{{ $i.Table.Name }}._from_row(row) for row in rows]
+{{- end }}
+{{ end }}
+
+{{ define "refresh" }}
+{{- $r := .Data }}
+
+
+def {{ $r.Name }}(db: DB, *, ctx: Optional[Context] = None) -> None:
+{{- if $r.Concurrently }}
+    """Refreshes the contents of the '{{ qualify $r.Table.Schema $r.Table.SQLName }}' materialized view
+    concurrently, without locking out reads of the view.
+    """
+{{- else }}
+    """Refreshes the contents of the '{{ qualify $r.Table.Schema $r.Table.SQLName }}' materialized view."""
+{{- end }}
+    # query
+    {{ sqlstr "refresh" $r 1 }}
+    logf(sqlstr)
+    with cursor(db, ctx{{ hook $r "" "refresh" }}) as cur:
+        cur.execute(sqlstr)
+{{ end }}
+
+{{ define "setting" }}
+{{- $s := .Data }}
+
+
+def {{ $s.Name }}(db: DB, value: str, local: bool = False, *, ctx: Optional[Context] = None) -> None:
+    """Sets the '{{ $s.SQLName }}' setting, read by the row level security policies of
+    {{ range $i, $t := $s.Tables }}{{ if $i }}, {{ end }}'{{ qualify $s.Schema $t }}'{{ end }}.
+
+    When local is true, the setting only applies to the current transaction.
+    """
+    # query
+    {{ sqlstr "setting" $s 1 }}
+    args = (value, local)
+    logf(sqlstr, *args)
+    with cursor(db, ctx{{ hook $s "" "set" }}) as cur:
+        cur.execute(sqlstr, args)
+{{ end }}
+
+{{ define "factories" }}
+
+
+# the sequence and base time of the fake data generated by factory funcs
+_factory_seq = itertools.count(1)
+_factory_time = datetime.datetime(2000, 1, 1, tzinfo=datetime.timezone.utc)
+{{ end }}
+
+{{ define "factory" }}
+{{- $f := .Data }}
+{{- $t := $f.Table }}
+
+
+def {{ $f.Name }}() -> {{ $t.Name }}:
+    """Returns a {{ $t.Name }} with fake data, for use in tests.
+
+    Nullable, generated and foreign key fields are left unset.
+    """
+{{- if $f.Values }}
+    n = next(_factory_seq)
+    return {{ $t.Name }}(
+{{- range $f.Values }}
+        {{ .Field.Name }}={{ .Value }},
+{{- end }}
+    )
+{{- else }}
+    return {{ $t.Name }}()
+{{- end }}
+{{- if $t.PrimaryKeys }}
+
+
+def {{ $f.Create }}(db: DB, *, ctx: Optional[Context] = None, **fields: Any) -> {{ $t.Name }}:
+    """Inserts a {{ $t.Name }} with fake data to the database, for use in tests. fields
+    are set on the {{ $t.Name }} before it is inserted.
+{{- if $f.Refs }}
+
+    Rows referenced by foreign keys that are left unset are created first.
+{{- end }}
+    """
+    v = dataclasses.replace({{ $f.Name }}(), **fields)
+{{- range $r := $f.Refs }}
+    # create referenced {{ $r.Key.RefTable }}
+    if {{ factory_unset $r.Key }}:
+        ref = {{ $r.Create }}(db, ctx=ctx)
+{{- range $i, $z := $r.Key.Fields }}
+        v.{{ $z.Name }} = ref.{{ (index $r.Key.RefFields $i).Name }}
+{{- end }}
+{{- end }}
+    v.insert(db, ctx=ctx)
+    return v
+{{- end }}
+{{ end }}
+
+{{ define "fixture" }}
+{{- $f := .Data }}
+{{- $t := $f.Table }}
+
+
+@pytest.fixture
+def {{ $t.Module }}_factory(db: DB) -> Callable[..., {{ $t.Name }}]:
+    """Returns {{ $f.Create }} bound to the db fixture, for use in tests."""
+    return functools.partial({{ $f.Create }}, db)
+{{ end }}
+
+{{ define "testdb" }}
+
+
+@pytest.fixture
+def db() -> Iterator[Any]:
+    """Yields a connection to the database in the XO_TEST_DSN environment variable
+    for round trip tests, rolling back the changes when the test completes.
+
+    The test is skipped when XO_TEST_DSN is not set.
+    """
+    dsn = os.environ.get("XO_TEST_DSN")
+    if not dsn:
+        pytest.skip("XO_TEST_DSN is not set")
+{{- if driver "postgres" }}
+    psycopg = pytest.importorskip("psycopg")
+    conn = psycopg.connect(dsn)
+{{- else if driver "mysql" }}
+    pymysql = pytest.importorskip("pymysql")
+    u = urllib.parse.urlsplit(dsn)
+    conn = pymysql.connect(
+        host=u.hostname,
+        port=u.port or 3306,
+        user=u.username,
+        password=u.password or "",
+        database=u.path.lstrip("/"),
+    )
+{{- else if driver "sqlserver" }}
+    pymssql = pytest.importorskip("pymssql")
+    u = urllib.parse.urlsplit(dsn)
+    conn = pymssql.connect(
+        server=u.hostname,
+        port=u.port or 1433,
+        user=u.username,
+        password=u.password or "",
+        database=u.path.lstrip("/"),
+    )
+{{- else if driver "oracle" }}
+    oracledb = pytest.importorskip("oracledb")
+    conn = oracledb.connect(dsn)
+{{- else }}
+    conn = sqlite3.connect(dsn)
+{{- end }}
+    try:
+        yield conn
+    finally:
+        conn.rollback()
+        conn.close()
+{{ end }}
+
+{{ define "roundtrip" }}
+{{- $r := .Data }}
+{{- $t := $r.Table }}
+
+
+def {{ $r.Name }}(db: Any) -> None:
+    """Inserts, gets, updates and deletes a {{ $t.Name }}."""
+    # insert
+    v = {{ $r.Create }}(db)
+    assert v.exists()
+{{- with $r.Lookup }}
+    # get
+    assert {{ . }} is not None
+{{- end }}
+{{- if ne (len $t.Fields) (len $t.PrimaryKeys) }}
+    # update
+    v.update(db)
+{{- end }}
+    # delete
+    v.delete(db)
+    assert v.deleted()
+{{- with $r.Lookup }}
+    assert {{ . }} is None
+{{- end }}
+{{ end }}
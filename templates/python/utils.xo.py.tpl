@@ -0,0 +1,614 @@
+{{ define "utils" }}
+
+
+_logger: Callable[..., Any] = lambda s, *args: None
+
+
+def logf(s: str, *args: Any) -> None:
+    """Logs a message using the package logger."""
+    _logger(s, *args)
+
+
+def set_logger(logger: Optional[Callable[..., Any]]) -> None:
+    """Sets the package logger, called with the SQL query and its args.
+
+    For example, set_logger(print) or set_logger(logging.getLogger().debug).
+    """
+    global _logger
+    _logger = logger if logger is not None else lambda s, *args: None
+
+{{ if hooks }}
+@dataclass(frozen=True)
+class QueryInfo:
+    """QueryInfo is the query run by a generated func, passed to a QueryHook.
+
+    The name is the name of the generated func (ie, author_by_author_id or
+    Author.insert), the table is the name of the queried table or view, if any,
+    and the op is the operation (ie, select, insert, update, upsert, delete).
+    """
+
+    name: str
+    table: str
+    op: str
+    sql: str
+
+
+class QueryHook(Protocol):
+    """QueryHook is called around the queries run by generated funcs, for
+    collecting metrics or tracing.
+    """
+
+    def before(self, q: QueryInfo) -> Any:
+        """Called before the query is run, returning the state passed to after."""
+
+    def after(self, q: QueryInfo, state: Any, err: Optional[Exception]) -> None:
+        """Called after the query is run and its rows are read, with the state
+        returned by before and the error of the query, if any.
+        """
+
+
+_hook: Optional[QueryHook] = None
+
+
+def set_hook(hook: Optional[QueryHook]) -> None:
+    """Sets the package query hook, called around each query run by generated
+    funcs.
+    """
+    global _hook
+    _hook = hook
+
+{{ end }}
+
+class Cursor(Protocol):
+    """Cursor is the DB-API cursor used by generated code."""
+
+    rowcount: int
+{{- if driver "mysql" "sqlite3" }}
+    lastrowid: Any
+{{- end }}
+
+    def execute(self, sqlstr: Any, args: Any = ...) -> Any: ...
+
+    def fetchone(self) -> Any: ...
+
+    def fetchall(self) -> Any: ...
+{{- if not (driver "sqlite3") }}
+
+    def nextset(self) -> Any: ...
+{{- end }}
+{{- if stream }}
+
+    def fetchmany(self) -> Any: ...
+{{- end }}
+
+    def close(self) -> Any: ...
+{{- if driver "oracle" }}
+
+    def var(self, typ: Any) -> Any: ...
+{{- end }}
+
+
+class DB(Protocol):
+    """DB is the DB-API connection used by generated code."""
+
+    def cursor(self) -> Any: ...
+
+{{ if repository }}
+class Connection(DB, Protocol):
+    """Connection is the DB-API connection committing or rolling back transactions."""
+
+    def commit(self) -> Any: ...
+
+    def rollback(self) -> Any: ...
+
+{{ end }}
+
+class Error(Exception):
+    """Error is the base error of generated code."""
+
+
+class AlreadyExistsError(Error):
+    """AlreadyExistsError is raised when inserting a row that already exists."""
+
+    def __init__(self, op: str = "insert") -> None:
+        super().__init__(f"{op} failed: already exists")
+
+
+class DoesNotExistError(Error):
+    """DoesNotExistError is raised when changing a row that does not exist."""
+
+    def __init__(self, op: str = "update") -> None:
+        super().__init__(f"{op} failed: does not exist")
+
+
+class MarkedForDeletionError(Error):
+    """MarkedForDeletionError is raised when changing a deleted row."""
+
+    def __init__(self, op: str = "update") -> None:
+        super().__init__(f"{op} failed: marked for deletion")
+
+
+{{ if .Data.JSON -}}
+def decode_json(typ: Any, v: Any) -> Any:
+    """Decodes the value of a json column as typ, a TypedDict or a Pydantic
+    model.
+    """
+    if isinstance(v, (bytes, bytearray)):
+        v = v.decode()
+    if isinstance(v, str):
+        v = json.loads(v)
+    if hasattr(typ, "model_validate"):
+        return typ.model_validate(v)
+    return v
+
+
+def encode_json(v: Any) -> str:
+    """Encodes the value of a json column, a TypedDict or a Pydantic model."""
+    if hasattr(v, "model_dump_json"):
+        return v.model_dump_json()
+    return json.dumps(v)
+
+
+{{ end -}}
+{{ if .Data.Times -}}
+def decode_time(typ: Any, v: Any) -> Any:
+    """Decodes a date, time or timestamp stored by sqlite3 as text, or as a unix
+    timestamp, as typ.
+    """
+    if type(v) is typ:
+        return v
+    if isinstance(v, (int, float)):
+        v = datetime.datetime.fromtimestamp(v, datetime.timezone.utc)
+    elif typ is datetime.time:
+        return datetime.time.fromisoformat(v.decode() if isinstance(v, bytes) else v)
+    else:
+        v = datetime.datetime.fromisoformat(v.decode() if isinstance(v, bytes) else v)
+    if typ is datetime.date:
+        return v.date()
+    if typ is datetime.time:
+        return v.time()
+    return v
+
+
+def encode_time(v: Any) -> str:
+    """Encodes a date, time or timestamp as text for sqlite3."""
+    if isinstance(v, datetime.datetime):
+        return v.isoformat(" ")
+    return v.isoformat()
+
+
+{{ end -}}
+{{ if .Data.Spatial -}}
+{{- if not (driver "mysql") }}
+# the EWKB flag of geometry types with a SRID
+_EWKB_SRID = 0x20000000
+
+
+{{ end -}}
+@dataclass
+class Geometry:
+    """Geometry is the value of a spatial column, as WKB and its spatial
+    reference system identifier (SRID).
+
+    When shapely is installed, shape returns the value as a shapely geometry,
+    and from_shape creates a Geometry from a shapely geometry.
+    """
+
+    wkb: bytes = b""
+    srid: int = 0
+
+    @classmethod
+    def point(cls, x: float, y: float, srid: int = 0) -> Geometry:
+        """Creates a point Geometry."""
+        return cls(struct.pack("<BIdd", 1, 1, x, y), srid)
+
+    @classmethod
+    def from_shape(cls, shape: Any, srid: int = 0) -> Geometry:
+        """Creates a Geometry from a shapely geometry."""
+        import shapely
+
+        return cls(shapely.to_wkb(shape), srid)
+
+    def shape(self) -> Any:
+        """Returns the Geometry as a shapely geometry."""
+        import shapely
+
+        return shapely.from_wkb(self.wkb)
+
+    @classmethod
+    def from_db(cls, v: Any) -> Geometry:
+{{- if driver "mysql" }}
+        """Creates a Geometry from its database value, the SRID followed by the
+        WKB.
+        """
+        buf = bytes(v)
+        return cls(buf[4:], struct.unpack("<I", buf[:4])[0])
+{{- else }}
+        """Creates a Geometry from its database value, (hex encoded) EWKB."""
+        buf = bytes.fromhex(v) if isinstance(v, str) else bytes(v)
+        order = "<" if buf[0] == 1 else ">"
+        (typ,) = struct.unpack(order + "I", buf[1:5])
+        if not typ & _EWKB_SRID:
+            return cls(buf)
+        (srid,) = struct.unpack(order + "I", buf[5:9])
+        return cls(buf[:1] + struct.pack(order + "I", typ & ~_EWKB_SRID) + buf[9:], srid)
+{{- end }}
+
+    def to_db(self) -> Any:
+        """Returns the database value of the Geometry, or None when empty."""
+        if not self.wkb:
+            return None
+{{- if driver "mysql" }}
+        return struct.pack("<I", self.srid) + self.wkb
+{{- else }}
+        if not self.srid:
+            return self.wkb.hex()
+        order = "<" if self.wkb[0] == 1 else ">"
+        (typ,) = struct.unpack(order + "I", self.wkb[1:5])
+        head = struct.pack(order + "II", typ | _EWKB_SRID, self.srid)
+        return (self.wkb[:1] + head + self.wkb[5:]).hex()
+{{- end }}
+
+
+{{ end -}}
+{{ if .Data.PgTypes -}}
+T = TypeVar("T")
+
+
+@dataclass
+class Range(Generic[T]):
+    """Range is the value of a range type. A bound of None is unbounded.
+
+    For example, Range(start, end) is the range [start, end).
+    """
+
+    lower: Optional[T] = None
+    upper: Optional[T] = None
+    lower_inc: bool = True
+    upper_inc: bool = False
+    empty: bool = False
+
+    @classmethod
+    def from_db(cls, v: Any, typ: Any) -> Range[Any]:
+        """Creates a Range from its database value, parsing the bounds as typ."""
+        if not isinstance(v, (str, bytes)):
+            # range type of the driver, ie psycopg's Range
+            if v.isempty:
+                return cls(empty=True)
+            return cls(v.lower, v.upper, v.lower_inc, v.upper_inc)
+        s = v.decode() if isinstance(v, bytes) else v
+        if s == "empty":
+            return cls(empty=True)
+        if len(s) < 3 or s[0] not in "[(" or s[-1] not in "])":
+            raise Error(f"invalid range {s!r}")
+        fields = split_text(s[1:-1])
+        if len(fields) != 2:
+            raise Error(f"invalid range {s!r}")
+        return cls(parse_text(typ, fields[0]), parse_text(typ, fields[1]), s[0] == "[", s[-1] == "]")
+
+    def to_db(self) -> str:
+        """Returns the database value of the Range."""
+        if self.empty:
+            return "empty"
+        lower, upper = format_text(self.lower), format_text(self.upper)
+        return ("[" if self.lower_inc else "(") + lower + "," + upper + ("]" if self.upper_inc else ")")
+
+
+def parse_composite(v: Any, n: int) -> list[Optional[str]]:
+    """Parses the text representation of a composite value with n attributes.
+    Null attributes are returned as None.
+    """
+    s = v.decode() if isinstance(v, bytes) else str(v)
+    if len(s) < 2 or s[0] != "(" or s[-1] != ")":
+        raise Error(f"invalid composite {s!r}")
+    fields = split_text(s[1:-1])
+    if len(fields) != n:
+        raise Error(f"invalid composite {s!r}: expected {n} attributes, got: {len(fields)}")
+    return fields
+
+
+def format_composite(*values: Any) -> str:
+    """Formats the text representation of a composite value."""
+    return "(" + ",".join(format_text(v) for v in values) + ")"
+
+
+def split_text(s: str) -> list[Optional[str]]:
+    """Splits the comma separated, and optionally quoted, fields of the text
+    representation of a composite or range value. Empty unquoted fields are
+    returned as None.
+    """
+    fields: list[Optional[str]] = []
+    buf: list[str] = []
+    quoted = in_quote = False
+    i = 0
+    while i < len(s):
+        c = s[i]
+        if c == "\\" and i + 1 < len(s):
+            i += 1
+            buf.append(s[i])
+        elif c == '"' and in_quote and s[i + 1 : i + 2] == '"':
+            i += 1
+            buf.append('"')
+        elif c == '"':
+            quoted, in_quote = True, not in_quote
+        elif c == "," and not in_quote:
+            fields.append("".join(buf) if quoted or buf else None)
+            buf, quoted = [], False
+        else:
+            buf.append(c)
+        i += 1
+    fields.append("".join(buf) if quoted or buf else None)
+    return fields
+
+
+def parse_text(typ: Any, s: Optional[str]) -> Any:
+    """Parses the text representation of a composite attribute or range bound
+    as typ. Returns None when s is None.
+    """
+    if s is None:
+        return None
+    if typ is bool:
+        return s in ("t", "true")
+    if typ is bytes:
+        return bytes.fromhex(s.removeprefix("\\x"))
+    if typ in (datetime.datetime, datetime.date, datetime.time):
+        return typ.fromisoformat(s)
+    if hasattr(typ, "from_db"):
+        return typ.from_db(s)
+    return typ(s)
+
+
+def format_text(v: Any) -> str:
+    """Formats the quoted text representation of v, as a composite attribute or
+    range bound. Returns an empty string when v is None.
+    """
+    if v is None:
+        return ""
+    if isinstance(v, bool):
+        s = "t" if v else "f"
+    elif isinstance(v, (bytes, bytearray)):
+        s = "\\x" + v.hex()
+    elif isinstance(v, enum.Enum):
+        s = str(v.value)
+    elif hasattr(v, "to_db"):
+        s = str(v.to_db())
+    else:
+        s = str(v)
+    return '"' + s.replace("\\", "\\\\").replace('"', '\\"') + '"'
+
+
+{{ end -}}
+{{ if paginate -}}
+class InvalidCursorError(Error):
+    """InvalidCursorError is raised when decoding an invalid cursor."""
+
+
+def encode_cursor(*keys: Any) -> str:
+    """Encodes the key values of a cursor."""
+    buf = json.dumps(list(keys), default=str).encode()
+    return base64.urlsafe_b64encode(buf).rstrip(b"=").decode()
+
+
+def decode_cursor(s: str, n: int) -> tuple[Any, ...]:
+    """Decodes the n key values of a cursor encoded by encode_cursor."""
+    try:
+        keys = json.loads(base64.urlsafe_b64decode(s + "=" * (-len(s) % 4)))
+    except ValueError as err:
+        raise InvalidCursorError(f"invalid cursor ({err})") from err
+    if not isinstance(keys, list) or len(keys) != n:
+        raise InvalidCursorError(f"invalid cursor (expected {n} keys)")
+    return tuple(keys)
+
+
+{{ end -}}
+{{ if filter -}}
+class FilterClause:
+    """FilterClause is a WHERE clause built by filter funcs."""
+
+    def __init__(self, *conds: str) -> None:
+        self.conds = list(conds)
+        self.args: list[Any] = []
+
+    def add(self, col: str, op: str, value: Any) -> None:
+        """Adds a condition comparing col to value with op (ie, "=", "IN" or
+        "IS NULL"), unless value is None.
+        """
+        if value is None:
+            return
+        if op == "IS NULL":
+            self.conds.append(col + (" IS NULL" if value else " IS NOT NULL"))
+        elif op == "IN":
+            params = [self._param(v) for v in value]
+            if not params:
+                self.conds.append("1 = 0")
+            else:
+                self.conds.append(col + " IN (" + ", ".join(params) + ")")
+        else:
+            self.conds.append(col + " " + op + " " + self._param(value))
+
+    def clause(self) -> str:
+        """Returns the WHERE clause of the conditions."""
+        if not self.conds:
+            return ""
+        return " WHERE " + " AND ".join(self.conds)
+
+    def _param(self, value: Any) -> str:
+        """Adds value to the args, returning its placeholder."""
+        self.args.append(value)
+{{- if driver "sqlite3" }}
+        return "?"
+{{- else if driver "oracle" }}
+        return ":" + str(len(self.args))
+{{- else }}
+        return "%s"
+{{- end }}
+
+
+{{ end -}}
+class CancelledError(Error):
+    """CancelledError is raised when the context of a query is cancelled."""
+
+    def __init__(self) -> None:
+        super().__init__("context cancelled")
+
+
+class DeadlineExceededError(Error):
+    """DeadlineExceededError is raised when the deadline of the context of a query
+    is exceeded.
+    """
+
+    def __init__(self) -> None:
+        super().__init__("context deadline exceeded")
+
+
+class Context:
+    """Context carries the deadline and cancellation of queries, like Go's
+    context.Context.
+
+    Generated funcs accept an optional ctx, limiting the statement timeout to the
+    time remaining before the deadline{{ if driver "postgres" "oracle" "sqlite3" }} and interrupting running queries on
+    cancel{{ end }}.
+    """
+
+    def __init__(self, timeout: Optional[float] = None) -> None:
+        self.deadline = time.monotonic() + timeout if timeout is not None else None
+        self._lock = threading.Lock()
+        self._cancelled = False
+        self._interrupts: list[Callable[[], Any]] = []
+
+    def cancel(self) -> None:
+        """Cancels the context{{ if driver "postgres" "oracle" "sqlite3" }}, interrupting running queries{{ end }}."""
+        with self._lock:
+            self._cancelled = True
+            interrupts = list(self._interrupts)
+        for interrupt in interrupts:
+            interrupt()
+
+    def remaining(self) -> Optional[float]:
+        """Returns the seconds remaining before the deadline, or None when the
+        context has no deadline.
+        """
+        if self.deadline is None:
+            return None
+        return max(self.deadline - time.monotonic(), 0.0)
+
+    def err(self) -> Optional[Error]:
+        """Returns the error of the context when it is cancelled or its deadline
+        exceeded, otherwise None.
+        """
+        if self._cancelled:
+            return CancelledError()
+        if self.deadline is not None and time.monotonic() >= self.deadline:
+            return DeadlineExceededError()
+        return None
+
+    @contextlib.contextmanager
+    def _interrupt(self, interrupt: Callable[[], Any]) -> Iterator[None]:
+        """Calls interrupt when the context is cancelled during the block."""
+        with self._lock:
+            self._interrupts.append(interrupt)
+        try:
+            yield
+        finally:
+            with self._lock:
+                self._interrupts.remove(interrupt)
+
+
+@contextlib.contextmanager
+def {{ if hooks }}_cursor{{ else }}cursor{{ end }}(db: DB, ctx: Optional[Context] = None) -> Iterator[Cursor]:
+    """Opens a cursor on db, closing it on exit.
+
+    When ctx is not None, the statement timeout is limited to the time remaining
+    before its deadline, and the errors of the block are raised as the error of
+    ctx once it is done.
+    """
+    if ctx is None:
+        cur = db.cursor()
+        try:
+            yield cur
+        finally:
+            cur.close()
+        return
+    if (ctx_err := ctx.err()) is not None:
+        raise ctx_err
+    timeout = ctx.remaining()
+    cur = db.cursor()
+    try:
+{{- if driver "postgres" }}
+        conn: Any = db
+        if timeout is not None:
+            # local to the transaction
+            cur.execute("SELECT set_config('statement_timeout', %s, true)", (str(max(int(timeout * 1000), 1)),))
+        with ctx._interrupt(conn.cancel):
+            yield cur
+{{- else if driver "mysql" }}
+        # only SELECT statements are limited by max_execution_time
+        if timeout is not None:
+            cur.execute("SET max_execution_time = %s", (max(int(timeout * 1000), 1),))
+        try:
+            yield cur
+        finally:
+            if timeout is not None:
+                cur.execute("SET max_execution_time = DEFAULT")
+{{- else if driver "sqlserver" }}
+        # the time spent waiting on locks is limited
+        if timeout is not None:
+            cur.execute("SET LOCK_TIMEOUT " + str(max(int(timeout * 1000), 1)))
+        try:
+            yield cur
+        finally:
+            if timeout is not None:
+                cur.execute("SET LOCK_TIMEOUT -1")
+{{- else if driver "oracle" }}
+        conn: Any = db
+        prev = conn.call_timeout
+        if timeout is not None:
+            conn.call_timeout = max(int(timeout * 1000), 1)
+        try:
+            with ctx._interrupt(conn.cancel):
+                yield cur
+        finally:
+            conn.call_timeout = prev
+{{- else if driver "sqlite3" }}
+        # the progress handler aborts the query when ctx is done
+        conn: Any = db
+        done = ctx.err
+        conn.set_progress_handler(lambda: done() is not None, 1000)
+        try:
+            yield cur
+        finally:
+            conn.set_progress_handler(None, 0)
+{{- else }}
+        yield cur
+{{- end }}
+    except Exception as err:
+        if (ctx_err := ctx.err()) is not None:
+            raise ctx_err from err
+        raise
+    finally:
+        cur.close()
+{{- if hooks }}
+
+
+@contextlib.contextmanager
+def cursor(db: DB, ctx: Optional[Context] = None, q: Optional[QueryInfo] = None) -> Iterator[Cursor]:
+    """Opens a cursor on db, closing it on exit, like _cursor. When q is not None,
+    the package hook is called around the block.
+    """
+    hook = _hook
+    if hook is None or q is None:
+        with _cursor(db, ctx) as cur:
+            yield cur
+        return
+    state = hook.before(q)
+    err: Optional[Exception] = None
+    try:
+        with _cursor(db, ctx) as cur:
+            yield cur
+    except Exception as e:
+        err = e
+        raise
+    finally:
+        hook.after(q, state, err)
+{{- end }}
+{{ end }}
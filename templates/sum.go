@@ -0,0 +1,122 @@
+package templates
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// SumFile is the name of the file in the out directory recording the files
+// generated by xo, and their content hashes.
+//
+// Each line contains a file's SHA-256 hash and name, in the same format as
+// sha256sum:
+//
+//	a4d6...e2f1  author.xo.go
+const SumFile = ".xo.sum"
+
+// ReadSum reads the generated file hashes from the sum file in the out
+// directory. Returns an empty map when the sum file does not exist.
+func ReadSum(out string) (map[string]string, error) {
+	sums := make(map[string]string)
+	buf, err := os.ReadFile(filepath.Join(out, SumFile))
+	switch {
+	case os.IsNotExist(err):
+		return sums, nil
+	case err != nil:
+		return nil, err
+	}
+	s := bufio.NewScanner(bytes.NewReader(buf))
+	for i := 1; s.Scan(); i++ {
+		sum, file, ok := strings.Cut(s.Text(), "  ")
+		if !ok || len(sum) != 2*sha256.Size || file == "" {
+			return nil, fmt.Errorf("%s:%d: invalid line", SumFile, i)
+		}
+		sums[filepath.FromSlash(file)] = sum
+	}
+	return sums, s.Err()
+}
+
+// writeSum writes the generated file hashes to the sum file in the out
+// directory. The sum file is not written when its content is unchanged.
+func writeSum(out string, sums map[string]string) error {
+	var files []string
+	for file := range sums {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	buf := new(bytes.Buffer)
+	for _, file := range files {
+		fmt.Fprintf(buf, "%s  %s\n", sums[file], filepath.ToSlash(file))
+	}
+	return writeFile(filepath.Join(out, SumFile), buf.Bytes())
+}
+
+// hash returns the hex encoded SHA-256 hash of buf.
+func hash(buf []byte) string {
+	sum := sha256.Sum256(buf)
+	return hex.EncodeToString(sum[:])
+}
+
+// writeFile writes buf to name, when name does not exist or its content
+// differs, preserving the modification time of unchanged files.
+func writeFile(name string, buf []byte) error {
+	switch old, err := os.ReadFile(name); {
+	case err == nil && hash(old) == hash(buf):
+		return nil
+	case err != nil && !os.IsNotExist(err):
+		return err
+	}
+	return os.WriteFile(name, buf, 0o644)
+}
+
+// Prune removes stale files from the out directory. Stale files are files
+// recorded in the sum file that were not generated.
+//
+// Stale files that were modified after they were generated are not removed.
+func (ts *Set) Prune(out string) error {
+	sums, err := ReadSum(out)
+	if err != nil {
+		return err
+	}
+	var files, modified []string
+	for file := range sums {
+		if _, ok := ts.files[file]; !ok {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	for _, file := range files {
+		name := filepath.Join(out, file)
+		switch buf, err := os.ReadFile(name); {
+		case os.IsNotExist(err):
+		case err != nil:
+			return err
+		case hash(buf) != sums[file]:
+			modified = append(modified, file)
+			continue
+		default:
+			if err := os.Remove(name); err != nil {
+				return err
+			}
+			// remove the directory when it is empty
+			if dir := filepath.Dir(file); dir != "." {
+				_ = os.Remove(filepath.Join(out, dir))
+			}
+		}
+		delete(sums, file)
+	}
+	if err := writeSum(out, sums); err != nil {
+		return err
+	}
+	if len(modified) != 0 {
+		return fmt.Errorf("stale files modified since generated, not removed: %s", strings.Join(modified, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,114 @@
+package templates
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPrune(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		sums    map[string]string
+		emitted []string
+		exp     []string
+		err     bool
+	}{
+		{
+			name:    "emitted",
+			files:   map[string]string{"a.xo.go": "a"},
+			sums:    map[string]string{"a.xo.go": "a"},
+			emitted: []string{"a.xo.go"},
+			exp:     []string{"a.xo.go"},
+		},
+		{
+			name:    "stale",
+			files:   map[string]string{"a.xo.go": "a", "b.xo.go": "b", "public/c.xo.go": "c"},
+			sums:    map[string]string{"a.xo.go": "a", "b.xo.go": "b", "public/c.xo.go": "c"},
+			emitted: []string{"a.xo.go"},
+			exp:     []string{"a.xo.go"},
+		},
+		{
+			name:    "not owned",
+			files:   map[string]string{"a.xo.go": "a", "b.xo.go": "b"},
+			sums:    map[string]string{"a.xo.go": "a"},
+			emitted: []string{"a.xo.go"},
+			exp:     []string{"a.xo.go", "b.xo.go"},
+		},
+		{
+			name:    "modified",
+			files:   map[string]string{"a.xo.go": "a", "b.xo.go": "modified"},
+			sums:    map[string]string{"a.xo.go": "a", "b.xo.go": "b"},
+			emitted: []string{"a.xo.go"},
+			exp:     []string{"a.xo.go", "b.xo.go"},
+			err:     true,
+		},
+		{
+			name: "missing",
+			sums: map[string]string{"a.xo.go": "a"},
+		},
+	}
+	for i, test := range tests {
+		out := t.TempDir()
+		sums := make(map[string]string)
+		for file, content := range test.files {
+			name := filepath.Join(out, filepath.FromSlash(file))
+			if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+				t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+			}
+			if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+				t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+			}
+		}
+		for file, content := range test.sums {
+			sums[filepath.FromSlash(file)] = hash([]byte(content))
+		}
+		if err := writeSum(out, sums); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		ts := &Set{files: make(map[string]*EmittedTemplate)}
+		for _, file := range test.emitted {
+			ts.files[filepath.FromSlash(file)] = &EmittedTemplate{}
+		}
+		err := ts.Prune(out)
+		switch {
+		case test.err && err == nil:
+			t.Errorf("test %d (%s) expected error, got nil", i, test.name)
+		case !test.err && err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		var files []string
+		if err := filepath.WalkDir(out, func(name string, d os.DirEntry, err error) error {
+			if err != nil || d.IsDir() || d.Name() == SumFile {
+				return err
+			}
+			file, err := filepath.Rel(out, name)
+			files = append(files, filepath.ToSlash(file))
+			return err
+		}); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		if len(files) != len(test.exp) {
+			t.Fatalf("test %d (%s) expected %v, got: %v", i, test.name, test.exp, files)
+		}
+		for j, file := range files {
+			if file != test.exp[j] {
+				t.Errorf("test %d (%s) expected %q, got: %q", i, test.name, test.exp[j], file)
+			}
+		}
+		if _, err := os.Stat(filepath.Join(out, "public")); err == nil {
+			t.Errorf("test %d (%s) expected empty directory to be removed", i, test.name)
+		}
+		// check sum only records remaining files
+		sums, err = ReadSum(out)
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		for file := range sums {
+			if _, err := os.Stat(filepath.Join(out, file)); err != nil {
+				t.Errorf("test %d (%s) expected %s to not be in %s", i, test.name, file, SumFile)
+			}
+		}
+	}
+}
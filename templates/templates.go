@@ -6,12 +6,14 @@ import (
 	"embed"
 	"fmt"
 	"io/fs"
-	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"plugin"
 	"reflect"
 	"sort"
 	"strings"
+	"sync"
 	"text/template"
 
 	"github.com/traefik/yaegi/interp"
@@ -23,7 +25,8 @@ import (
 // Set holds a set of templates and handles generating files for a target
 // files.
 //
-// Set should not be used more than once to output its contents.
+// Set should not be used more than once to output its contents, without first
+// being reset.
 type Set struct {
 	symbols  map[string]map[string]reflect.Value
 	initfunc string
@@ -32,6 +35,7 @@ type Set struct {
 	targets  map[string]*Target
 	files    map[string]*EmittedTemplate
 	post     map[string][]byte
+	hooks    []Hook
 	err      error
 	goTpl    *template.Template
 }
@@ -128,6 +132,33 @@ func (ts *Set) Add(ctx context.Context, name string, src fs.FS, unrestricted boo
 	if err != nil {
 		return "", err
 	}
+	return ts.addTarget(target)
+}
+
+// AddPlugin adds a target from a Go plugin to the template set. The plugin
+// must export the template set's init func, and the template files are read
+// from the plugin's directory.
+//
+// The plugin must be built with the same version of xo and Go as the running
+// binary.
+func (ts *Set) AddPlugin(ctx context.Context, name, path string) (string, error) {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return "", fmt.Errorf("%s: unable to open plugin: %w", name, err)
+	}
+	v, err := p.Lookup(ts.initfunc)
+	if err != nil {
+		return "", fmt.Errorf("%s: unable to lookup %q: %w", name, ts.initfunc, err)
+	}
+	target, err := ts.initTarget(ctx, name, os.DirFS(filepath.Dir(path)), v, nil)
+	if err != nil {
+		return "", err
+	}
+	return ts.addTarget(target)
+}
+
+// addTarget adds the target to the template set.
+func (ts *Set) addTarget(target *Target) (string, error) {
 	// check target not already defined
 	if ts.Has(target.Name) {
 		return "", fmt.Errorf("cannot redefine template target %q", target.Name)
@@ -136,6 +167,39 @@ func (ts *Set) Add(ctx context.Context, name string, src fs.FS, unrestricted boo
 	return target.Name, nil
 }
 
+// Reset resets the generated files and collected errors, allowing the template
+// set to be used to generate files again.
+func (ts *Set) Reset() {
+	ts.files = make(map[string]*EmittedTemplate)
+	ts.post = make(map[string][]byte)
+	ts.err = nil
+	ts.goTpl = nil
+}
+
+// Hook is a hook run by the template set when generating files, allowing a
+// program using xo as a library to customize the generated files without
+// writing a template. Any of the funcs may be nil.
+type Hook struct {
+	// Set runs before the set is processed by the template target, and can
+	// modify the set (ie, adding columns to tables).
+	Set func(ctx context.Context, mode string, set *xo.Set) error
+	// Emit runs for each template emitted by the template target, and can
+	// modify the template (ie, its destination) or emit additional templates.
+	// The template is not generated when false is returned. Templates emitted
+	// by the hook are not passed to the hooks.
+	Emit func(ctx context.Context, tpl *xo.Template, emit func(xo.Template)) (bool, error)
+	// File runs for each generated file after post processing, and can
+	// modify the file's content. The file is not written when nil is
+	// returned.
+	File func(ctx context.Context, name string, buf []byte) ([]byte, error)
+}
+
+// AddHook adds a hook to the template set. Hooks are run in the order they
+// were added, and are not removed by Reset.
+func (ts *Set) AddHook(hook Hook) {
+	ts.hooks = append(ts.hooks, hook)
+}
+
 // Use sets the target being used.
 func (ts *Set) Use(name string) {
 	ts.target = name
@@ -201,9 +265,10 @@ func (ts *Set) NewContext(ctx context.Context, mode string) context.Context {
 	return ctx
 }
 
-// addFile returns a function that handles adding templates.
+// addFile returns a function that handles adding templates, passing each
+// template to the hooks.
 func (ts *Set) addFile(ctx context.Context) func(xo.Template) {
-	return func(t xo.Template) {
+	add := func(t xo.Template) {
 		singleFile := xo.Single(ctx)
 		if singleFile != "" {
 			// Force all templates to be outputted in the specified file if xo is in single mode.
@@ -214,20 +279,45 @@ func (ts *Set) addFile(ctx context.Context) func(xo.Template) {
 		}
 		ts.files[t.Dest].Template = append(ts.files[t.Dest].Template, t)
 	}
+	return func(t xo.Template) {
+		for _, hook := range ts.hooks {
+			if hook.Emit == nil {
+				continue
+			}
+			ok, err := hook.Emit(ctx, &t, add)
+			switch {
+			case err != nil:
+				if _, ok := ts.files[t.Dest]; !ok {
+					ts.files[t.Dest] = &EmittedTemplate{}
+				}
+				ts.files[t.Dest].Err = append(ts.files[t.Dest].Err, err)
+				return
+			case !ok:
+				return
+			}
+		}
+		add(t)
+	}
 }
 
-// Pre performs pre processing of the template target.
+// Pre performs pre processing of the template target, after running the set
+// hooks.
 func (ts *Set) Pre(ctx context.Context, outDir string, mode string, set *xo.Set) {
 	target, ok := ts.targets[ts.target]
-	switch {
-	case !ok:
+	if !ok {
 		ts.err = fmt.Errorf("unknown target %q", ts.target)
 		return
-	case target.Type.Pre == nil:
-		return
+	}
+	for _, hook := range ts.hooks {
+		if hook.Set == nil {
+			continue
+		}
+		if ts.err = hook.Set(ctx, mode, set); ts.err != nil {
+			return
+		}
 	}
 	if target.Type.Pre == nil {
-
+		return
 	}
 	out := os.DirFS(outDir)
 	ts.err = target.Type.Pre(ctx, mode, set, out, ts.addFile(ctx))
@@ -236,7 +326,8 @@ func (ts *Set) Pre(ctx context.Context, outDir string, mode string, set *xo.Set)
 	}
 }
 
-// Process processes the template target.
+// Process processes the template target. Files are rendered in parallel,
+// using the number of jobs from the context.
 func (ts *Set) Process(ctx context.Context, outDir string, mode string, set *xo.Set) {
 	target, ok := ts.targets[ts.target]
 	switch {
@@ -263,7 +354,10 @@ func (ts *Set) Process(ctx context.Context, outDir string, mode string, set *xo.
 		return
 	}
 	// Parse templates and provide functions if applicable.
-	ts.goTpl = template.New("")
+	ts.goTpl = template.New("").Funcs(template.FuncMap{
+		"render":     ts.render,
+		"provenance": provenance(ctx),
+	})
 	var funcs template.FuncMap
 	if target.Type.Funcs != nil {
 		var err error
@@ -288,9 +382,10 @@ func (ts *Set) Process(ctx context.Context, outDir string, mode string, set *xo.
 		return filenames[i] < filenames[j]
 	})
 	// Generate all files with the constructed template.
-	for _, file := range filenames {
+	parallel(xo.Jobs(ctx), filenames, func(file string) {
 		emitted := ts.files[file]
-		sort.Slice(emitted.Template, func(i int, j int) bool {
+		// templates with the same sort keys keep the order they were emitted
+		sort.SliceStable(emitted.Template, func(i int, j int) bool {
 			if emitted.Template[i].Partial != emitted.Template[j].Partial {
 				return order[emitted.Template[i].Partial] < order[emitted.Template[j].Partial]
 			}
@@ -318,7 +413,73 @@ func (ts *Set) Process(ctx context.Context, outDir string, mode string, set *xo.
 				continue
 			}
 		}
+	})
+}
+
+// parallel calls f for each file, using up to jobs goroutines.
+func parallel(jobs int, files []string, f func(string)) {
+	if jobs <= 1 || len(files) <= 1 {
+		for _, file := range files {
+			f(file)
+		}
+		return
+	}
+	ch := make(chan string)
+	var wg sync.WaitGroup
+	for i := 0; i < jobs && i < len(files); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for file := range ch {
+				f(file)
+			}
+		}()
+	}
+	for _, file := range files {
+		ch <- file
+	}
+	close(ch)
+	wg.Wait()
+}
+
+// provenance returns a func that returns the provenance header lines from
+// the context, each starting with prefix.
+//
+// Available to all templates as the "provenance" func:
+//
+//	{{ with provenance "// " }}{{ . }}{{ end }}
+func provenance(ctx context.Context) func(string) string {
+	return func(prefix string) string {
+		lines := xo.ProvenanceOf(ctx).Lines()
+		for i, line := range lines {
+			lines[i] = prefix + line
+		}
+		return strings.Join(lines, "\n")
+	}
+}
+
+// render renders the named partial inline, with data as the template's data.
+// When indent is provided, it is added to the start of each non-empty line.
+//
+// Available to all templates as the "render" func:
+//
+//	{{ render "field" $f "\t" }}
+func (ts *Set) render(name string, data interface{}, indent ...string) (string, error) {
+	buf := new(bytes.Buffer)
+	if err := ts.goTpl.ExecuteTemplate(buf, name, xo.Template{Partial: name, Data: data}); err != nil {
+		return "", err
+	}
+	s := buf.String()
+	if prefix := strings.Join(indent, ""); prefix != "" {
+		lines := strings.Split(s, "\n")
+		for i, line := range lines {
+			if strings.TrimSpace(line) != "" {
+				lines[i] = prefix + line
+			}
+		}
+		s = strings.Join(lines, "\n")
 	}
+	return s, nil
 }
 
 // Post performs post processing of the template target.
@@ -346,8 +507,82 @@ func (ts *Set) Post(ctx context.Context, mode string) {
 	}
 }
 
-// Dump dumps generated files to disk.
+// FileHooks runs the file hooks over the generated files. Files are removed
+// when a hook returns nil.
+//
+// Errors are collected for each file.
+func (ts *Set) FileHooks(ctx context.Context) {
+	var files []string
+	for file := range ts.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	for _, hook := range ts.hooks {
+		if hook.File == nil {
+			continue
+		}
+		for _, file := range files {
+			emitted, ok := ts.files[file]
+			if !ok {
+				continue
+			}
+			buf, err := hook.File(ctx, file, emitted.Buf.Bytes())
+			switch {
+			case err != nil:
+				emitted.Err = append(emitted.Err, fmt.Errorf("%s: %w", file, err))
+			case buf == nil:
+				delete(ts.files, file)
+			default:
+				emitted.Buf.Reset()
+				emitted.Buf.Write(buf)
+			}
+		}
+	}
+}
+
+// Exec runs the external command name over the generated files with the
+// suffix, replacing each file's content with the command's output. The
+// content is written to the command's stdin, and any {} in args is replaced
+// with the file's path. The command is run in the out directory.
+//
+// Errors are collected for each file.
+func (ts *Set) Exec(ctx context.Context, out, suffix, name string, args ...string) {
+	var files []string
+	for file := range ts.files {
+		if strings.HasSuffix(file, suffix) {
+			files = append(files, file)
+		}
+	}
+	sort.Strings(files)
+	parallel(xo.Jobs(ctx), files, func(file string) {
+		cmdArgs := make([]string, len(args))
+		for i, arg := range args {
+			cmdArgs[i] = strings.ReplaceAll(arg, "{}", filepath.Join(out, file))
+		}
+		stdout, stderr := new(bytes.Buffer), new(bytes.Buffer)
+		cmd := exec.CommandContext(ctx, name, cmdArgs...)
+		cmd.Dir, cmd.Stdin, cmd.Stdout, cmd.Stderr = out, bytes.NewReader(ts.files[file].Buf.Bytes()), stdout, stderr
+		if err := cmd.Run(); err != nil {
+			if s := strings.TrimSpace(stderr.String()); s != "" {
+				err = fmt.Errorf("%w: %s", err, s)
+			}
+			ts.files[file].Err = append(ts.files[file].Err, fmt.Errorf("%s: %s: %w", file, name, err))
+			return
+		}
+		ts.files[file].Buf.Reset()
+		ts.files[file].Buf.Write(stdout.Bytes())
+	})
+}
+
+// Dump dumps generated files to disk. Files with unchanged content are not
+// written, and the generated files are recorded in the out directory's sum
+// file.
 func (ts *Set) Dump(out string) {
+	sums, err := ReadSum(out)
+	if err != nil {
+		ts.err = err
+		return
+	}
 	var files []string
 	for file := range ts.files {
 		files = append(files, file)
@@ -355,10 +590,27 @@ func (ts *Set) Dump(out string) {
 	sort.Strings(files)
 	for _, file := range files {
 		buf := ts.files[file].Buf.Bytes()
-		if err := ioutil.WriteFile(filepath.Join(out, file), buf, 0644); err != nil {
+		name := filepath.Join(out, file)
+		// templates may emit files in subdirectories
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
 			ts.files[file].Err = append(ts.files[file].Err, err)
+			continue
+		}
+		if err := writeFile(name, buf); err != nil {
+			ts.files[file].Err = append(ts.files[file].Err, err)
+			continue
 		}
+		sums[file] = hash(buf)
 	}
+	if err := writeSum(out, sums); err != nil {
+		ts.err = err
+	}
+}
+
+// Files returns the generated files, keyed by their path relative to the out
+// directory.
+func (ts *Set) Files() map[string]*EmittedTemplate {
+	return ts.files
 }
 
 // Errors returns any collected errors.
@@ -414,14 +666,19 @@ func (ts *Set) NewTemplate(ctx context.Context, target string, src fs.FS, unrest
 	if err != nil {
 		return nil, fmt.Errorf("%s: unable to eval %q: %w", target, ts.initfunc, err)
 	}
+	return ts.initTarget(ctx, target, src, v.Interface(), i)
+}
+
+// initTarget creates a target by calling the template init func v.
+func (ts *Set) initTarget(ctx context.Context, target string, src fs.FS, v interface{}, i *interp.Interpreter) (*Target, error) {
 	// convert init
-	tplInit, ok := v.Interface().(func(context.Context, func(xo.TemplateType)) error)
+	tplInit, ok := v.(func(context.Context, func(xo.TemplateType)) error)
 	if !ok {
-		return nil, fmt.Errorf("%s: %s has signature `%T` (must be `func(context.Context, func(github.com/xo/types.TemplateType)) error`)", target, ts.initfunc, v.Interface())
+		return nil, fmt.Errorf("%s: %s has signature `%T` (must be `func(context.Context, func(github.com/xo/types.TemplateType)) error`)", target, ts.initfunc, v)
 	}
 	// init
 	var typ xo.TemplateType
-	err = tplInit(ctx, func(tplType xo.TemplateType) {
+	err := tplInit(ctx, func(tplType xo.TemplateType) {
 		typ = tplType
 	})
 	if err != nil {
@@ -623,5 +880,6 @@ func (src sourceFS) Open(name string) (fs.File, error) {
 //go:embed dot
 //go:embed go
 //go:embed json
+//go:embed python
 //go:embed yaml
 var files embed.FS
@@ -0,0 +1,175 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"testing/fstest"
+	"text/template"
+
+	xo "github.com/xo/xo/types"
+)
+
+func TestRender(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		exp  string
+	}{
+		{
+			name: "data",
+			src:  `{{ render "field" "id" }}`,
+			exp:  "id int",
+		},
+		{
+			name: "indent",
+			src:  `{{ render "fields" (list "id" "name") "\t" }}`,
+			exp:  "\tid int\n\tname int\n",
+		},
+		{
+			name: "nested",
+			src:  `type T struct {` + "\n" + `{{ render "fields" (list "a" "b") "    " }}}`,
+			exp:  "type T struct {\n    a int\n    b int\n}",
+		},
+	}
+	for i, test := range tests {
+		ts := new(Set)
+		ts.goTpl = template.Must(template.New("").Funcs(template.FuncMap{
+			"render": ts.render,
+			"list": func(v ...string) []string {
+				return v
+			},
+		}).Parse(`{{ define "field" }}{{ .Data }} int{{ end }}` +
+			`{{ define "fields" }}{{ range .Data }}{{ render "field" . }}` + "\n" + `{{ end }}{{ end }}` +
+			`{{ define "test" }}` + test.src + `{{ end }}`))
+		buf := new(bytes.Buffer)
+		if err := ts.goTpl.ExecuteTemplate(buf, "test", nil); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.name, err)
+		}
+		if s := buf.String(); s != test.exp {
+			t.Errorf("test %d (%s) expected %q, got: %q", i, test.name, test.exp, s)
+		}
+	}
+}
+
+func TestParallel(t *testing.T) {
+	tests := []struct {
+		jobs  int
+		files int
+	}{
+		{0, 0},
+		{1, 1},
+		{1, 10},
+		{4, 1},
+		{4, 100},
+		{100, 4},
+	}
+	for i, test := range tests {
+		files := make([]string, test.files)
+		for j := range files {
+			files[j] = strconv.Itoa(j)
+		}
+		var mu sync.Mutex
+		seen := make(map[string]int)
+		parallel(test.jobs, files, func(file string) {
+			mu.Lock()
+			defer mu.Unlock()
+			seen[file]++
+		})
+		if len(seen) != len(files) {
+			t.Errorf("test %d (%d/%d) expected %d files, got: %d", i, test.jobs, test.files, len(files), len(seen))
+		}
+		for file, n := range seen {
+			if n != 1 {
+				t.Errorf("test %d (%d/%d) expected %s to be processed once, got: %d", i, test.jobs, test.files, file, n)
+			}
+		}
+	}
+}
+
+func TestHooks(t *testing.T) {
+	ctx := context.Background()
+	ts := NewTemplateSet(nil, "")
+	ts.targets["test"] = &Target{
+		Name: "test",
+		Type: xo.TemplateType{
+			Process: func(_ context.Context, _ string, set *xo.Set, emit func(xo.Template)) error {
+				for _, schema := range set.Schemas {
+					for _, table := range schema.Tables {
+						emit(xo.Template{Partial: "table", Dest: table.Name + ".txt", Data: table})
+					}
+				}
+				return nil
+			},
+		},
+		Src: fstest.MapFS{
+			"test.tpl": {Data: []byte(`{{ define "table" }}{{ .Data.Name }}:{{ range .Data.Columns }} {{ .Name }}{{ end }}` + "\n" + `{{ end }}`)},
+		},
+	}
+	ts.Use("test")
+	ts.AddHook(Hook{
+		// add a column to each table
+		Set: func(_ context.Context, _ string, set *xo.Set) error {
+			for i := range set.Schemas[0].Tables {
+				set.Schemas[0].Tables[i].Columns = append(set.Schemas[0].Tables[i].Columns, xo.Field{Name: "created_at"})
+			}
+			return nil
+		},
+		// skip b, and emit a's table to a second file
+		Emit: func(_ context.Context, tpl *xo.Template, emit func(xo.Template)) (bool, error) {
+			switch tpl.Dest {
+			case "a.txt":
+				emit(xo.Template{Partial: tpl.Partial, Dest: "all.txt", Data: tpl.Data})
+			case "b.txt":
+				return false, nil
+			}
+			return true, nil
+		},
+	})
+	ts.AddHook(Hook{
+		// remove c, and upper case the others
+		File: func(_ context.Context, name string, buf []byte) ([]byte, error) {
+			if name == "c.txt" {
+				return nil, nil
+			}
+			return bytes.ToUpper(buf), nil
+		},
+	})
+	set := &xo.Set{Schemas: []xo.Schema{{Tables: []xo.Table{
+		{Name: "a", Columns: []xo.Field{{Name: "id"}}},
+		{Name: "b"},
+		{Name: "c"},
+	}}}}
+	ts.Pre(ctx, t.TempDir(), "schema", set)
+	ts.Process(ctx, "", "schema", set)
+	ts.FileHooks(ctx)
+	if errs := ts.Errors(); len(errs) != 0 {
+		t.Fatalf("expected no errors, got: %v", errs)
+	}
+	var files []string
+	for file, emitted := range ts.files {
+		files = append(files, file+"="+emitted.Buf.String())
+	}
+	sort.Strings(files)
+	exp := "a.txt=A: ID CREATED_AT\n,all.txt=A: ID CREATED_AT\n"
+	if s := strings.Join(files, ","); s != exp {
+		t.Errorf("expected %q, got: %q", exp, s)
+	}
+	// errors are collected for the file
+	ts.Reset()
+	ts.AddHook(Hook{
+		File: func(context.Context, string, []byte) ([]byte, error) {
+			return nil, errors.New("failed")
+		},
+	})
+	ts.Process(ctx, "", "schema", set)
+	ts.FileHooks(ctx)
+	if errs := ts.Errors(); len(errs) != 2 || errs[0].Error() != "a.txt: failed" {
+		t.Errorf("expected 2 errors, got: %v", errs)
+	}
+}
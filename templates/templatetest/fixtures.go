@@ -0,0 +1,231 @@
+package templatetest
+
+import (
+	xo "github.com/xo/xo/types"
+)
+
+// enums returns a postgres set with enums, and a table using the enums as
+// columns and arrays.
+func enums() *xo.Set {
+	one, two, three := 1, 2, 3
+	bookType := xo.Enum{
+		Name: "book_type",
+		Values: []xo.Field{
+			{Name: "FICTION", ConstValue: &one},
+			{Name: "non-fiction", ConstValue: &two},
+			{Name: "2nd edition", ConstValue: &three},
+		},
+	}
+	xo.SetEnumAliases(&bookType, nil)
+	mood := xo.Enum{
+		Name: "mood",
+		Values: []xo.Field{
+			{Name: "happy", ConstValue: &one},
+			{Name: "sad", ConstValue: &two},
+		},
+	}
+	xo.SetEnumAliases(&mood, nil)
+	bookID := sequence("book_id", "integer")
+	books := xo.Table{
+		Type: "table",
+		Name: "books",
+		Columns: []xo.Field{
+			bookID,
+			field("kind", "book_type", false),
+			field("mood", "mood", true),
+			{Name: "kinds", Type: xo.Type{Type: "book_type", IsArray: true}},
+		},
+		PrimaryKeys: []xo.Field{bookID},
+	}
+	books.Indexes = []xo.Index{
+		{Name: "books_pkey", Fields: []xo.Field{bookID}, IsUnique: true, IsPrimary: true, Func: "book_by_book_id"},
+		{Name: "books_kind_idx", Fields: []xo.Field{books.Columns[1]}, Func: "books_by_kind"},
+	}
+	return &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Enums:  []xo.Enum{bookType, mood},
+		Tables: []xo.Table{books},
+	}}}
+}
+
+// foreignKeys returns a postgres set with a table that refers to another
+// table with two foreign keys. The referenced table has a sensitive column.
+func foreignKeys() *xo.Set {
+	authorID := sequence("author_id", "integer")
+	name := field("name", "text", false)
+	name.Sensitive = true
+	authors := xo.Table{
+		Type:        "table",
+		Name:        "authors",
+		Columns:     []xo.Field{authorID, name},
+		PrimaryKeys: []xo.Field{authorID},
+	}
+	authors.Indexes = []xo.Index{
+		{Name: "authors_pkey", Fields: []xo.Field{authorID}, IsUnique: true, IsPrimary: true, Func: "author_by_author_id"},
+		{Name: "authors_name_key", Fields: []xo.Field{authors.Columns[1]}, IsUnique: true, Func: "author_by_name"},
+	}
+	bookID := sequence("book_id", "integer")
+	books := xo.Table{
+		Type: "table",
+		Name: "books",
+		Columns: []xo.Field{
+			bookID,
+			field("author_id", "integer", false),
+			field("editor_id", "integer", true),
+			field("title", "text", false),
+			field("published", "timestamp with time zone", true),
+			field("created_at", "timestamp with time zone", false),
+			field("updated_at", "timestamp with time zone", true),
+		},
+		PrimaryKeys: []xo.Field{bookID},
+	}
+	books.Indexes = []xo.Index{
+		{Name: "books_pkey", Fields: []xo.Field{bookID}, IsUnique: true, IsPrimary: true, Func: "book_by_book_id"},
+		{Name: "books_author_id_idx", Fields: []xo.Field{books.Columns[1]}, Func: "books_by_author_id"},
+	}
+	books.ForeignKeys = []xo.ForeignKey{
+		{Name: "books_author_id_fkey", Fields: []xo.Field{books.Columns[1]}, RefTable: "authors", RefFields: []xo.Field{authorID}, Func: "author_by_author_id", RefFunc: "author_by_author_id"},
+		{Name: "books_editor_id_fkey", Fields: []xo.Field{books.Columns[2]}, RefTable: "authors", RefFields: []xo.Field{authorID}, Func: "author_by_editor_id", RefFunc: "author_by_author_id"},
+	}
+	return &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Tables: []xo.Table{authors, books},
+	}}}
+}
+
+// compositeKeys returns a sqlite3 set with a table with a composite primary
+// key, that refers to tables with single primary keys.
+func compositeKeys() *xo.Set {
+	userID := sequence("user_id", "integer")
+	users := xo.Table{
+		Type:        "table",
+		Name:        "users",
+		Columns:     []xo.Field{userID, field("email", "text", false)},
+		PrimaryKeys: []xo.Field{userID},
+	}
+	users.Indexes = []xo.Index{
+		{Name: "users_user_id_pkey", Fields: []xo.Field{userID}, IsUnique: true, IsPrimary: true, Func: "user_by_user_id"},
+	}
+	groupID := sequence("group_id", "integer")
+	groups := xo.Table{
+		Type:        "table",
+		Name:        "groups",
+		Columns:     []xo.Field{groupID, field("name", "text", false)},
+		PrimaryKeys: []xo.Field{groupID},
+	}
+	groups.Indexes = []xo.Index{
+		{Name: "groups_group_id_pkey", Fields: []xo.Field{groupID}, IsUnique: true, IsPrimary: true, Func: "group_by_group_id"},
+	}
+	memberUserID := primary("user_id", "integer")
+	memberGroupID := primary("group_id", "integer")
+	members := xo.Table{
+		Type:        "table",
+		Name:        "members",
+		Columns:     []xo.Field{memberUserID, memberGroupID, field("role", "text", true)},
+		PrimaryKeys: []xo.Field{memberUserID, memberGroupID},
+		Manual:      true,
+	}
+	members.Indexes = []xo.Index{
+		{Name: "members_user_id_group_id_pkey", Fields: []xo.Field{memberUserID, memberGroupID}, IsUnique: true, IsPrimary: true, Func: "member_by_user_id_group_id"},
+		{Name: "members_group_id_idx", Fields: []xo.Field{memberGroupID}, Func: "members_by_group_id"},
+	}
+	members.ForeignKeys = []xo.ForeignKey{
+		{Name: "members_user_id_fkey", Fields: []xo.Field{memberUserID}, RefTable: "users", RefFields: []xo.Field{userID}, Func: "user", RefFunc: "user_by_user_id"},
+		{Name: "members_group_id_fkey", Fields: []xo.Field{memberGroupID}, RefTable: "groups", RefFields: []xo.Field{groupID}, Func: "group", RefFunc: "group_by_group_id"},
+	}
+	return &xo.Set{Schemas: []xo.Schema{{
+		Driver: "sqlite3",
+		Name:   "main",
+		Tables: []xo.Table{users, groups, members},
+	}}}
+}
+
+// identifiers returns a mysql set with table and column names that are
+// reserved words, contain characters other than letters and digits, start
+// with a digit, or are mixed case.
+func identifiers() *xo.Set {
+	orderID := sequence("order_id", "int")
+	order := xo.Table{
+		Type: "table",
+		Name: "order",
+		Columns: []xo.Field{
+			orderID,
+			field("select", "varchar", false),
+			field("type", "int", true),
+			field("2fa_code", "varchar", true),
+			field("CamelCase", "datetime", true),
+			field("user-name", "text", false),
+		},
+		PrimaryKeys: []xo.Field{orderID},
+	}
+	order.Indexes = []xo.Index{
+		{Name: "PRIMARY", Fields: []xo.Field{orderID}, IsUnique: true, IsPrimary: true, Func: "order_by_order_id"},
+		{Name: "select", Fields: []xo.Field{order.Columns[1]}, IsUnique: true, Func: "order_by_select"},
+	}
+	itemID := sequence("item_id", "int")
+	items := xo.Table{
+		Type:        "table",
+		Name:        "order_items",
+		Columns:     []xo.Field{itemID, field("order_id", "int", false), field("range", "int", false)},
+		PrimaryKeys: []xo.Field{itemID},
+	}
+	items.Indexes = []xo.Index{
+		{Name: "PRIMARY", Fields: []xo.Field{itemID}, IsUnique: true, IsPrimary: true, Func: "order_item_by_item_id"},
+	}
+	items.ForeignKeys = []xo.ForeignKey{
+		{Name: "order_items_ibfk_1", Fields: []xo.Field{items.Columns[1]}, RefTable: "order", RefFields: []xo.Field{orderID}, Func: "order", RefFunc: "order_by_order_id"},
+	}
+	return &xo.Set{Schemas: []xo.Schema{{
+		Driver: "mysql",
+		Name:   "xo",
+		Tables: []xo.Table{order, items},
+	}}}
+}
+
+// softDelete returns a postgres set with a table with soft delete and version
+// columns, and partial indexes with and without a predicate on the soft delete
+// column.
+func softDelete() *xo.Set {
+	userID := sequence("user_id", "integer")
+	users := xo.Table{
+		Type: "table",
+		Name: "users",
+		Columns: []xo.Field{
+			userID,
+			field("email", "text", false),
+			field("team_id", "integer", true),
+			field("version", "integer", false),
+			field("deleted_at", "timestamp with time zone", true),
+		},
+		PrimaryKeys: []xo.Field{userID},
+	}
+	users.Indexes = []xo.Index{
+		{Name: "users_pkey", Fields: []xo.Field{userID}, IsUnique: true, IsPrimary: true, Func: "user_by_user_id"},
+		{Name: "users_email_key", Fields: []xo.Field{users.Columns[1]}, IsUnique: true, Predicate: "(deleted_at IS NULL)", Func: "user_by_email"},
+		{Name: "users_team_id_idx", Fields: []xo.Field{users.Columns[2]}, Predicate: "(team_id IS NOT NULL)", Func: "users_by_team_id"},
+	}
+	return &xo.Set{Schemas: []xo.Schema{{
+		Driver: "postgres",
+		Name:   "public",
+		Tables: []xo.Table{users},
+	}}}
+}
+
+// field returns a field.
+func field(name, typ string, nullable bool) xo.Field {
+	return xo.Field{Name: name, Type: xo.Type{Type: typ, Nullable: nullable}}
+}
+
+// primary returns a primary key field.
+func primary(name, typ string) xo.Field {
+	return xo.Field{Name: name, Type: xo.Type{Type: typ}, IsPrimary: true}
+}
+
+// sequence returns a primary key field generated by a sequence.
+func sequence(name, typ string) xo.Field {
+	f := primary(name, typ)
+	f.IsSequence = true
+	return f
+}
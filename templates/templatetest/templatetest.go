@@ -0,0 +1,224 @@
+// Package templatetest provides canned fixtures and golden file helpers for
+// testing templates without a database.
+//
+// For example, to test a template in the 'templates/kotlin' directory against
+// the golden files in 'testdata':
+//
+//	func TestKotlin(t *testing.T) {
+//		ts, err := cmd.NewTemplateSet(context.Background(), "templates/kotlin", "")
+//		if err != nil {
+//			t.Fatalf("expected no error, got: %v", err)
+//		}
+//		templatetest.Golden(t, ts, "testdata")
+//	}
+//
+// Golden files are updated by running the tests with the -update flag.
+package templatetest
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/xo/xo/cmd"
+	"github.com/xo/xo/templates"
+	xo "github.com/xo/xo/types"
+)
+
+// update toggles updating the golden files.
+var update = flag.Bool("update", false, "update golden files")
+
+// Fixture is a canned set for testing templates.
+type Fixture struct {
+	// Name is the name of the fixture.
+	Name string
+	// Driver is the driver the set was loaded from.
+	Driver string
+	// Set is the set.
+	Set *xo.Set
+}
+
+// Fixtures returns the canned fixtures, covering enums, foreign keys,
+// sensitive fields, composite primary keys, identifiers that are not valid in
+// most languages, and partial indexes.
+//
+// Each call returns new sets, as templates may modify the set.
+func Fixtures() []Fixture {
+	return []Fixture{
+		{"enums", "postgres", enums()},
+		{"foreign_keys", "postgres", foreignKeys()},
+		{"composite_keys", "sqlite3", compositeKeys()},
+		{"identifiers", "mysql", identifiers()},
+		{"soft_delete", "postgres", softDelete()},
+	}
+}
+
+// Generate generates the files for the set with the template set, using the
+// command line args (ie, '--go-pkg=models'), returning the generated files
+// keyed by name.
+func Generate(ctx context.Context, ts *templates.Set, driver string, set *xo.Set, cmdargs ...string) (map[string][]byte, error) {
+	return generate(ctx, "schema", ts, driver, set, cmdargs...)
+}
+
+// GenerateQuery generates the files for the queries of the set with the
+// template set in query mode, using the command line args, returning the
+// generated files keyed by name.
+func GenerateQuery(ctx context.Context, ts *templates.Set, driver string, set *xo.Set, cmdargs ...string) (map[string][]byte, error) {
+	return generate(ctx, "query", ts, driver, set, cmdargs...)
+}
+
+// generate generates the files for the set in the mode.
+func generate(ctx context.Context, mode string, ts *templates.Set, driver string, set *xo.Set, cmdargs ...string) (map[string][]byte, error) {
+	dir, err := os.MkdirTemp("", "xo-templatetest-")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(dir)
+	// templates may use the out directory's name (ie, the go package name)
+	out := filepath.Join(dir, "models")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		return nil, err
+	}
+	ts.Reset()
+	args := cmd.NewArgs(ts.Target(), ts.Targets()...)
+	f := cmd.SchemaCommand
+	if mode == "query" {
+		f = cmd.QueryCommand
+	}
+	c, err := f(ctx, ts, args)
+	if err != nil {
+		return nil, err
+	}
+	var schema string
+	if len(set.Schemas) != 0 {
+		schema = set.Schemas[0].Name
+	}
+	c.RunE = func(*cobra.Command, []string) error {
+		ts.Use(args.TemplateParams.Type.AsString())
+		ctx := cmd.BuildContext(ctx, args)
+		ctx = context.WithValue(ctx, xo.DriverKey, driver)
+		ctx = context.WithValue(ctx, xo.SchemaKey, schema)
+		return cmd.Generate(ctx, mode, ts, set, args)
+	}
+	c.SetArgs(append([]string{driver + "://", "--out=" + out}, cmdargs...))
+	c.SetOut(new(bytes.Buffer))
+	c.SilenceErrors, c.SilenceUsage = true, true
+	if err := c.ExecuteContext(ctx); err != nil {
+		return nil, err
+	}
+	return readFiles(out)
+}
+
+// Golden generates the files for each fixture with the template set, and
+// compares them to the golden files in '<dir>/<fixture>'. When the tests are
+// run with the -update flag, the golden files are written instead.
+func Golden(t *testing.T, ts *templates.Set, dir string, cmdargs ...string) {
+	t.Helper()
+	for _, fixture := range Fixtures() {
+		GoldenFixture(t, ts, dir, fixture, cmdargs...)
+	}
+}
+
+// GoldenFixture generates the files for the fixture with the template set,
+// and compares them to the golden files in '<dir>/<fixture>'. When the tests
+// are run with the -update flag, the golden files are written instead.
+func GoldenFixture(t *testing.T, ts *templates.Set, dir string, fixture Fixture, cmdargs ...string) {
+	t.Helper()
+	files, err := Generate(context.Background(), ts, fixture.Driver, fixture.Set, cmdargs...)
+	if err != nil {
+		t.Errorf("fixture %s expected no error, got: %v", fixture.Name, err)
+		return
+	}
+	golden := filepath.Join(dir, fixture.Name)
+	if *update {
+		if err := writeFiles(golden, files); err != nil {
+			t.Fatalf("fixture %s expected no error, got: %v", fixture.Name, err)
+		}
+		return
+	}
+	exp, err := readFiles(golden)
+	if err != nil {
+		t.Errorf("fixture %s expected no error, got: %v", fixture.Name, err)
+		return
+	}
+	for _, name := range names(files, exp) {
+		buf, ok := files[name]
+		expBuf, expOk := exp[name]
+		switch {
+		case !ok:
+			t.Errorf("fixture %s expected %s to be generated", fixture.Name, name)
+		case !expOk:
+			t.Errorf("fixture %s expected %s to not be generated", fixture.Name, name)
+		case !bytes.Equal(buf, expBuf):
+			t.Errorf("fixture %s expected %s to match golden file, got:\n%s", fixture.Name, name, buf)
+		}
+	}
+}
+
+// readFiles reads the files in dir, keyed by their slash separated path
+// relative to dir. The sum file is skipped.
+func readFiles(dir string) (map[string][]byte, error) {
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(name string, d os.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case d.IsDir(), d.Name() == templates.SumFile:
+			return nil
+		}
+		file, err := filepath.Rel(dir, name)
+		if err != nil {
+			return err
+		}
+		buf, err := os.ReadFile(name)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(file)] = buf
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("unable to read %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+// writeFiles replaces the files in dir.
+func writeFiles(dir string, files map[string][]byte) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return err
+	}
+	for file, buf := range files {
+		name := filepath.Join(dir, filepath.FromSlash(file))
+		if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+			return err
+		}
+		if err := os.WriteFile(name, buf, 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// names returns the sorted names of the files in a and b.
+func names(a, b map[string][]byte) []string {
+	m := make(map[string]bool)
+	for name := range a {
+		m[name] = true
+	}
+	for name := range b {
+		m[name] = true
+	}
+	var names []string
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,26 @@
+-- Generated by xo for the main schema.
+-- Template: createdb
+-- Schema: sha256:b9e436f10f801011
+
+-- table users
+CREATE TABLE users (
+  user_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  email TEXT NOT NULL
+);
+
+-- table groups
+CREATE TABLE groups (
+  group_id INTEGER NOT NULL PRIMARY KEY AUTOINCREMENT,
+  name TEXT NOT NULL
+);
+
+-- table members
+CREATE TABLE members (
+  user_id INTEGER NOT NULL REFERENCES users (user_id),
+  group_id INTEGER NOT NULL REFERENCES groups (group_id),
+  role TEXT,
+  PRIMARY KEY (user_id, group_id)
+);
+
+-- index members_group_id_idx
+CREATE INDEX members_group_id_idx ON members (group_id);
@@ -0,0 +1,28 @@
+-- Generated by xo for the public schema.
+-- Template: createdb
+-- Schema: sha256:10fd0aba162863df
+
+-- enum book_type
+CREATE TYPE book_type AS ENUM (
+  'FICTION',
+  'non-fiction',
+  '2nd edition'
+);
+
+-- enum mood
+CREATE TYPE mood AS ENUM (
+  'happy',
+  'sad'
+);
+
+-- table books
+CREATE TABLE books (
+  book_id SERIAL,
+  kind BOOK_TYPE NOT NULL,
+  mood MOOD,
+  kinds BOOK_TYPE[] NOT NULL,
+  PRIMARY KEY (book_id)
+);
+
+-- index books_kind_idx
+CREATE INDEX books_kind_idx ON books (kind);
@@ -0,0 +1,26 @@
+-- Generated by xo for the public schema.
+-- Template: createdb
+-- Schema: sha256:7d3bdb44b643c859
+
+-- table authors
+CREATE TABLE authors (
+  author_id SERIAL,
+  name TEXT NOT NULL,
+  PRIMARY KEY (author_id),
+  UNIQUE (name)
+);
+
+-- table books
+CREATE TABLE books (
+  book_id SERIAL,
+  author_id INTEGER NOT NULL REFERENCES authors (author_id),
+  editor_id INTEGER REFERENCES authors (author_id),
+  title TEXT NOT NULL,
+  published TIMESTAMPTZ,
+  created_at TIMESTAMPTZ NOT NULL,
+  updated_at TIMESTAMPTZ,
+  PRIMARY KEY (book_id)
+);
+
+-- index books_author_id_idx
+CREATE INDEX books_author_id_idx ON books (author_id);
@@ -0,0 +1,23 @@
+-- Generated by xo for the xo schema.
+-- Template: createdb
+-- Schema: sha256:85b22e57261ac16a
+
+-- table order
+CREATE TABLE order (
+  order_id INT AUTO_INCREMENT,
+  select VARCHAR NOT NULL,
+  type INT,
+  2fa_code VARCHAR,
+  CamelCase DATETIME,
+  user-name TEXT NOT NULL,
+  PRIMARY KEY (order_id),
+  UNIQUE (select)
+) ENGINE=InnoDB;
+
+-- table order_items
+CREATE TABLE order_items (
+  item_id INT AUTO_INCREMENT,
+  order_id INT NOT NULL REFERENCES order (order_id),
+  range INT NOT NULL,
+  PRIMARY KEY (item_id)
+) ENGINE=InnoDB;
@@ -0,0 +1,19 @@
+-- Generated by xo for the public schema.
+-- Template: createdb
+-- Schema: sha256:ec161bb6c1083762
+
+-- table users
+CREATE TABLE users (
+  user_id SERIAL,
+  email TEXT NOT NULL,
+  team_id INTEGER,
+  version INTEGER NOT NULL,
+  deleted_at TIMESTAMPTZ,
+  PRIMARY KEY (user_id)
+);
+
+-- index users_email_key
+CREATE UNIQUE INDEX users_email_key ON users (email) WHERE (deleted_at IS NULL);
+
+-- index users_team_id_idx
+CREATE INDEX users_team_id_idx ON users (team_id) WHERE (team_id IS NOT NULL);
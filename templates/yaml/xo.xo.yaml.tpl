@@ -1,5 +1,7 @@
 {{ define "yaml" -}}
 ---
 # Generated by xo.
+{{ with provenance "# " }}{{ . }}
+{{ end -}}
 {{ .Data | yaml -}}
 {{ end }}
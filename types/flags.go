@@ -2,6 +2,7 @@ package types
 
 import (
 	"fmt"
+	"regexp"
 	"strconv"
 	"strings"
 
@@ -35,11 +36,14 @@ func (flag FlagSet) Add(cmd *cobra.Command, values map[ContextKey]*Value) error
 	default:
 		return fmt.Errorf("unknown flag type %s", flag.Flag.Type)
 	}
-	// create value
+	// create value, shared by flags with the same context key
+	v := NewValue(flag.Flag.Type, flag.Flag.Default, flag.Flag.Desc, flag.Flag.Enums...)
 	if _, ok := values[flag.Flag.ContextKey]; !ok {
-		values[flag.Flag.ContextKey] = NewValue(flag.Flag.Type, flag.Flag.Default, flag.Flag.Desc, flag.Flag.Enums...)
+		values[flag.Flag.ContextKey] = v
 	}
-	flags, name, desc := cmd.Flags(), flag.Type+"-"+flag.Name, values[flag.Flag.ContextKey].Desc()
+	// use the flag's own description, as the value may be another
+	// template's flag
+	flags, name, desc := cmd.Flags(), flag.Type+"-"+flag.Name, v.Desc()
 	// force pflag flag as bool
 	noOptDefValue := ""
 	if flag.Flag.Type == "bool" {
@@ -155,7 +159,7 @@ func (v *Value) Set(s string) error {
 	case "[]string":
 		v.v = append(v.v.([]string), strings.Split(s, ",")...)
 	case "glob":
-		g, err := glob.Compile(s)
+		g, err := compileGlob(s)
 		if err != nil {
 			return err
 		}
@@ -210,6 +214,30 @@ func (v *Value) Type() string {
 	return v.typ
 }
 
+// compileGlob compiles a glob pattern. Patterns enclosed in slashes (ie,
+// /^django_.*$/) are compiled as a regular expression matching the whole
+// string.
+func compileGlob(s string) (glob.Glob, error) {
+	if len(s) < 2 || !strings.HasPrefix(s, "/") || !strings.HasSuffix(s, "/") {
+		return glob.Compile(s)
+	}
+	re, err := regexp.Compile("^(?:" + s[1:len(s)-1] + ")$")
+	if err != nil {
+		return nil, err
+	}
+	return regexpGlob{re}, nil
+}
+
+// regexpGlob wraps a regular expression as a glob.
+type regexpGlob struct {
+	re *regexp.Regexp
+}
+
+// Match satisfies the glob.Glob interface.
+func (g regexpGlob) Match(s string) bool {
+	return g.re.MatchString(s)
+}
+
 // contains determines if v contains str.
 func contains(v []string, str string) bool {
 	for _, s := range v {
@@ -0,0 +1,121 @@
+package types
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/kenshaw/inflector"
+	"github.com/kenshaw/snaker"
+)
+
+// Namer is the naming convention used for identifiers generated from
+// database names, shared by all templates.
+type Namer struct {
+	// Singularize toggles singularizing table names for type names.
+	Singularize bool
+	// StripPrefixes are prefixes stripped from table names (ie, 'tbl_').
+	StripPrefixes []string
+	// Renames are explicit names for tables ('<table>') and columns
+	// ('<table>.<column>').
+	Renames map[string]string
+}
+
+// NewNamer creates a naming convention, parsing renames in the form
+// <table>[.<column>]=<name>.
+func NewNamer(singularize bool, stripPrefixes, renames []string) (*Namer, error) {
+	n := &Namer{
+		Singularize:   singularize,
+		StripPrefixes: stripPrefixes,
+		Renames:       make(map[string]string),
+	}
+	for _, s := range renames {
+		i := strings.LastIndex(s, "=")
+		if i < 1 || i == len(s)-1 {
+			return nil, fmt.Errorf("invalid rename %q: must be <table>[.<column>]=<name>", s)
+		}
+		n.Renames[s[:i]] = s[i+1:]
+	}
+	return n, nil
+}
+
+// Name returns the name for a table, after applying any rename or stripping
+// a prefix, but without singularizing.
+func (n *Namer) Name(table string) string {
+	if name, ok := n.Renames[table]; ok {
+		return name
+	}
+	for _, prefix := range n.StripPrefixes {
+		if len(table) > len(prefix) && strings.HasPrefix(table, prefix) {
+			return table[len(prefix):]
+		}
+	}
+	return table
+}
+
+// Type returns the name for the type of a table. Renamed tables are used
+// as-is, otherwise the table name is stripped of any prefix and singularized.
+func (n *Namer) Type(table string) string {
+	if name, ok := n.Renames[table]; ok {
+		return name
+	}
+	return n.Singular(n.Name(table))
+}
+
+// Column returns the name for a table's column.
+func (n *Namer) Column(table, column string) string {
+	if name, ok := n.Renames[table+"."+column]; ok {
+		return name
+	}
+	return column
+}
+
+// Singular singularizes the last word of a snake_case name, when
+// singularization is enabled.
+func (n *Namer) Singular(s string) string {
+	if !n.Singularize {
+		return s
+	}
+	if i := strings.LastIndex(s, "_"); i != -1 {
+		return s[:i+1] + inflector.Singularize(s[i+1:])
+	}
+	return inflector.Singularize(s)
+}
+
+// Plural pluralizes a name.
+func (n *Namer) Plural(s string) string {
+	return inflector.Pluralize(s)
+}
+
+// Camel returns a snake_case name as a lower camelCase identifier, using the
+// configured initialisms.
+func (n *Namer) Camel(s string) string {
+	return snaker.ForceLowerCamelIdentifier(s)
+}
+
+// CamelExport returns a snake_case name as a CamelCase identifier, using the
+// configured initialisms.
+func (n *Namer) CamelExport(s string) string {
+	return snaker.ForceCamelIdentifier(s)
+}
+
+// Snake returns a CamelCase name as snake_case, using the configured
+// initialisms.
+func (n *Namer) Snake(s string) string {
+	return snaker.CamelToSnake(s)
+}
+
+// AddInitialisms adds initialisms (ie, 'API') to the initialisms used by all
+// templates.
+func AddInitialisms(initialisms ...string) error {
+	return snaker.DefaultInitialisms.Add(initialisms...)
+}
+
+// Naming returns the naming convention from the context, defaulting to
+// singularizing table names.
+func Naming(ctx context.Context) *Namer {
+	if n, ok := ctx.Value(NamingKey).(*Namer); ok {
+		return n
+	}
+	return &Namer{Singularize: true}
+}
@@ -0,0 +1,47 @@
+package types
+
+import (
+	"testing"
+)
+
+func TestNamer(t *testing.T) {
+	n, err := NewNamer(true, []string{"tbl_"}, []string{"people=person", "tbl_users.usr_nm=name"})
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		table  string
+		column string
+		name   string
+		typ    string
+		col    string
+	}{
+		{"authors", "author_id", "authors", "author", "author_id"},
+		{"book_tags", "tag", "book_tags", "book_tag", "tag"},
+		{"tbl_users", "usr_nm", "users", "user", "name"},
+		{"tbl_", "id", "tbl_", "tbl_", "id"},
+		{"people", "id", "person", "person", "id"},
+	}
+	for i, test := range tests {
+		if s := n.Name(test.table); s != test.name {
+			t.Errorf("test %d (%s) expected name %q, got: %q", i, test.table, test.name, s)
+		}
+		if s := n.Type(test.table); s != test.typ {
+			t.Errorf("test %d (%s) expected type %q, got: %q", i, test.table, test.typ, s)
+		}
+		if s := n.Column(test.table, test.column); s != test.col {
+			t.Errorf("test %d (%s) expected column %q, got: %q", i, test.table, test.col, s)
+		}
+	}
+	// singularization disabled
+	n.Singularize = false
+	if s := n.Type("tbl_users"); s != "users" {
+		t.Errorf("expected %q, got: %q", "users", s)
+	}
+	// invalid renames
+	for i, rename := range []string{"people", "=person", "people="} {
+		if _, err := NewNamer(true, nil, []string{rename}); err == nil {
+			t.Errorf("test %d (%q) expected error, got nil", i, rename)
+		}
+	}
+}
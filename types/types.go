@@ -3,7 +3,10 @@ package types
 
 import (
 	"context"
+	"crypto/sha256"
 	"database/sql"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io/fs"
 	"reflect"
@@ -11,6 +14,7 @@ import (
 	"strconv"
 	"strings"
 	"text/template"
+	"time"
 )
 
 // Set is a set of queries and schemas.
@@ -21,20 +25,21 @@ type Set struct {
 
 // Query is a query.
 type Query struct {
-	Driver       string   `json:"driver,omitempty"`
-	Name         string   `json:"name,omitempty"`
-	Comment      string   `json:"comment,omitempty"`
-	Exec         bool     `json:"exec,omitempty"`
-	Flat         bool     `json:"flat,omitempty"`
-	One          bool     `json:"one,omitempty"`
-	Interpolate  bool     `json:"interpolate,omitempty"`
-	Type         string   `json:"type,omitempty"`
-	TypeComment  string   `json:"type_comment,omitempty"`
-	Fields       []Field  `json:"fields,omitempty"`
-	ManualFields bool     `json:"manual_fields,omitempty"` // fields generated or provided by user
-	Params       []Field  `json:"params,omitempty"`
-	Query        []string `json:"query,omitempty"`
-	Comments     []string `json:"comments,omitempty"`
+	Driver       string    `json:"driver,omitempty"`
+	Name         string    `json:"name,omitempty"`
+	Comment      string    `json:"comment,omitempty"`
+	Exec         bool      `json:"exec,omitempty"`
+	Flat         bool      `json:"flat,omitempty"`
+	One          bool      `json:"one,omitempty"`
+	Interpolate  bool      `json:"interpolate,omitempty"`
+	Type         string    `json:"type,omitempty"`
+	TypeComment  string    `json:"type_comment,omitempty"`
+	Fields       []Field   `json:"fields,omitempty"`
+	ManualFields bool      `json:"manual_fields,omitempty"` // fields generated or provided by user
+	ResultSets   [][]Field `json:"result_sets,omitempty"`   // fields for each result set, when returning multiple result sets
+	Params       []Field   `json:"params,omitempty"`
+	Query        []string  `json:"query,omitempty"`
+	Comments     []string  `json:"comments,omitempty"`
 }
 
 // MarshalYAML satisfies the yaml.Marshaler interface.
@@ -46,12 +51,16 @@ func (q Query) MarshalYAML() (interface{}, error) {
 
 // Schema is a SQL schema.
 type Schema struct {
-	Driver string  `json:"type,omitempty"`
-	Name   string  `json:"name,omitempty"`
-	Enums  []Enum  `json:"enums,omitempty"`
-	Procs  []Proc  `json:"procs,omitempty"`
-	Tables []Table `json:"tables,omitempty"`
-	Views  []Table `json:"views,omitempty"`
+	Driver     string      `json:"type,omitempty"`
+	Name       string      `json:"name,omitempty"`
+	Enums      []Enum      `json:"enums,omitempty"`
+	Composites []Composite `json:"composites,omitempty"`
+	Ranges     []Range     `json:"ranges,omitempty"`
+	Procs      []Proc      `json:"procs,omitempty"`
+	Tables     []Table     `json:"tables,omitempty"`
+	Views      []Table     `json:"views,omitempty"`
+	MatViews   []Table     `json:"matviews,omitempty"`
+	Tenants    []string    `json:"tenants,omitempty"`
 }
 
 // EnumByName returns a enum by its name.
@@ -64,12 +73,158 @@ func (s Schema) EnumByName(name string) *Enum {
 	return nil
 }
 
+// TablesByDependency returns the schema's tables ordered so that each table
+// comes after the tables it refers to by foreign key, for creating or
+// inserting into tables in a valid order. Tables are otherwise kept in their
+// original order.
+//
+// Foreign keys to tables in other schemas are ignored, as are foreign keys
+// closing a reference cycle (see ReferenceCycles).
+func (s Schema) TablesByDependency() []Table {
+	m := make(map[string]Table)
+	for _, t := range s.Tables {
+		m[t.Name] = t
+	}
+	// 0 = unvisited, 1 = visiting, 2 = visited
+	state := make(map[string]int)
+	var sorted []Table
+	var visit func(Table)
+	visit = func(t Table) {
+		if state[t.Name] != 0 {
+			return
+		}
+		state[t.Name] = 1
+		for _, fk := range t.ForeignKeys {
+			if ref, ok := m[fk.RefTable]; ok && (fk.RefSchema == "" || fk.RefSchema == s.Name) {
+				visit(ref)
+			}
+		}
+		state[t.Name] = 2
+		sorted = append(sorted, t)
+	}
+	for _, t := range s.Tables {
+		visit(t)
+	}
+	return sorted
+}
+
+// ReferenceCycles returns the names of the tables in each foreign key
+// reference cycle in the schema, including tables that refer to themselves.
+// Rows of tables in a cycle cannot be inserted in dependency order without
+// the foreign keys being nullable or deferred.
+func (s Schema) ReferenceCycles() [][]string {
+	refs := make(map[string][]string)
+	for _, t := range s.Tables {
+		for _, fk := range t.ForeignKeys {
+			if fk.RefSchema == "" || fk.RefSchema == s.Name {
+				refs[t.Name] = append(refs[t.Name], fk.RefTable)
+			}
+		}
+	}
+	// reaches determines if table a refers to table b, directly or not
+	reaches := func(a, b string) bool {
+		seen := make(map[string]bool)
+		queue := refs[a]
+		for len(queue) != 0 {
+			name := queue[0]
+			queue = queue[1:]
+			switch {
+			case name == b:
+				return true
+			case seen[name]:
+				continue
+			}
+			seen[name] = true
+			queue = append(queue, refs[name]...)
+		}
+		return false
+	}
+	var cycles [][]string
+	done := make(map[string]bool)
+	for i, t := range s.Tables {
+		if done[t.Name] || !reaches(t.Name, t.Name) {
+			continue
+		}
+		cycle := []string{t.Name}
+		for _, u := range s.Tables[i+1:] {
+			if !done[u.Name] && reaches(t.Name, u.Name) && reaches(u.Name, t.Name) {
+				cycle = append(cycle, u.Name)
+				done[u.Name] = true
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	return cycles
+}
+
 // Enum is a enum type.
 type Enum struct {
 	Name   string  `json:"name,omitempty"`
 	Values []Field `json:"values,omitempty"`
 }
 
+// EnumAlias returns a identifier for a enum value's label that is valid in
+// most languages, by lower casing the label and replacing each run of
+// characters other than ASCII letters and digits with a '_'. Labels starting
+// with a digit are prefixed with 'v', and labels without any letters or digits
+// are aliased as 'value'.
+//
+// For example, 'in-progress' is aliased as 'in_progress', and '2fa_required'
+// as 'v2fa_required'.
+func EnumAlias(label string) string {
+	var sb strings.Builder
+	sep := false
+	for _, r := range strings.ToLower(label) {
+		if ('a' <= r && r <= 'z') || ('0' <= r && r <= '9') {
+			if sep && sb.Len() != 0 {
+				sb.WriteByte('_')
+			}
+			sb.WriteRune(r)
+			sep = false
+			continue
+		}
+		sep = true
+	}
+	switch s := sb.String(); {
+	case s == "":
+		return "value"
+	case '0' <= s[0] && s[0] <= '9':
+		return "v" + s
+	default:
+		return s
+	}
+}
+
+// SetEnumAliases sets the alias of each of the enum's values, applying the
+// overrides (by label) and ensuring aliases are unique by appending a numeric
+// suffix to duplicates.
+func SetEnumAliases(e *Enum, overrides map[string]string) {
+	seen := make(map[string]bool)
+	for i, v := range e.Values {
+		alias, ok := overrides[v.Name]
+		if !ok {
+			alias = EnumAlias(v.Name)
+		}
+		for base, n := alias, 2; seen[alias]; n++ {
+			alias = fmt.Sprintf("%s_%d", base, n)
+		}
+		seen[alias] = true
+		e.Values[i].Alias = alias
+	}
+}
+
+// Composite is a composite type.
+type Composite struct {
+	Name   string  `json:"name,omitempty"`
+	Fields []Field `json:"fields,omitempty"` // attributes
+}
+
+// Range is a range type.
+type Range struct {
+	Name    string `json:"name,omitempty"`
+	SubType Type   `json:"sub_type,omitempty"`
+}
+
 // Proc is a stored procedure.
 type Proc struct {
 	ID         string  `json:"-"`
@@ -90,14 +245,20 @@ func (p Proc) MarshalYAML() (interface{}, error) {
 
 // Table is a table or view.
 type Table struct {
-	Type        string       `json:"type,omitempty"` // 'table' or 'view'
-	Name        string       `json:"name,omitempty"`
-	Columns     []Field      `json:"columns,omitempty"`
-	PrimaryKeys []Field      `json:"primary_keys,omitempty"`
-	Indexes     []Index      `json:"indexes,omitempty"`
-	ForeignKeys []ForeignKey `json:"foreign_keys,omitempty"`
-	Manual      bool         `json:"manual,omitempty"`
-	Definition  string       `json:"definition,omitempty"` // empty for tables
+	Type         string       `json:"type,omitempty"` // 'table', 'view' or 'matview'
+	Name         string       `json:"name,omitempty"`
+	Columns      []Field      `json:"columns,omitempty"`
+	PrimaryKeys  []Field      `json:"primary_keys,omitempty"`
+	Indexes      []Index      `json:"indexes,omitempty"`
+	ForeignKeys  []ForeignKey `json:"foreign_keys,omitempty"`
+	Triggers     []Trigger    `json:"triggers,omitempty"`
+	Policies     []Policy     `json:"policies,omitempty"`
+	Manual       bool         `json:"manual,omitempty"`
+	Definition   string       `json:"definition,omitempty"`    // empty for tables
+	PartitionOf  string       `json:"partition_of,omitempty"`  // parent table, for partitions
+	RowSecurity  bool         `json:"row_security,omitempty"`  // row level security is enabled
+	Strict       bool         `json:"strict,omitempty"`        // sqlite3 STRICT table
+	WithoutRowid bool         `json:"without_rowid,omitempty"` // sqlite3 WITHOUT ROWID table
 }
 
 // MarshalYAML satisfies the yaml.Marshaler interface.
@@ -107,19 +268,49 @@ func (t Table) MarshalYAML() (interface{}, error) {
 	return reflectStruct(v)
 }
 
+// Trigger is a table trigger.
+type Trigger struct {
+	Name       string `json:"name,omitempty"`
+	Timing     string `json:"timing,omitempty"`     // 'BEFORE', 'AFTER' or 'INSTEAD OF'
+	Event      string `json:"event,omitempty"`      // ie, 'INSERT OR UPDATE'
+	Function   string `json:"function,omitempty"`   // postgres only
+	Definition string `json:"definition,omitempty"` // create statement
+}
+
+// MarshalYAML satisfies the yaml.Marshaler interface.
+func (t Trigger) MarshalYAML() (interface{}, error) {
+	v := t
+	v.Definition = forceLineEnd(v.Definition)
+	return reflectStruct(v)
+}
+
+// Policy is a row level security policy.
+type Policy struct {
+	Name        string   `json:"name,omitempty"`
+	Command     string   `json:"command,omitempty"` // 'ALL', 'SELECT', 'INSERT', 'UPDATE' or 'DELETE'
+	Roles       []string `json:"roles,omitempty"`
+	Restrictive bool     `json:"restrictive,omitempty"`
+	Using       string   `json:"using,omitempty"`
+	WithCheck   string   `json:"with_check,omitempty"`
+	Settings    []string `json:"settings,omitempty"` // settings read by the policy (ie, 'app.current_tenant')
+}
+
 // Index is a index.
 type Index struct {
-	Name      string  `json:"name,omitempty"`
-	Fields    []Field `json:"fields,omitempty"`
-	IsUnique  bool    `json:"is_unique,omitempty"`
-	IsPrimary bool    `json:"is_primary,omitempty"`
-	Func      string  `json:"-"`
+	Name        string   `json:"name,omitempty"`
+	Fields      []Field  `json:"fields,omitempty"`      // column key parts
+	Expressions []string `json:"expressions,omitempty"` // ordered key parts, when any are expressions (ie, lower(email))
+	Predicate   string   `json:"predicate,omitempty"`   // partial index predicate
+	IsUnique    bool     `json:"is_unique,omitempty"`
+	IsPrimary   bool     `json:"is_primary,omitempty"`
+	Func        string   `json:"-"`
 }
 
 // ForeignKey is a foreign key.
 type ForeignKey struct {
 	Name      string  `json:"name,omitempty"`       // constraint name
 	Fields    []Field `json:"column,omitempty"`     // column that has the key on it
+	RefSchema string  `json:"ref_schema,omitempty"` // schema of the ref table, when not the table's schema
 	RefTable  string  `json:"ref_table,omitempty"`  // table the foreign key refers to
 	RefFields []Field `json:"ref_column,omitempty"` // column in ref table the index refers to
 	Func      string  `json:"-"`                    // foreign key func name (based on fkey mode)
@@ -128,16 +319,41 @@ type ForeignKey struct {
 
 // Field is a column, index, enum value, or stored procedure parameter.
 type Field struct {
-	Name        string `json:"name,omitempty"`
-	Type        Type   `json:"datatype,omitempty"`
-	Default     string `json:"default,omitempty"`
-	IsPrimary   bool   `json:"is_primary,omitempty"`
-	IsSequence  bool   `json:"is_sequence,omitempty"`
-	ConstValue  *int   `json:"const_value,omitempty"`
-	Interpolate bool   `json:"interpolate,omitempty"`
-	Join        bool   `json:"join,omitempty"`
+	Name             string `json:"name,omitempty"`
+	Type             Type   `json:"datatype,omitempty"`
+	Default          string `json:"default,omitempty"`
+	IsPrimary        bool   `json:"is_primary,omitempty"`
+	IsSequence       bool   `json:"is_sequence,omitempty"`        // generated by a sequence or identity
+	SequenceName     string `json:"sequence_name,omitempty"`      // postgres only
+	IsIdentity       bool   `json:"is_identity,omitempty"`        // identity (or auto increment) column
+	IsIdentityAlways bool   `json:"is_identity_always,omitempty"` // GENERATED ALWAYS identity column (postgres only)
+	IsGenerated      bool   `json:"is_generated,omitempty"`
+	ConstValue       *int   `json:"const_value,omitempty"`
+	Alias            string `json:"alias,omitempty"` // identifier for enum values
+	Interpolate      bool   `json:"interpolate,omitempty"`
+	Join             bool   `json:"join,omitempty"`
+	Comment          string `json:"comment,omitempty"`
+	JSONType         string `json:"json_type,omitempty"` // concrete type for json columns
+	Sensitive        bool   `json:"sensitive,omitempty"` // redacted by templates
+}
+
+// CommentTag returns the value of a tag in a column comment, in the form
+// '@name' or '@name(value)', and whether the tag was found.
+//
+// For example, the comment 'user settings @json(Settings)' has the tag 'json'
+// with the value 'Settings'.
+func CommentTag(comment, name string) (string, bool) {
+	for _, m := range commentTagRE.FindAllStringSubmatch(comment, -1) {
+		if m[1] == name {
+			return strings.TrimSpace(m[2]), true
+		}
+	}
+	return "", false
 }
 
+// commentTagRE matches tags in comments.
+var commentTagRE = regexp.MustCompile(`(?:^|\s)@([a-z][a-z0-9_]*)(?:\(([^)]*)\))?`)
+
 // Type holds information for a database type.
 type Type struct {
 	Type     string `json:"type,omitempty"`
@@ -146,6 +362,8 @@ type Type struct {
 	Nullable bool   `json:"nullable,omitempty"`
 	IsArray  bool   `json:"array,omitempty"`
 	Unsigned bool   `json:"unsigned,omitempty"`
+	Geometry string `json:"geometry,omitempty"` // spatial subtype (ie, point)
+	SRID     int    `json:"srid,omitempty"`     // spatial reference system
 	Enum     *Enum  `json:"-"`
 }
 
@@ -159,9 +377,26 @@ type Type struct {
 //	type(precision, scale)
 //  type(precision, scale) unsigned
 //	timestamp(n) with [local] time zone (oracle only)
+//	geometry|geography(subtype[, srid]) (postgres only)
 //
 // The returned type is stripped of precision and scale.
 func ParseType(typ, driver string) (Type, error) {
+	// special case for postgis geometry|geography(subtype[, srid])
+	if m := spatialRE.FindStringSubmatch(typ); driver == "postgres" && m != nil {
+		var srid int
+		if m[3] != "" {
+			var err error
+			if srid, err = strconv.Atoi(m[3]); err != nil {
+				return Type{}, fmt.Errorf("could not parse srid: %w", err)
+			}
+		}
+		return Type{
+			Type:     m[1],
+			Geometry: strings.ToLower(m[2]),
+			SRID:     srid,
+			IsArray:  m[4] != "",
+		}, nil
+	}
 	// special case for oracle timestamp(n) with [local] time zone
 	if m := oracleTimestampRE.FindStringSubmatch(typ); driver == "oracle" && m != nil {
 		prec, err := strconv.Atoi(m[1])
@@ -210,13 +445,17 @@ func ParseType(typ, driver string) (Type, error) {
 	}, nil
 }
 
+// spatialRE is the regexp that matches "geometry|geography(subtype[, srid])"
+// definitions in postgres databases with postgis.
+var spatialRE = regexp.MustCompile(`^(geometry|geography)\(([A-Za-z]+)(?:,\s*([0-9]+))?\)(\[\])?$`)
+
 // oracleTimestampRE is the regexp that matches "timestamp(precision) with [local]
 // time zone" definitions in oracle databases.
 var oracleTimestampRE = regexp.MustCompile(`^timestamp\((\d)\) (with(?: local)? time zone)$`)
 
 // precRE is the regexp that matches "(precision[,scale])" definitions in a
-// database.
-var precRE = regexp.MustCompile(`\(([0-9]+)(\s*,\s*[0-9]+\s*)?\)$`)
+// database. The scale may be negative (oracle only).
+var precRE = regexp.MustCompile(`\(([0-9]+)(\s*,\s*-?[0-9]+\s*)?\)$`)
 
 // TemplateType is a template type.
 type TemplateType struct {
@@ -263,12 +502,20 @@ type ContextKey string
 
 // Context keys.
 const (
-	DriverKey    ContextKey = "driver"
-	DbKey        ContextKey = "db"
-	SchemaKey    ContextKey = "schema"
-	OutKey       ContextKey = "out"
-	SingleKey    ContextKey = "single"
-	ArrayModeKey ContextKey = "array-mode"
+	DriverKey     ContextKey = "driver"
+	DbKey         ContextKey = "db"
+	SchemaKey     ContextKey = "schema"
+	OutKey        ContextKey = "out"
+	SingleKey     ContextKey = "single"
+	JobsKey       ContextKey = "jobs"
+	ArrayModeKey  ContextKey = "array-mode"
+	FactoriesKey  ContextKey = "with-factories"
+	TestsKey      ContextKey = "with-tests"
+	TenantKey     ContextKey = "tenant"
+	NamingKey     ContextKey = "naming"
+	VersionKey    ContextKey = "version"
+	SourceKey     ContextKey = "source"
+	ProvenanceKey ContextKey = "provenance"
 )
 
 // DriverDbSchema returns the driver, database connection, and schema name from
@@ -292,6 +539,89 @@ func Single(ctx context.Context) string {
 	return s
 }
 
+// Jobs returns the number of files to process in parallel from the context.
+func Jobs(ctx context.Context) int {
+	if n, _ := ctx.Value(JobsKey).(int); n > 0 {
+		return n
+	}
+	return 1
+}
+
+// Factories returns true when factory funcs generating fake data for tests
+// are enabled in the context.
+func Factories(ctx context.Context) bool {
+	b, _ := ctx.Value(FactoriesKey).(bool)
+	return b
+}
+
+// Tests returns true when round trip tests for the generated code are enabled
+// in the context.
+func Tests(ctx context.Context) bool {
+	b, _ := ctx.Value(TestsKey).(bool)
+	return b
+}
+
+// Tenant returns the canonical schema of identically structured tenant
+// schemas from the context, or an empty string when not generating for
+// tenants.
+func Tenant(ctx context.Context) string {
+	s, _ := ctx.Value(TenantKey).(string)
+	return s
+}
+
+// Provenance is the provenance of generated files, written in the header of
+// each generated file.
+type Provenance struct {
+	// Template is the template type.
+	Template string
+	// Version is the xo version.
+	Version string
+	// Source is the database url, without credentials and with the host
+	// redacted.
+	Source string
+	// SchemaHash is the hash of the loaded set.
+	SchemaHash string
+	// Generated is the generation time, and is zero unless requested with
+	// --timestamp.
+	Generated time.Time
+}
+
+// Lines returns the provenance as header lines, omitting empty values.
+func (p Provenance) Lines() []string {
+	var lines []string
+	for _, v := range [][2]string{
+		{"Template", p.Template},
+		{"Version", p.Version},
+		{"Source", p.Source},
+		{"Schema", p.SchemaHash},
+	} {
+		if v[1] != "" {
+			lines = append(lines, v[0]+": "+v[1])
+		}
+	}
+	if !p.Generated.IsZero() {
+		lines = append(lines, "Generated: "+p.Generated.UTC().Format(time.RFC3339))
+	}
+	return lines
+}
+
+// ProvenanceOf returns the provenance of generated files from the context.
+func ProvenanceOf(ctx context.Context) Provenance {
+	p, _ := ctx.Value(ProvenanceKey).(Provenance)
+	return p
+}
+
+// HashSet returns a short hash of the set's content, for identifying the
+// schema used to generate files.
+func HashSet(set *Set) (string, error) {
+	buf, err := json.Marshal(set)
+	if err != nil {
+		return "", err
+	}
+	h := sha256.Sum256(buf)
+	return "sha256:" + hex.EncodeToString(h[:8]), nil
+}
+
 // forceLineEnd forces a \n on a string that doesn't contain one and is
 // non-empty.
 func forceLineEnd(s string) string {
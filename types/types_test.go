@@ -0,0 +1,141 @@
+package types
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestTablesByDependency(t *testing.T) {
+	tests := []struct {
+		s      string
+		exp    string
+		cycles [][]string
+	}{
+		{"a b c", "a b c", nil},
+		{"a:b b:c c", "c b a", nil},
+		{"a:b,c b c:b", "b c a", nil},
+		{"a:a b", "a b", [][]string{{"a"}}},
+		{"a:b b:a c:a", "b a c", [][]string{{"a", "b"}}},
+		{"a:b b:c c:a d e:d d2:x", "c b a d e d2", [][]string{{"a", "b", "c"}}},
+		{"a:b b:a c:d d:c", "b a d c", [][]string{{"a", "b"}, {"c", "d"}}},
+	}
+	for i, test := range tests {
+		schema := buildSchema(test.s)
+		var names []string
+		for _, table := range schema.TablesByDependency() {
+			names = append(names, table.Name)
+		}
+		if s := strings.Join(names, " "); s != test.exp {
+			t.Errorf("test %d (%q) expected %q, got: %q", i, test.s, test.exp, s)
+		}
+		if cycles := schema.ReferenceCycles(); !reflect.DeepEqual(cycles, test.cycles) {
+			t.Errorf("test %d (%q) expected cycles %v, got: %v", i, test.s, test.cycles, cycles)
+		}
+	}
+}
+
+// buildSchema builds a schema from a list of tables in the form
+// name[:ref,...], where ref is a table referred to by a foreign key.
+func buildSchema(s string) Schema {
+	var schema Schema
+	for _, f := range strings.Fields(s) {
+		name, refs, _ := strings.Cut(f, ":")
+		table := Table{Name: name}
+		if refs != "" {
+			for _, ref := range strings.Split(refs, ",") {
+				table.ForeignKeys = append(table.ForeignKeys, ForeignKey{RefTable: ref})
+			}
+		}
+		schema.Tables = append(schema.Tables, table)
+	}
+	return schema
+}
+
+func TestEnumAlias(t *testing.T) {
+	tests := []struct {
+		label string
+		exp   string
+	}{
+		{"active", "active"},
+		{"IN PROGRESS", "in_progress"},
+		{"in-progress", "in_progress"},
+		{"2fa_required", "v2fa_required"},
+		{"--a--b--", "a_b"},
+		{"v1.2", "v1_2"},
+		{"é", "value"},
+		{"", "value"},
+	}
+	for i, test := range tests {
+		if s := EnumAlias(test.label); s != test.exp {
+			t.Errorf("test %d (%q) expected %q, got: %q", i, test.label, test.exp, s)
+		}
+	}
+}
+
+func TestSetEnumAliases(t *testing.T) {
+	e := Enum{Name: "status"}
+	for _, label := range []string{"in-progress", "in_progress", "In Progress", "done", "in_progress_2"} {
+		e.Values = append(e.Values, Field{Name: label})
+	}
+	SetEnumAliases(&e, map[string]string{"done": "finished"})
+	var aliases []string
+	for _, v := range e.Values {
+		aliases = append(aliases, v.Alias)
+	}
+	exp := []string{"in_progress", "in_progress_2", "in_progress_3", "finished", "in_progress_2_2"}
+	if !reflect.DeepEqual(aliases, exp) {
+		t.Errorf("expected %v, got: %v", exp, aliases)
+	}
+}
+
+func TestCommentTag(t *testing.T) {
+	tests := []struct {
+		comment string
+		name    string
+		exp     string
+		ok      bool
+	}{
+		{"", "json", "", false},
+		{"@json(Settings)", "json", "Settings", true},
+		{"user settings @json( pkg.Settings ) @sensitive", "json", "pkg.Settings", true},
+		{"user settings @json(Settings) @sensitive", "sensitive", "", true},
+		{"mail@json(Settings)", "json", "", false},
+		{"@jsonb(Settings)", "json", "", false},
+	}
+	for i, test := range tests {
+		s, ok := CommentTag(test.comment, test.name)
+		if s != test.exp || ok != test.ok {
+			t.Errorf("test %d (%q) expected %q, %t, got: %q, %t", i, test.comment, test.exp, test.ok, s, ok)
+		}
+	}
+}
+
+func TestParseType(t *testing.T) {
+	tests := []struct {
+		typ    string
+		driver string
+		exp    Type
+	}{
+		{"integer", "postgres", Type{Type: "integer"}},
+		{"numeric(10,2)", "postgres", Type{Type: "numeric", Prec: 10, Scale: 2}},
+		{"character varying(255)[]", "postgres", Type{Type: "character varying", Prec: 255, IsArray: true}},
+		{"int(10) unsigned", "mysql", Type{Type: "int", Prec: 10, Unsigned: true}},
+		{"timestamp(6) with time zone", "oracle", Type{Type: "timestamp with time zone", Prec: 6}},
+		{"number(7,-3)", "oracle", Type{Type: "number", Prec: 7, Scale: -3}},
+		{"geometry", "postgres", Type{Type: "geometry"}},
+		{"geometry(Point,4326)", "postgres", Type{Type: "geometry", Geometry: "point", SRID: 4326}},
+		{"geography(MultiPolygonZ)", "postgres", Type{Type: "geography", Geometry: "multipolygonz"}},
+		{"geometry(LineString,3857)[]", "postgres", Type{Type: "geometry", Geometry: "linestring", SRID: 3857, IsArray: true}},
+		{"point", "mysql", Type{Type: "point"}},
+	}
+	for i, test := range tests {
+		typ, err := ParseType(test.typ, test.driver)
+		switch {
+		case err != nil:
+			t.Errorf("test %d (%s) expected no error, got: %v", i, test.typ, err)
+		case !reflect.DeepEqual(typ, test.exp):
+			t.Errorf("test %d (%s) expected %+v, got: %+v", i, test.typ, test.exp, typ)
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package xo
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestGenerator(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite3", name)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE authors (author_id INTEGER PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	ctx := context.Background()
+	out := filepath.Join(dir, "out")
+	if err := os.Mkdir(out, 0o755); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	g := New(Options{
+		URL:      "sq:" + name,
+		Template: "json",
+		Out:      out,
+	})
+	// load
+	set, err := g.Load(ctx)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if n := len(set.Schemas); n != 1 {
+		t.Fatalf("expected 1 schema, got: %d", n)
+	}
+	if tables := set.Schemas[0].Tables; len(tables) != 1 || tables[0].Name != "authors" {
+		t.Errorf("expected table authors, got: %v", tables)
+	}
+	// generate
+	if err := g.Generate(ctx); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	buf, err := os.ReadFile(filepath.Join(out, "xo.xo.json"))
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if s := string(buf); !strings.Contains(s, `"name": "authors"`) {
+		t.Errorf("expected authors in generated file, got: %s", s)
+	}
+	// invalid mode
+	if err := New(Options{URL: "sq:" + name, Mode: "bad"}).Generate(ctx); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+	// query mode without a query
+	if err := New(Options{URL: "sq:" + name, Mode: "query", Out: out}).Generate(ctx); err == nil || err.Error() != "must specify query" {
+		t.Errorf("expected must specify query error, got: %v", err)
+	}
+	if _, err := New(Options{URL: "sq:" + name, Mode: "query", Out: out}).Load(ctx); err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}
+
+func TestGeneratorNamedParams(t *testing.T) {
+	dir := t.TempDir()
+	name := filepath.Join(dir, "test.db")
+	db, err := sql.Open("sqlite3", name)
+	if err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if _, err := db.Exec(`CREATE TABLE authors (author_id INTEGER PRIMARY KEY, name TEXT NOT NULL)`); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatalf("expected no error, got: %v", err)
+	}
+	tests := []struct {
+		template string
+		file     string
+		exp      []string
+	}{
+		{"go", "authorresult.xo.go", []string{
+			"func AuthorResultsByAuthorIDName(ctx context.Context, db DB, authorID int, name string) ([]*AuthorResult, error) {",
+			"db.QueryContext(ctx, sqlstr, authorID, name)",
+		}},
+		{"python", "author_result.py", []string{
+			"def author_results_by_author_id_name(db: DB, author_id: int, name: str, *, ctx: Optional[Context] = None) -> list[AuthorResult]:",
+			"args = (author_id, name, author_id)",
+		}},
+	}
+	ctx := context.Background()
+	for i, test := range tests {
+		out := filepath.Join(dir, test.template, "models")
+		if err := os.MkdirAll(out, 0o755); err != nil {
+			t.Fatalf("test %d expected no error, got: %v", i, err)
+		}
+		g := New(Options{
+			URL:      "sq:" + name,
+			Mode:     "query",
+			Template: test.template,
+			Out:      out,
+			Query:    "SELECT author_id, name FROM authors WHERE author_id = :author_id OR (name = @name AND author_id <> :author_id)",
+			Args:     []string{"--named", "--param-type=author_id=int", "--param-type=name=string", "--type=AuthorResult"},
+		})
+		if err := g.Generate(ctx); err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.template, err)
+		}
+		buf, err := os.ReadFile(filepath.Join(out, test.file))
+		if err != nil {
+			t.Fatalf("test %d (%s) expected no error, got: %v", i, test.template, err)
+		}
+		for _, exp := range test.exp {
+			if s := string(buf); !strings.Contains(s, exp) {
+				t.Errorf("test %d (%s) expected %s to contain %q, got:\n%s", i, test.template, test.file, exp, s)
+			}
+		}
+	}
+}